@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"fluent-life-admin-api/internal/config"
+	"fluent-life-admin-api/internal/services"
+)
+
+// 检查脱敏练习步骤的弹窗配置是否符合 schema，列出不符合规范的步骤
+// 用法: go run cmd/audit-popup-configs/main.go [-module=<模块ID>] [-fix-empty]
+func main() {
+	moduleID := flag.String("module", "", "只检查指定模块")
+	fixEmpty := flag.Bool("fix-empty", false, "把为空的弹窗配置改写为 []")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+
+	report, err := services.AuditPopupConfigs(db, *moduleID, *fixEmpty)
+	if err != nil {
+		log.Fatalf("检查失败: %v", err)
+	}
+	log.Printf("步骤 %d 个：符合规范 %d 个，为空 %d 个（改写 %d 个），不符合规范 %d 个",
+		report.Checked, report.Valid, report.Empty, report.Fixed, len(report.Violations))
+	for _, v := range report.Violations {
+		log.Printf("✗ %s 第 %d 步「%s」(%s)", v.ModuleID, v.StepOrder, v.Title, v.StepID)
+		for _, e := range v.Errors {
+			log.Printf("    %s", e.Error())
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"fluent-life-admin-api/internal/config"
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+)
+
+// 重建 daily_metrics 历史汇总数据
+// 用法: go run cmd/backfill-rollups/main.go [-start 2025-01-01] [-end 2025-12-31]
+func main() {
+	startDate := flag.String("start", "", "开始日期 YYYY-MM-DD，默认为最早一条数据所在日期")
+	endDate := flag.String("end", "", "结束日期 YYYY-MM-DD（包含），默认为今天")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+
+	// 自动迁移
+	if err := models.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	rollup := services.NewRollupService(db)
+	start := *startDate
+	if start == "" {
+		earliest, err := rollup.EarliestDataDate()
+		if err != nil {
+			log.Fatalf("查询最早数据日期失败: %v", err)
+		}
+		start = earliest.Format("2006-01-02")
+	}
+
+	dateRange, err := services.NewDateRange(start, *endDate, 1)
+	if err != nil {
+		log.Fatalf("日期参数错误: %v", err)
+	}
+
+	log.Printf("开始回填统计汇总数据: %s ~ %s", dateRange.Start.Format("2006-01-02"), dateRange.LastDay().Format("2006-01-02"))
+	err = rollup.Backfill(dateRange, func(chunk services.DateRange) {
+		log.Printf("✓ 已完成 %s ~ %s", chunk.Start.Format("2006-01-02"), chunk.LastDay().Format("2006-01-02"))
+	})
+	if err != nil {
+		log.Fatalf("回填失败: %v", err)
+	}
+	log.Println("回填完成")
+}
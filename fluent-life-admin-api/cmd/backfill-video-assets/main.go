@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"fluent-life-admin-api/internal/config"
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+)
+
+// 根据训练记录与社区帖子重建 video_assets 视频索引
+// 用法: go run cmd/backfill-video-assets/main.go [-since 2025-01-01]
+func main() {
+	sinceDate := flag.String("since", "", "只同步该日期之后创建的训练记录、更新的帖子 YYYY-MM-DD，默认全量")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+
+	// 自动迁移
+	if err := models.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	var since time.Time
+	if *sinceDate != "" {
+		since, err = services.ParseDate(*sinceDate)
+		if err != nil {
+			log.Fatalf("日期参数错误，应为 YYYY-MM-DD: %v", err)
+		}
+	}
+
+	log.Println("开始同步视频索引...")
+	report, err := services.NewVideoAssetService(db).Sync(since)
+	if err != nil {
+		log.Fatalf("同步失败: %v", err)
+	}
+	log.Printf("同步完成: 训练记录视频 %d 个, 帖子视频 %d 个, 移除失效索引 %d 个", report.Records, report.Posts, report.Removed)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"fluent-life-admin-api/internal/config"
+	"fluent-life-admin-api/internal/services"
+)
+
+// 清理存储中没有帖子、训练记录或头像引用的媒体文件
+// 用法: go run cmd/gc-media/main.go [-prefix videos/] [-min-age 24h] [-delete]
+func main() {
+	prefix := flag.String("prefix", "", "只处理该前缀下的对象")
+	minAge := flag.Duration("min-age", 24*time.Hour, "只处理创建超过该时长的对象，为刚上传尚未写入记录的文件留出时间")
+	del := flag.Bool("delete", false, "实际删除；默认只列出无引用对象")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+
+	store, err := config.InitStorage(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init storage: %v", err)
+	}
+
+	media := services.NewMediaService(db, store, cfg.Storage.PublicBaseURL, nil, "")
+	report, err := media.GC(context.Background(), *prefix, *minAge, !*del)
+	if err != nil {
+		log.Fatalf("清理失败: %v", err)
+	}
+	for _, obj := range report.Samples {
+		log.Printf("无引用: %s (%d 字节, %s)", obj.Key, obj.Size, obj.LastModified.Format("2006-01-02 15:04:05"))
+	}
+	for _, e := range report.Errors {
+		log.Printf("删除失败: %s", e)
+	}
+	log.Printf("扫描 %d 个对象，无引用 %d 个（%d 字节），已删除 %d 个", report.Scanned, report.Orphans.Objects, report.Orphans.Bytes, report.Deleted)
+	if !*del {
+		log.Println("预览模式，未删除任何文件；确认后加 -delete 执行删除")
+	}
+}
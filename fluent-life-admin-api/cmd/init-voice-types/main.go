@@ -1,17 +1,30 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+
 	"fluent-life-admin-api/internal/config"
 	"fluent-life-admin-api/internal/models"
-	"github.com/google/uuid"
+	"fluent-life-admin-api/internal/services"
 )
 
+// 按供应商音色清单新增或更新音色，不删除已有音色
+// 用法: go run cmd/init-voice-types/main.go [-catalog configs/voice_catalog.json|https://...] [-disable-missing] [-dry-run]
 func main() {
+	source := flag.String("catalog", "", "音色清单的文件路径或 http(s) 地址，默认为 VOICE_CATALOG_SOURCE")
+	disableMissing := flag.Bool("disable-missing", false, "停用不在清单中的音色")
+	dryRun := flag.Bool("dry-run", false, "只预览变更，不写入数据库")
+	flag.Parse()
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if *source == "" {
+		*source = cfg.VoiceCatalogSource
+	}
 
 	db, err := config.InitDB(cfg)
 	if err != nil {
@@ -23,83 +36,40 @@ func main() {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
-	// 删除所有现有音色数据
-	log.Println("开始删除所有现有音色数据...")
-	result := db.Exec("DELETE FROM voice_types")
-	if result.Error != nil {
-		log.Fatalf("删除音色数据失败: %v", result.Error)
+	catalog, err := services.LoadVoiceCatalog(context.Background(), *source)
+	if err != nil {
+		log.Fatalf("加载音色清单失败: %v", err)
 	}
-	log.Printf("✓ 已删除 %d 条音色数据", result.RowsAffected)
+	log.Printf("从 %s 读取到 %d 个音色", *source, len(catalog.Voices))
 
-	// 火山引擎豆包语音合成模型 2.0 常用音色列表（8个推荐音色）
-	// 参考文档: https://www.volcengine.com/docs/6561/1257544?lang=zh
-	voiceTypes := []models.VoiceType{
-		{
-			Name:        "Vivi 2.0 通用",
-			Type:        "zh_female_vv_uranus_bigtts",
-			Description: "通用女声，中文/英语混合，适合多语言场景",
-			Enabled:     true,
-		},
-		{
-			Name:        "小何 2.0 通用",
-			Type:        "zh_female_xiaohe_uranus_bigtts",
-			Description: "通用女声，中文，适合日常对话和内容朗读",
-			Enabled:     true,
-		},
-		{
-			Name:        "云舟 2.0 通用",
-			Type:        "zh_male_m191_uranus_bigtts",
-			Description: "通用男声，中文，适合知识讲解和正式场合",
-			Enabled:     true,
-		},
-		{
-			Name:        "儿童绘本",
-			Type:        "zh_female_xueayi_saturn_bigtts",
-			Description: "精品克隆音色，适合儿童内容、绘本朗读",
-			Enabled:     true,
-		},
-		{
-			Name:        "大壹",
-			Type:        "zh_male_dayi_saturn_bigtts",
-			Description: "精品克隆音色，适合角色扮演和内容创作",
-			Enabled:     true,
-		},
-		{
-			Name:        "黑猫侦探社咪",
-			Type:        "zh_female_mizai_saturn_bigtts",
-			Description: "精品克隆音色，适合故事讲述和角色配音",
-			Enabled:     true,
-		},
-		{
-			Name:        "鸡汤女",
-			Type:        "zh_female_jitangnv_saturn_bigtts",
-			Description: "精品克隆音色，适合情感表达和心灵鸡汤类内容",
-			Enabled:     true,
-		},
-		{
-			Name:        "魅力女友",
-			Type:        "zh_female_meilinvyou_saturn_bigtts",
-			Description: "精品克隆音色，适合视频配音和互动场景",
-			Enabled:     true,
-		},
+	report, err := services.NewVoiceCatalogService(db).Sync(catalog, *disableMissing, *dryRun)
+	if err != nil {
+		log.Fatalf("同步音色失败: %v", err)
 	}
-
-	log.Println("开始插入新的音色类型...")
-
-	// 批量创建新音色类型
-	for _, vt := range voiceTypes {
-		// 设置ID
-		if vt.ID == uuid.Nil {
-			vt.ID = uuid.New()
-		}
-
-		// 创建新音色类型
-		if err := db.Create(&vt).Error; err != nil {
-			log.Fatalf("创建音色类型 %s 失败: %v", vt.Name, err)
+	for _, t := range report.Created {
+		log.Printf("新增: %s", t)
+	}
+	for _, t := range report.Updated {
+		log.Printf("更新: %s", t)
+	}
+	for _, t := range report.Disabled {
+		log.Printf("停用: %s", t)
+	}
+	for _, t := range report.Missing {
+		log.Printf("不在清单中（保留）: %s", t)
+	}
+	for _, u := range report.Unavailable {
+		log.Printf("⚠ 音色 %s（%s）仍被 %d 个用户、%d 个AI角色使用", u.Type, u.Status, u.UserCount, len(u.Roles))
+		for _, r := range u.Roles {
+			log.Printf("    AI角色: %s (%s)", r.Name, r.ID)
 		}
-
-		log.Printf("✓ 成功创建音色类型: %s (%s)", vt.Name, vt.Type)
 	}
-
-	log.Printf("音色类型初始化完成！共插入 %d 个音色", len(voiceTypes))
+	if len(report.Unavailable) > 0 {
+		log.Println("可通过 POST /api/v1/admin/voice-types/remap 把这些引用批量替换为可用音色")
+	}
+	log.Printf("音色同步完成：新增 %d 个，更新 %d 个，未变化 %d 个，停用 %d 个",
+		len(report.Created), len(report.Updated), report.Unchanged, len(report.Disabled))
+	if *dryRun {
+		log.Println("预览模式，未写入数据库")
+	}
 }
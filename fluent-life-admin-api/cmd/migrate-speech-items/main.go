@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"fluent-life-admin-api/internal/config"
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+)
+
+// 把语音技巧中 JSON 字符串形式的训练要点与练习文本迁移为条目
+// 用法: go run cmd/migrate-speech-items/main.go [-dry-run]
+func main() {
+	dryRun := flag.Bool("dry-run", false, "只检查不写入")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+
+	// 自动迁移
+	if err := models.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	report, err := services.NewSpeechItemService(db).MigrateLegacy(*dryRun)
+	if err != nil {
+		log.Fatalf("迁移失败: %v", err)
+	}
+	log.Printf("语音技巧 %d 个：迁移 %d 个，已迁移 %d 个；新建训练要点 %d 条、练习文本 %d 条",
+		report.Techniques, report.Migrated, report.AlreadyDone, report.TipsCreated, report.TextsCreated)
+	for _, f := range report.Failures {
+		log.Printf("✗ %s (%s) 的 %s 无法解析: %s\n  原始内容: %s", f.Name, f.TechniqueID, f.Field, f.Error, f.Raw)
+	}
+	if *dryRun {
+		log.Println("dry-run 模式，未写入任何数据")
+	} else if len(report.Failures) > 0 {
+		log.Printf("%d 个字段未迁移，修正后可重新执行", len(report.Failures))
+	} else {
+		log.Println("迁移完成")
+	}
+}
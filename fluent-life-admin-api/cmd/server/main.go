@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"fluent-life-admin-api/internal/config"
 	"fluent-life-admin-api/internal/handlers"
 	"fluent-life-admin-api/internal/middleware"
 	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/mailer"
 	"fluent-life-admin-api/pkg/response"
+	"fluent-life-admin-api/pkg/tts"
+	"fluent-life-admin-api/pkg/videoanalysis"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"     // Import uuid
@@ -54,6 +60,33 @@ func main() {
 		}
 	}
 
+	// 定时汇总仪表盘统计数据到 daily_metrics
+	if cfg.RollupIntervalMinutes > 0 {
+		go services.NewRollupService(db).RunScheduler(context.Background(), time.Duration(cfg.RollupIntervalMinutes)*time.Minute)
+	}
+
+	// 定时同步视频索引 video_assets
+	if cfg.VideoSyncIntervalMinutes > 0 {
+		go services.NewVideoAssetService(db).RunScheduler(context.Background(), time.Duration(cfg.VideoSyncIntervalMinutes)*time.Minute)
+	}
+
+	// 定时报表
+	reportMailer := mailer.New(mailer.Config{
+		Host:     cfg.SMTP.Host,
+		Port:     cfg.SMTP.Port,
+		Username: cfg.SMTP.Username,
+		Password: cfg.SMTP.Password,
+		From:     cfg.SMTP.From,
+	})
+	go services.NewReportService(db, reportMailer).RunScheduler(context.Background())
+
+	// 脱敏模块发布：为已有模块建立发布状态，定时发布与下线
+	exposureReleases := services.NewExposureReleaseService(db)
+	if err := exposureReleases.Bootstrap(); err != nil {
+		log.Printf("Failed to bootstrap exposure module releases: %v", err)
+	}
+	go exposureReleases.RunScheduler(context.Background())
+
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -65,10 +98,35 @@ func main() {
 		response.Success(c, gin.H{"status": "ok"}, "服务运行正常")
 	})
 
-	adminHandler := handlers.NewAdminHandler(db)
+	// 媒体文件存储（本地目录或 S3 兼容存储）
+	store, err := config.InitStorage(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init storage: %v", err)
+	}
+	media := services.NewMediaService(db, store, cfg.Storage.PublicBaseURL, config.InitMediaSigner(cfg), cfg.Media.ProxyURL)
+
+	ttsProvider, err := tts.New(cfg.TTSProvider)
+	if err != nil {
+		log.Fatalf("Failed to init TTS provider: %v", err)
+	}
+	adminHandler := handlers.NewAdminHandler(db, media, cfg.VoiceCatalogSource, services.NewVoicePreviewService(db, media, ttsProvider))
 	exposureModuleHandler := handlers.NewAdminExposureModuleHandler(db)
-	adminVideoHandler := handlers.NewAdminVideoHandler(db)
+	videoAnalyzer, err := videoanalysis.New(cfg.VideoAnalyzer)
+	if err != nil {
+		log.Fatalf("Failed to init video analyzer: %v", err)
+	}
+	adminVideoHandler := handlers.NewAdminVideoHandler(db, media, services.NewVideoAnalysisService(db, media, videoAnalyzer))
 	adminPermissionHandler := handlers.NewAdminPermissionHandler(db)
+	adminAnalyticsHandler := handlers.NewAdminAnalyticsHandler(db)
+	adminReportHandler := handlers.NewAdminReportHandler(db, reportMailer)
+	adminExportHandler := handlers.NewAdminExportHandler(db, media, cfg.ExportDir, time.Duration(cfg.ExportRetentionHours)*time.Hour)
+	go adminExportHandler.RunCleanup(context.Background())
+	adminTranslationHandler := handlers.NewAdminTranslationHandler(db)
+	adminExperimentHandler := handlers.NewAdminExperimentHandler(db)
+	adminStorageHandler := handlers.NewAdminStorageHandler(media)
+	contentHandler := handlers.NewContentHandler(db)
+	notificationHandler := handlers.NewNotificationHandler(db)
+	mediaHandler := handlers.NewMediaHandler(media)
 
 	api := r.Group("/api/v1")
 	{
@@ -78,6 +136,34 @@ func main() {
 		// 测试根路由
 		api.GET("/test-root", adminHandler.TestRoute)
 
+		// 今日朗诵文案（公开，按排期及回退规则解析）
+		api.GET("/daily-expressions/today", adminHandler.GetTodayExpression)
+
+		// 练习与帮助内容（公开，按 locale 参数或 Accept-Language 返回译文）
+		api.GET("/tongue-twisters", contentHandler.GetTongueTwisters)
+		api.GET("/speech-techniques", contentHandler.GetSpeechTechniques)
+		api.GET("/help-categories", contentHandler.GetHelpCenter)
+		api.GET("/exposure-modules", contentHandler.GetExposureModules)
+
+		// A/B 实验（需要用户登录）
+		experiments := api.Group("/experiments")
+		experiments.Use(middleware.UserAuthMiddleware(db))
+		{
+			experiments.GET("/assignments", contentHandler.GetExperimentAssignments)
+			experiments.POST("/:id/exposures", contentHandler.RecordExperimentExposure)
+		}
+
+		// 管理端播放用户媒体的签名链接（签名绑定管理员，不需要 Authorization 头）
+		api.GET("/media", mediaHandler.ServeMedia)
+
+		// 站内通知（需要用户登录）
+		notifications := api.Group("/notifications")
+		notifications.Use(middleware.UserAuthMiddleware(db))
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.POST("/:id/read", notificationHandler.MarkNotificationRead)
+		}
+
 		// 需要认证的管理接口（简化版，实际应该使用JWT中间件）
 		admin := api.Group("/admin")
 		admin.Use(middleware.UserAuthMiddleware(db))
@@ -114,6 +200,27 @@ func main() {
 			admin.PUT("/training/records/:id", adminHandler.UpdateTrainingRecord)
 			admin.POST("/training/records/delete-batch", adminHandler.DeleteTrainingRecord)
 
+			// 数据分析
+			admin.GET("/analytics/timeseries", adminAnalyticsHandler.GetTimeSeries)
+			admin.GET("/analytics/retention", adminAnalyticsHandler.GetRetention)
+			admin.GET("/analytics/exposure-funnel", adminAnalyticsHandler.GetExposureFunnels)
+
+			// 定时报表
+			admin.GET("/reports", adminReportHandler.GetReportSchedules)
+			admin.GET("/reports/:id", adminReportHandler.GetReportSchedule)
+			admin.POST("/reports", adminReportHandler.CreateReportSchedule)
+			admin.PUT("/reports/:id", adminReportHandler.UpdateReportSchedule)
+			admin.DELETE("/reports/:id", adminReportHandler.DeleteReportSchedule)
+			admin.POST("/reports/:id/send", adminReportHandler.SendReportNow)
+			admin.GET("/reports/:id/runs", adminReportHandler.GetReportRuns)
+
+			// 数据导出
+			admin.GET("/export", adminExportHandler.GetExportResources)
+			admin.GET("/export/:resource", adminExportHandler.Export)
+			admin.GET("/export-jobs", adminExportHandler.GetExportJobs)
+			admin.GET("/export-jobs/:id", adminExportHandler.GetExportJob)
+			admin.GET("/export-jobs/:id/download", adminExportHandler.DownloadExportJob)
+
 			// 随机匹配记录
 			admin.GET("/random-match", adminHandler.GetRandomMatchRecords)
 
@@ -146,9 +253,14 @@ func main() {
 			admin.POST("/tongue-twisters/delete-batch", adminHandler.DeleteTongueTwister)
 			admin.DELETE("/tongue-twisters/all", adminHandler.DeleteAllTongueTwisters)
 			admin.POST("/tongue-twisters/clean", adminHandler.CleanTongueTwisters)
+			admin.POST("/tongue-twisters/analyze", adminHandler.AnalyzeTongueTwister)
+			admin.POST("/tongue-twisters/regrade", adminHandler.RegradeTongueTwisters)
 
 			// 每日朗诵文案管理
 			admin.GET("/daily-expressions", adminHandler.GetDailyExpressions)
+			admin.GET("/daily-expressions/calendar", adminHandler.GetExpressionCalendar)
+			admin.POST("/daily-expressions/auto-schedule", adminHandler.AutoScheduleExpressions)
+			admin.GET("/daily-expressions/today", adminHandler.GetTodayExpression)
 			admin.GET("/daily-expressions/:id", adminHandler.GetDailyExpression)
 			admin.POST("/daily-expressions", adminHandler.CreateDailyExpression)
 			admin.POST("/daily-expressions/batch-create", adminHandler.BatchCreateDailyExpressions)
@@ -162,6 +274,43 @@ func main() {
 			admin.POST("/speech-techniques/batch-create", adminHandler.BatchCreateSpeechTechniques)
 			admin.PUT("/speech-techniques/:id", adminHandler.UpdateSpeechTechnique)
 			admin.POST("/speech-techniques/delete-batch", adminHandler.DeleteSpeechTechnique)
+			admin.POST("/speech-techniques/migrate-items", adminHandler.MigrateSpeechItems)
+			admin.GET("/speech-techniques/:id/:kind", adminHandler.GetSpeechItems)
+			admin.POST("/speech-techniques/:id/:kind", adminHandler.CreateSpeechItem)
+			admin.POST("/speech-techniques/:id/:kind/reorder", adminHandler.ReorderSpeechItems)
+			admin.PUT("/speech-techniques/:id/:kind/:item_id", adminHandler.UpdateSpeechItem)
+			admin.DELETE("/speech-techniques/:id/:kind/:item_id", adminHandler.DeleteSpeechItem)
+
+			// 练习内容批量导入
+			admin.GET("/content-import/:kind/fields", adminHandler.GetImportFields)
+			admin.POST("/content-import/:kind", adminHandler.ImportContent)
+			admin.GET("/content-imports", adminHandler.GetContentImports)
+			admin.GET("/content-imports/:id/errors", adminHandler.DownloadImportErrors)
+
+			// 练习内容近似重复审核
+			admin.GET("/content-duplicates", adminHandler.GetContentDuplicates)
+			admin.POST("/content-duplicates/:id/keep", adminHandler.KeepContentDuplicate)
+			admin.POST("/content-duplicates/:id/merge", adminHandler.MergeContentDuplicate)
+
+			// 内容版本管理（绕口令、每日朗诵文案、语音技巧、法律文档）
+			admin.GET("/content-versions/:type/:id", adminHandler.GetContentVersions)
+			admin.POST("/content-versions/:type/:id/drafts", adminHandler.CreateContentDraft)
+			admin.POST("/content-versions/:type/:id/revert", adminHandler.RevertContent)
+			admin.GET("/content-revisions/:id", adminHandler.GetContentRevision)
+			admin.PUT("/content-revisions/:id", adminHandler.UpdateContentDraft)
+			admin.DELETE("/content-revisions/:id", adminHandler.DeleteContentDraft)
+			admin.POST("/content-revisions/:id/submit", adminHandler.SubmitContentRevision)
+			admin.POST("/content-revisions/:id/approve", adminHandler.ApproveContentRevision)
+			admin.POST("/content-revisions/:id/reject", adminHandler.RejectContentRevision)
+			admin.GET("/content-revisions/:id/diff", adminHandler.GetContentRevisionDiff)
+			admin.GET("/content-reviews", adminHandler.GetContentReviews)
+
+			// 内容翻译
+			admin.GET("/translations/config", adminTranslationHandler.GetTranslationConfig)
+			admin.GET("/translations/missing", adminTranslationHandler.GetMissingTranslations)
+			admin.GET("/translations/:type/:id", adminTranslationHandler.GetContentTranslations)
+			admin.PUT("/translations/:type/:id/:locale", adminTranslationHandler.SaveContentTranslations)
+			admin.DELETE("/translations/:type/:id/:locale", adminTranslationHandler.DeleteContentTranslations)
 
 			// 成就管理
 			admin.POST("/achievements", adminHandler.CreateAchievement)
@@ -223,6 +372,10 @@ func main() {
 			admin.POST("/voice-types", adminHandler.CreateVoiceType)
 			admin.PUT("/voice-types/:id", adminHandler.UpdateVoiceType)
 			admin.DELETE("/voice-types/:id", adminHandler.DeleteVoiceType)
+			admin.POST("/voice-types/:id/preview", adminHandler.PreviewVoiceType)
+			admin.GET("/voice-types/usage", adminHandler.GetVoiceTypeUsage)
+			admin.POST("/voice-types/sync", adminHandler.SyncVoiceTypes)
+			admin.POST("/voice-types/remap", adminHandler.RemapVoiceTypes)
 
 			// 脱敏练习管理
 			exposureManagement := admin.Group("/exposure")
@@ -232,9 +385,22 @@ func main() {
 				exposureManagement.POST("/modules", exposureModuleHandler.CreateModule)
 				// 批量更新顺序必须在 /modules/:id 之前，否则会匹配到 :id
 				exposureManagement.PUT("/modules/order", exposureModuleHandler.BatchUpdateModulesOrder)
+				exposureManagement.GET("/modules/export", exposureModuleHandler.ExportModules)
+				exposureManagement.POST("/modules/import", exposureModuleHandler.ImportModules)
 				exposureManagement.GET("/modules/:id", exposureModuleHandler.GetModule)
 				exposureManagement.PUT("/modules/:id", exposureModuleHandler.UpdateModule)
 				exposureManagement.DELETE("/modules/:id", exposureModuleHandler.DeleteModule)
+				exposureManagement.GET("/modules/:id/funnel", exposureModuleHandler.GetModuleFunnel)
+				exposureManagement.POST("/modules/:id/clone", exposureModuleHandler.CloneModule)
+
+				// 发布与版本
+				exposureManagement.GET("/modules/:id/release", exposureModuleHandler.GetModuleRelease)
+				exposureManagement.POST("/modules/:id/publish", exposureModuleHandler.PublishModule)
+				exposureManagement.POST("/modules/:id/unpublish", exposureModuleHandler.UnpublishModule)
+				exposureManagement.PUT("/modules/:id/schedule", exposureModuleHandler.ScheduleModule)
+				exposureManagement.POST("/modules/:id/rollback", exposureModuleHandler.RollbackModule)
+				exposureManagement.GET("/modules/:id/snapshots", exposureModuleHandler.GetModuleSnapshots)
+				exposureManagement.GET("/modules/:id/snapshots/:version", exposureModuleHandler.GetModuleSnapshot)
 
 				// 步骤管理
 				exposureManagement.GET("/modules/:id/steps", exposureModuleHandler.GetModuleSteps)
@@ -242,13 +408,42 @@ func main() {
 				exposureManagement.PUT("/modules/:id/steps/order", exposureModuleHandler.BatchUpdateStepsOrder)
 				exposureManagement.PUT("/steps/:step_id", exposureModuleHandler.UpdateStep)
 				exposureManagement.DELETE("/steps/:step_id", exposureModuleHandler.DeleteStep)
+
+				// 步骤类型
+				exposureManagement.GET("/step-types", exposureModuleHandler.GetStepTypes)
+
+				// 弹窗配置校验
+				exposureManagement.GET("/popup-configs/schema", exposureModuleHandler.GetPopupConfigsSchema)
+				exposureManagement.POST("/popup-configs/validate", exposureModuleHandler.ValidatePopupConfigs)
+				exposureManagement.POST("/popup-configs/audit", exposureModuleHandler.AuditPopupConfigs)
 			}
 
+			// A/B 实验
+			admin.GET("/experiments", adminExperimentHandler.GetExperiments)
+			admin.POST("/experiments", adminExperimentHandler.CreateExperiment)
+			admin.GET("/experiments/:id", adminExperimentHandler.GetExperiment)
+			admin.PUT("/experiments/:id", adminExperimentHandler.UpdateExperiment)
+			admin.DELETE("/experiments/:id", adminExperimentHandler.DeleteExperiment)
+			admin.POST("/experiments/:id/start", adminExperimentHandler.StartExperiment)
+			admin.POST("/experiments/:id/stop", adminExperimentHandler.StopExperiment)
+			admin.GET("/experiments/:id/results", adminExperimentHandler.GetExperimentResults)
+
 			// 视频管理
 			admin.GET("/videos", adminVideoHandler.GetVideoList)
 			admin.GET("/videos/:id", adminVideoHandler.GetVideoDetail)
 			admin.DELETE("/videos/:id", adminVideoHandler.DeleteVideo)
 			admin.POST("/videos/batch-delete", adminVideoHandler.BatchDeleteVideos)
+			admin.GET("/videos/moderation/queue", adminVideoHandler.GetModerationQueue)
+			admin.POST("/videos/:id/review", adminVideoHandler.ReviewVideo)
+			admin.POST("/videos/batch-review", adminVideoHandler.BatchReviewVideos)
+			admin.POST("/videos/:id/analyze", adminVideoHandler.AnalyzeVideo)
+			admin.GET("/video-analyses", adminVideoHandler.GetVideoAnalyses)
+			admin.GET("/video-analyses/:id", adminVideoHandler.GetVideoAnalysis)
+
+			// 媒体存储
+			admin.GET("/storage/usage", adminStorageHandler.GetStorageUsage)
+			admin.POST("/storage/gc", adminStorageHandler.RunStorageGC)
+			admin.GET("/media/access-logs", adminStorageHandler.GetMediaAccessLogs)
 
 			// 权限管理 - 角色管理
 			admin.GET("/roles", adminPermissionHandler.GetRoles)
@@ -1,10 +1,16 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
+	"strconv"
 	"time"
 
+	"fluent-life-admin-api/pkg/signedurl"
+	"fluent-life-admin-api/pkg/storage"
+
 	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -22,6 +28,55 @@ type Config struct {
 		Name     string `mapstructure:"DB_NAME"`
 		SSLMode  string `mapstructure:"DB_SSLMODE"`
 	} `mapstructure:",squash"`
+
+	// 定时报表发信使用的 SMTP 服务，本地开发默认连接 MailHog
+	SMTP struct {
+		Host     string `mapstructure:"SMTP_HOST"`
+		Port     string `mapstructure:"SMTP_PORT"`
+		Username string `mapstructure:"SMTP_USERNAME"`
+		Password string `mapstructure:"SMTP_PASSWORD"`
+		From     string `mapstructure:"SMTP_FROM"`
+	} `mapstructure:",squash"`
+
+	// 异步导出文件的存放目录
+	ExportDir string `mapstructure:"EXPORT_DIR"`
+	// 异步导出文件的保留时长（小时），过期后删除文件
+	ExportRetentionHours int `mapstructure:"EXPORT_RETENTION_HOURS"`
+
+	// 统计汇总任务间隔（分钟），0 表示不在服务内运行
+	RollupIntervalMinutes int `mapstructure:"ROLLUP_INTERVAL_MINUTES"`
+
+	// 视频索引同步间隔（分钟），0 表示不在服务内运行
+	VideoSyncIntervalMinutes int `mapstructure:"VIDEO_SYNC_INTERVAL_MINUTES"`
+
+	// 媒体文件存储，driver 为 local 或 s3（S3 兼容存储，本地开发使用 MinIO）
+	Storage struct {
+		Driver        string `mapstructure:"STORAGE_DRIVER"`
+		PublicBaseURL string `mapstructure:"STORAGE_PUBLIC_BASE_URL"` // 帖子与训练记录中媒体地址的前缀
+		LocalDir      string `mapstructure:"STORAGE_LOCAL_DIR"`
+		S3Endpoint    string `mapstructure:"S3_ENDPOINT"`
+		S3Region      string `mapstructure:"S3_REGION"`
+		S3Bucket      string `mapstructure:"S3_BUCKET"`
+		S3AccessKey   string `mapstructure:"S3_ACCESS_KEY"`
+		S3SecretKey   string `mapstructure:"S3_SECRET_KEY"`
+		S3PathStyle   bool   `mapstructure:"S3_USE_PATH_STYLE"`
+	} `mapstructure:",squash"`
+
+	// 管理端播放用户媒体的签名链接
+	Media struct {
+		SigningSecret string `mapstructure:"MEDIA_SIGNING_SECRET"`  // 为空时每次启动随机生成，重启后旧链接失效
+		URLTTLMinutes int    `mapstructure:"MEDIA_URL_TTL_MINUTES"` // 链接有效期
+		ProxyURL      string `mapstructure:"MEDIA_PROXY_URL"`       // 代理接口的完整地址，为空时按请求的 Host 生成
+	} `mapstructure:",squash"`
+
+	// 管理端重新分析视频使用的分析器，目前只有本地模拟分析器 stub
+	VideoAnalyzer string `mapstructure:"VIDEO_ANALYZER"`
+
+	// 音色清单的本地文件路径或 http(s) 地址，同步音色时使用
+	VoiceCatalogSource string `mapstructure:"VOICE_CATALOG_SOURCE"`
+
+	// 音色试听使用的语音合成服务，目前只有本地模拟合成 stub
+	TTSProvider string `mapstructure:"TTS_PROVIDER"`
 }
 
 func Load() (*Config, error) {
@@ -58,6 +113,22 @@ func setDefaults() {
 	viper.SetDefault("DB_PASSWORD", "postgres")
 	viper.SetDefault("DB_NAME", "fluent_life")
 	viper.SetDefault("DB_SSLMODE", "disable")
+	viper.SetDefault("SMTP_HOST", "localhost")
+	viper.SetDefault("SMTP_PORT", "1025")
+	viper.SetDefault("SMTP_FROM", "report@fluentlife.local")
+	viper.SetDefault("EXPORT_DIR", "./data/exports")
+	viper.SetDefault("EXPORT_RETENTION_HOURS", 72)
+	viper.SetDefault("ROLLUP_INTERVAL_MINUTES", 15)
+	viper.SetDefault("VIDEO_SYNC_INTERVAL_MINUTES", 5)
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_PUBLIC_BASE_URL", "/uploads/")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./data/uploads")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_USE_PATH_STYLE", true)
+	viper.SetDefault("MEDIA_URL_TTL_MINUTES", 30)
+	viper.SetDefault("VIDEO_ANALYZER", "stub")
+	viper.SetDefault("VOICE_CATALOG_SOURCE", "./configs/voice_catalog.json")
+	viper.SetDefault("TTS_PROVIDER", "stub")
 }
 
 func overrideFromEnv(cfg *Config) {
@@ -85,6 +156,88 @@ func overrideFromEnv(cfg *Config) {
 	if sslMode := os.Getenv("DB_SSLMODE"); sslMode != "" {
 		cfg.Database.SSLMode = sslMode
 	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		cfg.SMTP.Host = host
+	}
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		cfg.SMTP.Port = port
+	}
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		cfg.SMTP.Username = username
+	}
+	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
+		cfg.SMTP.Password = password
+	}
+	if from := os.Getenv("SMTP_FROM"); from != "" {
+		cfg.SMTP.From = from
+	}
+	if dir := os.Getenv("EXPORT_DIR"); dir != "" {
+		cfg.ExportDir = dir
+	}
+	if retention := os.Getenv("EXPORT_RETENTION_HOURS"); retention != "" {
+		if hours, err := strconv.Atoi(retention); err == nil {
+			cfg.ExportRetentionHours = hours
+		}
+	}
+	if interval := os.Getenv("ROLLUP_INTERVAL_MINUTES"); interval != "" {
+		if minutes, err := strconv.Atoi(interval); err == nil {
+			cfg.RollupIntervalMinutes = minutes
+		}
+	}
+	if interval := os.Getenv("VIDEO_SYNC_INTERVAL_MINUTES"); interval != "" {
+		if minutes, err := strconv.Atoi(interval); err == nil {
+			cfg.VideoSyncIntervalMinutes = minutes
+		}
+	}
+	if driver := os.Getenv("STORAGE_DRIVER"); driver != "" {
+		cfg.Storage.Driver = driver
+	}
+	if baseURL := os.Getenv("STORAGE_PUBLIC_BASE_URL"); baseURL != "" {
+		cfg.Storage.PublicBaseURL = baseURL
+	}
+	if dir := os.Getenv("STORAGE_LOCAL_DIR"); dir != "" {
+		cfg.Storage.LocalDir = dir
+	}
+	if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+		cfg.Storage.S3Endpoint = endpoint
+	}
+	if region := os.Getenv("S3_REGION"); region != "" {
+		cfg.Storage.S3Region = region
+	}
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		cfg.Storage.S3Bucket = bucket
+	}
+	if accessKey := os.Getenv("S3_ACCESS_KEY"); accessKey != "" {
+		cfg.Storage.S3AccessKey = accessKey
+	}
+	if secretKey := os.Getenv("S3_SECRET_KEY"); secretKey != "" {
+		cfg.Storage.S3SecretKey = secretKey
+	}
+	if pathStyle := os.Getenv("S3_USE_PATH_STYLE"); pathStyle != "" {
+		if v, err := strconv.ParseBool(pathStyle); err == nil {
+			cfg.Storage.S3PathStyle = v
+		}
+	}
+	if secret := os.Getenv("MEDIA_SIGNING_SECRET"); secret != "" {
+		cfg.Media.SigningSecret = secret
+	}
+	if ttl := os.Getenv("MEDIA_URL_TTL_MINUTES"); ttl != "" {
+		if minutes, err := strconv.Atoi(ttl); err == nil {
+			cfg.Media.URLTTLMinutes = minutes
+		}
+	}
+	if proxyURL := os.Getenv("MEDIA_PROXY_URL"); proxyURL != "" {
+		cfg.Media.ProxyURL = proxyURL
+	}
+	if analyzer := os.Getenv("VIDEO_ANALYZER"); analyzer != "" {
+		cfg.VideoAnalyzer = analyzer
+	}
+	if source := os.Getenv("VOICE_CATALOG_SOURCE"); source != "" {
+		cfg.VoiceCatalogSource = source
+	}
+	if provider := os.Getenv("TTS_PROVIDER"); provider != "" {
+		cfg.TTSProvider = provider
+	}
 }
 
 func InitDB(cfg *Config) (*gorm.DB, error) {
@@ -129,3 +282,40 @@ func InitDB(cfg *Config) (*gorm.DB, error) {
 	return db, nil
 }
 
+// InitStorage 按配置创建媒体存储；S3 存储的 bucket 不存在时自动创建（本地 MinIO）
+func InitStorage(cfg *Config) (storage.Storage, error) {
+	store, err := storage.New(storage.Config{
+		Driver:         cfg.Storage.Driver,
+		PublicBaseURL:  cfg.Storage.PublicBaseURL,
+		LocalDir:       cfg.Storage.LocalDir,
+		S3Endpoint:     cfg.Storage.S3Endpoint,
+		S3Region:       cfg.Storage.S3Region,
+		S3Bucket:       cfg.Storage.S3Bucket,
+		S3AccessKey:    cfg.Storage.S3AccessKey,
+		S3SecretKey:    cfg.Storage.S3SecretKey,
+		S3UsePathStyle: cfg.Storage.S3PathStyle,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init storage: %w", err)
+	}
+	if s3, ok := store.(*storage.S3); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s3.EnsureBucket(ctx); err != nil {
+			return nil, fmt.Errorf("failed to ensure bucket: %w", err)
+		}
+	}
+	return store, nil
+}
+
+// InitMediaSigner 创建媒体播放链接的签名器
+func InitMediaSigner(cfg *Config) *signedurl.Signer {
+	if cfg.Media.SigningSecret == "" {
+		log.Println("MEDIA_SIGNING_SECRET 未设置，使用随机密钥，重启后已签发的播放链接失效")
+	}
+	ttl := time.Duration(cfg.Media.URLTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = 30 * time.Minute
+	}
+	return signedurl.New(cfg.Media.SigningSecret, ttl)
+}
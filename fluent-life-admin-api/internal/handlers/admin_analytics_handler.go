@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminAnalyticsHandler 管理员数据分析处理器
+type AdminAnalyticsHandler struct {
+	db        *gorm.DB
+	analytics *services.AnalyticsService
+	retention *services.RetentionService
+	funnel    *services.FunnelService
+}
+
+// NewAdminAnalyticsHandler 创建管理员数据分析处理器
+func NewAdminAnalyticsHandler(db *gorm.DB) *AdminAnalyticsHandler {
+	return &AdminAnalyticsHandler{
+		db:        db,
+		analytics: services.NewAnalyticsService(db),
+		retention: services.NewRetentionService(db),
+		funnel:    services.NewFunnelService(db),
+	}
+}
+
+// splitQueryList 解析逗号分隔的查询参数，忽略空项
+func splitQueryList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GetTimeSeries 获取按天/周/月聚合的时间序列统计
+// GET /api/v1/admin/analytics/timeseries?start_date=2026-01-01&end_date=2026-01-31&granularity=day&metrics=new_users,active_users
+func (h *AdminAnalyticsHandler) GetTimeSeries(c *gin.Context) {
+	granularity, err := services.ParseGranularity(c.Query("granularity"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dateRange, err := services.NewDateRange(c.Query("start_date"), c.Query("end_date"), 30)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	metrics := splitQueryList(c.Query("metrics"))
+	for _, metric := range metrics {
+		if !services.IsKnownMetric(metric) {
+			response.Error(c, http.StatusBadRequest, "不支持的指标: "+metric)
+			return
+		}
+	}
+
+	result, err := h.analytics.TimeSeries(dateRange, granularity, metrics)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取统计数据失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, result, "获取成功")
+}
+
+// GetRetention 获取注册队列留存三角（按注册日或注册周分组）
+// GET /api/v1/admin/analytics/retention?cohort=week&start_date=&end_date=&periods=12&gender=&language=&format=csv
+func (h *AdminAnalyticsHandler) GetRetention(c *gin.Context) {
+	granularity, err := services.ParseGranularity(c.DefaultQuery("cohort", "week"))
+	if err != nil || granularity == services.GranularityMonth {
+		response.Error(c, http.StatusBadRequest, "cohort 仅支持 day 或 week")
+		return
+	}
+
+	defaultDays, defaultPeriods := 56, 12
+	if granularity == services.GranularityDay {
+		defaultDays, defaultPeriods = 30, 30
+	}
+
+	dateRange, err := services.NewDateRange(c.Query("start_date"), c.Query("end_date"), defaultDays)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	periods, _ := strconv.Atoi(c.DefaultQuery("periods", strconv.Itoa(defaultPeriods)))
+	if periods < 1 || periods > 90 {
+		periods = defaultPeriods
+	}
+
+	filter := services.RetentionFilter{
+		Gender:   c.Query("gender"),
+		Language: c.Query("language"),
+	}
+
+	result, err := h.retention.Cohorts(dateRange, granularity, periods, filter)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "计算留存失败: "+err.Error())
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		filename := "retention_" + result.StartDate + "_" + result.EndDate + ".csv"
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		if err := result.WriteCSV(c.Writer); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	response.Success(c, result, "获取成功")
+}
+
+// GetExposureFunnels 获取全部脱敏练习模块的步骤完成漏斗
+// GET /api/v1/admin/analytics/exposure-funnel?start_date=2026-01-01&end_date=2026-01-31
+func (h *AdminAnalyticsHandler) GetExposureFunnels(c *gin.Context) {
+	dateRange, err := services.NewDateRange(c.Query("start_date"), c.Query("end_date"), 30)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	funnels, err := h.funnel.AllModuleFunnels(dateRange)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "计算漏斗失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"funnels": funnels}, "获取成功")
+}
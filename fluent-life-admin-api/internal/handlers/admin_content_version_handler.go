@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// contentActor 当前管理员及其是否有发布权限
+func (h *AdminHandler) contentActor(c *gin.Context) services.Actor {
+	var actorID uuid.UUID
+	if userID, ok := c.Get("userID"); ok {
+		actorID = userID.(uuid.UUID)
+	}
+	role, _ := c.Get("userRole")
+	roleCode, _ := role.(string)
+	return services.NewContentVersionService(h.db).Actor(actorID, roleCode)
+}
+
+// respondVersionError 把版本管理的错误转换为对应的 HTTP 状态码
+func respondVersionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRevisionNotFound), errors.Is(err, services.ErrContentNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrRevisionConflict), errors.Is(err, services.ErrRevisionState):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrNoPublishPerm), errors.Is(err, services.ErrNotDraftOwner):
+		response.Error(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrRevisionInvalid):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "操作失败: "+err.Error())
+	}
+}
+
+// saveContentVersion 内容编辑统一走版本流程：生成草稿并提交。
+// 有发布权限时立即发布并把最新内容读入 live 返回；否则返回待审核的版本
+func (h *AdminHandler) saveContentVersion(c *gin.Context, contentType string, id uuid.UUID, changes map[string]interface{}, live interface{}) {
+	svc := services.NewContentVersionService(h.db)
+	actor := h.contentActor(c)
+	draft, err := svc.CreateDraft(contentType, id, changes, c.Query("note"), actor)
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	rev, err := svc.Submit(draft.ID, actor)
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+
+	if rev.Status != services.RevisionPublished {
+		h.logOperation(c, "submit", contentType, id.String(), "提交修改待审核: "+rev.ID.String(), "success")
+		response.Success(c, rev, "已提交审核，发布后生效")
+		return
+	}
+	if err := h.db.Where("id = ?", id).First(live).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取更新后的内容失败")
+		return
+	}
+	h.logOperation(c, "update", contentType, id.String(), fmt.Sprintf("发布版本 %d", rev.Number), "success")
+	response.Success(c, live, "更新成功")
+}
+
+// GetContentVersions 获取内容的版本历史（含未发布的草稿）
+// GET /api/v1/admin/content-versions/:type/:id
+func (h *AdminHandler) GetContentVersions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的内容ID")
+		return
+	}
+	revisions, err := services.NewContentVersionService(h.db).History(c.Param("type"), id)
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	response.Success(c, revisions, "获取成功")
+}
+
+// CreateContentDraft 基于最新发布版本创建草稿，data 只需包含要修改的字段
+// POST /api/v1/admin/content-versions/:type/:id/drafts  {"data": {"title": "..."}, "note": "..."}
+func (h *AdminHandler) CreateContentDraft(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的内容ID")
+		return
+	}
+	var req struct {
+		Data map[string]interface{} `json:"data"`
+		Note string                 `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	draft, err := services.NewContentVersionService(h.db).CreateDraft(c.Param("type"), id, req.Data, req.Note, h.contentActor(c))
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	h.logOperation(c, "create_draft", c.Param("type"), id.String(), "创建草稿: "+draft.ID.String(), "success")
+	response.Success(c, draft, "草稿已创建")
+}
+
+// RevertContent 回滚到指定的发布版本（生成新版本，有发布权限时立即生效，否则进入审核）
+// POST /api/v1/admin/content-versions/:type/:id/revert  {"number": 3, "note": "..."}
+func (h *AdminHandler) RevertContent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的内容ID")
+		return
+	}
+	var req struct {
+		Number int    `json:"number" binding:"required,min=1"`
+		Note   string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误，需要提供版本号 number")
+		return
+	}
+
+	rev, err := services.NewContentVersionService(h.db).Revert(c.Param("type"), id, req.Number, req.Note, h.contentActor(c))
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	h.logOperation(c, "revert", c.Param("type"), id.String(), fmt.Sprintf("回滚到版本 %d", req.Number), "success")
+	if rev.Status == services.RevisionPublished {
+		response.Success(c, rev, "回滚成功")
+		return
+	}
+	response.Success(c, rev, "回滚已提交审核，发布后生效")
+}
+
+// GetContentRevision 获取版本详情
+// GET /api/v1/admin/content-revisions/:id
+func (h *AdminHandler) GetContentRevision(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的版本ID")
+		return
+	}
+	rev, err := services.NewContentVersionService(h.db).Get(id)
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	response.Success(c, rev, "获取成功")
+}
+
+// UpdateContentDraft 修改草稿；rebase 为 true 时把草稿的修改合并到最新发布版本上（发布冲突后使用）
+// PUT /api/v1/admin/content-revisions/:id  {"data": {...}, "note": "...", "rebase": false}
+func (h *AdminHandler) UpdateContentDraft(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的版本ID")
+		return
+	}
+	var req struct {
+		Data   map[string]interface{} `json:"data"`
+		Note   *string                `json:"note"`
+		Rebase bool                   `json:"rebase"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	draft, err := services.NewContentVersionService(h.db).UpdateDraft(id, req.Data, req.Note, req.Rebase, h.contentActor(c))
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	response.Success(c, draft, "草稿已保存")
+}
+
+// DeleteContentDraft 删除未发布的草稿
+// DELETE /api/v1/admin/content-revisions/:id
+func (h *AdminHandler) DeleteContentDraft(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的版本ID")
+		return
+	}
+	if err := services.NewContentVersionService(h.db).DiscardDraft(id, h.contentActor(c)); err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	response.Success(c, nil, "删除成功")
+}
+
+// SubmitContentRevision 提交草稿：有发布权限时直接发布，否则进入审核队列
+// POST /api/v1/admin/content-revisions/:id/submit
+func (h *AdminHandler) SubmitContentRevision(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的版本ID")
+		return
+	}
+	rev, err := services.NewContentVersionService(h.db).Submit(id, h.contentActor(c))
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	if rev.Status == services.RevisionPublished {
+		h.logOperation(c, "publish", rev.ContentType, rev.ContentID.String(), fmt.Sprintf("发布版本 %d", rev.Number), "success")
+		response.Success(c, rev, "发布成功")
+		return
+	}
+	h.logOperation(c, "submit", rev.ContentType, rev.ContentID.String(), "提交修改待审核: "+rev.ID.String(), "success")
+	response.Success(c, rev, "已提交审核")
+}
+
+// ApproveContentRevision 审核通过并发布（需要 content:publish 权限）
+// POST /api/v1/admin/content-revisions/:id/approve  {"comment": "..."}
+func (h *AdminHandler) ApproveContentRevision(c *gin.Context) {
+	h.reviewContentRevision(c, true)
+}
+
+// RejectContentRevision 驳回修改（需要 content:publish 权限）
+// POST /api/v1/admin/content-revisions/:id/reject  {"comment": "..."}
+func (h *AdminHandler) RejectContentRevision(c *gin.Context) {
+	h.reviewContentRevision(c, false)
+}
+
+func (h *AdminHandler) reviewContentRevision(c *gin.Context, approve bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的版本ID")
+		return
+	}
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	svc := services.NewContentVersionService(h.db)
+	var rev *models.ContentRevision
+	if approve {
+		rev, err = svc.Approve(id, req.Comment, h.contentActor(c))
+	} else {
+		rev, err = svc.Reject(id, req.Comment, h.contentActor(c))
+	}
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	if approve {
+		h.logOperation(c, "approve", rev.ContentType, rev.ContentID.String(), fmt.Sprintf("审核通过并发布版本 %d", rev.Number), "success")
+		response.Success(c, rev, "审核通过，已发布")
+		return
+	}
+	h.logOperation(c, "reject", rev.ContentType, rev.ContentID.String(), "驳回修改: "+req.Comment, "success")
+	response.Success(c, rev, "已驳回")
+}
+
+// GetContentRevisionDiff 比较两个版本的差异；不指定 against 时与最新发布版本比较
+// GET /api/v1/admin/content-revisions/:id/diff?against=<revision_id>
+func (h *AdminHandler) GetContentRevisionDiff(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的版本ID")
+		return
+	}
+	var against *uuid.UUID
+	if v := c.Query("against"); v != "" {
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的对比版本ID")
+			return
+		}
+		against = &parsed
+	}
+
+	diff, err := services.NewContentVersionService(h.db).Diff(id, against)
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+	response.Success(c, diff, "获取成功")
+}
+
+// GetContentReviews 待审核的内容修改
+// GET /api/v1/admin/content-reviews?type=tongue_twister&page=1&page_size=20
+func (h *AdminHandler) GetContentReviews(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	contentType := c.Query("type")
+	if contentType != "" && !services.ValidContentType(contentType) {
+		response.Error(c, http.StatusBadRequest, "不支持的内容类型")
+		return
+	}
+
+	revisions, total, err := services.NewContentVersionService(h.db).ReviewQueue(contentType, page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取审核队列失败")
+		return
+	}
+	response.Success(c, gin.H{
+		"revisions": revisions,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gradeTongueTwister 按内容计算难度分数，未指定难度时使用建议级别
+func gradeTongueTwister(t *models.TongueTwister) {
+	analysis := services.AnalyzeDifficulty(t.Content)
+	t.DifficultyScore = analysis.Score
+	if t.Level == "" {
+		t.Level = analysis.SuggestedLevel
+	}
+}
+
+// AnalyzeTongueTwister 分析绕口令文本的拼音难度（编辑时预览）
+// POST /api/v1/admin/tongue-twisters/analyze  {"content": "..."}
+func (h *AdminHandler) AnalyzeTongueTwister(c *gin.Context) {
+	var req struct {
+		Content string `json:"content" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误，需要提供绕口令内容")
+		return
+	}
+
+	response.Success(c, services.AnalyzeDifficulty(req.Content), "分析成功")
+}
+
+// RegradeTongueTwisters 按拼音分析批量重新评定绕口令难度
+// POST /api/v1/admin/tongue-twisters/regrade  {"ids": [], "dry_run": true, "include_low_confidence": false}，ids 为空时处理全部
+func (h *AdminHandler) RegradeTongueTwisters(c *gin.Context) {
+	var req struct {
+		IDs                  []string `json:"ids"`
+		DryRun               bool     `json:"dry_run"`
+		IncludeLowConfidence bool     `json:"include_low_confidence"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := services.NewDifficultyService(h.db).Regrade(req.IDs, req.DryRun, req.IncludeLowConfidence)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if req.DryRun {
+		response.Success(c, result, "预览成功")
+		return
+	}
+	details := fmt.Sprintf("重新评级 %d 条，级别变更 %d 条，覆盖率不足跳过 %d 条", result.Total, result.Changed, result.Skipped)
+	h.logOperation(c, "RegradeTongueTwisters", "tongue_twister", "", details, "Success")
+	response.Success(c, result, "评级完成")
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetContentDuplicates 获取近似重复内容簇（绕口令、每日朗诵文案、语音技巧练习文本）
+// GET /api/v1/admin/content-duplicates?kind=tongue_twister&threshold=0.8&include_reviewed=false&page=1&page_size=20
+func (h *AdminHandler) GetContentDuplicates(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	threshold, _ := strconv.ParseFloat(c.Query("threshold"), 64)
+	threshold, err := services.ValidDuplicateThreshold(threshold)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	kind := c.Query("kind")
+	switch kind {
+	case "", services.DuplicateKindTongueTwister, services.DuplicateKindDailyExpression, services.DuplicateKindPracticeText:
+	default:
+		response.Error(c, http.StatusBadRequest, "不支持的内容类型")
+		return
+	}
+	includeReviewed, _ := strconv.ParseBool(c.DefaultQuery("include_reviewed", "false"))
+
+	clusters, err := services.NewDuplicateService(h.db).Clusters(threshold, kind)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "检测重复内容失败: "+err.Error())
+		return
+	}
+	// 默认只返回待审核的簇（已确认保留的簇成员不变时不再出现）
+	if !includeReviewed {
+		pending := clusters[:0]
+		for _, cluster := range clusters {
+			if cluster.Review == nil {
+				pending = append(pending, cluster)
+			}
+		}
+		clusters = pending
+	}
+
+	total := len(clusters)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	response.Success(c, gin.H{
+		"clusters":  clusters[start:end],
+		"threshold": threshold,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// KeepContentDuplicate 确认簇内内容不是重复，全部保留
+// POST /api/v1/admin/content-duplicates/:id/keep  {"threshold": 0.8, "note": "..."}
+func (h *AdminHandler) KeepContentDuplicate(c *gin.Context) {
+	var req struct {
+		Threshold float64 `json:"threshold"`
+		Note      string  `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	threshold, err := services.ValidDuplicateThreshold(req.Threshold)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var reviewedBy uuid.UUID
+	if userID, ok := c.Get("userID"); ok {
+		reviewedBy = userID.(uuid.UUID)
+	}
+
+	review, err := services.NewDuplicateService(h.db).Keep(c.Param("id"), threshold, req.Note, reviewedBy)
+	if err != nil {
+		h.respondDuplicateError(c, err)
+		return
+	}
+	h.logOperation(c, "KeepContentDuplicate", "content_duplicate", review.Fingerprint, "确认保留: "+review.Members, "Success")
+	response.Success(c, review, "已标记为保留")
+}
+
+// MergeContentDuplicate 合并近似重复内容：保留一条，删除其余
+// POST /api/v1/admin/content-duplicates/:id/merge  {"threshold": 0.8, "keep": "tongue_twister:<id>", "remove": ["..."], "note": "..."}
+func (h *AdminHandler) MergeContentDuplicate(c *gin.Context) {
+	var req struct {
+		Threshold float64  `json:"threshold"`
+		Keep      string   `json:"keep" binding:"required"`
+		Remove    []string `json:"remove"`
+		Note      string   `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误，需要指定保留项 keep")
+		return
+	}
+	threshold, err := services.ValidDuplicateThreshold(req.Threshold)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var reviewedBy uuid.UUID
+	if userID, ok := c.Get("userID"); ok {
+		reviewedBy = userID.(uuid.UUID)
+	}
+
+	review, err := services.NewDuplicateService(h.db).Merge(c.Param("id"), threshold, req.Keep, req.Remove, req.Note, reviewedBy)
+	if err != nil {
+		h.logOperation(c, "MergeContentDuplicate", "content_duplicate", c.Param("id"), "合并失败: "+err.Error(), "Failure")
+		h.respondDuplicateError(c, err)
+		return
+	}
+	details := fmt.Sprintf("保留 %s，删除 %s", review.KeepKey, review.Removed)
+	h.logOperation(c, "MergeContentDuplicate", "content_duplicate", review.Fingerprint, details, "Success")
+	response.Success(c, review, "合并成功")
+}
+
+func (h *AdminHandler) respondDuplicateError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrDuplicateClusterChanged) {
+		response.Error(c, http.StatusConflict, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminExperimentHandler 管理员 A/B 实验处理器
+type AdminExperimentHandler struct {
+	db *gorm.DB
+}
+
+// NewAdminExperimentHandler 创建管理员 A/B 实验处理器
+func NewAdminExperimentHandler(db *gorm.DB) *AdminExperimentHandler {
+	return &AdminExperimentHandler{db: db}
+}
+
+func respondExperimentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrExperimentInvalid):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrExperimentNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrExperimentState), errors.Is(err, services.ErrExperimentConflict):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "操作失败: "+err.Error())
+	}
+}
+
+// experimentID 解析路径中的实验ID，无效时直接响应 400
+func experimentID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的实验ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetExperiments 获取实验列表
+// GET /api/v1/admin/experiments?status=running&target_type=exposure_step&page=1&page_size=20
+func (h *AdminExperimentHandler) GetExperiments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	experiments, total, err := services.NewExperimentService(h.db).List(c.Query("status"), c.Query("target_type"), page, pageSize)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"experiments": experiments,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+	}, "获取成功")
+}
+
+// GetExperiment 获取实验详情
+// GET /api/v1/admin/experiments/:id
+func (h *AdminExperimentHandler) GetExperiment(c *gin.Context) {
+	id, ok := experimentID(c)
+	if !ok {
+		return
+	}
+	experiment, err := services.NewExperimentService(h.db).Get(id)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"experiment": experiment}, "获取成功")
+}
+
+// CreateExperiment 创建实验（草稿状态），第一个分组为对照组
+// POST /api/v1/admin/experiments
+//
+//	{"name": "...", "target_type": "exposure_step", "target_id": "<step_id>", "window_hours": 168,
+//	 "variants": [{"key": "control"}, {"key": "short_guide", "weight": 1, "overrides": {"guide_content": "..."}}]}
+func (h *AdminExperimentHandler) CreateExperiment(c *gin.Context) {
+	var req services.ExperimentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	experiment, err := services.NewExperimentService(h.db).Create(req, currentAdminID(c))
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"experiment": experiment}, "创建成功")
+}
+
+// UpdateExperiment 修改未开始的实验
+// PUT /api/v1/admin/experiments/:id
+func (h *AdminExperimentHandler) UpdateExperiment(c *gin.Context) {
+	id, ok := experimentID(c)
+	if !ok {
+		return
+	}
+	var req services.ExperimentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	experiment, err := services.NewExperimentService(h.db).Update(id, req)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"experiment": experiment}, "更新成功")
+}
+
+// DeleteExperiment 删除实验及其曝光记录
+// DELETE /api/v1/admin/experiments/:id
+func (h *AdminExperimentHandler) DeleteExperiment(c *gin.Context) {
+	id, ok := experimentID(c)
+	if !ok {
+		return
+	}
+	if err := services.NewExperimentService(h.db).Delete(id); err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, nil, "删除成功")
+}
+
+// StartExperiment 开始实验
+// POST /api/v1/admin/experiments/:id/start
+func (h *AdminExperimentHandler) StartExperiment(c *gin.Context) {
+	id, ok := experimentID(c)
+	if !ok {
+		return
+	}
+	experiment, err := services.NewExperimentService(h.db).Start(id)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"experiment": experiment}, "实验已开始")
+}
+
+// StopExperiment 停止实验
+// POST /api/v1/admin/experiments/:id/stop
+func (h *AdminExperimentHandler) StopExperiment(c *gin.Context) {
+	id, ok := experimentID(c)
+	if !ok {
+		return
+	}
+	experiment, err := services.NewExperimentService(h.db).Stop(id)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"experiment": experiment}, "实验已停止")
+}
+
+// GetExperimentResults 各组的曝光人数、转化率及 95% 置信区间
+// GET /api/v1/admin/experiments/:id/results
+func (h *AdminExperimentHandler) GetExperimentResults(c *gin.Context) {
+	id, ok := experimentID(c)
+	if !ok {
+		return
+	}
+	results, err := services.NewExperimentService(h.db).Results(id)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, results, "获取成功")
+}
@@ -0,0 +1,509 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize 导出时每批查询的行数
+const exportBatchSize = 500
+
+// exportColumnInfo 可导出列的描述，返回给前端用于列选择
+type exportColumnInfo struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// exportSigner 为导出的媒体地址签发当前管理员的播放链接（见 mediaSigner）
+type exportSigner func(rawURL, videoSource string, videoID uuid.UUID) string
+
+// exportDataset 一个可导出的列表
+type exportDataset interface {
+	Title(lang string) string
+	Columns(lang string) []exportColumnInfo
+	Count(db *gorm.DB, params url.Values) (int64, error)
+	// Stream 按列表默认顺序（创建时间倒序）逐行输出选中列的值，媒体列经 sign 替换为签名链接
+	Stream(db *gorm.DB, params url.Values, keys []string, sign exportSigner, emit func([]string) error) error
+}
+
+// exportColumn 类型为 T 的列表中的一列
+type exportColumn[T any] struct {
+	Key   string
+	ZH    string
+	EN    string
+	Value func(row *T) string
+}
+
+// tableDataset 基于 gorm 模型的导出列表，复用对应列表接口的筛选函数
+type tableDataset[T any] struct {
+	table   string
+	zh, en  string
+	filter  func(query *gorm.DB, params url.Values) *gorm.DB
+	preload []string
+	// timeColumn 游标使用的时间列，为空时使用 created_at
+	timeColumn string
+	cursor     func(row *T) (time.Time, uuid.UUID)
+	columns    []exportColumn[T]
+	// media 保存媒体地址的列及其来源；导出时替换为签名链接，不输出原始地址
+	media map[string]string
+}
+
+func (d *tableDataset[T]) Title(lang string) string {
+	if lang == langEN {
+		return d.en
+	}
+	return d.zh
+}
+
+func (d *tableDataset[T]) Columns(lang string) []exportColumnInfo {
+	infos := make([]exportColumnInfo, 0, len(d.columns))
+	for _, col := range d.columns {
+		label := col.ZH
+		if lang == langEN {
+			label = col.EN
+		}
+		infos = append(infos, exportColumnInfo{Key: col.Key, Label: label})
+	}
+	return infos
+}
+
+func (d *tableDataset[T]) Count(db *gorm.DB, params url.Values) (int64, error) {
+	var total int64
+	err := d.filter(db.Model(new(T)), params).Count(&total).Error
+	return total, err
+}
+
+// Stream 使用 (时间列, id) 游标分批查询，避免深分页的 OFFSET 开销
+func (d *tableDataset[T]) Stream(db *gorm.DB, params url.Values, keys []string, sign exportSigner, emit func([]string) error) error {
+	timeColumn := d.table + ".created_at"
+	if d.timeColumn != "" {
+		timeColumn = d.table + "." + d.timeColumn
+	}
+	selected := make([]exportColumn[T], 0, len(keys))
+	for _, key := range keys {
+		for _, col := range d.columns {
+			if col.Key == key {
+				selected = append(selected, col)
+				break
+			}
+		}
+	}
+
+	var (
+		lastTime time.Time
+		lastID   uuid.UUID
+		first    = true
+	)
+	for {
+		query := d.filter(db.Model(new(T)), params)
+		for _, p := range d.preload {
+			query = query.Preload(p)
+		}
+		if !first {
+			query = query.Where("("+timeColumn+", "+d.table+".id) < (?, ?)", lastTime, lastID)
+		}
+
+		var batch []T
+		err := query.Order(timeColumn + " DESC, " + d.table + ".id DESC").Limit(exportBatchSize).Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			values := make([]string, len(selected))
+			for j, col := range selected {
+				values[j] = col.Value(&batch[i])
+				if source, ok := d.media[col.Key]; ok && values[j] != "" {
+					_, id := d.cursor(&batch[i])
+					values[j] = sign(values[j], source, id)
+				}
+			}
+			if err := emit(values); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		lastTime, lastID = d.cursor(&batch[len(batch)-1])
+		first = false
+	}
+}
+
+// columnKeys 返回列表全部列的 key
+func columnKeys(d exportDataset) []string {
+	infos := d.Columns(langZH)
+	keys := make([]string, len(infos))
+	for i, info := range infos {
+		keys[i] = info.Key
+	}
+	return keys
+}
+
+// 导出单元格格式化
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(services.ReportLocation).Format("2006-01-02 15:04:05")
+}
+
+func exportTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return exportTime(*t)
+}
+
+func exportStringPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// exportDate 日期列（如每日朗诵文案的发布日期）
+func exportDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// noFilter 没有筛选条件的列表
+func noFilter(query *gorm.DB, params url.Values) *gorm.DB {
+	return query
+}
+
+// exportDatasets 支持导出的列表，key 与列表接口路径一致
+var exportDatasets = map[string]exportDataset{
+	"users": &tableDataset[models.User]{
+		table: "users", zh: "用户列表", en: "Users",
+		filter: filterUsers,
+		cursor: func(r *models.User) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.User]{
+			{"id", "用户ID", "User ID", func(r *models.User) string { return r.ID.String() }},
+			{"username", "用户名", "Username", func(r *models.User) string { return r.Username }},
+			{"email", "邮箱", "Email", func(r *models.User) string { return exportStringPtr(r.Email) }},
+			{"phone", "手机号", "Phone", func(r *models.User) string { return exportStringPtr(r.Phone) }},
+			{"gender", "性别", "Gender", func(r *models.User) string { return exportStringPtr(r.Gender) }},
+			{"role", "角色", "Role", func(r *models.User) string { return r.Role }},
+			{"status", "状态", "Status", func(r *models.User) string { return strconv.Itoa(r.Status) }},
+			{"created_at", "注册时间", "Created At", func(r *models.User) string { return exportTime(r.CreatedAt) }},
+			{"last_login_at", "最后登录时间", "Last Login At", func(r *models.User) string { return exportTimePtr(r.LastLoginAt) }},
+		},
+	},
+	"posts": &tableDataset[models.Post]{
+		table: "posts", zh: "帖子列表", en: "Posts",
+		filter:  filterPosts,
+		preload: []string{"User"},
+		cursor:  func(r *models.Post) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		media:   map[string]string{"image": services.VideoSourcePost},
+		columns: []exportColumn[models.Post]{
+			{"id", "帖子ID", "Post ID", func(r *models.Post) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.Post) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.Post) string { return r.User.Username }},
+			{"content", "内容", "Content", func(r *models.Post) string { return r.Content }},
+			{"tag", "标签", "Tag", func(r *models.Post) string { return r.Tag }},
+			{"image", "图片/视频", "Image/Video", func(r *models.Post) string { return r.Image }},
+			{"likes_count", "点赞数", "Likes", func(r *models.Post) string { return strconv.Itoa(r.LikesCount) }},
+			{"comments_count", "评论数", "Comments", func(r *models.Post) string { return strconv.Itoa(r.CommentsCount) }},
+			{"created_at", "发布时间", "Created At", func(r *models.Post) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"rooms": &tableDataset[models.PracticeRoom]{
+		table: "practice_rooms", zh: "练习房间", en: "Practice Rooms",
+		filter:  filterRooms,
+		preload: []string{"User"},
+		cursor:  func(r *models.PracticeRoom) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.PracticeRoom]{
+			{"id", "房间ID", "Room ID", func(r *models.PracticeRoom) string { return r.ID.String() }},
+			{"title", "标题", "Title", func(r *models.PracticeRoom) string { return r.Title }},
+			{"theme", "主题", "Theme", func(r *models.PracticeRoom) string { return r.Theme }},
+			{"type", "类型", "Type", func(r *models.PracticeRoom) string { return r.Type }},
+			{"username", "创建者", "Owner", func(r *models.PracticeRoom) string { return r.User.Username }},
+			{"max_members", "最大成员数", "Max Members", func(r *models.PracticeRoom) string { return strconv.Itoa(r.MaxMembers) }},
+			{"current_members", "当前成员数", "Current Members", func(r *models.PracticeRoom) string { return strconv.Itoa(r.CurrentMembers) }},
+			{"is_active", "是否活跃", "Active", func(r *models.PracticeRoom) string { return strconv.FormatBool(r.IsActive) }},
+			{"created_at", "创建时间", "Created At", func(r *models.PracticeRoom) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"training_records": &tableDataset[models.TrainingRecord]{
+		table: "training_records", zh: "训练记录", en: "Training Records",
+		filter:  filterTrainingRecords,
+		preload: []string{"User"},
+		cursor:  func(r *models.TrainingRecord) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.TrainingRecord]{
+			{"id", "记录ID", "Record ID", func(r *models.TrainingRecord) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.TrainingRecord) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.TrainingRecord) string { return r.User.Username }},
+			{"type", "训练类型", "Type", func(r *models.TrainingRecord) string { return r.Type }},
+			{"duration", "时长（秒）", "Duration (s)", func(r *models.TrainingRecord) string { return strconv.Itoa(r.Duration) }},
+			{"timestamp", "训练时间", "Timestamp", func(r *models.TrainingRecord) string { return exportTime(r.Timestamp) }},
+			{"created_at", "创建时间", "Created At", func(r *models.TrainingRecord) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"comments": &tableDataset[models.Comment]{
+		table: "comments", zh: "评论列表", en: "Comments",
+		filter:  filterComments,
+		preload: []string{"User"},
+		cursor:  func(r *models.Comment) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.Comment]{
+			{"id", "评论ID", "Comment ID", func(r *models.Comment) string { return r.ID.String() }},
+			{"post_id", "帖子ID", "Post ID", func(r *models.Comment) string { return r.PostID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.Comment) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.Comment) string { return r.User.Username }},
+			{"content", "内容", "Content", func(r *models.Comment) string { return r.Content }},
+			{"likes_count", "点赞数", "Likes", func(r *models.Comment) string { return strconv.Itoa(r.LikesCount) }},
+			{"created_at", "评论时间", "Created At", func(r *models.Comment) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"follows": &tableDataset[models.Follow]{
+		table: "follows", zh: "关注关系", en: "Follows",
+		filter:  filterFollows,
+		preload: []string{"Follower", "Followee"},
+		cursor:  func(r *models.Follow) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.Follow]{
+			{"id", "ID", "ID", func(r *models.Follow) string { return r.ID.String() }},
+			{"follower_id", "关注者ID", "Follower ID", func(r *models.Follow) string { return r.FollowerID.String() }},
+			{"follower", "关注者", "Follower", func(r *models.Follow) string { return r.Follower.Username }},
+			{"followee_id", "被关注者ID", "Followee ID", func(r *models.Follow) string { return r.FolloweeID.String() }},
+			{"followee", "被关注者", "Followee", func(r *models.Follow) string { return r.Followee.Username }},
+			{"created_at", "关注时间", "Created At", func(r *models.Follow) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"post-collections": &tableDataset[models.PostCollection]{
+		table: "post_collections", zh: "收藏列表", en: "Collections",
+		filter:  filterPostCollections,
+		preload: []string{"User"},
+		cursor:  func(r *models.PostCollection) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.PostCollection]{
+			{"id", "ID", "ID", func(r *models.PostCollection) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.PostCollection) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.PostCollection) string { return r.User.Username }},
+			{"post_id", "帖子ID", "Post ID", func(r *models.PostCollection) string { return r.PostID.String() }},
+			{"created_at", "收藏时间", "Created At", func(r *models.PostCollection) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"random-match": &tableDataset[models.RandomMatchRecord]{
+		table: "random_match_records", zh: "随机匹配记录", en: "Random Match Records",
+		filter:  filterRandomMatchRecords,
+		preload: []string{"User", "MatchedUser"},
+		cursor:  func(r *models.RandomMatchRecord) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.RandomMatchRecord]{
+			{"id", "记录ID", "Record ID", func(r *models.RandomMatchRecord) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.RandomMatchRecord) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.RandomMatchRecord) string { return r.User.Username }},
+			{"matched_user", "匹配用户", "Matched User", func(r *models.RandomMatchRecord) string {
+				if r.MatchedUser == nil {
+					return ""
+				}
+				return r.MatchedUser.Username
+			}},
+			{"status", "状态", "Status", func(r *models.RandomMatchRecord) string { return r.Status }},
+			{"wait_seconds", "等待时长（秒）", "Wait (s)", func(r *models.RandomMatchRecord) string {
+				if r.WaitSeconds == nil {
+					return ""
+				}
+				return strconv.Itoa(*r.WaitSeconds)
+			}},
+			{"matched_at", "匹配时间", "Matched At", func(r *models.RandomMatchRecord) string { return exportTimePtr(r.MatchedAt) }},
+			{"created_at", "创建时间", "Created At", func(r *models.RandomMatchRecord) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"post-likes": &tableDataset[models.PostLike]{
+		table: "post_likes", zh: "点赞列表", en: "Post Likes",
+		filter:  filterPostLikes,
+		preload: []string{"User"},
+		cursor:  func(r *models.PostLike) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.PostLike]{
+			{"id", "ID", "ID", func(r *models.PostLike) string { return r.ID.String() }},
+			{"post_id", "帖子ID", "Post ID", func(r *models.PostLike) string { return r.PostID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.PostLike) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.PostLike) string { return r.User.Username }},
+			{"created_at", "点赞时间", "Created At", func(r *models.PostLike) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"operation-logs": &tableDataset[models.OperationLog]{
+		table: "operation_logs", zh: "操作日志", en: "Operation Logs",
+		filter: filterOperationLogs,
+		cursor: func(r *models.OperationLog) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.OperationLog]{
+			{"id", "日志ID", "Log ID", func(r *models.OperationLog) string { return r.ID.String() }},
+			{"user_id", "操作人ID", "Operator ID", func(r *models.OperationLog) string { return r.UserID.String() }},
+			{"username", "操作人", "Operator", func(r *models.OperationLog) string { return r.Username }},
+			{"user_role", "角色", "Role", func(r *models.OperationLog) string { return r.UserRole }},
+			{"action", "操作", "Action", func(r *models.OperationLog) string { return r.Action }},
+			{"resource", "资源", "Resource", func(r *models.OperationLog) string { return r.Resource }},
+			{"resource_id", "资源ID", "Resource ID", func(r *models.OperationLog) string { return r.ResourceID }},
+			{"details", "详情", "Details", func(r *models.OperationLog) string { return r.Details }},
+			{"status", "状态", "Status", func(r *models.OperationLog) string { return r.Status }},
+			{"created_at", "操作时间", "Created At", func(r *models.OperationLog) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"tongue-twisters": &tableDataset[models.TongueTwister]{
+		table: "tongue_twisters", zh: "绕口令", en: "Tongue Twisters",
+		filter: filterTongueTwisters,
+		cursor: func(r *models.TongueTwister) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.TongueTwister]{
+			{"id", "ID", "ID", func(r *models.TongueTwister) string { return r.ID.String() }},
+			{"title", "标题", "Title", func(r *models.TongueTwister) string { return r.Title }},
+			{"content", "内容", "Content", func(r *models.TongueTwister) string { return r.Content }},
+			{"tips", "提示", "Tips", func(r *models.TongueTwister) string { return r.Tips }},
+			{"level", "难度", "Level", func(r *models.TongueTwister) string { return r.Level }},
+			{"difficulty_score", "难度分数", "Difficulty Score", func(r *models.TongueTwister) string {
+				return strconv.FormatFloat(r.DifficultyScore, 'f', 1, 64)
+			}},
+			{"order", "排序", "Order", func(r *models.TongueTwister) string { return strconv.Itoa(r.Order) }},
+			{"is_active", "是否启用", "Active", func(r *models.TongueTwister) string { return strconv.FormatBool(r.IsActive) }},
+			{"created_at", "创建时间", "Created At", func(r *models.TongueTwister) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"daily-expressions": &tableDataset[models.DailyExpression]{
+		table: "daily_expressions", zh: "每日朗诵文案", en: "Daily Expressions",
+		filter: filterDailyExpressions,
+		cursor: func(r *models.DailyExpression) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.DailyExpression]{
+			{"id", "ID", "ID", func(r *models.DailyExpression) string { return r.ID.String() }},
+			{"title", "标题", "Title", func(r *models.DailyExpression) string { return r.Title }},
+			{"content", "内容", "Content", func(r *models.DailyExpression) string { return r.Content }},
+			{"tips", "朗诵提示", "Tips", func(r *models.DailyExpression) string { return r.Tips }},
+			{"source", "来源", "Source", func(r *models.DailyExpression) string { return r.Source }},
+			{"date", "发布日期", "Date", func(r *models.DailyExpression) string { return exportDate(r.Date) }},
+			{"is_active", "是否启用", "Active", func(r *models.DailyExpression) string { return strconv.FormatBool(r.IsActive) }},
+			{"created_at", "创建时间", "Created At", func(r *models.DailyExpression) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"speech-techniques": &tableDataset[models.SpeechTechnique]{
+		table: "speech_techniques", zh: "说话技巧", en: "Speech Techniques",
+		filter: noFilter,
+		cursor: func(r *models.SpeechTechnique) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.SpeechTechnique]{
+			{"id", "ID", "ID", func(r *models.SpeechTechnique) string { return r.ID.String() }},
+			{"name", "名称", "Name", func(r *models.SpeechTechnique) string { return r.Name }},
+			{"icon", "图标", "Icon", func(r *models.SpeechTechnique) string { return r.Icon }},
+			{"description", "描述", "Description", func(r *models.SpeechTechnique) string { return r.Description }},
+			{"tips", "训练要点", "Tips", func(r *models.SpeechTechnique) string { return r.Tips }},
+			{"practice_texts", "练习文本", "Practice Texts", func(r *models.SpeechTechnique) string { return r.PracticeTexts }},
+			{"order", "排序", "Order", func(r *models.SpeechTechnique) string { return strconv.Itoa(r.Order) }},
+			{"is_active", "是否启用", "Active", func(r *models.SpeechTechnique) string { return strconv.FormatBool(r.IsActive) }},
+			{"created_at", "创建时间", "Created At", func(r *models.SpeechTechnique) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"achievements": &tableDataset[models.Achievement]{
+		table: "achievements", zh: "成就列表", en: "Achievements",
+		filter:     filterAchievements,
+		preload:    []string{"User"},
+		timeColumn: "unlocked_at",
+		cursor:     func(r *models.Achievement) (time.Time, uuid.UUID) { return r.UnlockedAt, r.ID },
+		columns: []exportColumn[models.Achievement]{
+			{"id", "ID", "ID", func(r *models.Achievement) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.Achievement) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.Achievement) string { return r.User.Username }},
+			{"achievement_type", "成就类型", "Achievement Type", func(r *models.Achievement) string { return r.AchievementType }},
+			{"unlocked_at", "解锁时间", "Unlocked At", func(r *models.Achievement) string { return exportTime(r.UnlockedAt) }},
+		},
+	},
+	"meditation-progress": &tableDataset[models.MeditationProgress]{
+		table: "meditation_progresses", zh: "冥想进度", en: "Meditation Progress",
+		filter:  filterMeditationProgresses,
+		preload: []string{"User"},
+		cursor:  func(r *models.MeditationProgress) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.MeditationProgress]{
+			{"id", "ID", "ID", func(r *models.MeditationProgress) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.MeditationProgress) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.MeditationProgress) string { return r.User.Username }},
+			{"stage", "阶段", "Stage", func(r *models.MeditationProgress) string { return strconv.Itoa(r.Stage) }},
+			{"completed_days", "完成天数", "Completed Days", func(r *models.MeditationProgress) string { return strconv.Itoa(r.CompletedDays) }},
+			{"unlocked", "是否解锁", "Unlocked", func(r *models.MeditationProgress) string { return strconv.FormatBool(r.Unlocked) }},
+			{"created_at", "创建时间", "Created At", func(r *models.MeditationProgress) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"ai-conversations": &tableDataset[models.AIConversation]{
+		table: "ai_conversations", zh: "AI对话", en: "AI Conversations",
+		filter:  filterAIConversations,
+		preload: []string{"User"},
+		cursor:  func(r *models.AIConversation) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.AIConversation]{
+			{"id", "ID", "ID", func(r *models.AIConversation) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.AIConversation) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.AIConversation) string { return r.User.Username }},
+			{"message_count", "消息数", "Messages", func(r *models.AIConversation) string { return strconv.Itoa(len(r.Messages)) }},
+			{"created_at", "创建时间", "Created At", func(r *models.AIConversation) string { return exportTime(r.CreatedAt) }},
+			{"updated_at", "最后对话时间", "Updated At", func(r *models.AIConversation) string { return exportTime(r.UpdatedAt) }},
+		},
+	},
+	// 验证码本身不导出
+	"verification-codes": &tableDataset[models.VerificationCode]{
+		table: "verification_codes", zh: "验证码记录", en: "Verification Codes",
+		filter: filterVerificationCodes,
+		cursor: func(r *models.VerificationCode) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.VerificationCode]{
+			{"id", "ID", "ID", func(r *models.VerificationCode) string { return r.ID.String() }},
+			{"identifier", "手机号/邮箱", "Identifier", func(r *models.VerificationCode) string { return r.Identifier }},
+			{"type", "类型", "Type", func(r *models.VerificationCode) string { return r.Type }},
+			{"used", "是否已使用", "Used", func(r *models.VerificationCode) string { return strconv.FormatBool(r.Used) }},
+			{"expires_at", "过期时间", "Expires At", func(r *models.VerificationCode) string { return exportTime(r.ExpiresAt) }},
+			{"created_at", "发送时间", "Created At", func(r *models.VerificationCode) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"user-settings": &tableDataset[models.UserSettings]{
+		table: "user_settings", zh: "用户设置", en: "User Settings",
+		filter: filterUserSettings,
+		cursor: func(r *models.UserSettings) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.UserSettings]{
+			{"id", "ID", "ID", func(r *models.UserSettings) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.UserSettings) string { return r.UserID.String() }},
+			{"enable_push_notifications", "推送通知", "Push Notifications", func(r *models.UserSettings) string {
+				return strconv.FormatBool(r.EnablePushNotifications)
+			}},
+			{"enable_email_notifications", "邮件通知", "Email Notifications", func(r *models.UserSettings) string {
+				return strconv.FormatBool(r.EnableEmailNotifications)
+			}},
+			{"public_profile", "公开资料", "Public Profile", func(r *models.UserSettings) string { return strconv.FormatBool(r.PublicProfile) }},
+			{"ai_voice_type", "AI音色", "AI Voice", func(r *models.UserSettings) string { return r.AIVoiceType }},
+			{"ai_speaking_speed", "AI语速", "AI Speaking Speed", func(r *models.UserSettings) string { return strconv.Itoa(r.AISpeakingSpeed) }},
+			{"ai_personality", "AI性格", "AI Personality", func(r *models.UserSettings) string { return r.AIPersonality }},
+			{"difficulty_level", "难度级别", "Difficulty", func(r *models.UserSettings) string { return r.DifficultyLevel }},
+			{"daily_goal_minutes", "每日目标（分钟）", "Daily Goal (min)", func(r *models.UserSettings) string { return strconv.Itoa(r.DailyGoalMinutes) }},
+			{"created_at", "创建时间", "Created At", func(r *models.UserSettings) string { return exportTime(r.CreatedAt) }},
+			{"updated_at", "更新时间", "Updated At", func(r *models.UserSettings) string { return exportTime(r.UpdatedAt) }},
+		},
+	},
+	"feedback": &tableDataset[models.Feedback]{
+		table: "feedbacks", zh: "用户反馈", en: "Feedback",
+		filter:  filterFeedback,
+		preload: []string{"User"},
+		cursor:  func(r *models.Feedback) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.Feedback]{
+			{"id", "ID", "ID", func(r *models.Feedback) string { return r.ID.String() }},
+			{"user_id", "用户ID", "User ID", func(r *models.Feedback) string { return r.UserID.String() }},
+			{"username", "用户名", "Username", func(r *models.Feedback) string { return r.User.Username }},
+			{"type", "类型", "Type", func(r *models.Feedback) string { return r.Type }},
+			{"content", "内容", "Content", func(r *models.Feedback) string { return r.Content }},
+			{"status", "状态", "Status", func(r *models.Feedback) string { return r.Status }},
+			{"response", "回复", "Response", func(r *models.Feedback) string { return exportStringPtr(r.Response) }},
+			{"created_at", "提交时间", "Created At", func(r *models.Feedback) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+	"legal-documents": &tableDataset[models.LegalDocument]{
+		table: "legal_documents", zh: "法律文档", en: "Legal Documents",
+		filter: noFilter,
+		cursor: func(r *models.LegalDocument) (time.Time, uuid.UUID) { return r.CreatedAt, r.ID },
+		columns: []exportColumn[models.LegalDocument]{
+			{"id", "ID", "ID", func(r *models.LegalDocument) string { return r.ID.String() }},
+			{"type", "类型", "Type", func(r *models.LegalDocument) string { return r.Type }},
+			{"title", "标题", "Title", func(r *models.LegalDocument) string { return r.Title }},
+			{"version", "版本", "Version", func(r *models.LegalDocument) string { return r.Version }},
+			{"content", "内容", "Content", func(r *models.LegalDocument) string { return r.Content }},
+			{"is_active", "是否启用", "Active", func(r *models.LegalDocument) string { return strconv.FormatBool(r.IsActive) }},
+			{"created_at", "创建时间", "Created At", func(r *models.LegalDocument) string { return exportTime(r.CreatedAt) }},
+		},
+	},
+}
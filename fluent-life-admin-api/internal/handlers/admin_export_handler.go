@@ -0,0 +1,516 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+	"fluent-life-admin-api/pkg/xlsx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 表头语言
+const (
+	langZH = "zh-CN"
+	langEN = "en"
+)
+
+// 导出任务状态
+const (
+	exportStatusPending = "pending"
+	exportStatusRunning = "running"
+	exportStatusSuccess = "success"
+	exportStatusFailed  = "failed"
+	exportStatusExpired = "expired"
+)
+
+// exportSyncLimit 超过该行数的导出自动转为异步任务
+const exportSyncLimit = 5000
+
+// exportCleanupInterval 清理过期导出文件的间隔
+const exportCleanupInterval = time.Hour
+
+// exportReservedParams 导出自身使用的参数，不作为列表筛选条件
+var exportReservedParams = []string{"format", "columns", "lang", "async", "page", "page_size"}
+
+// AdminExportHandler 管理员列表导出处理器
+type AdminExportHandler struct {
+	db        *gorm.DB
+	media     *services.MediaService
+	dir       string
+	retention time.Duration
+	jobs      chan struct{} // 限制同时运行的异步导出数量
+}
+
+// NewAdminExportHandler 创建管理员列表导出处理器，media 用于签发导出的媒体链接，dir 为异步导出文件的存放目录，
+// retention 为文件保留时长，0 表示不过期
+func NewAdminExportHandler(db *gorm.DB, media *services.MediaService, dir string, retention time.Duration) *AdminExportHandler {
+	// 服务重启时仍未完成的任务已经中断，标记为失败
+	db.Model(&models.ExportJob{}).
+		Where("status IN ?", []string{exportStatusPending, exportStatusRunning}).
+		Updates(map[string]interface{}{"status": exportStatusFailed, "error": "服务重启，任务中断"})
+
+	return &AdminExportHandler{db: db, media: media, dir: dir, retention: retention, jobs: make(chan struct{}, 2)}
+}
+
+// RunCleanup 定期删除过期的导出文件，并清理中断任务遗留在导出目录中的文件
+func (h *AdminExportHandler) RunCleanup(ctx context.Context) {
+	if h.retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(exportCleanupInterval)
+	defer ticker.Stop()
+	for {
+		h.cleanup(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *AdminExportHandler) cleanup(now time.Time) {
+	var jobs []models.ExportJob
+	err := h.db.Where("status = ? AND expires_at <= ?", exportStatusSuccess, now).Find(&jobs).Error
+	if err != nil {
+		log.Printf("[导出] 查询过期任务失败: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("[导出] 删除过期文件 %s 失败: %v", job.FilePath, err)
+			continue
+		}
+		h.db.Model(&models.ExportJob{}).Where("id = ?", job.ID).
+			Updates(map[string]interface{}{"status": exportStatusExpired, "file_path": ""})
+	}
+
+	// 没有对应成功任务的文件（进程在写入中途退出等）超过保留时长后同样删除
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || now.Sub(info.ModTime()) < h.retention {
+			continue
+		}
+		path := filepath.Join(h.dir, entry.Name())
+		var live int64
+		h.db.Model(&models.ExportJob{}).Where("file_path = ? AND status = ?", path, exportStatusSuccess).Count(&live)
+		if live == 0 {
+			os.Remove(path)
+		}
+	}
+}
+
+// rowWriter 导出文件的逐行写入器
+type rowWriter interface {
+	WriteHeader(cells []string) error
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// csvRowWriter 带 UTF-8 BOM 的 CSV 写入器，便于 Excel 直接打开
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func newCSVRowWriter(w io.Writer) (*csvRowWriter, error) {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return nil, err
+	}
+	return &csvRowWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvRowWriter) WriteHeader(cells []string) error { return c.w.Write(cells) }
+func (c *csvRowWriter) WriteRow(cells []string) error    { return c.w.Write(cells) }
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func newRowWriter(format string, w io.Writer, sheetName string) (rowWriter, error) {
+	if format == "xlsx" {
+		return xlsx.NewWriter(w, sheetName)
+	}
+	return newCSVRowWriter(w)
+}
+
+func exportContentType(format string) string {
+	if format == "xlsx" {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// exportRequest 解析后的导出参数
+type exportRequest struct {
+	resource string
+	dataset  exportDataset
+	format   string
+	lang     string
+	columns  []string
+	params   url.Values
+	sign     exportSigner
+}
+
+// parseExportRequest 解析导出参数，失败时直接写入错误响应
+func parseExportRequest(c *gin.Context) (*exportRequest, bool) {
+	resource := c.Param("resource")
+	dataset, ok := exportDatasets[resource]
+	if !ok {
+		response.Error(c, http.StatusNotFound, "不支持导出该列表: "+resource)
+		return nil, false
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "xlsx" {
+		response.Error(c, http.StatusBadRequest, "导出格式仅支持 csv 或 xlsx")
+		return nil, false
+	}
+
+	columns := columnKeys(dataset)
+	if requested := splitQueryList(c.Query("columns")); len(requested) > 0 {
+		known := make(map[string]bool, len(columns))
+		for _, key := range columns {
+			known[key] = true
+		}
+		for _, key := range requested {
+			if !known[key] {
+				response.Error(c, http.StatusBadRequest, "不支持的导出列: "+key)
+				return nil, false
+			}
+		}
+		columns = requested
+	}
+
+	params := c.Request.URL.Query()
+	for _, key := range exportReservedParams {
+		params.Del(key)
+	}
+
+	return &exportRequest{
+		resource: resource,
+		dataset:  dataset,
+		format:   format,
+		lang:     resolveLang(c.Query("lang"), c.GetHeader("Accept-Language")),
+		columns:  columns,
+		params:   params,
+	}, true
+}
+
+// resolveLang 确定表头语言：优先 lang 参数，其次 Accept-Language，默认中文
+func resolveLang(lang, acceptLanguage string) string {
+	if lang == "" {
+		lang = acceptLanguage
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(lang)), "en") {
+		return langEN
+	}
+	return langZH
+}
+
+// escapeCell 以 = + - @ 等开头的单元格在 Excel 中会被当作公式执行，前面加 ' 作为文本；数字保持原样
+func escapeCell(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// headerRow 返回选中列的本地化表头
+func (r *exportRequest) headerRow() []string {
+	labels := make(map[string]string)
+	for _, info := range r.dataset.Columns(r.lang) {
+		labels[info.Key] = info.Label
+	}
+	header := make([]string, len(r.columns))
+	for i, key := range r.columns {
+		header[i] = labels[key]
+	}
+	return header
+}
+
+func (r *exportRequest) fileName() string {
+	return fmt.Sprintf("%s_%s.%s", r.resource, time.Now().Format("20060102150405"), r.format)
+}
+
+// write 将全部匹配行写入 w，progress 每写入一批后回调已写入行数
+func (h *AdminExportHandler) write(r *exportRequest, w io.Writer, progress func(int64)) (int64, error) {
+	writer, err := newRowWriter(r.format, w, r.dataset.Title(r.lang))
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.WriteHeader(r.headerRow()); err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	err = r.dataset.Stream(h.db, r.params, r.columns, r.sign, func(values []string) error {
+		rows++
+		if progress != nil && rows%exportBatchSize == 0 {
+			progress(rows)
+		}
+		for i, value := range values {
+			values[i] = escapeCell(value)
+		}
+		return writer.WriteRow(values)
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, writer.Close()
+}
+
+// GetExportResources 获取可导出的列表及其列
+// GET /api/v1/admin/export?lang=zh-CN
+func (h *AdminExportHandler) GetExportResources(c *gin.Context) {
+	lang := resolveLang(c.Query("lang"), c.GetHeader("Accept-Language"))
+	keys := make([]string, 0, len(exportDatasets))
+	for key := range exportDatasets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	resources := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		dataset := exportDatasets[key]
+		resources = append(resources, gin.H{
+			"resource": key,
+			"title":    dataset.Title(lang),
+			"columns":  dataset.Columns(lang),
+		})
+	}
+
+	response.Success(c, gin.H{
+		"resources":  resources,
+		"formats":    []string{"csv", "xlsx"},
+		"sync_limit": exportSyncLimit,
+	}, "获取成功")
+}
+
+// Export 按列表筛选条件导出全部匹配行；行数超过同步上限或 async=true 时创建异步任务
+// GET /api/v1/admin/export/:resource?format=xlsx&columns=id,username&lang=en&keyword=...
+func (h *AdminExportHandler) Export(c *gin.Context) {
+	req, ok := parseExportRequest(c)
+	if !ok {
+		return
+	}
+	// 在请求内确定签名用的管理员与代理地址，异步任务结束时请求早已返回
+	req.sign = mediaSigner(c, h.media)
+
+	total, err := req.dataset.Count(h.db, req.params)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "统计导出行数失败: "+err.Error())
+		return
+	}
+
+	if total > exportSyncLimit || c.Query("async") == "true" {
+		job := models.ExportJob{
+			Resource:  req.resource,
+			Format:    req.format,
+			Columns:   strings.Join(req.columns, ","),
+			Lang:      req.lang,
+			Params:    req.params.Encode(),
+			Status:    exportStatusPending,
+			TotalRows: total,
+		}
+		if userID, ok := c.Get("userID"); ok {
+			job.CreatedBy = userID.(uuid.UUID)
+		}
+		if err := h.db.Create(&job).Error; err != nil {
+			response.Error(c, http.StatusInternalServerError, "创建导出任务失败: "+err.Error())
+			return
+		}
+		go h.runJob(job.ID, req)
+
+		response.Success(c, gin.H{"async": true, "job": job}, "导出任务已创建")
+		return
+	}
+
+	c.Header("Content-Type", exportContentType(req.format))
+	c.Header("Content-Disposition", "attachment; filename="+req.fileName())
+	if _, err := h.write(req, c.Writer, nil); err != nil {
+		// 响应头已发送，只能中断输出并记录错误
+		log.Printf("[导出] %s 导出失败: %v", req.resource, err)
+		c.Error(err)
+	}
+}
+
+// runJob 执行异步导出任务
+func (h *AdminExportHandler) runJob(jobID uuid.UUID, req *exportRequest) {
+	h.jobs <- struct{}{}
+	defer func() { <-h.jobs }()
+
+	started := time.Now()
+	h.db.Model(&models.ExportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":     exportStatusRunning,
+		"started_at": started,
+	})
+
+	fail := func(err error) {
+		log.Printf("[导出] 任务 %s 失败: %v", jobID, err)
+		h.db.Model(&models.ExportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+			"status":      exportStatusFailed,
+			"error":       err.Error(),
+			"finished_at": time.Now(),
+		})
+	}
+
+	if err := os.MkdirAll(h.dir, 0o755); err != nil {
+		fail(fmt.Errorf("创建导出目录失败: %w", err))
+		return
+	}
+	path := filepath.Join(h.dir, jobID.String()+"."+req.format)
+	f, err := os.Create(path)
+	if err != nil {
+		fail(fmt.Errorf("创建导出文件失败: %w", err))
+		return
+	}
+
+	rows, err := h.write(req, f, func(n int64) {
+		h.db.Model(&models.ExportJob{}).Where("id = ?", jobID).Update("exported_rows", n)
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		fail(err)
+		return
+	}
+
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	finished := time.Now()
+	updates := map[string]interface{}{
+		"status":        exportStatusSuccess,
+		"exported_rows": rows,
+		"file_name":     req.fileName(),
+		"file_path":     path,
+		"file_size":     size,
+		"finished_at":   finished,
+	}
+	if h.retention > 0 {
+		updates["expires_at"] = finished.Add(h.retention)
+	}
+	h.db.Model(&models.ExportJob{}).Where("id = ?", jobID).Updates(updates)
+}
+
+// GetExportJobs 获取导出任务列表
+// GET /api/v1/admin/export-jobs?page=1&page_size=20&status=success&mine=true
+func (h *AdminExportHandler) GetExportJobs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := h.db.Model(&models.ExportJob{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if resource := c.Query("resource"); resource != "" {
+		query = query.Where("resource = ?", resource)
+	}
+	if c.Query("mine") == "true" {
+		if userID, ok := c.Get("userID"); ok {
+			query = query.Where("created_by = ?", userID)
+		}
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var jobs []models.ExportJob
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&jobs).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取导出任务失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"jobs":      jobs,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// GetExportJob 获取导出任务详情（用于轮询进度）
+// GET /api/v1/admin/export-jobs/:id
+func (h *AdminExportHandler) GetExportJob(c *gin.Context) {
+	job, ok := h.findJob(c)
+	if !ok {
+		return
+	}
+	response.Success(c, job, "获取成功")
+}
+
+// DownloadExportJob 下载已完成的导出文件
+// GET /api/v1/admin/export-jobs/:id/download
+func (h *AdminExportHandler) DownloadExportJob(c *gin.Context) {
+	job, ok := h.findJob(c)
+	if !ok {
+		return
+	}
+	if job.Status == exportStatusExpired {
+		response.Error(c, http.StatusGone, "导出文件已过期，请重新导出")
+		return
+	}
+	if job.Status != exportStatusSuccess {
+		response.Error(c, http.StatusConflict, "导出任务尚未完成")
+		return
+	}
+	if _, err := os.Stat(job.FilePath); err != nil {
+		response.Error(c, http.StatusGone, "导出文件已不存在")
+		return
+	}
+
+	c.Header("Content-Type", exportContentType(job.Format))
+	c.FileAttachment(job.FilePath, job.FileName)
+}
+
+func (h *AdminExportHandler) findJob(c *gin.Context) (*models.ExportJob, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的任务ID")
+		return nil, false
+	}
+
+	var job models.ExportJob
+	if err := h.db.First(&job, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.Error(c, http.StatusNotFound, "导出任务不存在")
+		} else {
+			response.Error(c, http.StatusInternalServerError, "获取导出任务失败: "+err.Error())
+		}
+		return nil, false
+	}
+	return &job, true
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import "testing"
+
+func TestEscapeCell(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"hello", "hello"},
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+86 138", "'+86 138"},
+		{"-cmd", "'-cmd"},
+		{"@SUM(1)", "'@SUM(1)"},
+		{"\t=1", "'\t=1"},
+		{"-12.5", "-12.5"},
+		{"+3", "+3"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := escapeCell(tt.in); got != tt.want {
+			t.Errorf("escapeCell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondBundleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBundleInvalid):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrExposureModuleNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrExposureModuleExists):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "操作失败: "+err.Error())
+	}
+}
+
+// ExportModules 导出模块及步骤为 JSON 包，ids 为空时导出全部模块
+// GET /api/v1/admin/exposure/modules/export?ids=a,b&download=true
+func (h *AdminExposureModuleHandler) ExportModules(c *gin.Context) {
+	var ids []string
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	bundle, err := services.NewExposureBundleService(h.db).Export(ids)
+	if err != nil {
+		respondBundleError(c, err)
+		return
+	}
+
+	if download, _ := strconv.ParseBool(c.DefaultQuery("download", "true")); !download {
+		response.Success(c, bundle, "导出成功")
+		return
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "导出失败: "+err.Error())
+		return
+	}
+	name := "exposure_modules_" + time.Now().Format("20060102150405") + ".json"
+	if len(ids) == 1 {
+		name = "exposure_module_" + ids[0] + ".json"
+	}
+	c.Header("Content-Disposition", "attachment; filename="+name)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// ImportModules 导入 JSON 包，conflict 指定模块ID已存在时的处理方式
+// POST /api/v1/admin/exposure/modules/import?conflict=skip|overwrite|rename&dry_run=true  body: 导出的 JSON 包，或 multipart: file
+func (h *AdminExposureModuleHandler) ImportModules(c *gin.Context) {
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "请上传导入文件")
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "读取文件失败: "+err.Error())
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxImportFileSize+1))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "读取文件失败: "+err.Error())
+		return
+	}
+	if len(data) > maxImportFileSize {
+		response.Error(c, http.StatusBadRequest, "导入文件不能超过 10MB")
+		return
+	}
+
+	bundle, err := services.ParseExposureBundle(data)
+	if err != nil {
+		respondBundleError(c, err)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	report, err := services.NewExposureBundleService(h.db).Import(bundle, c.DefaultQuery("conflict", services.BundleConflictSkip), dryRun)
+	if err != nil {
+		respondBundleError(c, err)
+		return
+	}
+	if dryRun {
+		response.Success(c, report, "预览成功")
+		return
+	}
+	response.Success(c, report, "导入完成")
+}
+
+// CloneModule 复制模块及其步骤、译文，新模块默认停用
+// POST /api/v1/admin/exposure/modules/:id/clone  {"id": "新模块ID，可选", "title": "可选"}
+func (h *AdminExposureModuleHandler) CloneModule(c *gin.Context) {
+	var req struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	module, err := services.NewExposureBundleService(h.db).Clone(c.Param("id"), strings.TrimSpace(req.ID), strings.TrimSpace(req.Title))
+	if err != nil {
+		respondBundleError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"module": module}, "复制成功")
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/jsonschema"
 	"fluent-life-admin-api/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -22,6 +26,24 @@ func NewAdminExposureModuleHandler(db *gorm.DB) *AdminExposureModuleHandler {
 	return &AdminExposureModuleHandler{db: db}
 }
 
+// respondStepErrors 返回步骤的字段级校验错误
+func respondStepErrors(c *gin.Context, errs []jsonschema.Error) {
+	response.ErrorWithData(c, http.StatusBadRequest, "步骤校验失败: "+errs[0].Error(), gin.H{"errors": errs})
+}
+
+// rawJSONField 读取可以是 JSON 字符串或直接是 JSON 值的字段
+func rawJSONField(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
 // GetModules 获取所有模块（管理员）
 // GET /api/v1/admin/exposure/modules
 func (h *AdminExposureModuleHandler) GetModules(c *gin.Context) {
@@ -119,6 +141,34 @@ func (h *AdminExposureModuleHandler) GetModule(c *gin.Context) {
 	response.Success(c, gin.H{"module": module}, "获取成功")
 }
 
+// GetModuleFunnel 获取模块步骤完成漏斗
+// GET /api/v1/admin/exposure/modules/:id/funnel?start_date=2026-01-01&end_date=2026-01-31
+func (h *AdminExposureModuleHandler) GetModuleFunnel(c *gin.Context) {
+	moduleID := c.Param("id")
+	if moduleID == "" {
+		response.Error(c, http.StatusBadRequest, "模块ID不能为空")
+		return
+	}
+
+	dateRange, err := services.NewDateRange(c.Query("start_date"), c.Query("end_date"), 30)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	funnel, err := services.NewFunnelService(h.db).ModuleFunnel(moduleID, dateRange)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.Error(c, http.StatusNotFound, "模块不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "计算漏斗失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"funnel": funnel}, "获取成功")
+}
+
 // CreateModule 创建模块（管理员）
 // POST /api/v1/admin/exposure/modules
 func (h *AdminExposureModuleHandler) CreateModule(c *gin.Context) {
@@ -152,10 +202,15 @@ func (h *AdminExposureModuleHandler) CreateModule(c *gin.Context) {
 		Icon:         req.Icon,
 		Color:        req.Color,
 		DisplayOrder: req.DisplayOrder,
-		IsActive:     req.IsActive,
 	}
 
-	if err := h.db.Create(&module).Error; err != nil {
+	// 上线状态由发布控制：启用即在同一事务中发布
+	if err := services.NewExposureReleaseService(h.db).Create(&module, req.IsActive, "创建模块", currentAdminID(c)); err != nil {
+		var validation *services.ExposureValidationError
+		if errors.As(err, &validation) {
+			respondReleaseError(c, err)
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "创建模块失败: "+err.Error())
 		return
 	}
@@ -214,13 +269,27 @@ func (h *AdminExposureModuleHandler) UpdateModule(c *gin.Context) {
 	if req.DisplayOrder != nil {
 		updates["display_order"] = *req.DisplayOrder
 	}
-	if req.IsActive != nil {
-		updates["is_active"] = *req.IsActive
+
+	if len(updates) > 0 {
+		if err := h.db.Model(&module).Updates(updates).Error; err != nil {
+			response.Error(c, http.StatusInternalServerError, "更新模块失败: "+err.Error())
+			return
+		}
 	}
 
-	if err := h.db.Model(&module).Updates(updates).Error; err != nil {
-		response.Error(c, http.StatusInternalServerError, "更新模块失败: "+err.Error())
-		return
+	// is_active 不再直接修改：启用即发布当前草稿，停用即下线
+	if req.IsActive != nil && *req.IsActive != module.IsActive {
+		releases := services.NewExposureReleaseService(h.db)
+		var err error
+		if *req.IsActive {
+			_, err = releases.Publish(moduleID, "启用模块", currentAdminID(c))
+		} else {
+			err = releases.Unpublish(moduleID, currentAdminID(c))
+		}
+		if err != nil {
+			respondReleaseError(c, err)
+			return
+		}
 	}
 
 	// 重新查询更新后的数据
@@ -254,6 +323,11 @@ func (h *AdminExposureModuleHandler) DeleteModule(c *gin.Context) {
 		response.Error(c, http.StatusInternalServerError, "删除模块失败: "+err.Error())
 		return
 	}
+	// 同时删除发布状态与快照，模块从公开接口中下线
+	if err := services.NewExposureReleaseService(h.db).Forget(moduleID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "删除模块快照失败: "+err.Error())
+		return
+	}
 
 	response.Success(c, nil, "删除成功")
 }
@@ -311,15 +385,16 @@ func (h *AdminExposureModuleHandler) CreateStep(c *gin.Context) {
 	}
 
 	var req struct {
-		StepOrder           int    `json:"step_order" binding:"required"`
-		StepType            string `json:"step_type" binding:"required"`
-		Title               string `json:"title" binding:"required"`
-		Description         string `json:"description" binding:"required"`
-		GuideContent        string `json:"guide_content"`
-		ScenarioListTitle   string `json:"scenario_list_title"`
-		ScenarioListContent string `json:"scenario_list_content"`
-		PopupConfigs        string `json:"popup_configs"` // JSON字符串，包含多个弹窗配置
-		Icon                string `json:"icon" binding:"required"`
+		StepOrder           int         `json:"step_order" binding:"required"`
+		StepType            string      `json:"step_type" binding:"required"`
+		Title               string      `json:"title" binding:"required"`
+		Description         string      `json:"description" binding:"required"`
+		GuideContent        string      `json:"guide_content"`
+		ScenarioListTitle   string      `json:"scenario_list_title"`
+		ScenarioListContent string      `json:"scenario_list_content"`
+		PopupConfigs        interface{} `json:"popup_configs"` // 弹窗配置数组，JSON 数组或 JSON 字符串
+		Config              interface{} `json:"config"`        // 步骤类型专属配置，JSON 对象或 JSON 字符串
+		Icon                string      `json:"icon" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -327,20 +402,6 @@ func (h *AdminExposureModuleHandler) CreateStep(c *gin.Context) {
 		return
 	}
 
-	// 检查步骤类型是否有效
-	validTypes := []string{"approach", "conversation", "upload", "analysis", "profile", "community"}
-	isValidType := false
-	for _, t := range validTypes {
-		if req.StepType == t {
-			isValidType = true
-			break
-		}
-	}
-	if !isValidType {
-		response.Error(c, http.StatusBadRequest, "无效的步骤类型")
-		return
-	}
-
 	step := models.ExposureStep{
 		ModuleID:            moduleID,
 		StepOrder:           req.StepOrder,
@@ -350,10 +411,17 @@ func (h *AdminExposureModuleHandler) CreateStep(c *gin.Context) {
 		GuideContent:        req.GuideContent,
 		ScenarioListTitle:   req.ScenarioListTitle,
 		ScenarioListContent: req.ScenarioListContent,
-		PopupConfigs:        req.PopupConfigs,
+		PopupConfigs:        rawJSONField(req.PopupConfigs),
+		Config:              rawJSONField(req.Config),
 		Icon:                req.Icon,
 	}
 
+	// 按步骤类型注册表校验类型、必填字段、弹窗配置与类型配置
+	if errs := services.ValidateExposureStep(&step); len(errs) > 0 {
+		respondStepErrors(c, errs)
+		return
+	}
+
 	if err := h.db.Create(&step).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "创建步骤失败: "+err.Error())
 		return
@@ -366,11 +434,6 @@ func (h *AdminExposureModuleHandler) CreateStep(c *gin.Context) {
 		return
 	}
 
-	// 处理 popup_configs 为 NULL 的情况
-	if step.PopupConfigs == "" {
-		step.PopupConfigs = "[]"
-	}
-
 	response.Success(c, gin.H{"step": step}, "创建成功")
 }
 
@@ -398,6 +461,7 @@ func (h *AdminExposureModuleHandler) UpdateStep(c *gin.Context) {
 		ScenarioListTitle   *string `json:"scenario_list_title"`
 		ScenarioListContent *string `json:"scenario_list_content"`
 		PopupConfigs        *string `json:"popup_configs"` // JSON字符串，包含多个弹窗配置
+		Config              *string `json:"config"`
 		Icon                *string `json:"icon"`
 	}
 
@@ -445,16 +509,15 @@ func (h *AdminExposureModuleHandler) UpdateStep(c *gin.Context) {
 			req.ScenarioListContent = &v
 		}
 	}
-	// 处理弹窗配置字段
+	// 处理弹窗配置与类型配置字段：可传 JSON 字符串或直接传 JSON 值
 	if val, exists := rawData["popup_configs"]; exists {
-		v := "[]"
-		if val != nil {
-			if str, ok := val.(string); ok {
-				v = str
-			}
-		}
+		v := rawJSONField(val)
 		req.PopupConfigs = &v
 	}
+	if val, exists := rawData["config"]; exists {
+		v := rawJSONField(val)
+		req.Config = &v
+	}
 	if val, ok := rawData["icon"]; ok {
 		if v, ok := val.(string); ok {
 			req.Icon = &v
@@ -472,34 +535,48 @@ func (h *AdminExposureModuleHandler) UpdateStep(c *gin.Context) {
 		return
 	}
 
-	// 更新字段
+	// 更新字段，merged 为更新后的步骤，用于校验
 	updates := make(map[string]interface{})
+	merged := step
 	if req.StepOrder != nil {
 		updates["step_order"] = *req.StepOrder
+		merged.StepOrder = *req.StepOrder
 	}
 	if req.StepType != nil {
 		updates["step_type"] = *req.StepType
+		merged.StepType = *req.StepType
 	}
 	if req.Title != nil {
 		updates["title"] = *req.Title
+		merged.Title = *req.Title
 	}
 	if req.Description != nil {
 		updates["description"] = *req.Description
+		merged.Description = *req.Description
 	}
 	if req.GuideContent != nil {
 		updates["guide_content"] = *req.GuideContent
+		merged.GuideContent = *req.GuideContent
 	}
 	if req.ScenarioListTitle != nil {
 		updates["scenario_list_title"] = *req.ScenarioListTitle
+		merged.ScenarioListTitle = *req.ScenarioListTitle
 	}
 	if req.ScenarioListContent != nil {
 		updates["scenario_list_content"] = *req.ScenarioListContent
+		merged.ScenarioListContent = *req.ScenarioListContent
 	}
 	if req.PopupConfigs != nil {
 		updates["popup_configs"] = *req.PopupConfigs
+		merged.PopupConfigs = *req.PopupConfigs
 	}
 	if req.Icon != nil {
 		updates["icon"] = *req.Icon
+		merged.Icon = *req.Icon
+	}
+	if req.Config != nil {
+		updates["config"] = *req.Config
+		merged.Config = *req.Config
 	}
 
 	if len(updates) == 0 {
@@ -507,6 +584,18 @@ func (h *AdminExposureModuleHandler) UpdateStep(c *gin.Context) {
 		return
 	}
 
+	// 按步骤类型注册表校验更新后的步骤，弹窗配置与类型配置保存规范化后的值
+	if errs := services.ValidateExposureStep(&merged); len(errs) > 0 {
+		respondStepErrors(c, errs)
+		return
+	}
+	if _, ok := updates["popup_configs"]; ok {
+		updates["popup_configs"] = merged.PopupConfigs
+	}
+	if _, ok := updates["config"]; ok {
+		updates["config"] = merged.Config
+	}
+
 	if err := h.db.Model(&step).Updates(updates).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "更新步骤失败: "+err.Error())
 		return
@@ -591,6 +680,13 @@ func (h *AdminExposureModuleHandler) BatchUpdateStepsOrder(c *gin.Context) {
 		}
 	}()
 
+	// 锁定模块，避免发布时读到排序到一半的步骤
+	if err := services.LockExposureModule(tx, moduleID); err != nil {
+		tx.Rollback()
+		respondReleaseError(c, err)
+		return
+	}
+
 	for _, item := range req.Steps {
 		stepID, err := uuid.Parse(item.ID)
 		if err != nil {
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPopupConfigsSchema 获取弹窗配置的 JSON Schema
+// GET /api/v1/admin/exposure/popup-configs/schema
+func (h *AdminExposureModuleHandler) GetPopupConfigsSchema(c *gin.Context) {
+	response.Success(c, gin.H{"schema": services.PopupConfigsSchema()}, "获取成功")
+}
+
+// ValidatePopupConfigs 只校验弹窗配置，不保存
+// POST /api/v1/admin/exposure/popup-configs/validate  {"popup_configs": "[...]" 或 [...]}
+func (h *AdminExposureModuleHandler) ValidatePopupConfigs(c *gin.Context) {
+	var req struct {
+		PopupConfigs interface{} `json:"popup_configs"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	normalized, errs := services.ValidatePopupConfigs(rawJSONField(req.PopupConfigs), "popup_configs")
+	result := gin.H{"valid": len(errs) == 0, "errors": errs}
+	if len(errs) == 0 {
+		result["normalized"] = json.RawMessage(normalized)
+	}
+	response.Success(c, result, "校验完成")
+}
+
+// AuditPopupConfigs 检查已有步骤的弹窗配置，列出不符合规范的步骤；fix_empty=true 时把空值改写为 []
+// POST /api/v1/admin/exposure/popup-configs/audit?module_id=&fix_empty=false
+func (h *AdminExposureModuleHandler) AuditPopupConfigs(c *gin.Context) {
+	fixEmpty, _ := strconv.ParseBool(c.DefaultQuery("fix_empty", "false"))
+	report, err := services.AuditPopupConfigs(h.db, c.Query("module_id"), fixEmpty)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "检查失败: "+err.Error())
+		return
+	}
+	response.Success(c, report, "检查完成")
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentAdminID 当前管理员ID，未登录时为 nil
+func currentAdminID(c *gin.Context) *uuid.UUID {
+	if userID, ok := c.Get("userID"); ok {
+		id := userID.(uuid.UUID)
+		return &id
+	}
+	return nil
+}
+
+func respondReleaseError(c *gin.Context, err error) {
+	var validation *services.ExposureValidationError
+	switch {
+	case errors.As(err, &validation):
+		response.ErrorWithData(c, http.StatusBadRequest, validation.Error(), gin.H{"errors": validation.Errors})
+	case errors.Is(err, services.ErrExposureModuleNotFound), errors.Is(err, services.ErrSnapshotNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrScheduleInvalid):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "操作失败: "+err.Error())
+	}
+}
+
+// GetModuleRelease 获取模块的发布状态：线上版本、排期、草稿是否有未发布的修改及发布前校验错误
+// GET /api/v1/admin/exposure/modules/:id/release
+func (h *AdminExposureModuleHandler) GetModuleRelease(c *gin.Context) {
+	state, err := services.NewExposureReleaseService(h.db).State(c.Param("id"))
+	if err != nil {
+		respondReleaseError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"release": state}, "获取成功")
+}
+
+// PublishModule 把模块草稿发布为新的线上快照
+// POST /api/v1/admin/exposure/modules/:id/publish  {"note": "发布说明"}
+func (h *AdminExposureModuleHandler) PublishModule(c *gin.Context) {
+	var req struct {
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+	}
+	snapshot, err := services.NewExposureReleaseService(h.db).Publish(c.Param("id"), req.Note, currentAdminID(c))
+	if err != nil {
+		respondReleaseError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"snapshot": snapshot}, "发布成功")
+}
+
+// UnpublishModule 下线模块
+// POST /api/v1/admin/exposure/modules/:id/unpublish
+func (h *AdminExposureModuleHandler) UnpublishModule(c *gin.Context) {
+	if err := services.NewExposureReleaseService(h.db).Unpublish(c.Param("id"), currentAdminID(c)); err != nil {
+		respondReleaseError(c, err)
+		return
+	}
+	response.Success(c, nil, "已下线")
+}
+
+// ScheduleModule 设置定时发布与下线时间，传 null 取消
+// PUT /api/v1/admin/exposure/modules/:id/schedule  {"publish_at": "2026-11-01T09:00:00+08:00", "unpublish_at": null}
+func (h *AdminExposureModuleHandler) ScheduleModule(c *gin.Context) {
+	var req struct {
+		PublishAt   *time.Time `json:"publish_at"`
+		UnpublishAt *time.Time `json:"unpublish_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误，时间格式应为 RFC3339: "+err.Error())
+		return
+	}
+	release, err := services.NewExposureReleaseService(h.db).Schedule(c.Param("id"), req.PublishAt, req.UnpublishAt, currentAdminID(c))
+	if err != nil {
+		respondReleaseError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"release": release}, "设置成功")
+}
+
+// RollbackModule 回滚到历史快照：以该快照内容生成新版本并上线，restore_draft=true 时同时覆盖草稿
+// POST /api/v1/admin/exposure/modules/:id/rollback  {"version": 3, "note": "", "restore_draft": false}
+func (h *AdminExposureModuleHandler) RollbackModule(c *gin.Context) {
+	var req struct {
+		Version      int    `json:"version" binding:"required,min=1"`
+		Note         string `json:"note"`
+		RestoreDraft bool   `json:"restore_draft"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误，需要提供版本号 version")
+		return
+	}
+	snapshot, err := services.NewExposureReleaseService(h.db).Rollback(c.Param("id"), req.Version, req.Note, req.RestoreDraft, currentAdminID(c))
+	if err != nil {
+		respondReleaseError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"snapshot": snapshot}, "回滚成功")
+}
+
+// GetModuleSnapshots 获取模块的发布快照列表（不含内容）
+// GET /api/v1/admin/exposure/modules/:id/snapshots?page=1&page_size=20
+func (h *AdminExposureModuleHandler) GetModuleSnapshots(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	snapshots, total, err := services.NewExposureReleaseService(h.db).Snapshots(c.Param("id"), page, pageSize)
+	if err != nil {
+		respondReleaseError(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"snapshots": snapshots,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// GetModuleSnapshot 获取某个版本的快照内容
+// GET /api/v1/admin/exposure/modules/:id/snapshots/:version
+func (h *AdminExposureModuleHandler) GetModuleSnapshot(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		response.Error(c, http.StatusBadRequest, "无效的版本号")
+		return
+	}
+	snapshot, err := services.NewExposureReleaseService(h.db).Snapshot(c.Param("id"), version)
+	if err != nil {
+		respondReleaseError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"snapshot": snapshot}, "获取成功")
+}
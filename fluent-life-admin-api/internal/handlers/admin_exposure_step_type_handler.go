@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStepTypes 获取步骤类型注册表：各类型的必填/可选字段、类型配置 schema 与渲染提示，供管理端动态渲染表单
+// GET /api/v1/admin/exposure/step-types
+func (h *AdminExposureModuleHandler) GetStepTypes(c *gin.Context) {
+	response.Success(c, gin.H{
+		"types":         services.ExposureStepTypes(),
+		"fields":        services.ExposureStepFields(),
+		"common_fields": []string{"step_order", "title", "description", "icon"},
+	}, "获取成功")
+}
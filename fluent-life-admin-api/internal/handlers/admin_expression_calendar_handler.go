@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetExpressionCalendar 获取每日朗诵文案的月度排期日历（空档、冲突、来源重复）
+// GET /api/v1/admin/daily-expressions/calendar?month=2026-10&source_cooldown_days=2
+func (h *AdminHandler) GetExpressionCalendar(c *gin.Context) {
+	month, err := services.ParseMonth(c.Query("month"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	rules := services.DefaultScheduleRules
+	if v := c.Query("source_cooldown_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 || days > 30 {
+			response.Error(c, http.StatusBadRequest, "来源间隔天数应在 0 ~ 30 之间")
+			return
+		}
+		rules.SourceCooldownDays = days
+	}
+
+	calendar, err := services.NewExpressionCalendarService(h.db).Calendar(month, rules)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.Success(c, calendar, "获取成功")
+}
+
+// AutoScheduleExpressions 将待排期池中的文案自动填入空档
+// POST /api/v1/admin/daily-expressions/auto-schedule  {"month": "2026-10"} 或 {"start_date": "...", "end_date": "..."}，可选 source_cooldown_days、max_per_source_per_month、dry_run
+func (h *AdminHandler) AutoScheduleExpressions(c *gin.Context) {
+	var req struct {
+		Month                string `json:"month"`
+		StartDate            string `json:"start_date"`
+		EndDate              string `json:"end_date"`
+		SourceCooldownDays   *int   `json:"source_cooldown_days"`
+		MaxPerSourcePerMonth int    `json:"max_per_source_per_month"`
+		DryRun               bool   `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	var start, end time.Time
+	if req.StartDate != "" || req.EndDate != "" {
+		var err1, err2 error
+		start, err1 = time.Parse("2006-01-02", req.StartDate)
+		end, err2 = time.Parse("2006-01-02", req.EndDate)
+		if err1 != nil || err2 != nil {
+			response.Error(c, http.StatusBadRequest, "日期格式应为 YYYY-MM-DD")
+			return
+		}
+		if end.Before(start) || end.Sub(start) > 366*24*time.Hour {
+			response.Error(c, http.StatusBadRequest, "日期范围无效（最长一年）")
+			return
+		}
+	} else {
+		month, err := services.ParseMonth(req.Month)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		start, end = month, month.AddDate(0, 1, -1)
+	}
+
+	rules := services.DefaultScheduleRules
+	if req.SourceCooldownDays != nil {
+		rules.SourceCooldownDays = *req.SourceCooldownDays
+	}
+	rules.MaxPerSourcePerMonth = req.MaxPerSourcePerMonth
+	if rules.SourceCooldownDays < 0 || rules.SourceCooldownDays > 30 || rules.MaxPerSourcePerMonth < 0 {
+		response.Error(c, http.StatusBadRequest, "排期规则参数无效")
+		return
+	}
+
+	result, err := services.NewExpressionCalendarService(h.db).AutoSchedule(start, end, rules, req.DryRun, h.contentActor(c))
+	if err != nil {
+		if !req.DryRun {
+			h.logOperation(c, "AutoScheduleExpressions", "daily_expression", "", "自动排期失败: "+err.Error(), "Failure")
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if req.DryRun {
+		response.Success(c, result, "预览成功")
+		return
+	}
+	details := fmt.Sprintf("自动排期 %s ~ %s：安排 %d 篇，未填空档 %d 天", result.Start, result.End, len(result.Assignments), len(result.Unfilled))
+	h.logOperation(c, "AutoScheduleExpressions", "daily_expression", "", details, "Success")
+	response.Success(c, result, "排期成功")
+}
+
+// GetTodayExpression 获取某天展示的每日朗诵文案（当天无排期时按回退规则选取）；
+// 公开接口的日期不能晚于今天，避免提前看到未发布的排期，管理端预览不受限制
+// GET /api/v1/daily-expressions/today?date=2026-10-19&locale=zh-TW（公开）
+// GET /api/v1/admin/daily-expressions/today?date=2026-10-19（预览）
+func (h *AdminHandler) GetTodayExpression(c *gin.Context) {
+	today := services.CalendarToday()
+	date := today
+	if v := c.Query("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "日期格式应为 YYYY-MM-DD")
+			return
+		}
+		date = d
+	}
+	if _, isAdmin := c.Get("userID"); !isAdmin && date.After(today) {
+		date = today
+	}
+
+	result, err := services.NewExpressionCalendarService(h.db).Today(date)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取今日文案失败")
+		return
+	}
+	result.Locale = requestLocale(c)
+	if result.Expression != nil {
+		if err := services.NewTranslationService(h.db).Localize(services.TranslateDailyExpression, result.Expression, result.Locale); err != nil {
+			response.Error(c, http.StatusInternalServerError, "获取译文失败")
+			return
+		}
+	}
+	response.Success(c, result, "获取成功")
+}
@@ -3,11 +3,13 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
 	"fluent-life-admin-api/pkg/auth" // Import auth package
 	"fluent-life-admin-api/pkg/response"
 
@@ -18,11 +20,14 @@ import (
 )
 
 type AdminHandler struct {
-	db *gorm.DB
+	db                 *gorm.DB
+	media              *services.MediaService
+	voiceCatalogSource string
+	voicePreviews      *services.VoicePreviewService
 }
 
-func NewAdminHandler(db *gorm.DB) *AdminHandler {
-	return &AdminHandler{db: db}
+func NewAdminHandler(db *gorm.DB, media *services.MediaService, voiceCatalogSource string, voicePreviews *services.VoicePreviewService) *AdminHandler {
+	return &AdminHandler{db: db, media: media, voiceCatalogSource: voiceCatalogSource, voicePreviews: voicePreviews}
 }
 
 // logOperation 记录管理员操作日志
@@ -44,12 +49,28 @@ func (h *AdminHandler) logOperation(c *gin.Context, action, resource, resourceID
 	h.db.Create(&logEntry) // Log asynchronously, errors here should not block main operation
 }
 
+// filterRandomMatchRecords 应用随机匹配记录列表的筛选条件，列表与导出共用
+func filterRandomMatchRecords(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按用户ID筛选
+	if userID := params.Get("user_id"); userID != "" {
+		query = query.Where("random_match_records.user_id = ?", userID)
+	}
+
+	if status := params.Get("status"); status != "" {
+		query = query.Where("random_match_records.status = ?", status)
+	}
+	if keyword := params.Get("keyword"); keyword != "" {
+		kw := "%" + strings.ToLower(keyword) + "%"
+		query = query.Joins("LEFT JOIN users u ON u.id = random_match_records.user_id").
+			Where("LOWER(u.username) LIKE ?", kw)
+	}
+	return query
+}
+
 // GetRandomMatchRecords 获取 1v1 随机匹配记录
 func (h *AdminHandler) GetRandomMatchRecords(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	status := c.Query("status")
-	keyword := c.Query("keyword")
 
 	if page < 1 {
 		page = 1
@@ -58,21 +79,7 @@ func (h *AdminHandler) GetRandomMatchRecords(c *gin.Context) {
 		pageSize = 20
 	}
 
-	query := h.db.Model(&models.RandomMatchRecord{}).Preload("User").Preload("MatchedUser")
-
-	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
-
-	if status != "" {
-		query = query.Where("status = ?", status)
-	}
-	if keyword != "" {
-		kw := "%" + strings.ToLower(keyword) + "%"
-		query = query.Joins("LEFT JOIN users u ON u.id = random_match_records.user_id").
-			Where("LOWER(u.username) LIKE ?", kw)
-	}
+	query := filterRandomMatchRecords(h.db.Model(&models.RandomMatchRecord{}).Preload("User").Preload("MatchedUser"), c.Request.URL.Query())
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -81,7 +88,7 @@ func (h *AdminHandler) GetRandomMatchRecords(c *gin.Context) {
 	}
 
 	var records []models.RandomMatchRecord
-	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&records).Error; err != nil {
+	if err := query.Order("random_match_records.created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&records).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取匹配记录失败")
 		return
 	}
@@ -286,6 +293,16 @@ func (h *AdminHandler) Login(c *gin.Context) {
 	}, "登录成功")
 }
 
+// filterUsers 应用用户列表的筛选条件，列表与导出共用
+func filterUsers(query *gorm.DB, params url.Values) *gorm.DB {
+	// 搜索
+	if keyword := params.Get("keyword"); keyword != "" {
+		query = query.Where("username LIKE ? OR email LIKE ? OR phone LIKE ?",
+			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	return query
+}
+
 // 获取用户列表
 func (h *AdminHandler) GetUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -295,13 +312,7 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 	var users []models.User
 	var total int64
 
-	query := h.db.Model(&models.User{})
-
-	// 搜索
-	if keyword := c.Query("keyword"); keyword != "" {
-		query = query.Where("username LIKE ? OR email LIKE ? OR phone LIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
-	}
+	query := filterUsers(h.db.Model(&models.User{}), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -427,6 +438,25 @@ func (h *AdminHandler) DeleteAllTongueTwisters(c *gin.Context) {
 	response.Success(c, nil, "所有绕口令删除成功")
 }
 
+// filterPosts 应用帖子列表的筛选条件，列表与导出共用
+func filterPosts(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按用户ID筛选
+	if userID := params.Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	// 搜索
+	if keyword := params.Get("keyword"); keyword != "" {
+		query = query.Where("content LIKE ?", "%"+keyword+"%")
+	}
+
+	// 按是否因视频审核未通过而隐藏筛选
+	if hidden, err := strconv.ParseBool(params.Get("hidden")); err == nil {
+		query = query.Where("hidden = ?", hidden)
+	}
+	return query
+}
+
 // 获取帖子列表
 func (h *AdminHandler) GetPosts(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -436,17 +466,7 @@ func (h *AdminHandler) GetPosts(c *gin.Context) {
 	var posts []models.Post
 	var total int64
 
-	query := h.db.Model(&models.Post{}).Preload("User")
-
-	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
-
-	// 搜索
-	if keyword := c.Query("keyword"); keyword != "" {
-		query = query.Where("content LIKE ?", "%"+keyword+"%")
-	}
+	query := filterPosts(h.db.Model(&models.Post{}).Preload("User"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -454,6 +474,9 @@ func (h *AdminHandler) GetPosts(c *gin.Context) {
 		response.Error(c, http.StatusInternalServerError, "查询失败")
 		return
 	}
+	for i := range posts {
+		signPostImage(c, h.media, &posts[i])
+	}
 
 	response.Success(c, gin.H{
 		"posts":     posts,
@@ -472,6 +495,7 @@ func (h *AdminHandler) GetPost(c *gin.Context) {
 		response.Error(c, http.StatusNotFound, "帖子不存在")
 		return
 	}
+	signPostImage(c, h.media, &post)
 
 	response.Success(c, post, "获取成功")
 }
@@ -514,6 +538,19 @@ func (h *AdminHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
+	// 帖子中的图片/视频在提交后从存储中删除
+	var mediaURLs []string
+	if err := tx.Table("posts").Where("id IN ? AND COALESCE(image, '') <> ''", req.IDs).Pluck("image", &mediaURLs).Error; err != nil {
+		tx.Rollback()
+		response.Error(c, http.StatusInternalServerError, "删除帖子失败")
+		return
+	}
+	if err := tx.Where("source = ? AND source_id IN ?", services.VideoSourcePost, req.IDs).Delete(&models.VideoAsset{}).Error; err != nil {
+		tx.Rollback()
+		response.Error(c, http.StatusInternalServerError, "删除视频索引失败")
+		return
+	}
+
 	// 最后删除帖子
 	if err := tx.Where("id IN ?", req.IDs).Delete(&models.Post{}).Error; err != nil {
 		tx.Rollback()
@@ -523,36 +560,41 @@ func (h *AdminHandler) DeletePost(c *gin.Context) {
 	}
 
 	tx.Commit()
+	h.media.RemoveURLs(c.Request.Context(), mediaURLs)
 	h.logOperation(c, "DeletePost", "Post", strings.Join(req.IDs, ","), "删除帖子成功", "Success")
 	response.Success(c, nil, "删除成功")
 }
 
-// 获取房间列表
-func (h *AdminHandler) GetRooms(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var rooms []models.PracticeRoom
-	var total int64
-
-	query := h.db.Model(&models.PracticeRoom{}).Preload("User")
-
+// filterRooms 应用练习房间列表的筛选条件，列表与导出共用
+func filterRooms(query *gorm.DB, params url.Values) *gorm.DB {
 	// 搜索
-	if keyword := c.Query("keyword"); keyword != "" {
+	if keyword := params.Get("keyword"); keyword != "" {
 		query = query.Where("title LIKE ? OR theme LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	// 筛选房间类型
-	if roomType := c.Query("type"); roomType != "" {
+	if roomType := params.Get("type"); roomType != "" {
 		query = query.Where("type = ?", roomType)
 	}
 
 	// 筛选活跃状态
-	if isActive := c.Query("is_active"); isActive != "" {
+	if isActive := params.Get("is_active"); isActive != "" {
 		active := isActive == "true"
 		query = query.Where("is_active = ?", active)
 	}
+	return query
+}
+
+// 获取房间列表
+func (h *AdminHandler) GetRooms(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var rooms []models.PracticeRoom
+	var total int64
+
+	query := filterRooms(h.db.Model(&models.PracticeRoom{}).Preload("User"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -777,6 +819,20 @@ func (h *AdminHandler) CreateAchievement(c *gin.Context) {
 	response.Success(c, achievement, "成就创建成功")
 }
 
+// filterAchievements 应用成就列表的筛选条件，列表与导出共用
+func filterAchievements(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按用户ID筛选
+	if userID := params.Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	// 按成就类型筛选
+	if achievementType := params.Get("achievement_type"); achievementType != "" {
+		query = query.Where("achievement_type = ?", achievementType)
+	}
+	return query
+}
+
 // GetAchievements 获取成就列表
 func (h *AdminHandler) GetAchievements(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -786,17 +842,7 @@ func (h *AdminHandler) GetAchievements(c *gin.Context) {
 	var achievements []models.Achievement
 	var total int64
 
-	query := h.db.Model(&models.Achievement{}).Preload("User")
-
-	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
-
-	// 按成就类型筛选
-	if achievementType := c.Query("achievement_type"); achievementType != "" {
-		query = query.Where("achievement_type = ?", achievementType)
-	}
+	query := filterAchievements(h.db.Model(&models.Achievement{}).Preload("User"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -884,6 +930,20 @@ func (h *AdminHandler) CreateMeditationProgress(c *gin.Context) {
 	response.Success(c, progress, "冥想进度创建成功")
 }
 
+// filterMeditationProgresses 应用冥想进度列表的筛选条件，列表与导出共用
+func filterMeditationProgresses(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按用户ID筛选
+	if userID := params.Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	// 按阶段筛选
+	if stage := params.Get("stage"); stage != "" {
+		query = query.Where("stage = ?", stage)
+	}
+	return query
+}
+
 // GetMeditationProgresses 获取冥想进度列表
 func (h *AdminHandler) GetMeditationProgresses(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -893,17 +953,7 @@ func (h *AdminHandler) GetMeditationProgresses(c *gin.Context) {
 	var progresses []models.MeditationProgress
 	var total int64
 
-	query := h.db.Model(&models.MeditationProgress{})
-
-	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
-
-	// 按阶段筛选
-	if stage := c.Query("stage"); stage != "" {
-		query = query.Where("stage = ?", stage)
-	}
+	query := filterMeditationProgresses(h.db.Model(&models.MeditationProgress{}), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -987,6 +1037,15 @@ func (h *AdminHandler) DeleteMeditationProgress(c *gin.Context) {
 	response.Success(c, nil, "删除成功")
 }
 
+// filterAIConversations 应用AI对话列表的筛选条件，列表与导出共用
+func filterAIConversations(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按用户ID筛选
+	if userID := params.Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	return query
+}
+
 // GetAIConversations 获取AI对话列表
 func (h *AdminHandler) GetAIConversations(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -996,12 +1055,7 @@ func (h *AdminHandler) GetAIConversations(c *gin.Context) {
 	var conversations []models.AIConversation
 	var total int64
 
-	query := h.db.Model(&models.AIConversation{}).Preload("User")
-
-	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
+	query := filterAIConversations(h.db.Model(&models.AIConversation{}).Preload("User"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1052,32 +1106,36 @@ func (h *AdminHandler) DeleteAIConversation(c *gin.Context) {
 	response.Success(c, nil, "删除AI对话成功")
 }
 
-// GetVerificationCodes 获取验证码列表
-func (h *AdminHandler) GetVerificationCodes(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var codes []models.VerificationCode
-	var total int64
-
-	query := h.db.Model(&models.VerificationCode{})
-
+// filterVerificationCodes 应用验证码列表的筛选条件，列表与导出共用
+func filterVerificationCodes(query *gorm.DB, params url.Values) *gorm.DB {
 	// 按标识符筛选 (手机号/邮箱)
-	if identifier := c.Query("identifier"); identifier != "" {
+	if identifier := params.Get("identifier"); identifier != "" {
 		query = query.Where("identifier LIKE ?", "%"+identifier+"%")
 	}
 
 	// 按类型筛选 (register/login)
-	if codeType := c.Query("type"); codeType != "" {
+	if codeType := params.Get("type"); codeType != "" {
 		query = query.Where("type = ?", codeType)
 	}
 
 	// 按是否已使用筛选
-	if used := c.Query("used"); used != "" {
+	if used := params.Get("used"); used != "" {
 		isUsed := used == "true"
 		query = query.Where("used = ?", isUsed)
 	}
+	return query
+}
+
+// GetVerificationCodes 获取验证码列表
+func (h *AdminHandler) GetVerificationCodes(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var codes []models.VerificationCode
+	var total int64
+
+	query := filterVerificationCodes(h.db.Model(&models.VerificationCode{}), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1139,6 +1197,18 @@ func (h *AdminHandler) GetTrainingStats(c *gin.Context) {
 		PracticeCount   int64 `json:"practice_count"`
 	}
 
+	// 历史汇总数据完整时，由汇总服务累加 daily_metrics 并实时统计今天
+	if dashboard, ok, err := services.NewRollupService(h.db).DashboardStats(time.Now()); err == nil && ok {
+		stats.TotalRecords = dashboard.TotalRecords
+		stats.TotalUsers = dashboard.TotalUsers
+		stats.MeditationCount = dashboard.MeditationCount
+		stats.AirflowCount = dashboard.AirflowCount
+		stats.ExposureCount = dashboard.ExposureCount
+		stats.PracticeCount = dashboard.PracticeCount
+		response.Success(c, stats, "获取成功")
+		return
+	}
+
 	h.db.Model(&models.TrainingRecord{}).Count(&stats.TotalRecords)
 	h.db.Model(&models.User{}).Count(&stats.TotalUsers)
 	h.db.Model(&models.TrainingRecord{}).Where("type = ?", "meditation").Count(&stats.MeditationCount)
@@ -1149,35 +1219,39 @@ func (h *AdminHandler) GetTrainingStats(c *gin.Context) {
 	response.Success(c, stats, "获取成功")
 }
 
-// 获取训练记录列表
-func (h *AdminHandler) GetTrainingRecords(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var records []models.TrainingRecord
-	var total int64
-
-	// 使用 Preload 预加载用户信息，确保关联数据正确加载
-	query := h.db.Model(&models.TrainingRecord{}).Preload("User")
-
+// filterTrainingRecords 应用训练记录列表的筛选条件，列表与导出共用
+func filterTrainingRecords(query *gorm.DB, params url.Values) *gorm.DB {
 	// 按类型筛选
-	if recordType := c.Query("type"); recordType != "" {
+	if recordType := params.Get("type"); recordType != "" {
 		query = query.Where("type = ?", recordType)
 	}
 
 	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
+	if userID := params.Get("user_id"); userID != "" {
 		query = query.Where("user_id = ?", userID)
 	}
 
 	// 按日期范围筛选
-	if startDate := c.Query("start_date"); startDate != "" {
+	if startDate := params.Get("start_date"); startDate != "" {
 		query = query.Where("timestamp >= ?", startDate)
 	}
-	if endDate := c.Query("end_date"); endDate != "" {
+	if endDate := params.Get("end_date"); endDate != "" {
 		query = query.Where("timestamp <= ?", endDate)
 	}
+	return query
+}
+
+// 获取训练记录列表
+func (h *AdminHandler) GetTrainingRecords(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var records []models.TrainingRecord
+	var total int64
+
+	// 使用 Preload 预加载用户信息，确保关联数据正确加载
+	query := filterTrainingRecords(h.db.Model(&models.TrainingRecord{}).Preload("User"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1211,6 +1285,7 @@ func (h *AdminHandler) GetTrainingRecords(c *gin.Context) {
 	}
 	dtos := make([]trainingRecordDTO, 0, len(records))
 	for _, r := range records {
+		signRecordVideo(c, h.media, &r)
 		username := ""
 		if r.User.Username != "" {
 			username = r.User.Username
@@ -1242,6 +1317,7 @@ func (h *AdminHandler) GetTrainingRecord(c *gin.Context) {
 		return
 	}
 
+	signRecordVideo(c, h.media, &record)
 	response.Success(c, record, "获取成功")
 }
 
@@ -1273,7 +1349,16 @@ func (h *AdminHandler) UpdateTrainingRecord(c *gin.Context) {
 		record.Duration = *req.Duration
 	}
 	if req.Data != nil {
+		// 视频地址不通过此接口修改（返回给管理端的是签名链接），删除视频使用视频管理接口
+		videoURL, hasVideo := record.Data["video_url"]
 		record.Data = models.JSONB(*req.Data)
+		if record.Data == nil {
+			record.Data = models.JSONB{}
+		}
+		delete(record.Data, "video_url")
+		if hasVideo {
+			record.Data["video_url"] = videoURL
+		}
 	}
 	if req.Timestamp != nil {
 		record.Timestamp = *req.Timestamp
@@ -1286,6 +1371,7 @@ func (h *AdminHandler) UpdateTrainingRecord(c *gin.Context) {
 	}
 
 	h.logOperation(c, "UpdateTrainingRecord", "TrainingRecord", record.ID.String(), "训练记录更新成功", "Success")
+	signRecordVideo(c, h.media, &record)
 	response.Success(c, record, "更新成功")
 }
 
@@ -1300,11 +1386,27 @@ func (h *AdminHandler) DeleteTrainingRecord(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.Where("id IN ?", req.IDs).Delete(&models.TrainingRecord{}).Error; err != nil {
+	var mediaURLs []string
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("training_records").
+			Select("data->>'video_url'").
+			Where("id IN ? AND COALESCE(data->>'video_url', '') <> ''", req.IDs).
+			Scan(&mediaURLs).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("source = ? AND source_id IN ?", services.VideoSourceExposure, req.IDs).Delete(&models.VideoAsset{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", req.IDs).Delete(&models.TrainingRecord{}).Error
+	})
+	if err != nil {
 		h.logOperation(c, "DeleteTrainingRecord", "TrainingRecord", strings.Join(req.IDs, ","), "删除训练记录失败: "+err.Error(), "Failure")
 		response.Error(c, http.StatusInternalServerError, "删除失败")
 		return
 	}
+	// 训练记录中的视频在删除记录后从存储中删除
+	h.media.RemoveURLs(c.Request.Context(), mediaURLs)
 
 	h.logOperation(c, "DeleteTrainingRecord", "TrainingRecord", strings.Join(req.IDs, ","), "删除训练记录成功", "Success")
 	response.Success(c, nil, "删除成功")
@@ -1312,44 +1414,48 @@ func (h *AdminHandler) DeleteTrainingRecord(c *gin.Context) {
 
 // ========== 操作日志管理 ==========
 
-// 获取操作日志列表
-func (h *AdminHandler) GetOperationLogs(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var logs []models.OperationLog
-	var total int64
-
-	query := h.db.Model(&models.OperationLog{})
-
+// filterOperationLogs 应用操作日志列表的筛选条件，列表与导出共用
+func filterOperationLogs(query *gorm.DB, params url.Values) *gorm.DB {
 	// 按操作类型筛选
-	if action := c.Query("action"); action != "" {
+	if action := params.Get("action"); action != "" {
 		query = query.Where("action LIKE ?", "%"+action+"%")
 	}
 
 	// 按资源类型筛选
-	if resource := c.Query("resource"); resource != "" {
+	if resource := params.Get("resource"); resource != "" {
 		query = query.Where("resource = ?", resource)
 	}
 
 	// 按状态筛选
-	if status := c.Query("status"); status != "" {
+	if status := params.Get("status"); status != "" {
 		query = query.Where("status = ?", status)
 	}
 
 	// 按管理员筛选
-	if username := c.Query("username"); username != "" {
+	if username := params.Get("username"); username != "" {
 		query = query.Where("username LIKE ?", "%"+username+"%")
 	}
 
 	// 按时间范围筛选
-	if startDate := c.Query("start_date"); startDate != "" {
+	if startDate := params.Get("start_date"); startDate != "" {
 		query = query.Where("created_at >= ?", startDate)
 	}
-	if endDate := c.Query("end_date"); endDate != "" {
+	if endDate := params.Get("end_date"); endDate != "" {
 		query = query.Where("created_at <= ?", endDate)
 	}
+	return query
+}
+
+// 获取操作日志列表
+func (h *AdminHandler) GetOperationLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var logs []models.OperationLog
+	var total int64
+
+	query := filterOperationLogs(h.db.Model(&models.OperationLog{}), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1381,31 +1487,35 @@ func (h *AdminHandler) GetOperationLog(c *gin.Context) {
 
 // ========== 评论管理 ==========
 
-// 获取评论列表
-func (h *AdminHandler) GetComments(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var comments []models.Comment
-	var total int64
-
-	query := h.db.Model(&models.Comment{}).Preload("User").Preload("Post")
-
+// filterComments 应用评论列表的筛选条件，列表与导出共用
+func filterComments(query *gorm.DB, params url.Values) *gorm.DB {
 	// 按帖子ID筛选
-	if postID := c.Query("post_id"); postID != "" {
+	if postID := params.Get("post_id"); postID != "" {
 		query = query.Where("post_id = ?", postID)
 	}
 
 	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
+	if userID := params.Get("user_id"); userID != "" {
 		query = query.Where("user_id = ?", userID)
 	}
 
 	// 搜索评论内容
-	if keyword := c.Query("keyword"); keyword != "" {
+	if keyword := params.Get("keyword"); keyword != "" {
 		query = query.Where("content LIKE ?", "%"+keyword+"%")
 	}
+	return query
+}
+
+// 获取评论列表
+func (h *AdminHandler) GetComments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var comments []models.Comment
+	var total int64
+
+	query := filterComments(h.db.Model(&models.Comment{}).Preload("User").Preload("Post"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1506,6 +1616,20 @@ func (h *AdminHandler) DeleteComment(c *gin.Context) {
 
 // ========== 关注/收藏关系管理 ==========
 
+// filterFollows 应用关注列表的筛选条件，列表与导出共用
+func filterFollows(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按关注者ID筛选
+	if followerID := params.Get("follower_id"); followerID != "" {
+		query = query.Where("follower_id = ?", followerID)
+	}
+
+	// 按被关注者ID筛选
+	if followeeID := params.Get("followee_id"); followeeID != "" {
+		query = query.Where("followee_id = ?", followeeID)
+	}
+	return query
+}
+
 // 获取关注列表
 func (h *AdminHandler) GetFollows(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -1515,17 +1639,7 @@ func (h *AdminHandler) GetFollows(c *gin.Context) {
 	var follows []models.Follow
 	var total int64
 
-	query := h.db.Model(&models.Follow{}).Preload("Follower").Preload("Followee")
-
-	// 按关注者ID筛选
-	if followerID := c.Query("follower_id"); followerID != "" {
-		query = query.Where("follower_id = ?", followerID)
-	}
-
-	// 按被关注者ID筛选
-	if followeeID := c.Query("followee_id"); followeeID != "" {
-		query = query.Where("followee_id = ?", followeeID)
-	}
+	query := filterFollows(h.db.Model(&models.Follow{}).Preload("Follower").Preload("Followee"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1563,6 +1677,20 @@ func (h *AdminHandler) DeleteFollow(c *gin.Context) {
 	response.Success(c, nil, "删除成功")
 }
 
+// filterPostCollections 应用收藏列表的筛选条件，列表与导出共用
+func filterPostCollections(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按用户ID筛选
+	if userID := params.Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	// 按帖子ID筛选
+	if postID := params.Get("post_id"); postID != "" {
+		query = query.Where("post_id = ?", postID)
+	}
+	return query
+}
+
 // 获取收藏列表
 func (h *AdminHandler) GetPostCollections(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -1572,17 +1700,7 @@ func (h *AdminHandler) GetPostCollections(c *gin.Context) {
 	var collections []models.PostCollection
 	var total int64
 
-	query := h.db.Model(&models.PostCollection{}).Preload("User").Preload("Post")
-
-	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
-
-	// 按帖子ID筛选
-	if postID := c.Query("post_id"); postID != "" {
-		query = query.Where("post_id = ?", postID)
-	}
+	query := filterPostCollections(h.db.Model(&models.PostCollection{}).Preload("User").Preload("Post"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1622,6 +1740,20 @@ func (h *AdminHandler) DeletePostCollection(c *gin.Context) {
 
 // ========== 点赞管理 ==========
 
+// filterPostLikes 应用点赞列表的筛选条件，列表与导出共用
+func filterPostLikes(query *gorm.DB, params url.Values) *gorm.DB {
+	// 按帖子ID筛选
+	if postID := params.Get("post_id"); postID != "" {
+		query = query.Where("post_id = ?", postID)
+	}
+
+	// 按用户ID筛选
+	if userID := params.Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	return query
+}
+
 // 获取帖子点赞列表
 func (h *AdminHandler) GetPostLikes(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -1631,17 +1763,7 @@ func (h *AdminHandler) GetPostLikes(c *gin.Context) {
 	var likes []models.PostLike
 	var total int64
 
-	query := h.db.Model(&models.PostLike{}).Preload("User").Preload("Post")
-
-	// 按帖子ID筛选
-	if postID := c.Query("post_id"); postID != "" {
-		query = query.Where("post_id = ?", postID)
-	}
-
-	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
+	query := filterPostLikes(h.db.Model(&models.PostLike{}).Preload("User").Preload("Post"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1682,54 +1804,32 @@ func (h *AdminHandler) DeletePostLike(c *gin.Context) {
 // ========== 增强的数据统计 ==========
 
 // 获取详细的数据统计
+// 已结束的自然日读取 daily_metrics 汇总，只有今天实时计数；历史汇总不完整时全部实时统计
 func (h *AdminHandler) GetDetailedStats(c *gin.Context) {
-	var stats struct {
-		// 用户统计
-		TotalUsers        int64 `json:"total_users"`
-		ActiveUsers       int64 `json:"active_users"`         // 最近7天活跃用户
-		NewUsersToday     int64 `json:"new_users_today"`      // 今日新增用户
-		NewUsersThisWeek  int64 `json:"new_users_this_week"`  // 本周新增用户
-		NewUsersThisMonth int64 `json:"new_users_this_month"` // 本月新增用户
-
-		// 训练统计
-		TotalRecords    int64 `json:"total_records"`
-		MeditationCount int64 `json:"meditation_count"`
-		AirflowCount    int64 `json:"airflow_count"`
-		ExposureCount   int64 `json:"exposure_count"`
-		PracticeCount   int64 `json:"practice_count"`
-		TotalDuration   int64 `json:"total_duration"` // 总训练时长（分钟）
-		AvgDuration     int64 `json:"avg_duration"`   // 平均训练时长（分钟）
-
-		// 社区统计
-		TotalPosts       int64 `json:"total_posts"`
-		TotalComments    int64 `json:"total_comments"`
-		TotalLikes       int64 `json:"total_likes"`
-		TotalCollections int64 `json:"total_collections"`
-		TotalFollows     int64 `json:"total_follows"`
-		TotalRooms       int64 `json:"total_rooms"`
-		ActiveRooms      int64 `json:"active_rooms"`
-
-		// AI功能统计
-		TotalAIConversations int64 `json:"total_ai_conversations"`
-
-		// 内容统计
-		TotalTongueTwisters   int64 `json:"total_tongue_twisters"`
-		TotalDailyExpressions int64 `json:"total_daily_expressions"`
+	stats, ok, err := services.NewRollupService(h.db).DashboardStats(time.Now())
+	if err != nil {
+		log.Printf("[统计] 读取汇总数据失败，回退为实时统计: %v", err)
+	}
+	if !ok {
+		stats = h.detailedStatsLive()
 	}
 
-	// 用户统计
-	h.db.Model(&models.User{}).Count(&stats.TotalUsers)
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
-	h.db.Model(&models.User{}).Where("created_at >= ?", sevenDaysAgo).Count(&stats.ActiveUsers)
+	response.Success(c, stats, "获取成功")
+}
 
-	today := time.Now().Format("2006-01-02")
-	h.db.Model(&models.User{}).Where("DATE(created_at) = ?", today).Count(&stats.NewUsersToday)
+// detailedStatsLive 直接扫描原始表计算仪表盘统计，仅在历史汇总数据不完整时使用
+func (h *AdminHandler) detailedStatsLive() *services.DashboardStats {
+	stats := &services.DashboardStats{}
 
-	weekStart := time.Now().AddDate(0, 0, -int(time.Now().Weekday()))
-	h.db.Model(&models.User{}).Where("created_at >= ?", weekStart).Count(&stats.NewUsersThisWeek)
+	// 用户统计（所有日期边界按北京时间的自然日/周/月计算）
+	now := time.Now()
+	h.db.Model(&models.User{}).Count(&stats.TotalUsers)
+	sevenDaysAgo := services.StartOfDay(now).AddDate(0, 0, -6)
+	stats.ActiveUsers, _ = services.NewAnalyticsService(h.db).ActiveUserCount(sevenDaysAgo, now)
 
-	monthStart := time.Now().AddDate(0, 0, -time.Now().Day())
-	h.db.Model(&models.User{}).Where("created_at >= ?", monthStart).Count(&stats.NewUsersThisMonth)
+	h.db.Model(&models.User{}).Where("created_at >= ?", services.StartOfDay(now)).Count(&stats.NewUsersToday)
+	h.db.Model(&models.User{}).Where("created_at >= ?", services.StartOfWeek(now)).Count(&stats.NewUsersThisWeek)
+	h.db.Model(&models.User{}).Where("created_at >= ?", services.StartOfMonth(now)).Count(&stats.NewUsersThisMonth)
 
 	// 训练统计
 	h.db.Model(&models.TrainingRecord{}).Count(&stats.TotalRecords)
@@ -1762,37 +1862,41 @@ func (h *AdminHandler) GetDetailedStats(c *gin.Context) {
 	h.db.Model(&models.TongueTwister{}).Count(&stats.TotalTongueTwisters)
 	h.db.Model(&models.DailyExpression{}).Count(&stats.TotalDailyExpressions)
 
-	response.Success(c, stats, "获取成功")
+	return stats
 }
 
 // ========== 绕口令管理 ==========
 
-// 获取绕口令列表
-func (h *AdminHandler) GetTongueTwisters(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var tongueTwisters []models.TongueTwister
-	var total int64
-
-	query := h.db.Model(&models.TongueTwister{})
-
+// filterTongueTwisters 应用绕口令列表的筛选条件，列表与导出共用
+func filterTongueTwisters(query *gorm.DB, params url.Values) *gorm.DB {
 	// 搜索
-	if keyword := c.Query("keyword"); keyword != "" {
+	if keyword := params.Get("keyword"); keyword != "" {
 		query = query.Where("title LIKE ? OR content LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	// 按难度筛选
-	if level := c.Query("level"); level != "" {
+	if level := params.Get("level"); level != "" {
 		query = query.Where("level = ?", level)
 	}
 
 	// 按状态筛选
-	if isActive := c.Query("is_active"); isActive != "" {
+	if isActive := params.Get("is_active"); isActive != "" {
 		active := isActive == "true"
 		query = query.Where("is_active = ?", active)
 	}
+	return query
+}
+
+// 获取绕口令列表
+func (h *AdminHandler) GetTongueTwisters(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var tongueTwisters []models.TongueTwister
+	var total int64
+
+	query := filterTongueTwisters(h.db.Model(&models.TongueTwister{}), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -1829,6 +1933,7 @@ func (h *AdminHandler) CreateTongueTwister(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
 		return
 	}
+	gradeTongueTwister(&req)
 
 	if err := h.db.Create(&req).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "创建失败")
@@ -1858,6 +1963,7 @@ func (h *AdminHandler) BatchCreateTongueTwisters(c *gin.Context) {
 	}
 
 	for _, twister := range req {
+		gradeTongueTwister(&twister)
 		if err := tx.Create(&twister).Error; err != nil {
 			tx.Rollback()
 			response.Error(c, http.StatusInternalServerError, "批量创建失败: "+err.Error())
@@ -1892,13 +1998,15 @@ func (h *AdminHandler) UpdateTongueTwister(c *gin.Context) {
 	tongueTwister.Level = req.Level
 	tongueTwister.Order = req.Order
 	tongueTwister.IsActive = req.IsActive
+	gradeTongueTwister(&tongueTwister)
 
-	if err := h.db.Save(&tongueTwister).Error; err != nil {
+	// 修改经版本流程发布，无发布权限时进入审核
+	changes, err := services.ModelChanges(services.VersionTongueTwister, tongueTwister)
+	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "更新失败")
 		return
 	}
-
-	response.Success(c, tongueTwister, "更新成功")
+	h.saveContentVersion(c, services.VersionTongueTwister, tongueTwister.ID, changes, &tongueTwister)
 }
 
 // 删除绕口令（支持批量删除）
@@ -1970,32 +2078,45 @@ func (h *AdminHandler) CleanTongueTwisters(c *gin.Context) {
 
 // ========== 每日朗诵文案管理 ==========
 
-// 获取每日朗诵文案列表
-func (h *AdminHandler) GetDailyExpressions(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var expressions []models.DailyExpression
-	var total int64
-
-	query := h.db.Model(&models.DailyExpression{})
-
+// filterDailyExpressions 应用每日朗诵文案列表的筛选条件，列表与导出共用
+func filterDailyExpressions(query *gorm.DB, params url.Values) *gorm.DB {
 	// 搜索
-	if keyword := c.Query("keyword"); keyword != "" {
+	if keyword := params.Get("keyword"); keyword != "" {
 		query = query.Where("title LIKE ? OR content LIKE ? OR source LIKE ?",
 			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	// 按状态筛选
-	if isActive := c.Query("is_active"); isActive != "" {
+	if isActive := params.Get("is_active"); isActive != "" {
 		active := isActive == "true"
 		query = query.Where("is_active = ?", active)
 	}
 
+	// 按是否已排期筛选（scheduled=false 为待排期池）
+	if scheduled := params.Get("scheduled"); scheduled != "" {
+		if scheduled == "true" {
+			query = query.Where("date IS NOT NULL")
+		} else {
+			query = query.Where("date IS NULL")
+		}
+	}
+	return query
+}
+
+// 获取每日朗诵文案列表
+func (h *AdminHandler) GetDailyExpressions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var expressions []models.DailyExpression
+	var total int64
+
+	query := filterDailyExpressions(h.db.Model(&models.DailyExpression{}), c.Request.URL.Query())
+
 	query.Count(&total)
 
-	if err := query.Offset(offset).Limit(pageSize).Order("date DESC, created_at DESC").Find(&expressions).Error; err != nil {
+	if err := query.Offset(offset).Limit(pageSize).Order("date DESC NULLS LAST, created_at DESC").Find(&expressions).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "查询失败")
 		return
 	}
@@ -2060,12 +2181,12 @@ func (h *AdminHandler) UpdateDailyExpression(c *gin.Context) {
 	expression.Date = req.Date
 	expression.IsActive = req.IsActive
 
-	if err := h.db.Save(&expression).Error; err != nil {
+	changes, err := services.ModelChanges(services.VersionDailyExpression, expression)
+	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "更新失败")
 		return
 	}
-
-	response.Success(c, expression, "更新成功")
+	h.saveContentVersion(c, services.VersionDailyExpression, expression.ID, changes, &expression)
 }
 
 // 删除每日朗诵文案（支持批量删除）
@@ -2165,8 +2286,13 @@ func (h *AdminHandler) CreateSpeechTechnique(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
 		return
 	}
+	lists, err := parseSpeechLists(&req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
 
-	if err := h.db.Create(&req).Error; err != nil {
+	if err := h.db.Transaction(func(tx *gorm.DB) error { return createSpeechTechnique(tx, &req, lists) }); err != nil {
 		response.Error(c, http.StatusInternalServerError, "创建失败")
 		return
 	}
@@ -2190,20 +2316,31 @@ func (h *AdminHandler) UpdateSpeechTechnique(c *gin.Context) {
 		return
 	}
 
+	// 训练要点与练习文本随其余字段一起进入版本（不提供则保持不变），发布时同步到条目
+	lists, err := parseSpeechLists(&req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	if _, ok := lists[services.SpeechItemTips]; ok {
+		technique.Tips = req.Tips
+	}
+	if _, ok := lists[services.SpeechItemPracticeTexts]; ok {
+		technique.PracticeTexts = req.PracticeTexts
+	}
+
 	technique.Name = req.Name
 	technique.Icon = req.Icon
 	technique.Description = req.Description
-	technique.Tips = req.Tips
-	technique.PracticeTexts = req.PracticeTexts
 	technique.Order = req.Order
 	technique.IsActive = req.IsActive
 
-	if err := h.db.Save(&technique).Error; err != nil {
+	changes, err := services.ModelChanges(services.VersionSpeechTechnique, technique)
+	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "更新失败")
 		return
 	}
-
-	response.Success(c, technique, "更新成功")
+	h.saveContentVersion(c, services.VersionSpeechTechnique, technique.ID, changes, &technique)
 }
 
 // DeleteSpeechTechnique 删除语音技巧
@@ -2217,7 +2354,13 @@ func (h *AdminHandler) DeleteSpeechTechnique(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.Where("id IN ?", req.IDs).Delete(&models.SpeechTechnique{}).Error; err != nil {
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := services.NewSpeechItemService(tx).DeleteForTechniques(tx, req.IDs); err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", req.IDs).Delete(&models.SpeechTechnique{}).Error
+	})
+	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "删除失败")
 		return
 	}
@@ -2244,8 +2387,14 @@ func (h *AdminHandler) BatchCreateSpeechTechniques(c *gin.Context) {
 		return
 	}
 
-	for _, technique := range req {
-		if err := tx.Create(&technique).Error; err != nil {
+	for i := range req {
+		lists, err := parseSpeechLists(&req[i])
+		if err != nil {
+			tx.Rollback()
+			response.Error(c, http.StatusBadRequest, "第 "+strconv.Itoa(i+1)+" 条参数错误: "+err.Error())
+			return
+		}
+		if err := createSpeechTechnique(tx, &req[i], lists); err != nil {
 			tx.Rollback()
 			response.Error(c, http.StatusInternalServerError, "批量创建失败: "+err.Error())
 			return
@@ -2414,31 +2563,35 @@ func (h *AdminHandler) UpdateUserSettings(c *gin.Context) {
 	response.Success(c, settings, "更新成功")
 }
 
-// GetAllUserSettings 获取所有用户设置（分页）
-func (h *AdminHandler) GetAllUserSettings(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var settings []models.UserSettings
-	var total int64
-
-	query := h.db.Model(&models.UserSettings{})
-
+// filterUserSettings 应用用户设置列表的筛选条件，列表与导出共用
+func filterUserSettings(query *gorm.DB, params url.Values) *gorm.DB {
 	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
+	if userID := params.Get("user_id"); userID != "" {
 		query = query.Where("user_id = ?", userID)
 	}
 
 	// 按主题筛选
-	if theme := c.Query("theme"); theme != "" {
+	if theme := params.Get("theme"); theme != "" {
 		query = query.Where("theme = ?", theme)
 	}
 
 	// 按难度筛选
-	if difficulty := c.Query("difficulty_level"); difficulty != "" {
+	if difficulty := params.Get("difficulty_level"); difficulty != "" {
 		query = query.Where("difficulty_level = ?", difficulty)
 	}
+	return query
+}
+
+// GetAllUserSettings 获取所有用户设置（分页）
+func (h *AdminHandler) GetAllUserSettings(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var settings []models.UserSettings
+	var total int64
+
+	query := filterUserSettings(h.db.Model(&models.UserSettings{}), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -2493,31 +2646,35 @@ func (h *AdminHandler) ResetUserSettings(c *gin.Context) {
 
 // ========== 用户反馈管理 ==========
 
-// GetFeedbackList 获取反馈列表
-func (h *AdminHandler) GetFeedbackList(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
-
-	var feedbacks []models.Feedback
-	var total int64
-
-	query := h.db.Model(&models.Feedback{}).Preload("User")
-
+// filterFeedback 应用反馈列表的筛选条件，列表与导出共用
+func filterFeedback(query *gorm.DB, params url.Values) *gorm.DB {
 	// 按类型筛选
-	if feedbackType := c.Query("type"); feedbackType != "" {
+	if feedbackType := params.Get("type"); feedbackType != "" {
 		query = query.Where("type = ?", feedbackType)
 	}
 
 	// 按状态筛选
-	if status := c.Query("status"); status != "" {
+	if status := params.Get("status"); status != "" {
 		query = query.Where("status = ?", status)
 	}
 
 	// 按用户ID筛选
-	if userID := c.Query("user_id"); userID != "" {
+	if userID := params.Get("user_id"); userID != "" {
 		query = query.Where("user_id = ?", userID)
 	}
+	return query
+}
+
+// GetFeedbackList 获取反馈列表
+func (h *AdminHandler) GetFeedbackList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var feedbacks []models.Feedback
+	var total int64
+
+	query := filterFeedback(h.db.Model(&models.Feedback{}).Preload("User"), c.Request.URL.Query())
 
 	query.Count(&total)
 
@@ -2730,47 +2887,25 @@ func (h *AdminHandler) UpdateLegalDocument(c *gin.Context) {
 		return
 	}
 
-	// 更新字段
+	// 只提交传入的字段，启用时同类型其他文档的停用在发布时处理
+	changes := map[string]interface{}{}
 	if req.Type != nil {
-		if *req.Type != "terms_of_service" && *req.Type != "privacy_policy" {
-			response.Error(c, http.StatusBadRequest, "无效的文档类型")
-			return
-		}
-		doc.Type = *req.Type
+		changes["type"] = *req.Type
 	}
 	if req.Title != nil {
-		doc.Title = *req.Title
+		changes["title"] = *req.Title
 	}
 	if req.Content != nil {
-		doc.Content = *req.Content
+		changes["content"] = *req.Content
 	}
 	if req.Version != nil {
-		doc.Version = *req.Version
+		changes["version"] = *req.Version
 	}
 	if req.IsActive != nil {
-		// 如果启用新文档，禁用同类型的其他文档
-		if *req.IsActive && !doc.IsActive {
-			h.db.Model(&models.LegalDocument{}).
-				Where("type = ? AND id != ?", doc.Type, id).
-				Update("is_active", false)
-		}
-		doc.IsActive = *req.IsActive
-	}
-
-	// 更新更新人
-	userID, exists := c.Get("userID")
-	if exists {
-		uid := userID.(uuid.UUID)
-		doc.UpdatedBy = &uid
-	}
-
-	if err := h.db.Save(&doc).Error; err != nil {
-		response.Error(c, http.StatusInternalServerError, "更新法律文档失败")
-		return
+		changes["is_active"] = *req.IsActive
 	}
 
-	h.logOperation(c, "update", "legal_document", doc.ID.String(), "更新法律文档: "+doc.Title, "success")
-	response.Success(c, doc, "更新成功")
+	h.saveContentVersion(c, services.VersionLegalDocument, doc.ID, changes, &doc)
 }
 
 // DeleteLegalDocument 删除法律文档
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 10 << 20
+
+// GetImportFields 获取导入字段定义（用于前端列映射）
+// GET /api/v1/admin/content-import/:kind/fields
+func (h *AdminHandler) GetImportFields(c *gin.Context) {
+	fields, ok := services.ImportFields(c.Param("kind"))
+	if !ok {
+		response.Error(c, http.StatusNotFound, "不支持的导入类型")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"fields":  fields,
+		"formats": []string{"csv", "xlsx", "json"},
+		"modes":   []string{services.ImportModeInsert, services.ImportModeUpsert},
+	}, "获取成功")
+}
+
+// ImportContent 导入练习内容（绕口令/每日朗诵/语音技巧）
+// POST /api/v1/admin/content-import/:kind  multipart: file, format, mode=insert|upsert, dry_run=true, mapping={"源列":"字段"}
+func (h *AdminHandler) ImportContent(c *gin.Context) {
+	kind := c.Param("kind")
+	if _, ok := services.ImportFields(kind); !ok {
+		response.Error(c, http.StatusNotFound, "不支持的导入类型")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "请上传导入文件")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		response.Error(c, http.StatusBadRequest, "导入文件不能超过 10MB")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "读取文件失败: "+err.Error())
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize+1))
+	file.Close()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "读取文件失败: "+err.Error())
+		return
+	}
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			response.Error(c, http.StatusBadRequest, "列映射格式错误，应为 {\"源列\": \"字段\"}")
+			return
+		}
+	}
+
+	headers, rows, firstRow, err := services.ParseImportTable(c.PostForm("format"), fileHeader.Filename, data)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+	opts := services.ImportOptions{
+		Kind:     kind,
+		FileName: fileHeader.Filename,
+		Mode:     c.DefaultPostForm("mode", services.ImportModeInsert),
+		DryRun:   dryRun,
+		Mapping:  mapping,
+	}
+
+	var createdBy uuid.UUID
+	if userID, ok := c.Get("userID"); ok {
+		createdBy = userID.(uuid.UUID)
+	}
+
+	report, err := services.NewImportService(h.db).Import(headers, rows, firstRow, opts, createdBy)
+	if err != nil {
+		if !dryRun {
+			h.logOperation(c, "ImportContent", kind, "", "导入失败: "+err.Error(), "Failure")
+		}
+		response.Error(c, http.StatusBadRequest, "导入失败: "+err.Error())
+		return
+	}
+
+	if dryRun {
+		response.Success(c, report, "预览成功")
+		return
+	}
+	details := fmt.Sprintf("导入 %s: 新增 %d，更新 %d，跳过 %d，失败 %d", fileHeader.Filename, report.Created, report.Updated, report.Skipped, report.Invalid)
+	h.logOperation(c, "ImportContent", kind, report.ID.String(), details, "Success")
+	response.Success(c, report, "导入完成")
+}
+
+// GetContentImports 获取导入记录
+// GET /api/v1/admin/content-imports?kind=tongue_twisters&page=1&page_size=20
+func (h *AdminHandler) GetContentImports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := h.db.Model(&models.ContentImport{})
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var imports []models.ContentImport
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&imports).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取导入记录失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"imports":   imports,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// DownloadImportErrors 下载导入错误报告（CSV）
+// GET /api/v1/admin/content-imports/:id/errors
+func (h *AdminHandler) DownloadImportErrors(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的导入记录ID")
+		return
+	}
+
+	var record models.ContentImport
+	if err := h.db.First(&record, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.Error(c, http.StatusNotFound, "导入记录不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取导入记录失败")
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=import_errors_"+record.ID.String()+".csv")
+	if err := services.WriteErrorReport(c.Writer, &record); err != nil {
+		c.Error(err)
+	}
+}
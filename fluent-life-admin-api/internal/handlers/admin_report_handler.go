@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/mailer"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminReportHandler 管理员定时报表处理器
+type AdminReportHandler struct {
+	db      *gorm.DB
+	reports *services.ReportService
+}
+
+// NewAdminReportHandler 创建管理员定时报表处理器
+func NewAdminReportHandler(db *gorm.DB, m *mailer.Mailer) *AdminReportHandler {
+	return &AdminReportHandler{db: db, reports: services.NewReportService(db, m)}
+}
+
+type reportScheduleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	CronExpr    string `json:"cron_expr" binding:"required"`
+	Metrics     string `json:"metrics" binding:"required"`
+	Recipients  string `json:"recipients" binding:"required"`
+	Granularity string `json:"granularity"`
+	RangeDays   int    `json:"range_days"`
+	Enabled     *bool  `json:"enabled"`
+}
+
+// apply 将请求写入报表配置并校验，同时重新计算下一次触发时间
+func (req *reportScheduleRequest) apply(schedule *models.ReportSchedule) error {
+	schedule.Name = req.Name
+	schedule.CronExpr = req.CronExpr
+	schedule.Metrics = req.Metrics
+	schedule.Recipients = req.Recipients
+	schedule.Granularity = req.Granularity
+	schedule.RangeDays = req.RangeDays
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if err := services.ValidateSchedule(schedule); err != nil {
+		return err
+	}
+	next, err := services.NextRunTime(schedule.CronExpr, time.Now())
+	if err != nil {
+		return err
+	}
+	schedule.NextRunAt = next
+	return nil
+}
+
+// GetReportSchedules 获取定时报表列表
+// GET /api/v1/admin/reports?page=1&page_size=20
+func (h *AdminReportHandler) GetReportSchedules(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	var total int64
+	h.db.Model(&models.ReportSchedule{}).Count(&total)
+
+	var schedules []models.ReportSchedule
+	if err := h.db.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&schedules).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取报表列表失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"schedules": schedules,
+		"metrics":   services.MetricLabels,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// GetReportSchedule 获取定时报表详情
+// GET /api/v1/admin/reports/:id
+func (h *AdminReportHandler) GetReportSchedule(c *gin.Context) {
+	schedule, ok := h.findSchedule(c)
+	if !ok {
+		return
+	}
+	response.Success(c, schedule, "获取成功")
+}
+
+// CreateReportSchedule 创建定时报表
+// POST /api/v1/admin/reports
+func (h *AdminReportHandler) CreateReportSchedule(c *gin.Context) {
+	var req reportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	schedule := models.ReportSchedule{Enabled: true}
+	if err := req.apply(&schedule); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userID, ok := c.Get("userID"); ok {
+		schedule.CreatedBy = userID.(uuid.UUID)
+	}
+
+	if err := h.db.Create(&schedule).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "创建报表失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, schedule, "创建成功")
+}
+
+// UpdateReportSchedule 更新定时报表
+// PUT /api/v1/admin/reports/:id
+func (h *AdminReportHandler) UpdateReportSchedule(c *gin.Context) {
+	schedule, ok := h.findSchedule(c)
+	if !ok {
+		return
+	}
+
+	var req reportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	if err := req.apply(schedule); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.db.Save(schedule).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "更新报表失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, schedule, "更新成功")
+}
+
+// DeleteReportSchedule 删除定时报表及其执行记录
+// DELETE /api/v1/admin/reports/:id
+func (h *AdminReportHandler) DeleteReportSchedule(c *gin.Context) {
+	schedule, ok := h.findSchedule(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("schedule_id = ?", schedule.ID).Delete(&models.ReportRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(schedule).Error
+	})
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "删除报表失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, nil, "删除成功")
+}
+
+// SendReportNow 立即生成并发送报表，不影响定时计划
+// POST /api/v1/admin/reports/:id/send
+func (h *AdminReportHandler) SendReportNow(c *gin.Context) {
+	schedule, ok := h.findSchedule(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.reports.Run(schedule, services.ReportTriggerManual)
+	if err != nil {
+		// 失败详情同时记录在执行历史中
+		response.Error(c, http.StatusInternalServerError, "发送失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, run, "发送成功")
+}
+
+// GetReportRuns 获取报表执行历史
+// GET /api/v1/admin/reports/:id/runs?page=1&page_size=20&status=failed
+func (h *AdminReportHandler) GetReportRuns(c *gin.Context) {
+	schedule, ok := h.findSchedule(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := h.db.Model(&models.ReportRun{}).Where("schedule_id = ?", schedule.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var runs []models.ReportRun
+	if err := query.Order("started_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取执行记录失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"runs":      runs,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// findSchedule 按路径参数查找报表配置，失败时直接写入错误响应
+func (h *AdminReportHandler) findSchedule(c *gin.Context) (*models.ReportSchedule, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的报表ID")
+		return nil, false
+	}
+
+	var schedule models.ReportSchedule
+	if err := h.db.First(&schedule, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.Error(c, http.StatusNotFound, "报表不存在")
+		} else {
+			response.Error(c, http.StatusInternalServerError, "获取报表失败: "+err.Error())
+		}
+		return nil, false
+	}
+	return &schedule, true
+}
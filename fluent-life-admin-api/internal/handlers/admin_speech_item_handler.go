@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// parseSpeechLists 严格校验请求中的训练要点与练习文本（JSON 字符串数组）；空串表示不提供
+func parseSpeechLists(t *models.SpeechTechnique) (map[string][]string, error) {
+	lists := make(map[string][]string)
+	for kind, raw := range map[string]string{services.SpeechItemTips: t.Tips, services.SpeechItemPracticeTexts: t.PracticeTexts} {
+		if raw == "" {
+			continue
+		}
+		items, err := services.StrictStringList(raw)
+		if err != nil {
+			return nil, errors.New(kind + " " + err.Error())
+		}
+		lists[kind] = items
+	}
+	return lists, nil
+}
+
+// createSpeechTechnique 创建语音技巧并生成条目
+func createSpeechTechnique(tx *gorm.DB, t *models.SpeechTechnique, lists map[string][]string) error {
+	t.Tips, t.PracticeTexts = "[]", "[]"
+	// Select("*") 让 is_active=false 也写入，而不是被列默认值 true 取代
+	if err := tx.Select("*").Create(t).Error; err != nil {
+		return err
+	}
+	items := services.NewSpeechItemService(tx)
+	for kind, texts := range lists {
+		if err := items.Replace(tx, t.ID, kind, texts); err != nil {
+			return err
+		}
+	}
+	return tx.First(t, "id = ?", t.ID).Error
+}
+
+func respondSpeechItemError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrContentNotFound), errors.Is(err, services.ErrSpeechItemNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrSpeechItemInvalid):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "操作失败: "+err.Error())
+	}
+}
+
+// speechItemPaths URL 路径段到条目类型
+var speechItemPaths = map[string]string{
+	"tips":           services.SpeechItemTips,
+	"practice-texts": services.SpeechItemPracticeTexts,
+}
+
+// speechItemParams 解析技巧 ID 与条目类型
+func speechItemParams(c *gin.Context) (uuid.UUID, string, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的语音技巧ID")
+		return uuid.Nil, "", false
+	}
+	kind, ok := speechItemPaths[c.Param("kind")]
+	if !ok {
+		response.Error(c, http.StatusNotFound, "条目类型应为 tips 或 practice-texts")
+		return uuid.Nil, "", false
+	}
+	return id, kind, true
+}
+
+// GetSpeechItems 获取语音技巧的训练要点或练习文本条目（含停用的）
+// GET /api/v1/admin/speech-techniques/:id/tips
+// GET /api/v1/admin/speech-techniques/:id/practice-texts
+func (h *AdminHandler) GetSpeechItems(c *gin.Context) {
+	id, kind, ok := speechItemParams(c)
+	if !ok {
+		return
+	}
+	items, err := services.NewSpeechItemService(h.db).List(id, kind)
+	if err != nil {
+		respondSpeechItemError(c, err)
+		return
+	}
+	response.Success(c, items, "获取成功")
+}
+
+// CreateSpeechItem 新增条目，未指定 order 时追加到末尾
+// POST /api/v1/admin/speech-techniques/:id/practice-texts  {"content": "...", "difficulty": "basic", "audio_url": "", "is_active": true}
+func (h *AdminHandler) CreateSpeechItem(c *gin.Context) {
+	id, kind, ok := speechItemParams(c)
+	if !ok {
+		return
+	}
+	var req services.SpeechItemInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	item, err := services.NewSpeechItemService(h.db).Create(id, kind, req)
+	if err != nil {
+		respondSpeechItemError(c, err)
+		return
+	}
+	h.logOperation(c, "create", "speech_technique_"+kind, id.String(), "新增条目", "success")
+	response.Success(c, item, "创建成功")
+}
+
+// UpdateSpeechItem 修改条目，只修改传入的字段
+// PUT /api/v1/admin/speech-techniques/:id/:kind/:item_id
+func (h *AdminHandler) UpdateSpeechItem(c *gin.Context) {
+	id, kind, ok := speechItemParams(c)
+	if !ok {
+		return
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的条目ID")
+		return
+	}
+	var req services.SpeechItemInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	item, err := services.NewSpeechItemService(h.db).Update(id, kind, itemID, req)
+	if err != nil {
+		respondSpeechItemError(c, err)
+		return
+	}
+	h.logOperation(c, "update", "speech_technique_"+kind, itemID.String(), "修改条目", "success")
+	response.Success(c, item, "更新成功")
+}
+
+// DeleteSpeechItem 删除条目
+// DELETE /api/v1/admin/speech-techniques/:id/:kind/:item_id
+func (h *AdminHandler) DeleteSpeechItem(c *gin.Context) {
+	id, kind, ok := speechItemParams(c)
+	if !ok {
+		return
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的条目ID")
+		return
+	}
+	if err := services.NewSpeechItemService(h.db).Delete(id, kind, itemID); err != nil {
+		respondSpeechItemError(c, err)
+		return
+	}
+	h.logOperation(c, "delete", "speech_technique_"+kind, itemID.String(), "删除条目", "success")
+	response.Success(c, nil, "删除成功")
+}
+
+// ReorderSpeechItems 按给定顺序重排条目，ids 需包含该技巧的全部条目
+// POST /api/v1/admin/speech-techniques/:id/:kind/reorder  {"ids": ["...", "..."]}
+func (h *AdminHandler) ReorderSpeechItems(c *gin.Context) {
+	id, kind, ok := speechItemParams(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		IDs []uuid.UUID `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误，需要提供条目ID列表")
+		return
+	}
+	if err := services.NewSpeechItemService(h.db).Reorder(id, kind, req.IDs); err != nil {
+		respondSpeechItemError(c, err)
+		return
+	}
+	response.Success(c, nil, "排序成功")
+}
+
+// MigrateSpeechItems 把旧的 JSON 字符串要点与练习文本迁移为条目，返回无法解析的记录
+// POST /api/v1/admin/speech-techniques/migrate-items?dry_run=true
+func (h *AdminHandler) MigrateSpeechItems(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "true"))
+	report, err := services.NewSpeechItemService(h.db).MigrateLegacy(dryRun)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !dryRun {
+		h.logOperation(c, "migrate", "speech_technique", "", "迁移语音技巧条目", "success")
+	}
+	response.Success(c, report, "迁移完成")
+}
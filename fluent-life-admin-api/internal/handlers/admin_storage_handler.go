@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminStorageHandler 管理员媒体存储处理器
+type AdminStorageHandler struct {
+	media *services.MediaService
+}
+
+// NewAdminStorageHandler 创建管理员媒体存储处理器
+func NewAdminStorageHandler(media *services.MediaService) *AdminStorageHandler {
+	return &AdminStorageHandler{media: media}
+}
+
+// GetStorageUsage 存储用量，按来源（训练视频、帖子、头像、音色试听、练习示范音频、无引用）与用户汇总
+// GET /api/v1/admin/storage/usage?prefix=&top=20
+func (h *AdminStorageHandler) GetStorageUsage(c *gin.Context) {
+	top, _ := strconv.Atoi(c.DefaultQuery("top", "20"))
+	if top < 1 || top > 100 {
+		top = 20
+	}
+	report, err := h.media.Usage(c.Request.Context(), c.Query("prefix"), top)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "统计存储用量失败: "+err.Error())
+		return
+	}
+	response.Success(c, report, "获取成功")
+}
+
+// RunStorageGC 查找并删除没有帖子、训练记录或头像引用的对象，默认只预览不删除
+// POST /api/v1/admin/storage/gc?dry_run=true&min_age_hours=24&prefix=
+func (h *AdminStorageHandler) RunStorageGC(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "true"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "dry_run 参数无效")
+		return
+	}
+	minAgeHours, err := strconv.Atoi(c.DefaultQuery("min_age_hours", "24"))
+	if err != nil || minAgeHours < 1 {
+		response.Error(c, http.StatusBadRequest, "min_age_hours 至少为 1")
+		return
+	}
+	report, err := h.media.GC(c.Request.Context(), c.Query("prefix"), time.Duration(minAgeHours)*time.Hour, dryRun)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "清理失败: "+err.Error())
+		return
+	}
+	msg := "清理完成"
+	if dryRun {
+		msg = "预览完成，未删除任何文件"
+	}
+	response.Success(c, report, msg)
+}
+
+// GetMediaAccessLogs 管理员播放用户媒体的审计日志
+// GET /api/v1/admin/media/access-logs?page=1&page_size=20&admin_id=&user_id=&video_id=&start_date=2026-01-01&end_date=2026-01-31
+func (h *AdminStorageHandler) GetMediaAccessLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	var filter services.MediaAccessFilter
+	for param, dest := range map[string]**uuid.UUID{
+		"admin_id": &filter.AdminID,
+		"user_id":  &filter.OwnerID, // 视频上传者
+		"video_id": &filter.VideoID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				response.Error(c, http.StatusBadRequest, "无效的 "+param)
+				return
+			}
+			*dest = &id
+		}
+	}
+	if startDate := c.Query("start_date"); startDate != "" {
+		start, err := services.ParseDate(startDate)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "开始日期格式错误，应为 YYYY-MM-DD")
+			return
+		}
+		filter.Start = &start
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		end, err := services.ParseDate(endDate)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "结束日期格式错误，应为 YYYY-MM-DD")
+			return
+		}
+		end = end.AddDate(0, 0, 1) // 包含结束日期当天
+		filter.End = &end
+	}
+
+	logs, total, err := h.media.AccessLogs(filter, page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取访问日志失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{
+		"logs":      logs,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminTranslationHandler 管理员内容翻译处理器
+type AdminTranslationHandler struct {
+	db *gorm.DB
+}
+
+// NewAdminTranslationHandler 创建管理员内容翻译处理器
+func NewAdminTranslationHandler(db *gorm.DB) *AdminTranslationHandler {
+	return &AdminTranslationHandler{db: db}
+}
+
+func respondTranslationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTranslationInvalid):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrContentNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "操作失败: "+err.Error())
+	}
+}
+
+// GetTranslationConfig 支持的语言、回退顺序及可翻译的内容类型与字段
+// GET /api/v1/admin/translations/config
+func (h *AdminTranslationHandler) GetTranslationConfig(c *gin.Context) {
+	fallbacks := make(map[string][]string, len(services.SupportedLocales))
+	for _, locale := range services.SupportedLocales {
+		fallbacks[locale] = services.FallbackChain(locale)
+	}
+	response.Success(c, gin.H{
+		"default_locale": services.DefaultLocale,
+		"locales":        services.SupportedLocales,
+		"fallbacks":      fallbacks,
+		"types":          services.TranslatableTypes(),
+	}, "获取成功")
+}
+
+// GetMissingTranslations 某个语言下缺少译文的内容及各类型的翻译进度
+// GET /api/v1/admin/translations/missing?locale=zh-TW&type=tongue_twister&include_stale=true&page=1&page_size=20
+func (h *AdminTranslationHandler) GetMissingTranslations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	locale := c.Query("locale")
+	if locale == "" {
+		response.Error(c, http.StatusBadRequest, "请指定语言 locale")
+		return
+	}
+	includeStale, _ := strconv.ParseBool(c.DefaultQuery("include_stale", "true"))
+
+	report, err := services.NewTranslationService(h.db).Missing(locale, c.Query("type"), includeStale, page, pageSize)
+	if err != nil {
+		respondTranslationError(c, err)
+		return
+	}
+	response.Success(c, report, "获取成功")
+}
+
+// GetContentTranslations 一条内容的原文与各语言译文（含是否过期）
+// GET /api/v1/admin/translations/:type/:id
+func (h *AdminTranslationHandler) GetContentTranslations(c *gin.Context) {
+	item, err := services.NewTranslationService(h.db).Get(c.Param("type"), c.Param("id"))
+	if err != nil {
+		respondTranslationError(c, err)
+		return
+	}
+	response.Success(c, item, "获取成功")
+}
+
+// SaveContentTranslations 保存一条内容在某个语言下的译文，字段值为空串表示删除该字段译文
+// PUT /api/v1/admin/translations/:type/:id/:locale  {"fields": {"title": "...", "content": "..."}}
+func (h *AdminTranslationHandler) SaveContentTranslations(c *gin.Context) {
+	var req struct {
+		Fields map[string]string `json:"fields" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误，需要提供 fields")
+		return
+	}
+
+	var updatedBy uuid.UUID
+	if userID, ok := c.Get("userID"); ok {
+		updatedBy = userID.(uuid.UUID)
+	}
+	item, err := services.NewTranslationService(h.db).Save(c.Param("type"), c.Param("id"), c.Param("locale"), req.Fields, updatedBy)
+	if err != nil {
+		respondTranslationError(c, err)
+		return
+	}
+	response.Success(c, item, "保存成功")
+}
+
+// DeleteContentTranslations 删除一条内容在某个语言下的全部译文
+// DELETE /api/v1/admin/translations/:type/:id/:locale
+func (h *AdminTranslationHandler) DeleteContentTranslations(c *gin.Context) {
+	if err := services.NewTranslationService(h.db).DeleteLocale(c.Param("type"), c.Param("id"), c.Param("locale")); err != nil {
+		respondTranslationError(c, err)
+		return
+	}
+	response.Success(c, nil, "删除成功")
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxAnalysisPromptLength 重新分析时自定义提示词的长度上限
+const maxAnalysisPromptLength = 4000
+
+// GetVideoAnalyses 视频 AI 分析记录列表，按时间倒序
+// GET /api/v1/admin/video-analyses?page=1&page_size=20&video_id=&user_id=&module_id=&step_id=&status=success|failed
+func (h *AdminVideoHandler) GetVideoAnalyses(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	filter := services.VideoAnalysisFilter{
+		Source:   c.Query("source"),
+		ModuleID: c.Query("module_id"),
+		Status:   c.Query("status"),
+	}
+	switch filter.Status {
+	case "", models.VideoAnalysisSuccess, models.VideoAnalysisFailed:
+	default:
+		response.Error(c, http.StatusBadRequest, "无效的分析状态")
+		return
+	}
+	for param, dest := range map[string]**uuid.UUID{
+		"video_id": &filter.VideoID,
+		"user_id":  &filter.UserID,
+		"step_id":  &filter.StepID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				response.Error(c, http.StatusBadRequest, "无效的 "+param)
+				return
+			}
+			*dest = &id
+		}
+	}
+
+	analyses, total, err := h.analyses.List(filter, page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取分析记录失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{
+		"analyses":  analyses,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// GetVideoAnalysis 分析记录详情，附带视频（索引已删除时为 null）
+// GET /api/v1/admin/video-analyses/:id
+func (h *AdminVideoHandler) GetVideoAnalysis(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "分析记录不存在")
+		return
+	}
+	analysis, err := h.analyses.Get(id)
+	if err != nil {
+		if errors.Is(err, services.ErrVideoAnalysisNotFound) {
+			response.Error(c, http.StatusNotFound, "分析记录不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取分析记录失败: "+err.Error())
+		return
+	}
+
+	var video *VideoListItem
+	row, err := services.NewVideoAssetService(h.db).Get(analysis.VideoSource, analysis.VideoID)
+	if err == nil {
+		item := h.videoListItem(c, row)
+		video = &item
+	} else if !errors.Is(err, services.ErrVideoNotFound) {
+		response.Error(c, http.StatusInternalServerError, "获取视频失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{"analysis": analysis, "video": video}, "获取成功")
+}
+
+// AnalyzeVideo 用配置的分析器重新分析脱敏练习视频；分析失败时同样保存记录并返回 502
+// POST /api/v1/admin/videos/:id/analyze?source=exposure_module  {"prompt": "可选，为空时按步骤生成"}
+func (h *AdminVideoHandler) AnalyzeVideo(c *gin.Context) {
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "视频不存在")
+		return
+	}
+	var req struct {
+		Prompt string `json:"prompt"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "参数错误")
+			return
+		}
+	}
+	if utf8.RuneCountInString(req.Prompt) > maxAnalysisPromptLength {
+		response.Error(c, http.StatusBadRequest, "提示词过长")
+		return
+	}
+
+	analysis, err := h.analyses.Run(c.Request.Context(), c.Query("source"), videoID, req.Prompt, currentAdminID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrVideoNotFound):
+			response.Error(c, http.StatusNotFound, "视频不存在")
+		case errors.Is(err, services.ErrVideoAnalysisUnsupported):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "分析失败: "+err.Error())
+		}
+		return
+	}
+	if analysis.Status == models.VideoAnalysisFailed {
+		response.ErrorWithData(c, http.StatusBadGateway, "分析失败: "+analysis.Error, gin.H{"analysis": analysis})
+		return
+	}
+	response.Success(c, gin.H{"analysis": analysis}, "分析完成")
+}
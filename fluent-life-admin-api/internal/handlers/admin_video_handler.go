@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
 	"fluent-life-admin-api/pkg/response"
 	"fmt"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,11 +14,13 @@ import (
 )
 
 type AdminVideoHandler struct {
-	db *gorm.DB
+	db       *gorm.DB
+	media    *services.MediaService
+	analyses *services.VideoAnalysisService
 }
 
-func NewAdminVideoHandler(db *gorm.DB) *AdminVideoHandler {
-	return &AdminVideoHandler{db: db}
+func NewAdminVideoHandler(db *gorm.DB, media *services.MediaService, analyses *services.VideoAnalysisService) *AdminVideoHandler {
+	return &AdminVideoHandler{db: db, media: media, analyses: analyses}
 }
 
 // VideoListItem 视频列表项
@@ -35,18 +37,58 @@ type VideoListItem struct {
 	PostTitle    string `json:"post_title,omitempty"`
 	CreatedAt    string `json:"created_at"`
 	Duration     int    `json:"duration,omitempty"`
+
+	ModerationStatus string `json:"moderation_status"` // 审核状态：pending, approved, rejected
+	ModerationReason string `json:"moderation_reason,omitempty"`
+	ReviewerNote     string `json:"reviewer_note,omitempty"`
+	ReviewedBy       string `json:"reviewed_by,omitempty"`
+	ReviewedAt       string `json:"reviewed_at,omitempty"`
 }
 
-// GetVideoList 获取视频列表（管理员）
-// GET /api/v1/admin/videos?page=1&page_size=20&source=&user_id=&module_id=
+// videoListItem 把视频索引转换为列表项，ID 为来源记录的ID，VideoURL 为当前管理员的签名播放链接
+func (h *AdminVideoHandler) videoListItem(c *gin.Context, row *services.VideoAssetRow) VideoListItem {
+	item := VideoListItem{
+		ID:        row.SourceID.String(),
+		VideoURL:  signedMediaURL(c, h.media, row.URL, row.Source, row.SourceID),
+		UserID:    row.UserID.String(),
+		Username:  row.Username,
+		Source:    row.Source,
+		CreatedAt: row.CreatedAt.Format("2006-01-02 15:04:05"),
+		Duration:  row.Duration,
+
+		ModerationStatus: row.ModerationStatus,
+		ModerationReason: row.ModerationReason,
+		ReviewerNote:     row.ReviewerNote,
+	}
+	if row.ReviewedBy != nil {
+		item.ReviewedBy = row.ReviewedBy.String()
+	}
+	if row.ReviewedAt != nil {
+		item.ReviewedAt = row.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+	switch row.Source {
+	case services.VideoSourceExposure:
+		item.SourceDetail = "脱敏练习"
+		item.ModuleID = row.ModuleID
+		// 模块已删除时退回训练记录中的步骤标题
+		item.ModuleTitle = row.ModuleTitle
+		if item.ModuleTitle == "" {
+			item.ModuleTitle = row.Title
+		}
+	case services.VideoSourcePost:
+		item.SourceDetail = "感悟广场"
+		item.PostID = row.SourceID.String()
+		item.PostTitle = row.Title
+	}
+	return item
+}
+
+// GetVideoList 获取视频列表（管理员），数据来自 video_assets 视频索引
+// GET /api/v1/admin/videos?page=1&page_size=20&source=&status=&user_id=&module_id=&start_date=2026-01-01&end_date=2026-01-31
 func (h *AdminVideoHandler) GetVideoList(c *gin.Context) {
 	// 获取查询参数
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	source := c.Query("source") // exposure_module, community_post
-	userIDStr := c.Query("user_id")
-	moduleIDStr := c.Query("module_id")
-
 	if page < 1 {
 		page = 1
 	}
@@ -54,159 +96,56 @@ func (h *AdminVideoHandler) GetVideoList(c *gin.Context) {
 		pageSize = 20
 	}
 
-	offset := (page - 1) * pageSize
-
-	var videos []VideoListItem
-	var total int64
-
-	// 从训练记录获取视频（脱敏练习）
-	if source == "" || source == "exposure_module" {
-		var trainingRecords []struct {
-			ID          uuid.UUID
-			UserID      uuid.UUID
-			DataStr     string `gorm:"column:data"`
-			Duration    int
-			CreatedAt   time.Time
-			Username    string
-			ModuleID    string
-			ModuleTitle string
-		}
-
-		trainingQuery := h.db.Table("training_records").
-			Select("training_records.id, training_records.user_id, training_records.data, training_records.duration, training_records.created_at, users.username").
-			Joins("LEFT JOIN users ON training_records.user_id = users.id").
-			Where("training_records.data->>'video_url' IS NOT NULL AND training_records.data->>'video_url' != ''").
-			Where("training_records.type = ?", "exposure")
-
-		if userIDStr != "" {
-			userID, err := uuid.Parse(userIDStr)
-			if err == nil {
-				trainingQuery = trainingQuery.Where("training_records.user_id = ?", userID)
-			}
-		}
-
-		if err := trainingQuery.
-			Order("training_records.created_at DESC").
-			Limit(pageSize).
-			Offset(offset).
-			Scan(&trainingRecords).Error; err == nil {
-
-			for _, record := range trainingRecords {
-				videoURL := ""
-				moduleID := ""
-				moduleTitle := ""
-
-				// 解析JSONB数据
-				if record.DataStr != "" {
-					var data map[string]interface{}
-					if err := json.Unmarshal([]byte(record.DataStr), &data); err == nil {
-						if url, ok := data["video_url"].(string); ok {
-							videoURL = url
-						}
-						if mid, ok := data["module_id"].(string); ok {
-							moduleID = mid
-						}
-						if mtitle, ok := data["step_title"].(string); ok {
-							moduleTitle = mtitle
-						}
-					}
-				}
-
-				// 如果指定了module_id，进行过滤
-				if moduleIDStr != "" && moduleID != moduleIDStr {
-					continue
-				}
-
-				// 获取模块标题
-				if moduleID != "" {
-					var module models.ExposureModule
-					if err := h.db.Where("id = ?", moduleID).First(&module).Error; err == nil {
-						moduleTitle = module.Title
-					}
-				}
-
-				if videoURL != "" {
-					videos = append(videos, VideoListItem{
-						ID:           record.ID.String(),
-						VideoURL:     videoURL,
-						UserID:       record.UserID.String(),
-						Username:     record.Username,
-						Source:       "exposure_module",
-						SourceDetail: "脱敏练习",
-						ModuleID:     moduleID,
-						ModuleTitle:  moduleTitle,
-						CreatedAt:    record.CreatedAt.Format("2006-01-02 15:04:05"),
-						Duration:     record.Duration,
-					})
-				}
-			}
-		}
+	filter := services.VideoAssetFilter{
+		Source:   c.Query("source"), // exposure_module, community_post
+		ModuleID: c.Query("module_id"),
+		Status:   c.Query("status"), // pending, approved, rejected
 	}
-
-	// 从社区帖子获取视频（感悟广场）
-	if source == "" || source == "community_post" {
-		var posts []struct {
-			ID        uuid.UUID
-			UserID    uuid.UUID
-			MediaURL  string `gorm:"column:image"` // 使用image列名，但映射到MediaURL字段
-			Content   string
-			CreatedAt time.Time
-			Username  string
+	if filter.Source != "" && filter.Source != services.VideoSourceExposure && filter.Source != services.VideoSourcePost {
+		response.Error(c, 400, "无效的来源")
+		return
+	}
+	switch filter.Status {
+	case "", models.VideoModerationPending, models.VideoModerationApproved, models.VideoModerationRejected:
+	default:
+		response.Error(c, 400, "无效的审核状态")
+		return
+	}
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			response.Error(c, 400, "无效的用户ID")
+			return
 		}
-
-		postQuery := h.db.Table("posts").
-			Select("posts.id, posts.user_id, posts.image, posts.content, posts.created_at, users.username").
-			Joins("LEFT JOIN users ON posts.user_id = users.id").
-			Where("posts.image IS NOT NULL AND posts.image != ''").
-			Where("(posts.image LIKE ? OR posts.image LIKE ?)", "%.webm%", "%.mp4%")
-
-		if userIDStr != "" {
-			userID, err := uuid.Parse(userIDStr)
-			if err == nil {
-				postQuery = postQuery.Where("posts.user_id = ?", userID)
-			}
+		filter.UserID = &userID
+	}
+	if startDate := c.Query("start_date"); startDate != "" {
+		start, err := services.ParseDate(startDate)
+		if err != nil {
+			response.Error(c, 400, "开始日期格式错误，应为 YYYY-MM-DD")
+			return
 		}
-
-		if err := postQuery.
-			Order("posts.created_at DESC").
-			Limit(pageSize).
-			Offset(offset).
-			Scan(&posts).Error; err == nil {
-
-			for _, post := range posts {
-				// 从content中提取标题（取前50个字符）
-				postTitle := post.Content
-				if len(postTitle) > 50 {
-					postTitle = postTitle[:50] + "..."
-				}
-
-				videos = append(videos, VideoListItem{
-					ID:           post.ID.String(),
-					VideoURL:     post.MediaURL,
-					UserID:       post.UserID.String(),
-					Username:     post.Username,
-					Source:       "community_post",
-					SourceDetail: "感悟广场",
-					PostID:       post.ID.String(),
-					PostTitle:    postTitle,
-					CreatedAt:    post.CreatedAt.Format("2006-01-02 15:04:05"),
-				})
-			}
+		filter.Start = &start
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		end, err := services.ParseDate(endDate)
+		if err != nil {
+			response.Error(c, 400, "结束日期格式错误，应为 YYYY-MM-DD")
+			return
 		}
+		end = end.AddDate(0, 0, 1) // 包含结束日期当天
+		filter.End = &end
 	}
 
-	// 统计总数
-	h.db.Table("training_records").
-		Where("data->>'video_url' IS NOT NULL AND data->>'video_url' != ''").
-		Where("type = ?", "exposure").
-		Count(&total)
-
-	var postCount int64
-	h.db.Table("posts").
-		Where("image IS NOT NULL AND image != ''").
-		Where("(image LIKE ? OR image LIKE ?)", "%.webm%", "%.mp4%").
-		Count(&postCount)
-	total += postCount
+	rows, total, err := services.NewVideoAssetService(h.db).List(filter, page, pageSize)
+	if err != nil {
+		response.Error(c, 500, "获取视频列表失败: "+err.Error())
+		return
+	}
+	videos := make([]VideoListItem, 0, len(rows))
+	for i := range rows {
+		videos = append(videos, h.videoListItem(c, &rows[i]))
+	}
 
 	response.Success(c, gin.H{
 		"videos":    videos,
@@ -217,137 +156,46 @@ func (h *AdminVideoHandler) GetVideoList(c *gin.Context) {
 }
 
 // GetVideoDetail 获取视频详情（管理员）
-// GET /api/v1/admin/videos/:id
+// GET /api/v1/admin/videos/:id?source=exposure_module|community_post
 func (h *AdminVideoHandler) GetVideoDetail(c *gin.Context) {
-	videoID := c.Param("id")
-	source := c.Query("source") // exposure_module, community_post
-
-	if source == "exposure_module" || source == "" {
-		// 从训练记录获取
-		var record models.TrainingRecord
-		if err := h.db.Preload("User").Where("id = ?", videoID).First(&record).Error; err == nil {
-			videoURL := ""
-			moduleID := ""
-			moduleTitle := ""
-
-			if record.Data != nil {
-				if url, ok := record.Data["video_url"].(string); ok {
-					videoURL = url
-				}
-				if mid, ok := record.Data["module_id"].(string); ok {
-					moduleID = mid
-				}
-			}
-
-			if moduleID != "" {
-				var module models.ExposureModule
-				if err := h.db.Where("id = ?", moduleID).First(&module).Error; err == nil {
-					moduleTitle = module.Title
-				}
-			}
-
-			if videoURL != "" {
-				response.Success(c, gin.H{
-					"video": VideoListItem{
-						ID:           record.ID.String(),
-						VideoURL:     videoURL,
-						UserID:       record.UserID.String(),
-						Username:     record.User.Username,
-						Source:       "exposure_module",
-						SourceDetail: "脱敏练习",
-						ModuleID:     moduleID,
-						ModuleTitle:  moduleTitle,
-						CreatedAt:    record.CreatedAt.Format("2006-01-02 15:04:05"),
-						Duration:     record.Duration,
-					},
-				}, "获取成功")
-				return
-			}
-		}
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, 404, "视频不存在")
+		return
 	}
 
-	if source == "community_post" || source == "" {
-		// 从社区帖子获取（使用原生SQL查询，因为Post模型可能没有Image字段）
-		var postData struct {
-			ID        uuid.UUID
-			UserID    uuid.UUID
-			Image     string
-			Content   string
-			CreatedAt time.Time
-			Username  string
-		}
-
-		if err := h.db.Table("posts").
-			Select("posts.id, posts.user_id, posts.image, posts.content, posts.created_at, users.username").
-			Joins("LEFT JOIN users ON posts.user_id = users.id").
-			Where("posts.id = ?", videoID).
-			Where("posts.image IS NOT NULL AND posts.image != ''").
-			Scan(&postData).Error; err == nil {
-
-			if postData.Image != "" {
-				// 从content中提取标题（取前50个字符）
-				postTitle := postData.Content
-				if len(postTitle) > 50 {
-					postTitle = postTitle[:50] + "..."
-				}
-
-				response.Success(c, gin.H{
-					"video": VideoListItem{
-						ID:           postData.ID.String(),
-						VideoURL:     postData.Image,
-						UserID:       postData.UserID.String(),
-						Username:     postData.Username,
-						Source:       "community_post",
-						SourceDetail: "感悟广场",
-						PostID:       postData.ID.String(),
-						PostTitle:    postTitle,
-						CreatedAt:    postData.CreatedAt.Format("2006-01-02 15:04:05"),
-					},
-				}, "获取成功")
-				return
-			}
+	row, err := services.NewVideoAssetService(h.db).Get(c.Query("source"), videoID)
+	if err != nil {
+		if errors.Is(err, services.ErrVideoNotFound) {
+			response.Error(c, 404, "视频不存在")
+			return
 		}
+		response.Error(c, 500, "获取视频失败: "+err.Error())
+		return
 	}
-
-	response.Error(c, 404, "视频不存在")
+	response.Success(c, gin.H{"video": h.videoListItem(c, row)}, "获取成功")
 }
 
-// DeleteVideo 删除视频（管理员）
+// DeleteVideo 删除视频（管理员）：清除训练记录的 video_url 或帖子的 image，移出视频索引并删除存储中的文件
 // DELETE /api/v1/admin/videos/:id?source=exposure_module|community_post
 func (h *AdminVideoHandler) DeleteVideo(c *gin.Context) {
-	videoID := c.Param("id")
-	source := c.DefaultQuery("source", "")
-
-	if source == "exposure_module" || source == "" {
-		// 从训练记录删除（删除data中的video_url字段）
-		var record models.TrainingRecord
-		if err := h.db.Where("id = ?", videoID).First(&record).Error; err == nil {
-			if record.Data != nil {
-				if _, ok := record.Data["video_url"].(string); ok {
-					// 清除video_url字段
-					delete(record.Data, "video_url")
-					if err := h.db.Model(&record).Update("data", record.Data).Error; err == nil {
-						response.Success(c, nil, "视频删除成功")
-						return
-					}
-				}
-			}
-		}
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, 404, "视频不存在")
+		return
 	}
 
-	if source == "community_post" || source == "" {
-		// 从社区帖子删除（删除image字段）
-		var post models.Post
-		if err := h.db.Where("id = ?", videoID).First(&post).Error; err == nil {
-			// 使用原始SQL更新image字段为空
-			if err := h.db.Exec("UPDATE posts SET image = '' WHERE id = ?", videoID).Error; err == nil {
-				response.Success(c, nil, "视频删除成功")
-				return
-			}
+	asset, err := services.NewVideoAssetService(h.db).Delete(c.Query("source"), videoID)
+	if err != nil {
+		if errors.Is(err, services.ErrVideoNotFound) {
+			response.Error(c, 404, "视频不存在")
+			return
 		}
+		response.Error(c, 500, "删除视频失败: "+err.Error())
+		return
 	}
-
-	response.Error(c, 404, "视频不存在")
+	h.media.RemoveURLs(c.Request.Context(), []string{asset.URL})
+	response.Success(c, nil, "视频删除成功")
 }
 
 // BatchDeleteVideos 批量删除视频
@@ -365,31 +213,23 @@ func (h *AdminVideoHandler) BatchDeleteVideos(c *gin.Context) {
 		return
 	}
 
+	videoAssets := services.NewVideoAssetService(h.db)
 	successCount := 0
 	failCount := 0
+	var urls []string
 
 	for _, item := range req.VideoIDs {
-		if item.Source == "exposure_module" {
-			var record models.TrainingRecord
-			if err := h.db.Where("id = ?", item.ID).First(&record).Error; err == nil {
-				if record.Data != nil {
-					if _, ok := record.Data["video_url"].(string); ok {
-						delete(record.Data, "video_url")
-						if err := h.db.Model(&record).Update("data", record.Data).Error; err == nil {
-							successCount++
-							continue
-						}
-					}
-				}
-			}
-		} else if item.Source == "community_post" {
-			if err := h.db.Exec("UPDATE posts SET image = '' WHERE id = ?", item.ID).Error; err == nil {
+		videoID, err := uuid.Parse(item.ID)
+		if err == nil && item.Source != "" {
+			if asset, err := videoAssets.Delete(item.Source, videoID); err == nil {
+				urls = append(urls, asset.URL)
 				successCount++
 				continue
 			}
 		}
 		failCount++
 	}
+	h.media.RemoveURLs(c.Request.Context(), urls)
 
 	response.Success(c, gin.H{
 		"success_count": successCount,
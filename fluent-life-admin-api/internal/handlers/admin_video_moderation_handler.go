@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func respondVideoReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrVideoNotFound):
+		response.Error(c, http.StatusNotFound, "视频不存在")
+	case errors.Is(err, services.ErrInvalidVideoReview):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "审核失败: "+err.Error())
+	}
+}
+
+// GetModerationQueue 视频待审核队列，按上传时间正序，同时返回各审核状态的数量与可选的拒绝原因
+// GET /api/v1/admin/videos/moderation/queue?page=1&page_size=20&source=
+func (h *AdminVideoHandler) GetModerationQueue(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	source := c.Query("source")
+	if source != "" && source != services.VideoSourceExposure && source != services.VideoSourcePost {
+		response.Error(c, http.StatusBadRequest, "无效的来源")
+		return
+	}
+
+	moderation := services.NewVideoModerationService(h.db)
+	rows, total, err := moderation.Queue(source, page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取审核队列失败: "+err.Error())
+		return
+	}
+	counts, err := moderation.Counts(source)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取审核队列失败: "+err.Error())
+		return
+	}
+	videos := make([]VideoListItem, 0, len(rows))
+	for i := range rows {
+		videos = append(videos, h.videoListItem(c, &rows[i]))
+	}
+
+	response.Success(c, gin.H{
+		"videos":    videos,
+		"counts":    counts,
+		"reasons":   services.VideoRejectReasons,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	}, "获取成功")
+}
+
+// ReviewVideo 审核视频：通过，或拒绝并隐藏关联帖子/标记训练记录、通知上传者；已拒绝的视频重新通过时恢复
+// POST /api/v1/admin/videos/:id/review?source=exposure_module|community_post  {"decision": "reject", "reason": "privacy", "note": "..."}
+func (h *AdminVideoHandler) ReviewVideo(c *gin.Context) {
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "视频不存在")
+		return
+	}
+	var req services.VideoReviewInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	row, err := services.NewVideoModerationService(h.db).Review(c.Query("source"), videoID, req, currentAdminID(c))
+	if err != nil {
+		respondVideoReviewError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"video": h.videoListItem(c, row)}, "审核成功")
+}
+
+// BatchReviewVideos 批量通过或拒绝视频，所有视频使用相同的原因与备注
+// POST /api/v1/admin/videos/batch-review  {"video_ids": [{"id": "...", "source": "community_post"}], "decision": "approve", "reason": "", "note": ""}
+func (h *AdminVideoHandler) BatchReviewVideos(c *gin.Context) {
+	var req struct {
+		VideoIDs []struct {
+			ID     string `json:"id"`
+			Source string `json:"source"` // exposure_module, community_post
+		} `json:"video_ids"`
+		services.VideoReviewInput
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.VideoIDs) == 0 {
+		response.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+	if len(req.VideoIDs) > 100 {
+		response.Error(c, http.StatusBadRequest, "一次最多审核 100 个视频")
+		return
+	}
+
+	moderation := services.NewVideoModerationService(h.db)
+	reviewer := currentAdminID(c)
+	successCount := 0
+	failCount := 0
+	for _, item := range req.VideoIDs {
+		videoID, err := uuid.Parse(item.ID)
+		if err == nil && item.Source != "" {
+			_, err = moderation.Review(item.Source, videoID, req.VideoReviewInput, reviewer)
+			// 原因或备注无效时每一项都会失败，直接返回
+			if errors.Is(err, services.ErrInvalidVideoReview) {
+				response.Error(c, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err == nil {
+				successCount++
+				continue
+			}
+		}
+		failCount++
+	}
+
+	response.Success(c, gin.H{
+		"success_count": successCount,
+		"fail_count":    failCount,
+	}, fmt.Sprintf("批量审核完成：成功 %d 个，失败 %d 个", successCount, failCount))
+}
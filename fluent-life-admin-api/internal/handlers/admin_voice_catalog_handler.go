@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVoiceTypeUsage 音色被用户设置与 AI 角色引用的情况；only_unavailable=true 时只返回已停用、已移除或不存在的音色
+// GET /api/v1/admin/voice-types/usage?only_unavailable=true
+func (h *AdminHandler) GetVoiceTypeUsage(c *gin.Context) {
+	usage, err := services.NewVoiceCatalogService(h.db).Usage()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取音色引用失败: "+err.Error())
+		return
+	}
+	if c.Query("only_unavailable") == "true" {
+		filtered := make([]services.VoiceUsage, 0)
+		for _, u := range usage {
+			if u.Status != services.VoiceStatusEnabled {
+				filtered = append(filtered, u)
+			}
+		}
+		usage = filtered
+	}
+	response.Success(c, gin.H{"usage": usage}, "获取成功")
+}
+
+// SyncVoiceTypes 按供应商音色清单新增或更新音色，不删除音色；清单可以在请求中直接提供、
+// 通过 url 下载，都为空时读取配置的 VOICE_CATALOG_SOURCE
+// POST /api/v1/admin/voice-types/sync  {"url": "", "catalog": {...}, "disable_missing": false, "dry_run": true}
+func (h *AdminHandler) SyncVoiceTypes(c *gin.Context) {
+	var req struct {
+		URL            string          `json:"url"`
+		Catalog        json.RawMessage `json:"catalog"`
+		DisableMissing bool            `json:"disable_missing"`
+		DryRun         bool            `json:"dry_run"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	var (
+		catalog *services.VoiceCatalog
+		err     error
+	)
+	switch {
+	case len(req.Catalog) > 0:
+		catalog, err = services.ParseVoiceCatalog(req.Catalog)
+	case req.URL != "":
+		if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+			response.Error(c, http.StatusBadRequest, "url 必须是 http(s) 地址")
+			return
+		}
+		catalog, err = services.LoadVoiceCatalog(c.Request.Context(), req.URL)
+	default:
+		catalog, err = services.LoadVoiceCatalog(c.Request.Context(), h.voiceCatalogSource)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVoiceCatalog) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	report, err := services.NewVoiceCatalogService(h.db).Sync(catalog, req.DisableMissing, req.DryRun)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "同步音色失败: "+err.Error())
+		return
+	}
+	if req.DryRun {
+		response.Success(c, report, "预览成功")
+		return
+	}
+	h.logOperation(c, "sync", "voice_type", "", fmt.Sprintf("同步音色清单: 新增 %d 个，更新 %d 个，停用 %d 个",
+		len(report.Created), len(report.Updated), len(report.Disabled)), "success")
+	response.Success(c, report, "同步成功")
+}
+
+// RemapVoiceTypes 把用户设置与 AI 角色中引用 from 音色的地方批量替换为已启用的 to 音色
+// POST /api/v1/admin/voice-types/remap  {"from": ["old_type"], "to": "new_type", "dry_run": true}
+func (h *AdminHandler) RemapVoiceTypes(c *gin.Context) {
+	var req struct {
+		From   []string `json:"from" binding:"required"`
+		To     string   `json:"to" binding:"required"`
+		DryRun bool     `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	report, err := services.NewVoiceCatalogService(h.db).Remap(req.From, req.To, req.DryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVoiceRemap) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "替换音色失败: "+err.Error())
+		return
+	}
+	if req.DryRun {
+		response.Success(c, report, "预览成功")
+		return
+	}
+	h.logOperation(c, "remap", "voice_type", report.To, fmt.Sprintf("批量替换音色 %s -> %s: 用户 %d 个，AI角色 %d 个",
+		strings.Join(report.From, ","), report.To, report.Users, len(report.Roles)), "success")
+	response.Success(c, report, "替换成功")
+}
+
+// rejectVoiceTypeInUse 音色仍被引用时返回 409 及引用情况，返回 true 表示已响应
+func (h *AdminHandler) rejectVoiceTypeInUse(c *gin.Context, voiceType, msg string) bool {
+	usage, err := services.NewVoiceCatalogService(h.db).UsageOf(voiceType)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "检查音色引用失败: "+err.Error())
+		return true
+	}
+	if usage == nil {
+		return false
+	}
+	response.ErrorWithData(c, http.StatusConflict, msg, gin.H{"usage": usage})
+	return true
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PreviewVoiceType 用音色合成试听文本并返回签名播放链接；音频缓存在媒体存储中，
+// 试听文本、音色类型或合成服务变化后重新合成，force=true 时强制重新合成
+// POST /api/v1/admin/voice-types/:id/preview?force=true
+func (h *AdminHandler) PreviewVoiceType(c *gin.Context) {
+	voiceTypeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的音色类型ID")
+		return
+	}
+
+	var voiceType models.VoiceType
+	if err := h.db.First(&voiceType, voiceTypeID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.Error(c, http.StatusNotFound, "音色类型不存在")
+		} else {
+			response.Error(c, http.StatusInternalServerError, "获取音色类型失败: "+err.Error())
+		}
+		return
+	}
+
+	preview, err := h.voicePreviews.Preview(c.Request.Context(), &voiceType, c.Query("force") == "true")
+	if err != nil {
+		response.Error(c, http.StatusBadGateway, err.Error())
+		return
+	}
+	response.Success(c, gin.H{
+		"type":         voiceType.Type,
+		"preview_text": preview.Text,
+		"preview_url":  signedMediaURL(c, h.media, preview.URL, "", uuid.Nil),
+		"cached":       preview.Cached,
+	}, "获取成功")
+}
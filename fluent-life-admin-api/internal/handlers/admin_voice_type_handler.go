@@ -49,7 +49,8 @@ func (h *AdminHandler) CreateVoiceType(c *gin.Context) {
 		Name        string `json:"name" binding:"required"`
 		Type        string `json:"type" binding:"required"`
 		Description string `json:"description"`
-		Enabled     bool   `json:"enabled"`
+		PreviewText string `json:"preview_text"`
+		Enabled     *bool  `json:"enabled"` // 缺省为启用
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -71,10 +72,12 @@ func (h *AdminHandler) CreateVoiceType(c *gin.Context) {
 		Name:        req.Name,
 		Type:        req.Type,
 		Description: req.Description,
-		Enabled:     req.Enabled,
+		PreviewText: req.PreviewText,
+		Enabled:     req.Enabled == nil || *req.Enabled,
 	}
 
-	if err := h.db.Create(&voiceType).Error; err != nil {
+	// Select("*") 让 enabled=false 也写入，而不是被列默认值 true 取代
+	if err := h.db.Select("*").Create(&voiceType).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "创建音色类型失败: "+err.Error())
 		return
 	}
@@ -83,8 +86,8 @@ func (h *AdminHandler) CreateVoiceType(c *gin.Context) {
 	response.Success(c, voiceType, "创建成功")
 }
 
-// UpdateVoiceType 更新音色类型（管理员）
-// PUT /api/v1/admin/voice-types/:id
+// UpdateVoiceType 更新音色类型（管理员）；停用或修改仍被引用的音色需要 force=true，否则返回 409 及引用情况
+// PUT /api/v1/admin/voice-types/:id?force=true
 func (h *AdminHandler) UpdateVoiceType(c *gin.Context) {
 	id := c.Param("id")
 	voiceTypeID, err := uuid.Parse(id)
@@ -97,6 +100,7 @@ func (h *AdminHandler) UpdateVoiceType(c *gin.Context) {
 		Name        string `json:"name" binding:"required"`
 		Type        string `json:"type" binding:"required"`
 		Description string `json:"description"`
+		PreviewText string `json:"preview_text"`
 		Enabled     bool   `json:"enabled"`
 	}
 
@@ -127,9 +131,21 @@ func (h *AdminHandler) UpdateVoiceType(c *gin.Context) {
 		}
 	}
 
+	if (voiceType.Type != req.Type || (voiceType.Enabled && !req.Enabled)) && c.Query("force") != "true" {
+		if h.rejectVoiceTypeInUse(c, voiceType.Type, "音色正在被使用，请先批量替换或确认后强制修改") {
+			return
+		}
+	}
+
+	// 音色类型或试听文本变化后，已缓存的试听音频失效
+	if voiceType.Type != req.Type || voiceType.PreviewText != req.PreviewText {
+		h.voicePreviews.Invalidate(c.Request.Context(), &voiceType)
+	}
+
 	voiceType.Name = req.Name
 	voiceType.Type = req.Type
 	voiceType.Description = req.Description
+	voiceType.PreviewText = req.PreviewText
 	voiceType.Enabled = req.Enabled
 
 	if err := h.db.Save(&voiceType).Error; err != nil {
@@ -141,8 +157,8 @@ func (h *AdminHandler) UpdateVoiceType(c *gin.Context) {
 	response.Success(c, voiceType, "更新成功")
 }
 
-// DeleteVoiceType 删除音色类型（管理员）
-// DELETE /api/v1/admin/voice-types/:id
+// DeleteVoiceType 删除音色类型（管理员）；仍被引用的音色需要 force=true，否则返回 409 及引用情况
+// DELETE /api/v1/admin/voice-types/:id?force=true
 func (h *AdminHandler) DeleteVoiceType(c *gin.Context) {
 	id := c.Param("id")
 	voiceTypeID, err := uuid.Parse(id)
@@ -161,31 +177,22 @@ func (h *AdminHandler) DeleteVoiceType(c *gin.Context) {
 		return
 	}
 
-	// 检查是否有AI角色正在使用此音色类型
-	var count int64
-	h.db.Model(&models.AppSetting{}).
-		Where("key = ?", "ai_simulation_roles").
-		Count(&count)
-	
-	if count > 0 {
-		// 检查是否有角色使用此音色
-		var setting models.AppSetting
-		if err := h.db.Where("key = ?", "ai_simulation_roles").First(&setting).Error; err == nil {
-			// 这里可以进一步检查JSON中是否使用了此音色类型
-			// 为了简化，我们允许删除，但会在日志中记录
-		}
+	// 检查是否有用户或AI角色正在使用此音色类型
+	if c.Query("force") != "true" && h.rejectVoiceTypeInUse(c, voiceType.Type, "音色正在被使用，请先批量替换或确认后强制删除") {
+		return
 	}
 
 	if err := h.db.Delete(&voiceType).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "删除音色类型失败: "+err.Error())
 		return
 	}
+	h.voicePreviews.Invalidate(c.Request.Context(), &voiceType)
 
 	h.logOperation(c, "delete", "voice_type", voiceType.ID.String(), "删除音色类型: "+voiceType.Name, "success")
 	response.Success(c, nil, "删除成功")
 }
 
-// GetEnabledVoiceTypes 获取所有启用的音色类型（用于下拉选择）
+// GetEnabledVoiceTypes 获取所有启用的音色类型（用于下拉选择），附带已缓存的试听音频签名链接
 // GET /api/v1/admin/voice-types/enabled
 func (h *AdminHandler) GetEnabledVoiceTypes(c *gin.Context) {
 	var voiceTypes []models.VoiceType
@@ -196,16 +203,23 @@ func (h *AdminHandler) GetEnabledVoiceTypes(c *gin.Context) {
 	
 	// 只返回必要的字段用于下拉选择
 	type VoiceTypeOption struct {
-		Type string `json:"type"`
-		Name string `json:"name"`
+		ID         uuid.UUID `json:"id"`
+		Type       string    `json:"type"`
+		Name       string    `json:"name"`
+		PreviewURL string    `json:"preview_url,omitempty"` // 没有缓存时为空，可调用试听接口生成
 	}
 	
 	options := make([]VoiceTypeOption, 0, len(voiceTypes))
 	for _, vt := range voiceTypes {
-		options = append(options, VoiceTypeOption{
+		option := VoiceTypeOption{
+			ID:   vt.ID,
 			Type: vt.Type,
 			Name: vt.Name,
-		})
+		}
+		if cached := h.voicePreviews.CachedURL(&vt); cached != "" {
+			option.PreviewURL = signedMediaURL(c, h.media, cached, "", uuid.Nil)
+		}
+		options = append(options, option)
 	}
 	
 	response.Success(c, gin.H{"voice_types": options}, "获取成功")
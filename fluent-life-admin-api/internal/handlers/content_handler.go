@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/auth"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContentHandler 公开的练习与帮助内容读取接口，按请求的语言返回译文
+type ContentHandler struct {
+	db *gorm.DB
+}
+
+// NewContentHandler 创建公开内容处理器
+func NewContentHandler(db *gorm.DB) *ContentHandler {
+	return &ContentHandler{db: db}
+}
+
+// requestLocale 按 ?locale= 与 Accept-Language 确定语言
+func requestLocale(c *gin.Context) string {
+	return services.ResolveLocale(c.Query("locale"), c.GetHeader("Accept-Language"))
+}
+
+// optionalUserID 公开接口中可选的用户身份，token 缺失或无效时视为匿名
+func optionalUserID(c *gin.Context) (uuid.UUID, bool) {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return uuid.Nil, false
+	}
+	claims, err := auth.ParseToken(strings.TrimPrefix(header, "Bearer "))
+	if err != nil || claims.UserID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return claims.UserID, true
+}
+
+// GetTongueTwisters 启用的绕口令
+// GET /api/v1/tongue-twisters?level=basic&locale=zh-TW
+func (h *ContentHandler) GetTongueTwisters(c *gin.Context) {
+	locale := requestLocale(c)
+	query := h.db.Where("is_active = ?", true)
+	if level := c.Query("level"); level != "" {
+		query = query.Where("level = ?", level)
+	}
+	var twisters []models.TongueTwister
+	if err := query.Order(`"order" ASC, created_at DESC`).Find(&twisters).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取失败")
+		return
+	}
+	if err := services.NewTranslationService(h.db).Localize(services.TranslateTongueTwister, &twisters, locale); err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取译文失败")
+		return
+	}
+	response.Success(c, gin.H{"locale": locale, "tongue_twisters": twisters}, "获取成功")
+}
+
+// localizedSpeechTechnique 带条目的语音技巧，tips、practice_texts 列按译文重新生成
+type localizedSpeechTechnique struct {
+	models.SpeechTechnique
+	TipItems      []models.SpeechTechniqueTip `json:"tip_items"`
+	PracticeItems []models.SpeechPracticeText `json:"practice_items"`
+}
+
+// GetSpeechTechniques 启用的语音技巧及其启用的条目
+// GET /api/v1/speech-techniques?locale=en-US
+func (h *ContentHandler) GetSpeechTechniques(c *gin.Context) {
+	locale := requestLocale(c)
+	var techniques []models.SpeechTechnique
+	if err := h.db.Where("is_active = ?", true).Order(`"order" ASC, created_at DESC`).Find(&techniques).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取失败")
+		return
+	}
+	ids := make([]string, len(techniques))
+	for i, t := range techniques {
+		ids[i] = t.ID.String()
+	}
+	var tips []models.SpeechTechniqueTip
+	var texts []models.SpeechPracticeText
+	if err := h.db.Where("technique_id IN ? AND is_active = ?", ids, true).Order(`"order", created_at`).Find(&tips).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取失败")
+		return
+	}
+	if err := h.db.Where("technique_id IN ? AND is_active = ?", ids, true).Order(`"order", created_at`).Find(&texts).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取失败")
+		return
+	}
+
+	translations := services.NewTranslationService(h.db)
+	for _, err := range []error{
+		translations.Localize(services.TranslateSpeechTechnique, &techniques, locale),
+		translations.Localize(services.TranslateSpeechTip, &tips, locale),
+		translations.Localize(services.TranslateSpeechPracticeText, &texts, locale),
+	} {
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "获取译文失败")
+			return
+		}
+	}
+
+	result := make([]*localizedSpeechTechnique, len(techniques))
+	byID := make(map[string]*localizedSpeechTechnique, len(techniques))
+	for i, t := range techniques {
+		result[i] = &localizedSpeechTechnique{SpeechTechnique: t, TipItems: []models.SpeechTechniqueTip{}, PracticeItems: []models.SpeechPracticeText{}}
+		byID[t.ID.String()] = result[i]
+	}
+	for _, tip := range tips {
+		if t := byID[tip.TechniqueID.String()]; t != nil {
+			t.TipItems = append(t.TipItems, tip)
+		}
+	}
+	for _, text := range texts {
+		if t := byID[text.TechniqueID.String()]; t != nil {
+			t.PracticeItems = append(t.PracticeItems, text)
+		}
+	}
+	for _, t := range result {
+		tipTexts := make([]string, len(t.TipItems))
+		for i, tip := range t.TipItems {
+			tipTexts[i] = tip.Content
+		}
+		practiceTexts := make([]string, len(t.PracticeItems))
+		for i, text := range t.PracticeItems {
+			practiceTexts[i] = text.Content
+		}
+		tipsJSON, _ := json.Marshal(tipTexts)
+		textsJSON, _ := json.Marshal(practiceTexts)
+		t.Tips, t.PracticeTexts = string(tipsJSON), string(textsJSON)
+	}
+	response.Success(c, gin.H{"locale": locale, "techniques": result}, "获取成功")
+}
+
+// GetHelpCenter 帮助中心分类及其启用的文章
+// GET /api/v1/help-categories?locale=zh-TW
+func (h *ContentHandler) GetHelpCenter(c *gin.Context) {
+	locale := requestLocale(c)
+	var categories []models.HelpCategory
+	err := h.db.Preload("Articles", func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_active = ?", true).Order(`"order" ASC`)
+	}).Order(`"order" ASC`).Find(&categories).Error
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取失败")
+		return
+	}
+
+	translations := services.NewTranslationService(h.db)
+	if err := translations.Localize(services.TranslateHelpCategory, &categories, locale); err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取译文失败")
+		return
+	}
+	for i := range categories {
+		if err := translations.Localize(services.TranslateHelpArticle, &categories[i].Articles, locale); err != nil {
+			response.Error(c, http.StatusInternalServerError, "获取译文失败")
+			return
+		}
+	}
+	response.Success(c, gin.H{"locale": locale, "categories": categories}, "获取成功")
+}
+
+// GetExposureModules 已上线的脱敏练习模块及其步骤（读取线上发布快照，草稿修改不影响）；
+// 携带用户 token 时按其所在的实验分组返回步骤文案
+// GET /api/v1/exposure-modules?locale=en-US
+func (h *ContentHandler) GetExposureModules(c *gin.Context) {
+	locale := requestLocale(c)
+	modules, err := services.NewExposureReleaseService(h.db).LiveModules()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取失败")
+		return
+	}
+
+	translations := services.NewTranslationService(h.db)
+	if err := translations.Localize(services.TranslateExposureModule, &modules, locale); err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取译文失败")
+		return
+	}
+	for i := range modules {
+		if err := translations.Localize(services.TranslateExposureStep, &modules[i].Steps, locale); err != nil {
+			response.Error(c, http.StatusInternalServerError, "获取译文失败")
+			return
+		}
+	}
+	// 登录用户按 A/B 实验分组替换步骤文案；实验文案只有原文语言
+	if userID, ok := optionalUserID(c); ok && locale == services.DefaultLocale {
+		if err := services.NewExperimentService(h.db).ApplyToModules(modules, userID); err != nil {
+			response.Error(c, http.StatusInternalServerError, "获取实验分组失败")
+			return
+		}
+	}
+	response.Success(c, gin.H{"locale": locale, "modules": modules}, "获取成功")
+}
+
+// GetExperimentAssignments 当前用户在进行中实验里的分组及覆盖内容，AI 角色实验由客户端替换 system_prompt；
+// 步骤实验只对原文语言的用户分组，与 GetExposureModules 一致
+// GET /api/v1/experiments/assignments?locale=zh-CN
+func (h *ContentHandler) GetExperimentAssignments(c *gin.Context) {
+	assignments, err := services.NewExperimentService(h.db).Assignments(c.MustGet("userID").(uuid.UUID), requestLocale(c))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取实验分组失败")
+		return
+	}
+	response.Success(c, gin.H{"assignments": assignments}, "获取成功")
+}
+
+// RecordExperimentExposure 记录当前用户看到了实验内容，分组由服务端按用户ID计算
+// POST /api/v1/experiments/:id/exposures?locale=zh-CN
+func (h *ContentHandler) RecordExperimentExposure(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的实验ID")
+		return
+	}
+	exposure, err := services.NewExperimentService(h.db).RecordExposure(id, c.MustGet("userID").(uuid.UUID), requestLocale(c))
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"exposure": exposure}, "记录成功")
+}
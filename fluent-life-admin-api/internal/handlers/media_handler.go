@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+	"fluent-life-admin-api/pkg/signedurl"
+	"fluent-life-admin-api/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MediaHandler 校验签名链接并返回用户媒体
+type MediaHandler struct {
+	media *services.MediaService
+}
+
+// NewMediaHandler 创建媒体代理处理器
+func NewMediaHandler(media *services.MediaService) *MediaHandler {
+	return &MediaHandler{media: media}
+}
+
+// signedMediaURL 当前管理员播放媒体用的签名链接；管理端接口只返回签名链接，不返回原始地址
+func signedMediaURL(c *gin.Context, media *services.MediaService, rawURL, videoSource string, videoID uuid.UUID) string {
+	return mediaSigner(c, media)(rawURL, videoSource, videoID)
+}
+
+// mediaSigner 为当前管理员签发媒体链接的函数；只读取一次请求信息，可在请求结束后继续使用（异步导出）
+func mediaSigner(c *gin.Context, media *services.MediaService) func(rawURL, videoSource string, videoID uuid.UUID) string {
+	adminID := currentAdminID(c)
+	if adminID == nil || media == nil {
+		return func(string, string, uuid.UUID) string { return "" }
+	}
+	proxyURL := media.ProxyURL()
+	if proxyURL == "" {
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		proxyURL = scheme + "://" + c.Request.Host + "/api/v1/media"
+	}
+	return func(rawURL, videoSource string, videoID uuid.UUID) string {
+		return media.SignURL(proxyURL, rawURL, *adminID, videoSource, videoID)
+	}
+}
+
+// signPostImage 把帖子 image 替换为签名链接
+func signPostImage(c *gin.Context, media *services.MediaService, post *models.Post) {
+	if post.Image != "" {
+		post.Image = signedMediaURL(c, media, post.Image, services.VideoSourcePost, post.ID)
+	}
+}
+
+// signRecordVideo 把训练记录 data.video_url 替换为签名播放链接
+func signRecordVideo(c *gin.Context, media *services.MediaService, record *models.TrainingRecord) {
+	if raw, ok := record.Data["video_url"].(string); ok && raw != "" {
+		record.Data["video_url"] = signedMediaURL(c, media, raw, services.VideoSourceExposure, record.ID)
+	}
+}
+
+// ServeMedia 校验签名与过期时间后返回存储中的文件（本地存储支持 Range），不在本存储中的地址返回 404；
+// 播放记录写入审计日志。<video> 无法携带 Authorization 头，因此该接口不要求登录，由签名绑定管理员
+// GET /api/v1/media?k=videos/a.mp4&a=<admin_id>&v=<source>:<id>&e=<expires>&s=<signature>
+func (h *MediaHandler) ServeMedia(c *gin.Context) {
+	target, err := h.media.VerifyURL(c.Request.URL.Query())
+	if err != nil {
+		if errors.Is(err, signedurl.ErrExpired) {
+			response.Error(c, http.StatusForbidden, "链接已过期，请刷新页面后重试")
+			return
+		}
+		if errors.Is(err, services.ErrMediaNotStored) {
+			response.Error(c, http.StatusNotFound, "文件不存在")
+			return
+		}
+		response.Error(c, http.StatusForbidden, "链接无效")
+		return
+	}
+
+	// 播放器拖动进度会发出多次分段请求，只在首段记录；审计失败时不返回内容
+	if r := c.GetHeader("Range"); r == "" || strings.HasPrefix(r, "bytes=0-") {
+		if err := h.media.LogAccess(target, c.ClientIP(), c.Request.UserAgent()); err != nil {
+			response.Error(c, http.StatusInternalServerError, "记录访问日志失败")
+			return
+		}
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	rc, info, err := h.media.Storage().Get(c.Request.Context(), target.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Error(c, http.StatusNotFound, "文件不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "读取文件失败")
+		return
+	}
+	defer rc.Close()
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, path.Base(info.Key), info.LastModified, rs)
+		return
+	}
+	c.DataFromReader(http.StatusOK, info.Size, contentType, rc, nil)
+}
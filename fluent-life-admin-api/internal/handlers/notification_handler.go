@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fluent-life-admin-api/internal/services"
+	"fluent-life-admin-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationHandler 用户站内通知处理器
+type NotificationHandler struct {
+	db *gorm.DB
+}
+
+// NewNotificationHandler 创建用户站内通知处理器
+func NewNotificationHandler(db *gorm.DB) *NotificationHandler {
+	return &NotificationHandler{db: db}
+}
+
+// GetNotifications 当前用户的通知，按时间倒序
+// GET /api/v1/notifications?page=1&page_size=20&unread=false
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	unreadOnly, _ := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+
+	notifications, total, unread, err := services.NewNotificationService(h.db).List(c.MustGet("userID").(uuid.UUID), unreadOnly, page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取通知失败")
+		return
+	}
+	response.Success(c, gin.H{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"total":         total,
+		"page":          page,
+		"page_size":     pageSize,
+	}, "获取成功")
+}
+
+// MarkNotificationRead 把一条通知标记为已读，id 为 all 时标记全部
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	var id *uuid.UUID
+	if c.Param("id") != "all" {
+		parsed, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			response.Error(c, http.StatusNotFound, "通知不存在")
+			return
+		}
+		id = &parsed
+	}
+
+	updated, err := services.NewNotificationService(h.db).MarkRead(c.MustGet("userID").(uuid.UUID), id)
+	if err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			response.Error(c, http.StatusNotFound, "通知不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "标记已读失败")
+		return
+	}
+	response.Success(c, gin.H{"updated": updated}, "已标记为已读")
+}
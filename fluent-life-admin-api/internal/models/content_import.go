@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContentImport 练习内容导入记录（含预览），Problems 保存校验失败、重复及存在近似内容的行，用于下载错误报告
+type ContentImport struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Kind           string    `gorm:"type:varchar(50);not null;index" json:"kind"` // tongue_twisters | daily_expressions | speech_techniques
+	FileName       string    `gorm:"type:varchar(255)" json:"file_name"`
+	Mode           string    `gorm:"type:varchar(20);not null" json:"mode"` // insert | upsert
+	DryRun         bool      `gorm:"not null;default:false" json:"dry_run"`
+	Mapping        string    `gorm:"type:text" json:"mapping"` // 源列 -> 字段，JSON
+	Total          int       `gorm:"not null;default:0" json:"total"`
+	Valid          int       `gorm:"not null;default:0" json:"valid"`
+	Invalid        int       `gorm:"not null;default:0" json:"invalid"`
+	Duplicates     int       `gorm:"not null;default:0" json:"duplicates"`
+	NearDuplicates int       `gorm:"not null;default:0" json:"near_duplicates"` // 存在近似内容的行数（仅提示）
+	Created        int       `gorm:"not null;default:0" json:"created"`
+	Updated        int       `gorm:"not null;default:0" json:"updated"`
+	Skipped        int       `gorm:"not null;default:0" json:"skipped"`
+	Problems       string    `gorm:"type:text" json:"-"`
+	CreatedBy      uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (c *ContentImport) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
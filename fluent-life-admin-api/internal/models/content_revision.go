@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContentRevision 内容版本（绕口令、每日朗诵文案、语音技巧、法律文档通用）
+// 草稿可反复修改；发布后的版本不再修改，Number 为该内容的发布序号，回滚会以旧版本内容生成新的发布版本
+type ContentRevision struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ContentType   string     `gorm:"type:varchar(50);not null;index:idx_content_revision_target" json:"content_type"`
+	ContentID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_content_revision_target" json:"content_id"`
+	Number        int        `gorm:"not null;default:0" json:"number"`                  // 发布序号，未发布为 0
+	BaseNumber    int        `gorm:"not null;default:0" json:"base_number"`             // 草稿基于的发布序号
+	Status        string     `gorm:"type:varchar(20);not null;index" json:"status"`     // draft | pending_review | rejected | published
+	Data          string     `gorm:"type:text;not null" json:"data"`                    // 字段快照，JSON
+	Note          string     `gorm:"type:varchar(500)" json:"note"`                     // 修改说明
+	RevertedFrom  int        `gorm:"not null;default:0" json:"reverted_from,omitempty"` // 回滚来源的发布序号
+	CreatedBy     *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`             // 初始版本为空
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty"`
+	ReviewedBy    *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	ReviewComment string     `gorm:"type:varchar(500)" json:"review_comment"`
+	PublishedBy   *uuid.UUID `gorm:"type:uuid" json:"published_by,omitempty"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (r *ContentRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
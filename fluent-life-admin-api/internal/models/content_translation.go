@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContentTranslation 内容单个字段在某个语言下的译文
+// 原文（zh-CN）仍保存在内容表中，这里只保存其他语言；SourceHash 记录翻译时原文的摘要，原文修改后可据此发现过期译文
+type ContentTranslation struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ContentType string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_content_translation_key,priority:1" json:"content_type"`
+	ContentID   string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_content_translation_key,priority:2" json:"content_id"` // 脱敏模块的 ID 不是 uuid，统一按字符串保存
+	Locale      string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_content_translation_key,priority:3;index" json:"locale"`
+	Field       string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_content_translation_key,priority:4" json:"field"`
+	Value       string     `gorm:"type:text;not null" json:"value"`
+	SourceHash  string     `gorm:"type:varchar(32);not null" json:"source_hash"`
+	UpdatedBy   *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (t *ContentTranslation) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DailyMetric 按天预聚合的统计指标（北京时间自然日），供仪表盘读取
+// 流量型指标（如 new_users）记录当天新增；快照型指标（如 rooms_active）记录当天最后一次汇总时的值
+type DailyMetric struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Date       time.Time `gorm:"type:date;not null;uniqueIndex:idx_daily_metrics_date_metric_dimension" json:"date"`
+	Metric     string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_daily_metrics_date_metric_dimension;index:idx_daily_metrics_metric" json:"metric"`
+	Dimension  string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_daily_metrics_date_metric_dimension" json:"dimension"` // 维度值，如训练类型；无维度时为空
+	Value      float64   `gorm:"not null;default:0" json:"value"`
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
+
+func (m *DailyMetric) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DuplicateReview 近似重复内容簇的审核记录
+// Fingerprint 由簇内成员计算，成员变化（新增近似内容或删除）后会生成新的簇，需要重新审核
+type DuplicateReview struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Fingerprint string    `gorm:"type:varchar(64);not null;index" json:"fingerprint"`
+	Decision    string    `gorm:"type:varchar(20);not null" json:"decision"` // keep: 确认不是重复，全部保留 | merge: 保留一条，删除其余
+	KeepKey     string    `gorm:"type:varchar(100)" json:"keep_key"`
+	Members     string    `gorm:"type:text" json:"members"` // 审核时的成员键，JSON 数组
+	Removed     string    `gorm:"type:text" json:"removed"` // 合并时删除的成员键，JSON 数组
+	Threshold   float64   `gorm:"not null" json:"threshold"`
+	Note        string    `gorm:"type:varchar(500)" json:"note"`
+	ReviewedBy  uuid.UUID `gorm:"type:uuid" json:"reviewed_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (d *DuplicateReview) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Experiment A/B 实验：对脱敏练习步骤的文案或 AI 角色的提示词做分组对比
+type Experiment struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string     `gorm:"type:varchar(200);not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	TargetType  string     `gorm:"type:varchar(20);not null;index:idx_experiments_target,priority:1" json:"target_type"` // 'exposure_step' | 'ai_role'
+	TargetID    string     `gorm:"type:varchar(64);not null;index:idx_experiments_target,priority:2" json:"target_id"`   // 步骤ID或AI角色ID
+	ModuleID    string     `gorm:"type:varchar(64)" json:"module_id,omitempty"`                                          // 目标步骤所属模块，开始实验时确定
+	StepOrder   int        `json:"step_order,omitempty"`                                                                 // 目标步骤的顺序，开始实验时确定
+	Status      string     `gorm:"type:varchar(20);not null;default:'draft';index" json:"status"`                        // 'draft' | 'running' | 'stopped'
+	Variants    string     `gorm:"type:jsonb;not null" json:"variants"`                                                  // 分组及各组覆盖的内容
+	WindowHours int        `gorm:"not null;default:168" json:"window_hours"`                                             // 首次曝光后计入转化的时长
+	StartedAt   *time.Time `json:"started_at"`
+	StoppedAt   *time.Time `json:"stopped_at"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (e *Experiment) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// ExperimentExposure 用户在实验中的曝光记录，每个用户每个实验一条
+type ExperimentExposure struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ExperimentID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_experiment_exposures_user,priority:1" json:"experiment_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_experiment_exposures_user,priority:2" json:"user_id"`
+	Variant        string    `gorm:"type:varchar(32);not null" json:"variant"`
+	ExposureCount  int       `gorm:"not null;default:1" json:"exposure_count"`
+	FirstExposedAt time.Time `gorm:"not null" json:"first_exposed_at"`
+	LastExposedAt  time.Time `gorm:"not null" json:"last_exposed_at"`
+}
+
+func (e *ExperimentExposure) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportJob 异步导出任务
+type ExportJob struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Resource     string     `gorm:"type:varchar(50);not null;index" json:"resource"` // 导出的列表，如 users、training_records
+	Format       string     `gorm:"type:varchar(10);not null" json:"format"`         // csv | xlsx
+	Columns      string     `gorm:"type:text" json:"columns"`                        // 导出列，逗号分隔，为空表示全部
+	Lang         string     `gorm:"type:varchar(10);not null;default:'zh-CN'" json:"lang"`
+	Params       string     `gorm:"type:text" json:"params"`                                   // 列表筛选参数（URL 查询串）
+	Status       string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"` // pending | running | success | failed | expired
+	TotalRows    int64      `gorm:"not null;default:0" json:"total_rows"`
+	ExportedRows int64      `gorm:"not null;default:0" json:"exported_rows"`
+	FileName     string     `gorm:"type:varchar(255)" json:"file_name"`
+	FilePath     string     `gorm:"type:varchar(500)" json:"-"`
+	FileSize     int64      `gorm:"not null;default:0" json:"file_size"`
+	Error        string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedBy    uuid.UUID  `gorm:"type:uuid;index" json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	ExpiresAt    *time.Time `gorm:"index" json:"expires_at"` // 导出文件的删除时间
+}
+
+func (j *ExportJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
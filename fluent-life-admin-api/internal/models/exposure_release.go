@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExposureModuleSnapshot 脱敏练习模块发布快照：发布时模块及全部步骤的内容，用户看到的是线上版本的快照
+type ExposureModuleSnapshot struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ModuleID       string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_exposure_snapshot_version,priority:1" json:"module_id"`
+	Version        int        `gorm:"not null;uniqueIndex:idx_exposure_snapshot_version,priority:2" json:"version"`
+	Data           string     `gorm:"type:jsonb;not null" json:"data,omitempty"` // 模块及步骤，结构同导出包中的模块
+	Note           string     `gorm:"type:varchar(500)" json:"note"`
+	RolledBackFrom *int       `json:"rolled_back_from,omitempty"` // 回滚生成的快照记录来源版本
+	PublishedBy    *uuid.UUID `gorm:"type:uuid" json:"published_by,omitempty"`
+	PublishedAt    time.Time  `gorm:"not null" json:"published_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (s *ExposureModuleSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// ExposureModuleRelease 模块的上线状态与发布排期
+type ExposureModuleRelease struct {
+	ModuleID    string     `gorm:"type:varchar(64);primary_key" json:"module_id"`
+	LiveVersion *int       `json:"live_version"`              // 当前线上的快照版本，为空表示未上线
+	PublishAt   *time.Time `gorm:"index" json:"publish_at"`   // 定时发布：到时发布当时的草稿
+	UnpublishAt *time.Time `gorm:"index" json:"unpublish_at"` // 定时下线
+	UpdatedBy   *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
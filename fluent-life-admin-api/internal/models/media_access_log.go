@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MediaAccessLog 管理员通过签名链接播放用户媒体的记录，断点续传的分段请求只记录首段
+type MediaAccessLog struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AdminID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_media_access_logs_admin_created,priority:1" json:"admin_id"`
+	VideoSource string     `gorm:"type:varchar(20)" json:"video_source,omitempty"` // 'exposure_module' | 'community_post'
+	VideoID     *uuid.UUID `gorm:"type:uuid" json:"video_id,omitempty"`            // 来源记录ID
+	OwnerID     *uuid.UUID `gorm:"type:uuid;index:idx_media_access_logs_owner_created,priority:1" json:"owner_id,omitempty"`
+	Target      string     `gorm:"type:text;not null" json:"target"` // 存储 key 或外部地址
+	IP          string     `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent   string     `gorm:"type:varchar(255)" json:"user_agent"`
+	CreatedAt   time.Time  `gorm:"index:idx_media_access_logs_created;index:idx_media_access_logs_admin_created,priority:2;index:idx_media_access_logs_owner_created,priority:2" json:"created_at"`
+}
+
+func (m *MediaAccessLog) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
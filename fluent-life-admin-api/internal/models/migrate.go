@@ -3,7 +3,7 @@ package models
 import "gorm.io/gorm"
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		&User{},
 		&VerificationCode{},
 		&TrainingRecord{},
@@ -30,7 +30,37 @@ func AutoMigrate(db *gorm.DB) error {
 		&Role{},
 		&Menu{},
 		&RandomMatchRecord{},
-	)
+		&DailyMetric{},
+		&ReportSchedule{},
+		&ReportRun{},
+		&ExportJob{},
+		&ContentImport{},
+		&DuplicateReview{},
+		&ContentRevision{},
+		&SpeechTechniqueTip{},
+		&SpeechPracticeText{},
+		&ContentTranslation{},
+		&ExposureModuleSnapshot{},
+		&ExposureModuleRelease{},
+		&Experiment{},
+		&ExperimentExposure{},
+		&VideoAsset{},
+		&Notification{},
+		&MediaAccessLog{},
+		&VideoAnalysis{},
+	); err != nil {
+		return err
+	}
+	return migrateExposureSteps(db)
+}
+
+// migrateExposureSteps 脱敏练习表由主服务创建，这里只补充管理端新增的列
+func migrateExposureSteps(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&ExposureStep{}) || migrator.HasColumn(&ExposureStep{}, "Config") {
+		return nil
+	}
+	return migrator.AddColumn(&ExposureStep{}, "Config")
 }
 
 
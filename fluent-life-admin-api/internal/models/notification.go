@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification 发给用户的站内通知，如视频审核未通过
+type Notification struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_notifications_user_created,priority:1" json:"user_id"`
+	Type      string     `gorm:"type:varchar(50);not null" json:"type"` // 'video_rejected'
+	Title     string     `gorm:"type:varchar(200);not null" json:"title"`
+	Content   string     `gorm:"type:text" json:"content"`
+	Data      string     `gorm:"type:jsonb" json:"data,omitempty"` // 关联对象，如 {"source": "community_post", "source_id": "..."}
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `gorm:"index:idx_notifications_user_created,priority:2" json:"created_at"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
@@ -12,8 +12,10 @@ type Post struct {
 	UserID        uuid.UUID `gorm:"type:uuid;not null;index:idx_posts_user_id" json:"user_id"`
 	Content       string    `gorm:"type:text;not null" json:"content"`
 	Tag           string    `gorm:"type:varchar(50);index:idx_posts_tag" json:"tag"`
+	Image         string    `gorm:"type:text" json:"image"` // 图片或视频地址；管理端接口只返回签名链接
 	LikesCount    int       `gorm:"not null;default:0" json:"likes_count"`
 	CommentsCount int       `gorm:"not null;default:0" json:"comments_count"`
+	Hidden        bool      `gorm:"not null;default:false;index:idx_posts_hidden" json:"hidden"` // 视频审核未通过时隐藏，客户端列表不展示
 	CreatedAt     time.Time `gorm:"index:idx_posts_created_at" json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 
@@ -15,6 +15,7 @@ type TongueTwister struct {
 	Tips        string    `gorm:"type:text" json:"tips"`
 	Level       string    `gorm:"type:varchar(20);not null;index:idx_tongue_twister_level" json:"level"` // 'basic' | 'intermediate' | 'advanced'
 	Order       int       `gorm:"not null;default:0;index:idx_tongue_twister_order" json:"order"`        // 排序字段
+	DifficultyScore float64 `gorm:"not null;default:0" json:"difficulty_score"` // 拼音分析得出的难度分数（0~100）
 	IsActive    bool      `gorm:"not null;default:true;index:idx_tongue_twister_active" json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -27,7 +28,7 @@ type DailyExpression struct {
 	Content     string    `gorm:"type:text;not null" json:"content"`
 	Tips        string    `gorm:"type:text" json:"tips"` // 朗诵技巧提示
 	Source      string    `gorm:"type:varchar(100)" json:"source"` // 来源，如"人民日报"
-	Date        time.Time `gorm:"type:date;not null;index:idx_daily_expression_date" json:"date"` // 发布日期
+	Date        *time.Time `gorm:"type:date;index:idx_daily_expression_date" json:"date"` // 发布日期，为空表示在待排期池中
 	IsActive    bool      `gorm:"not null;default:true;index:idx_daily_expression_active" json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -53,8 +54,8 @@ type SpeechTechnique struct {
 	Name          string    `gorm:"type:varchar(100);not null" json:"name"`                    // 技巧名称，如"慢速说话"
 	Icon          string    `gorm:"type:varchar(10)" json:"icon"`                              // 图标emoji
 	Description   string    `gorm:"type:varchar(200)" json:"description"`                     // 简短描述
-	Tips          string    `gorm:"type:text" json:"tips"`                                     // 训练要点，JSON数组字符串
-	PracticeTexts string   `gorm:"type:text" json:"practice_texts"`                          // 练习文本，JSON数组字符串
+	Tips          string    `gorm:"type:text" json:"tips"`                                     // 训练要点，JSON数组字符串，由 speech_technique_tips 生成
+	PracticeTexts string   `gorm:"type:text" json:"practice_texts"`                          // 练习文本，JSON数组字符串，由 speech_practice_texts 生成
 	Order         int       `gorm:"not null;default:0;index:idx_speech_technique_order" json:"order"` // 排序字段
 	IsActive      bool      `gorm:"not null;default:true;index:idx_speech_technique_active" json:"is_active"`
 	CreatedAt     time.Time `json:"created_at"`
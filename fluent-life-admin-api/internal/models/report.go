@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReportSchedule 定时报表配置
+type ReportSchedule struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string     `gorm:"type:varchar(100);not null" json:"name"`
+	CronExpr    string     `gorm:"type:varchar(100);not null" json:"cron_expr"`                // 5 段 cron 表达式，按北京时间解析
+	Metrics     string     `gorm:"type:text;not null" json:"metrics"`                          // 指标列表，逗号分隔
+	Recipients  string     `gorm:"type:text;not null" json:"recipients"`                       // 收件人邮箱，逗号分隔
+	Granularity string     `gorm:"type:varchar(10);not null;default:'day'" json:"granularity"` // day | week | month
+	RangeDays   int        `gorm:"not null;default:7" json:"range_days"`                       // 统计截至触发前一天的最近 N 天
+	Enabled     bool       `gorm:"not null;index" json:"enabled"`                              // 创建时总是写入，不使用数据库默认值
+	NextRunAt   *time.Time `gorm:"index" json:"next_run_at"`
+	LastRunAt   *time.Time `json:"last_run_at"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (r *ReportSchedule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// ReportRun 报表执行记录
+type ReportRun struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ScheduleID uuid.UUID  `gorm:"type:uuid;not null;index" json:"schedule_id"`
+	Trigger    string     `gorm:"type:varchar(20);not null" json:"trigger"`                  // schedule | manual
+	Status     string     `gorm:"type:varchar(20);not null;default:'running'" json:"status"` // running | success | failed
+	StartDate  string     `gorm:"type:varchar(10)" json:"start_date"`
+	EndDate    string     `gorm:"type:varchar(10)" json:"end_date"`
+	Recipients string     `gorm:"type:text" json:"recipients"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  time.Time  `gorm:"not null;index" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+func (r *ReportRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SpeechTechniqueTip 语音技巧的训练要点
+type SpeechTechniqueTip struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TechniqueID uuid.UUID `gorm:"type:uuid;not null;index:idx_speech_tip_technique" json:"technique_id"`
+	Content     string    `gorm:"type:text;not null" json:"content"`
+	Order       int       `gorm:"not null;default:0" json:"order"`
+	IsActive    bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SpeechPracticeText 语音技巧的练习文本
+type SpeechPracticeText struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TechniqueID uuid.UUID `gorm:"type:uuid;not null;index:idx_speech_practice_text_technique" json:"technique_id"`
+	Content     string    `gorm:"type:text;not null" json:"content"`
+	Order       int       `gorm:"not null;default:0" json:"order"`
+	Difficulty  string    `gorm:"type:varchar(20);not null;default:'basic'" json:"difficulty"` // 'basic' | 'intermediate' | 'advanced'
+	AudioURL    string    `gorm:"type:varchar(500)" json:"audio_url"`                          // 示范音频，可选
+	IsActive    bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t *SpeechTechniqueTip) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+func (t *SpeechPracticeText) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
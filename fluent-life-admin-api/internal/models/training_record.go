@@ -36,6 +36,7 @@ type TrainingRecord struct {
 	Type      string    `gorm:"type:varchar(20);not null;index:idx_training_records_type" json:"type"` // 'meditation' | 'airflow' | 'exposure' | 'practice'
 	Duration  int       `gorm:"not null" json:"duration"`                                              // 秒
 	Data      JSONB     `gorm:"type:jsonb;index:,type:gin" json:"data,omitempty"`
+	Flagged   bool      `gorm:"not null;default:false" json:"flagged"` // 视频审核未通过
 	Timestamp time.Time `gorm:"not null;index:idx_training_records_timestamp;index:idx_training_records_user_timestamp" json:"timestamp"`
 	CreatedAt time.Time `json:"created_at"`
 
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 视频分析状态
+const (
+	VideoAnalysisSuccess = "success"
+	VideoAnalysisFailed  = "failed"
+)
+
+// VideoAnalysis 脱敏练习视频的 AI 分析结果，每次分析（包括失败与重新分析）一条
+type VideoAnalysis struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AssetID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_video_analyses_asset_created,priority:1" json:"asset_id"` // video_assets.id
+	VideoSource string     `gorm:"type:varchar(20);not null" json:"video_source"`                                        // 视频索引删除后仍可追溯来源
+	VideoID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"video_id"`                                             // 来源记录ID
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ModuleID    string     `gorm:"type:varchar(64);index" json:"module_id,omitempty"`
+	StepID      *uuid.UUID `gorm:"type:uuid;index" json:"step_id,omitempty"` // exposure_steps.id
+	Analyzer    string     `gorm:"type:varchar(50);not null" json:"analyzer"`
+	Model       string     `gorm:"type:varchar(100)" json:"model"`
+	Prompt      string     `gorm:"type:text" json:"prompt"`
+	Verdict     string     `gorm:"type:text" json:"verdict"`
+	Scores      JSONB      `gorm:"type:jsonb" json:"scores,omitempty"` // 各维度 0-100 分
+	LatencyMs   int64      `gorm:"not null;default:0" json:"latency_ms"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"` // 'success' | 'failed'
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	TriggeredBy *uuid.UUID `gorm:"type:uuid" json:"triggered_by,omitempty"` // 管理端重新分析时为管理员ID
+	CreatedAt   time.Time  `gorm:"index;index:idx_video_analyses_asset_created,priority:2" json:"created_at"`
+}
+
+func (v *VideoAnalysis) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 视频审核状态
+const (
+	VideoModerationPending  = "pending"
+	VideoModerationApproved = "approved"
+	VideoModerationRejected = "rejected"
+)
+
+// VideoAsset 视频索引：汇总训练记录 data.video_url 与社区帖子 image 中的视频，由同步任务维护
+type VideoAsset struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Source    string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_video_assets_source,priority:1;index:idx_video_assets_source_created,priority:1" json:"source"` // 'exposure_module' | 'community_post'
+	SourceID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_video_assets_source,priority:2" json:"source_id"`                                                      // 训练记录ID或帖子ID
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index:idx_video_assets_user_created,priority:1" json:"user_id"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	ModuleID  string    `gorm:"type:varchar(64);index:idx_video_assets_module_created,priority:1" json:"module_id,omitempty"`
+	StepID    string    `gorm:"type:varchar(64)" json:"step_id,omitempty"`
+	Title     string    `gorm:"type:varchar(200)" json:"title,omitempty"` // 训练记录的步骤标题或帖子内容摘要
+	Duration  int       `gorm:"not null;default:0" json:"duration,omitempty"`
+	CreatedAt time.Time `gorm:"not null;index:idx_video_assets_created;index:idx_video_assets_source_created,priority:2;index:idx_video_assets_user_created,priority:2;index:idx_video_assets_module_created,priority:2" json:"created_at"` // 来源记录的创建时间
+	SyncedAt  time.Time `gorm:"not null" json:"synced_at"`
+
+	// 审核状态；同步时视频地址变化会重置为待审核
+	ModerationStatus string     `gorm:"type:varchar(20);not null;default:'pending';index:idx_video_assets_moderation,priority:1" json:"moderation_status"` // 'pending' | 'approved' | 'rejected'
+	ModerationReason string     `gorm:"type:varchar(30)" json:"moderation_reason,omitempty"`                                                               // 拒绝原因代码
+	ReviewerNote     string     `gorm:"type:varchar(500)" json:"reviewer_note,omitempty"`                                                                  // 审核备注，仅管理端可见
+	ReviewedBy       *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+}
+
+func (v *VideoAsset) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
@@ -14,6 +14,9 @@ type VoiceType struct {
 	Type        string    `gorm:"type:varchar(100);not null;unique" json:"type"` // 音色类型（技术标识），例如 "zh_female_wanqudashu_moon_bigtts"
 	Description string    `gorm:"type:varchar(255)" json:"description"`         // 音色描述
 	Enabled     bool      `gorm:"not null;default:true" json:"enabled"`         // 是否启用
+	PreviewText string    `gorm:"type:varchar(255)" json:"preview_text"`        // 试听文本，为空时使用默认文本
+	PreviewURL  string    `gorm:"type:varchar(500)" json:"-"`                   // 已缓存的试听音频地址
+	PreviewHash string    `gorm:"type:varchar(64)" json:"-"`                    // 缓存对应的合成服务、音色与文本的摘要，不一致时重新合成
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
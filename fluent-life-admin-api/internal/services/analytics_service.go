@@ -0,0 +1,375 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportTimezone 统计口径使用的时区名称，所有"天/周/月"边界都按北京时间计算
+const ReportTimezone = "Asia/Shanghai"
+
+// ReportLocation 统计口径使用的时区
+var ReportLocation = loadReportLocation()
+
+func loadReportLocation() *time.Location {
+	loc, err := time.LoadLocation(ReportTimezone)
+	if err != nil {
+		// 运行环境缺少 tzdata 时退化为固定 UTC+8（上海无夏令时，结果一致）
+		return time.FixedZone("CST", 8*3600)
+	}
+	return loc
+}
+
+// Granularity 时间序列的聚合粒度
+type Granularity string
+
+const (
+	GranularityDay   Granularity = "day"
+	GranularityWeek  Granularity = "week"
+	GranularityMonth Granularity = "month"
+)
+
+// ParseGranularity 解析聚合粒度，空字符串默认为 day
+func ParseGranularity(s string) (Granularity, error) {
+	switch Granularity(strings.ToLower(strings.TrimSpace(s))) {
+	case "", GranularityDay:
+		return GranularityDay, nil
+	case GranularityWeek:
+		return GranularityWeek, nil
+	case GranularityMonth:
+		return GranularityMonth, nil
+	default:
+		return "", fmt.Errorf("不支持的聚合粒度: %s", s)
+	}
+}
+
+// 可查询的指标
+const (
+	MetricNewUsers        = "new_users"
+	MetricActiveUsers     = "active_users"
+	MetricTrainingMinutes = "training_minutes"
+	MetricPosts           = "posts"
+	MetricComments        = "comments"
+	MetricAIConversations = "ai_conversations"
+)
+
+// AllMetrics 所有支持的指标，按展示顺序排列
+var AllMetrics = []string{
+	MetricNewUsers,
+	MetricActiveUsers,
+	MetricTrainingMinutes,
+	MetricPosts,
+	MetricComments,
+	MetricAIConversations,
+}
+
+// IsKnownMetric 判断指标名是否受支持
+func IsKnownMetric(metric string) bool {
+	for _, m := range AllMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// TrainingTypes 已知的训练类型，训练时长序列即使无数据也会补零输出
+var TrainingTypes = []string{"meditation", "airflow", "exposure", "practice"}
+
+// maxBuckets 单次查询允许的最大桶数量，避免一次拉取过长的按天序列
+const maxBuckets = 1000
+
+// StartOfDay 返回 t 所在自然日（北京时间）的零点
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(ReportLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, ReportLocation)
+}
+
+// StartOfWeek 返回 t 所在自然周（周一为第一天）的零点
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7 // 周一=0 ... 周日=6
+	return day.AddDate(0, 0, -offset)
+}
+
+// StartOfMonth 返回 t 所在自然月 1 日的零点
+func StartOfMonth(t time.Time) time.Time {
+	t = t.In(ReportLocation)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, ReportLocation)
+}
+
+// TruncateTime 按粒度截断到桶的起点
+func TruncateTime(t time.Time, g Granularity) time.Time {
+	switch g {
+	case GranularityWeek:
+		return StartOfWeek(t)
+	case GranularityMonth:
+		return StartOfMonth(t)
+	default:
+		return StartOfDay(t)
+	}
+}
+
+// nextBucket 返回下一个桶的起点
+func nextBucket(t time.Time, g Granularity) time.Time {
+	switch g {
+	case GranularityWeek:
+		return t.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// ParseDate 按北京时间解析 YYYY-MM-DD 格式的日期
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", strings.TrimSpace(s), ReportLocation)
+}
+
+// DateRange 半开区间 [Start, End)，边界均为北京时间零点
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NewDateRange 根据包含首尾的起止日期构造区间，startDate/endDate 为空时默认最近 defaultDays 天
+func NewDateRange(startDate, endDate string, defaultDays int) (DateRange, error) {
+	today := StartOfDay(time.Now())
+	end := today.AddDate(0, 0, 1)
+	if endDate != "" {
+		d, err := ParseDate(endDate)
+		if err != nil {
+			return DateRange{}, fmt.Errorf("结束日期格式错误，应为 YYYY-MM-DD")
+		}
+		end = d.AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -defaultDays)
+	if startDate != "" {
+		d, err := ParseDate(startDate)
+		if err != nil {
+			return DateRange{}, fmt.Errorf("开始日期格式错误，应为 YYYY-MM-DD")
+		}
+		start = d
+	}
+	if !start.Before(end) {
+		return DateRange{}, fmt.Errorf("开始日期不能晚于结束日期")
+	}
+	return DateRange{Start: start, End: end}, nil
+}
+
+// LastDay 返回区间内最后一天（包含）
+func (r DateRange) LastDay() time.Time {
+	return r.End.AddDate(0, 0, -1)
+}
+
+// Series 单个指标（可选维度）的时间序列，Values 与 TimeSeriesResult.Buckets 一一对应
+type Series struct {
+	Metric    string    `json:"metric"`
+	Dimension string    `json:"dimension,omitempty"`
+	Values    []float64 `json:"values"`
+	Total     float64   `json:"total"`
+}
+
+// TimeSeriesResult 时间序列查询结果
+type TimeSeriesResult struct {
+	Granularity Granularity `json:"granularity"`
+	StartDate   string      `json:"start_date"`
+	EndDate     string      `json:"end_date"`
+	Timezone    string      `json:"timezone"`
+	Buckets     []string    `json:"buckets"`
+	Series      []Series    `json:"series"`
+}
+
+// AnalyticsService 按时间桶聚合用户、训练与社区数据
+type AnalyticsService struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsService 创建统计分析服务
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	return &AnalyticsService{db: db}
+}
+
+// bucketRow 按桶聚合的原始查询结果
+type bucketRow struct {
+	Bucket    string
+	Dimension string
+	Value     float64
+}
+
+// bucketExpr 生成把时间列换算到北京时间并截断到桶起点的 SQL 表达式
+// g 与 column 只来自本文件内的常量，不接受外部输入
+func bucketExpr(g Granularity, column string) string {
+	return fmt.Sprintf("to_char(date_trunc('%s', %s AT TIME ZONE '%s'), 'YYYY-MM-DD')", g, column, ReportTimezone)
+}
+
+// BucketKeys 返回区间内所有桶的键（桶起点日期）
+func BucketKeys(r DateRange, g Granularity) ([]string, error) {
+	keys := make([]string, 0)
+	for t := TruncateTime(r.Start, g); t.Before(r.End); t = nextBucket(t, g) {
+		keys = append(keys, t.Format("2006-01-02"))
+		if len(keys) > maxBuckets {
+			return nil, fmt.Errorf("时间范围过大，请缩小范围或使用更粗的聚合粒度")
+		}
+	}
+	return keys, nil
+}
+
+// TimeSeries 查询指定指标在区间内按粒度聚合的序列，metrics 为空时返回全部指标
+func (s *AnalyticsService) TimeSeries(r DateRange, g Granularity, metrics []string) (*TimeSeriesResult, error) {
+	buckets, err := BucketKeys(r, g)
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		metrics = AllMetrics
+	}
+
+	result := &TimeSeriesResult{
+		Granularity: g,
+		StartDate:   r.Start.Format("2006-01-02"),
+		EndDate:     r.LastDay().Format("2006-01-02"),
+		Timezone:    ReportTimezone,
+		Buckets:     buckets,
+		Series:      make([]Series, 0),
+	}
+
+	for _, metric := range metrics {
+		series, err := s.metricSeries(metric, r, g, buckets)
+		if err != nil {
+			return nil, err
+		}
+		result.Series = append(result.Series, series...)
+	}
+	return result, nil
+}
+
+func (s *AnalyticsService) metricSeries(metric string, r DateRange, g Granularity, buckets []string) ([]Series, error) {
+	switch metric {
+	case MetricNewUsers:
+		return s.countSeries(metric, "users", "created_at", r, g, buckets)
+	case MetricPosts:
+		return s.countSeries(metric, "posts", "created_at", r, g, buckets)
+	case MetricComments:
+		return s.countSeries(metric, "comments", "created_at", r, g, buckets)
+	case MetricAIConversations:
+		return s.countSeries(metric, "ai_conversations", "created_at", r, g, buckets)
+	case MetricActiveUsers:
+		return s.activeUsersSeries(r, g, buckets)
+	case MetricTrainingMinutes:
+		return s.trainingMinutesSeries(r, g, buckets)
+	default:
+		return nil, fmt.Errorf("不支持的指标: %s", metric)
+	}
+}
+
+// countSeries 按创建时间统计行数
+func (s *AnalyticsService) countSeries(metric, table, column string, r DateRange, g Granularity, buckets []string) ([]Series, error) {
+	var rows []bucketRow
+	sql := fmt.Sprintf(
+		"SELECT %s AS bucket, COUNT(*) AS value FROM %s WHERE %s >= ? AND %s < ? GROUP BY 1",
+		bucketExpr(g, column), table, column, column,
+	)
+	if err := s.db.Raw(sql, r.Start, r.End).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("统计 %s 失败: %w", metric, err)
+	}
+	return []Series{fillSeries(metric, "", buckets, rows)}, nil
+}
+
+// activeUsersSQL 活跃用户的来源：训练记录时间与最近登录时间，按桶去重
+func activeUsersSQL(g Granularity) string {
+	return fmt.Sprintf(`
+		SELECT bucket, COUNT(DISTINCT user_id) AS value FROM (
+			SELECT user_id, %s AS bucket FROM training_records WHERE timestamp >= ? AND timestamp < ?
+			UNION ALL
+			SELECT id AS user_id, %s AS bucket FROM users WHERE last_login_at >= ? AND last_login_at < ?
+		) activity GROUP BY bucket`,
+		bucketExpr(g, "timestamp"), bucketExpr(g, "last_login_at"))
+}
+
+// activeUsersSeries 活跃用户 = 桶内有训练记录或登录过的去重用户数
+// 注意 users.last_login_at 只保留最近一次登录，历史桶主要依赖训练记录
+func (s *AnalyticsService) activeUsersSeries(r DateRange, g Granularity, buckets []string) ([]Series, error) {
+	var rows []bucketRow
+	if err := s.db.Raw(activeUsersSQL(g), r.Start, r.End, r.Start, r.End).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("统计活跃用户失败: %w", err)
+	}
+	series := fillSeries(MetricActiveUsers, "", buckets, rows)
+
+	// 活跃用户的合计是整个区间的去重人数，而非各桶相加
+	total, err := s.ActiveUserCount(r.Start, r.End)
+	if err != nil {
+		return nil, err
+	}
+	series.Total = float64(total)
+	return []Series{series}, nil
+}
+
+// ActiveUserCount 统计 [start, end) 内有训练记录或登录过的去重用户数
+func (s *AnalyticsService) ActiveUserCount(start, end time.Time) (int64, error) {
+	var total int64
+	err := s.db.Raw(`
+		SELECT COUNT(DISTINCT user_id) FROM (
+			SELECT user_id FROM training_records WHERE timestamp >= ? AND timestamp < ?
+			UNION
+			SELECT id AS user_id FROM users WHERE last_login_at >= ? AND last_login_at < ?
+		) activity`, start, end, start, end).Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("统计活跃用户失败: %w", err)
+	}
+	return total, nil
+}
+
+// trainingMinutesSeries 按训练类型统计训练分钟数（以训练记录的 timestamp 归桶）
+func (s *AnalyticsService) trainingMinutesSeries(r DateRange, g Granularity, buckets []string) ([]Series, error) {
+	var rows []bucketRow
+	sql := fmt.Sprintf(
+		"SELECT %s AS bucket, type AS dimension, COALESCE(SUM(duration), 0) / 60.0 AS value FROM training_records WHERE timestamp >= ? AND timestamp < ? GROUP BY 1, 2",
+		bucketExpr(g, "timestamp"),
+	)
+	if err := s.db.Raw(sql, r.Start, r.End).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("统计训练时长失败: %w", err)
+	}
+
+	byType := make(map[string][]bucketRow)
+	for _, t := range TrainingTypes {
+		byType[t] = nil
+	}
+	for _, row := range rows {
+		byType[row.Dimension] = append(byType[row.Dimension], row)
+	}
+
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	series := make([]Series, 0, len(types))
+	for _, t := range types {
+		series = append(series, fillSeries(MetricTrainingMinutes, t, buckets, byType[t]))
+	}
+	return series, nil
+}
+
+// fillSeries 将稀疏的查询结果按桶补零并计算合计
+func fillSeries(metric, dimension string, buckets []string, rows []bucketRow) Series {
+	index := make(map[string]int, len(buckets))
+	for i, b := range buckets {
+		index[b] = i
+	}
+	values := make([]float64, len(buckets))
+	var total float64
+	for _, row := range rows {
+		if i, ok := index[row.Bucket]; ok {
+			values[i] += row.Value
+			total += row.Value
+		}
+	}
+	return Series{Metric: metric, Dimension: dimension, Values: values, Total: total}
+}
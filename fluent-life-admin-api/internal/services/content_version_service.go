@@ -0,0 +1,901 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/textdiff"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 纳入版本管理的内容类型
+const (
+	VersionTongueTwister   = "tongue_twister"
+	VersionDailyExpression = "daily_expression"
+	VersionSpeechTechnique = "speech_technique"
+	VersionLegalDocument   = "legal_document"
+)
+
+// 版本状态
+const (
+	RevisionDraft     = "draft"
+	RevisionPending   = "pending_review"
+	RevisionRejected  = "rejected"
+	RevisionPublished = "published"
+)
+
+// PermissionContentPublish 直接发布内容及审核他人修改所需的权限；没有该权限的角色提交后需要审核
+const PermissionContentPublish = "content:publish"
+
+var (
+	ErrRevisionNotFound = errors.New("版本不存在")
+	ErrRevisionConflict = errors.New("内容在草稿创建后已有新的发布版本，请基于最新版本更新草稿后再提交")
+	ErrRevisionState    = errors.New("当前状态不允许该操作")
+	ErrNoPublishPerm    = errors.New("没有发布权限")
+	ErrNotDraftOwner    = errors.New("只能修改、提交或删除自己创建的草稿")
+	ErrContentNotFound  = errors.New("内容不存在")
+	ErrRevisionInvalid  = errors.New("内容校验失败")
+)
+
+// 字段类型
+const (
+	fieldString = "string"
+	fieldText   = "text"
+	fieldInt    = "int"
+	fieldBool   = "bool"
+	fieldDate   = "date"
+	fieldList   = "list" // JSON 字符串数组，统一保存为紧凑的 JSON 文本
+)
+
+type versionField struct {
+	Key      string
+	Label    string
+	Kind     string
+	Required bool
+	MaxLen   int
+	Enum     []string
+}
+
+type versionSpec struct {
+	Label  string
+	Table  string
+	Fields []versionField
+	// afterApply 发布后对实际内容的附加处理
+	afterApply func(tx *gorm.DB, id uuid.UUID, data map[string]interface{}, actor Actor) error
+}
+
+var versionSpecs = map[string]*versionSpec{
+	VersionTongueTwister: {
+		Label: "绕口令",
+		Table: "tongue_twisters",
+		Fields: []versionField{
+			{Key: "title", Label: "标题", Kind: fieldString, Required: true, MaxLen: 200},
+			{Key: "content", Label: "内容", Kind: fieldText, Required: true},
+			{Key: "tips", Label: "提示", Kind: fieldText},
+			{Key: "level", Label: "难度", Kind: fieldString, Required: true, Enum: TongueTwisterLevels},
+			{Key: "order", Label: "排序", Kind: fieldInt},
+			{Key: "is_active", Label: "是否启用", Kind: fieldBool},
+		},
+		afterApply: func(tx *gorm.DB, id uuid.UUID, data map[string]interface{}, actor Actor) error {
+			content, _ := data["content"].(string)
+			return tx.Table("tongue_twisters").Where("id = ?", id).Update("difficulty_score", AnalyzeDifficulty(content).Score).Error
+		},
+	},
+	VersionDailyExpression: {
+		Label: "每日朗诵文案",
+		Table: "daily_expressions",
+		Fields: []versionField{
+			{Key: "title", Label: "标题", Kind: fieldString, Required: true, MaxLen: 200},
+			{Key: "content", Label: "内容", Kind: fieldText, Required: true},
+			{Key: "tips", Label: "朗诵技巧", Kind: fieldText},
+			{Key: "source", Label: "来源", Kind: fieldString, MaxLen: 100},
+			{Key: "date", Label: "发布日期", Kind: fieldDate},
+			{Key: "is_active", Label: "是否启用", Kind: fieldBool},
+		},
+	},
+	// 训练要点与练习文本以启用条目的内容列表纳入版本，发布时按列表同步条目（见 SpeechItemService.SyncList）；
+	// 单独修改条目后列表变化，会在下次取版本时记录为版本流程外的修改
+	VersionSpeechTechnique: {
+		Label: "语音技巧",
+		Table: "speech_techniques",
+		Fields: []versionField{
+			{Key: "name", Label: "技巧名称", Kind: fieldString, Required: true, MaxLen: 100},
+			{Key: "icon", Label: "图标", Kind: fieldString, MaxLen: 10},
+			{Key: "description", Label: "简短描述", Kind: fieldString, MaxLen: 200},
+			{Key: "tips", Label: "训练要点", Kind: fieldList},
+			{Key: "practice_texts", Label: "练习文本", Kind: fieldList},
+			{Key: "order", Label: "排序", Kind: fieldInt},
+			{Key: "is_active", Label: "是否启用", Kind: fieldBool},
+		},
+		afterApply: func(tx *gorm.DB, id uuid.UUID, data map[string]interface{}, actor Actor) error {
+			items := NewSpeechItemService(tx)
+			for _, kind := range []string{SpeechItemTips, SpeechItemPracticeTexts} {
+				raw, ok := data[kind].(string)
+				if !ok {
+					continue
+				}
+				texts, err := StrictStringList(raw)
+				if err != nil {
+					return err
+				}
+				if err := items.SyncList(tx, id, kind, texts); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	VersionLegalDocument: {
+		Label: "法律文档",
+		Table: "legal_documents",
+		Fields: []versionField{
+			{Key: "type", Label: "文档类型", Kind: fieldString, Required: true, Enum: []string{"terms_of_service", "privacy_policy"}},
+			{Key: "title", Label: "文档标题", Kind: fieldString, Required: true, MaxLen: 200},
+			{Key: "content", Label: "文档内容", Kind: fieldText, Required: true},
+			{Key: "version", Label: "版本号", Kind: fieldString, Required: true, MaxLen: 20},
+			{Key: "is_active", Label: "是否启用", Kind: fieldBool},
+		},
+		afterApply: func(tx *gorm.DB, id uuid.UUID, data map[string]interface{}, actor Actor) error {
+			// 启用的文档同类型只保留一份
+			if active, _ := data["is_active"].(bool); active {
+				if err := tx.Table("legal_documents").Where("type = ? AND id != ?", data["type"], id).Update("is_active", false).Error; err != nil {
+					return err
+				}
+			}
+			updatedBy := actor.ID
+			return tx.Table("legal_documents").Where("id = ?", id).Update("updated_by", &updatedBy).Error
+		},
+	},
+}
+
+// ValidContentType 是否为纳入版本管理的内容类型
+func ValidContentType(contentType string) bool {
+	_, ok := versionSpecs[contentType]
+	return ok
+}
+
+// Actor 操作人及其是否有发布权限
+type Actor struct {
+	ID         uuid.UUID
+	Role       string
+	CanPublish bool
+}
+
+// RoleHasPermission 检查角色是否拥有权限（"*" 表示全部权限）
+func RoleHasPermission(db *gorm.DB, roleCode, permission string) bool {
+	var role models.Role
+	if err := db.Where("code = ?", roleCode).First(&role).Error; err != nil {
+		return false
+	}
+	if all, _ := role.Permissions["*"].(bool); all {
+		return true
+	}
+	granted, _ := role.Permissions[permission].(bool)
+	return granted
+}
+
+// FieldChange 两个版本间单个字段的差异
+type FieldChange struct {
+	Field   string        `json:"field"`
+	Label   string        `json:"label"`
+	From    interface{}   `json:"from"`
+	To      interface{}   `json:"to"`
+	Changed bool          `json:"changed"`
+	Ops     []textdiff.Op `json:"ops,omitempty"` // 文本字段的逐行（单行文本为逐字）差异
+}
+
+// RevisionDiff 两个版本的差异
+type RevisionDiff struct {
+	From    *models.ContentRevision `json:"from"`
+	To      *models.ContentRevision `json:"to"`
+	Changes []FieldChange           `json:"changes"`
+}
+
+// ContentVersionService 内容版本管理：草稿、审核、发布、差异与回滚
+type ContentVersionService struct {
+	db *gorm.DB
+}
+
+func NewContentVersionService(db *gorm.DB) *ContentVersionService {
+	return &ContentVersionService{db: db}
+}
+
+// Actor 根据当前用户与角色构造操作人
+func (s *ContentVersionService) Actor(userID uuid.UUID, role string) Actor {
+	return Actor{ID: userID, Role: role, CanPublish: RoleHasPermission(s.db, role, PermissionContentPublish)}
+}
+
+// snapshot 读取内容当前的字段值
+func (s *ContentVersionService) snapshot(tx *gorm.DB, spec *versionSpec, id uuid.UUID) (map[string]interface{}, error) {
+	cols := make([]string, len(spec.Fields))
+	for i, f := range spec.Fields {
+		cols[i] = `"` + f.Key + `"`
+	}
+	row := map[string]interface{}{}
+	err := tx.Table(spec.Table).Select(strings.Join(cols, ", ")).Where("id = ?", id).Take(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrContentNotFound, spec.Label)
+		}
+		return nil, err
+	}
+	data := make(map[string]interface{}, len(spec.Fields))
+	for _, f := range spec.Fields {
+		v, err := normalizeFieldValue(f, row[f.Key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Label, err)
+		}
+		data[f.Key] = v
+	}
+	return data, nil
+}
+
+// normalizeFieldValue 把 JSON 或数据库读出的值统一为字段类型的规范形式
+func normalizeFieldValue(f versionField, v interface{}) (interface{}, error) {
+	switch f.Kind {
+	case fieldInt:
+		switch n := v.(type) {
+		case nil:
+			return 0, nil
+		case float64:
+			if n != math.Trunc(n) {
+				return nil, fmt.Errorf("应为整数")
+			}
+			return int(n), nil
+		case int:
+			return n, nil
+		case int32:
+			return int(n), nil
+		case int64:
+			return int(n), nil
+		}
+		return nil, fmt.Errorf("应为整数")
+	case fieldBool:
+		switch b := v.(type) {
+		case nil:
+			return false, nil
+		case bool:
+			return b, nil
+		}
+		return nil, fmt.Errorf("应为 true/false")
+	case fieldDate:
+		switch d := v.(type) {
+		case nil:
+			return nil, nil
+		case time.Time:
+			return d.UTC().Format(dateLayout), nil
+		case *time.Time:
+			if d == nil {
+				return nil, nil
+			}
+			return d.UTC().Format(dateLayout), nil
+		case string:
+			if d == "" {
+				return nil, nil
+			}
+			t, ok := ParseContentDate(d)
+			if !ok {
+				return nil, fmt.Errorf("日期格式应为 YYYY-MM-DD")
+			}
+			return t.Format(dateLayout), nil
+		}
+		return nil, fmt.Errorf("日期格式应为 YYYY-MM-DD")
+	case fieldList:
+		var texts []string
+		switch list := v.(type) {
+		case nil:
+			texts = []string{}
+		case string:
+			parsed, err := StrictStringList(list)
+			if err != nil {
+				return nil, err
+			}
+			texts = parsed
+		case []interface{}:
+			texts = make([]string, 0, len(list))
+			for _, item := range list {
+				str, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("应为字符串数组")
+				}
+				if str = strings.TrimSpace(str); str != "" {
+					texts = append(texts, str)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("应为字符串数组")
+		}
+		raw, _ := json.Marshal(texts)
+		return string(raw), nil
+	default:
+		switch str := v.(type) {
+		case nil:
+			return "", nil
+		case string:
+			return str, nil
+		}
+		return nil, fmt.Errorf("应为字符串")
+	}
+}
+
+// mergeData 把修改合并到基础数据上并校验；未知字段、类型错误、必填为空都会报错
+func mergeData(spec *versionSpec, base, changes map[string]interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]versionField, len(spec.Fields))
+	for _, f := range spec.Fields {
+		fields[f.Key] = f
+	}
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	var problems []string
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := changes[key]
+		f, ok := fields[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("不支持的字段 %s", key))
+			continue
+		}
+		v, err := normalizeFieldValue(f, value)
+		if err != nil {
+			problems = append(problems, f.Label+err.Error())
+			continue
+		}
+		merged[key] = v
+	}
+	for _, f := range spec.Fields {
+		str, isString := merged[f.Key].(string)
+		if f.Required && isString && strings.TrimSpace(str) == "" {
+			problems = append(problems, f.Label+"不能为空")
+		}
+		if f.MaxLen > 0 && isString && len([]rune(str)) > f.MaxLen {
+			problems = append(problems, fmt.Sprintf("%s不能超过 %d 个字符", f.Label, f.MaxLen))
+		}
+		if len(f.Enum) > 0 && isString && str != "" && !containsString(f.Enum, str) {
+			problems = append(problems, fmt.Sprintf("%s应为 %s 之一", f.Label, strings.Join(f.Enum, "、")))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRevisionInvalid, strings.Join(problems, "；"))
+	}
+	return merged, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// current 返回与实际内容一致的最新发布版本：还没有版本记录时以当前内容生成初始版本；
+// 内容在版本流程之外被修改过（与最新发布版本不一致）时，把当前内容记录为新的发布版本，
+// 这样新草稿总是基于实际内容，而基于旧版本的草稿发布时会提示冲突，不会覆盖这些修改
+func (s *ContentVersionService) current(tx *gorm.DB, contentType string, id uuid.UUID) (*models.ContentRevision, error) {
+	spec := versionSpecs[contentType]
+	// 锁住内容行，避免并发请求重复记录同一次外部修改
+	if err := lockContent(tx, spec, id); err != nil {
+		return nil, err
+	}
+	data, err := s.snapshot(tx, spec, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest models.ContentRevision
+	err = tx.Where("content_type = ? AND content_id = ? AND status = ?", contentType, id, RevisionPublished).
+		Order("number DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	rev := models.ContentRevision{
+		ContentType: contentType,
+		ContentID:   id,
+		Number:      1,
+		Status:      RevisionPublished,
+		Note:        "初始版本",
+	}
+	if err == nil {
+		if valuesEqual(data, decodeRevisionData(&latest)) {
+			return &latest, nil
+		}
+		rev.Number = latest.Number + 1
+		rev.BaseNumber = latest.Number
+		rev.Note = "同步版本流程外的修改"
+	}
+
+	raw, _ := json.Marshal(data)
+	now := time.Now()
+	rev.Data = string(raw)
+	rev.PublishedAt = &now
+	if err := tx.Create(&rev).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func decodeRevisionData(rev *models.ContentRevision) map[string]interface{} {
+	data := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(rev.Data), &data)
+	return data
+}
+
+// CreateDraft 基于内容当前的发布版本创建草稿（见 current），changes 只需包含要修改的字段
+func (s *ContentVersionService) CreateDraft(contentType string, id uuid.UUID, changes map[string]interface{}, note string, actor Actor) (*models.ContentRevision, error) {
+	spec, ok := versionSpecs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: 不支持的内容类型 %s", ErrRevisionInvalid, contentType)
+	}
+	var draft *models.ContentRevision
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cur, err := s.current(tx, contentType, id)
+		if err != nil {
+			return err
+		}
+		merged, err := mergeData(spec, decodeRevisionData(cur), changes)
+		if err != nil {
+			return err
+		}
+		raw, _ := json.Marshal(merged)
+		createdBy := actor.ID
+		draft = &models.ContentRevision{
+			ContentType: contentType,
+			ContentID:   id,
+			BaseNumber:  cur.Number,
+			Status:      RevisionDraft,
+			Data:        string(raw),
+			Note:        note,
+			CreatedBy:   &createdBy,
+		}
+		return tx.Create(draft).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// discardRevisions 删除内容后清理其未发布的草稿与待审核修改，已发布的版本保留作为记录
+func discardRevisions(tx *gorm.DB, contentType string, id uuid.UUID) error {
+	return tx.Where("content_type = ? AND content_id = ? AND status <> ?", contentType, id, RevisionPublished).
+		Delete(&models.ContentRevision{}).Error
+}
+
+// Get 获取版本
+func (s *ContentVersionService) Get(revisionID uuid.UUID) (*models.ContentRevision, error) {
+	var rev models.ContentRevision
+	if err := s.db.First(&rev, "id = ?", revisionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// canEdit 草稿只能由作者或有发布权限的人修改
+func canEdit(rev *models.ContentRevision, actor Actor) bool {
+	return actor.CanPublish || (rev.CreatedBy != nil && *rev.CreatedBy == actor.ID)
+}
+
+// UpdateDraft 修改草稿（被驳回的草稿修改后回到草稿状态）；rebase 为 true 时以最新发布版本为基础重新合并
+func (s *ContentVersionService) UpdateDraft(revisionID uuid.UUID, changes map[string]interface{}, note *string, rebase bool, actor Actor) (*models.ContentRevision, error) {
+	rev, err := s.Get(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Status != RevisionDraft && rev.Status != RevisionRejected {
+		return nil, ErrRevisionState
+	}
+	if !canEdit(rev, actor) {
+		return nil, ErrNotDraftOwner
+	}
+	spec := versionSpecs[rev.ContentType]
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		base := decodeRevisionData(rev)
+		if rebase {
+			cur, err := s.current(tx, rev.ContentType, rev.ContentID)
+			if err != nil {
+				return err
+			}
+			// 以最新发布版本为基础，保留草稿中相对原基础版本做过的修改
+			var original models.ContentRevision
+			err = tx.Where("content_type = ? AND content_id = ? AND status = ? AND number = ?",
+				rev.ContentType, rev.ContentID, RevisionPublished, rev.BaseNumber).First(&original).Error
+			if err != nil {
+				return err
+			}
+			originalData := decodeRevisionData(&original)
+			edited := map[string]interface{}{}
+			for k, v := range base {
+				if !valuesEqual(v, originalData[k]) {
+					edited[k] = v
+				}
+			}
+			base, err = mergeData(spec, decodeRevisionData(cur), edited)
+			if err != nil {
+				return err
+			}
+			rev.BaseNumber = cur.Number
+		}
+		merged, err := mergeData(spec, base, changes)
+		if err != nil {
+			return err
+		}
+		raw, _ := json.Marshal(merged)
+		rev.Data = string(raw)
+		rev.Status = RevisionDraft
+		if note != nil {
+			rev.Note = *note
+		}
+		return tx.Save(rev).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// DiscardDraft 删除未发布的草稿
+func (s *ContentVersionService) DiscardDraft(revisionID uuid.UUID, actor Actor) error {
+	rev, err := s.Get(revisionID)
+	if err != nil {
+		return err
+	}
+	if rev.Status == RevisionPublished {
+		return ErrRevisionState
+	}
+	if !canEdit(rev, actor) {
+		return ErrNotDraftOwner
+	}
+	return s.db.Delete(rev).Error
+}
+
+// Submit 提交草稿：有发布权限时直接发布，否则进入待审核
+func (s *ContentVersionService) Submit(revisionID uuid.UUID, actor Actor) (*models.ContentRevision, error) {
+	rev, err := s.Get(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Status != RevisionDraft && rev.Status != RevisionRejected {
+		return nil, ErrRevisionState
+	}
+	if !canEdit(rev, actor) {
+		return nil, ErrNotDraftOwner
+	}
+	if actor.CanPublish {
+		return rev, s.publish(rev, actor, "")
+	}
+
+	now := time.Now()
+	res := s.db.Model(rev).Where("status IN ?", []string{RevisionDraft, RevisionRejected}).
+		Updates(map[string]interface{}{"status": RevisionPending, "submitted_at": now})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrRevisionState
+	}
+	rev.Status = RevisionPending
+	rev.SubmittedAt = &now
+	return rev, nil
+}
+
+// Approve 审核通过并发布
+func (s *ContentVersionService) Approve(revisionID uuid.UUID, comment string, actor Actor) (*models.ContentRevision, error) {
+	if !actor.CanPublish {
+		return nil, ErrNoPublishPerm
+	}
+	rev, err := s.Get(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Status != RevisionPending {
+		return nil, ErrRevisionState
+	}
+	return rev, s.publish(rev, actor, comment)
+}
+
+// Reject 驳回待审核的修改，作者可修改后重新提交
+func (s *ContentVersionService) Reject(revisionID uuid.UUID, comment string, actor Actor) (*models.ContentRevision, error) {
+	if !actor.CanPublish {
+		return nil, ErrNoPublishPerm
+	}
+	rev, err := s.Get(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	reviewer := actor.ID
+	res := s.db.Model(rev).Where("status = ?", RevisionPending).Updates(map[string]interface{}{
+		"status":         RevisionRejected,
+		"reviewed_by":    reviewer,
+		"reviewed_at":    now,
+		"review_comment": comment,
+	})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrRevisionState
+	}
+	rev.Status = RevisionRejected
+	rev.ReviewedBy = &reviewer
+	rev.ReviewedAt = &now
+	rev.ReviewComment = comment
+	return rev, nil
+}
+
+// lockContent 锁住内容行，保证同一内容的发布串行
+func lockContent(tx *gorm.DB, spec *versionSpec, id uuid.UUID) error {
+	var locked struct{ ID uuid.UUID }
+	err := tx.Table(spec.Table).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ?", id).Take(&locked).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %s", ErrContentNotFound, spec.Label)
+	}
+	return err
+}
+
+// writeContent 把版本数据写入实际内容
+func writeContent(tx *gorm.DB, spec *versionSpec, id uuid.UUID, data map[string]interface{}, actor Actor) error {
+	updates := make(map[string]interface{}, len(spec.Fields))
+	for _, f := range spec.Fields {
+		updates[f.Key] = data[f.Key]
+	}
+	updates["updated_at"] = time.Now()
+	if err := tx.Table(spec.Table).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return err
+	}
+	if spec.afterApply != nil {
+		return spec.afterApply(tx, id, data, actor)
+	}
+	return nil
+}
+
+// Apply 不经草稿与审核直接修改内容，并记录为新的发布版本；用于导入覆盖等由系统批量执行的写入，
+// 在调用方的事务 tx 中执行，内容没有变化时不生成版本
+func (s *ContentVersionService) Apply(tx *gorm.DB, contentType string, id uuid.UUID, changes map[string]interface{}, note string, actor Actor) error {
+	spec, ok := versionSpecs[contentType]
+	if !ok {
+		return fmt.Errorf("%w: 不支持的内容类型 %s", ErrRevisionInvalid, contentType)
+	}
+	if err := lockContent(tx, spec, id); err != nil {
+		return err
+	}
+	cur, err := s.current(tx, contentType, id)
+	if err != nil {
+		return err
+	}
+	base := decodeRevisionData(cur)
+	merged, err := mergeData(spec, base, changes)
+	if err != nil {
+		return err
+	}
+	if valuesEqual(merged, base) {
+		return nil
+	}
+	if err := writeContent(tx, spec, id, merged, actor); err != nil {
+		return err
+	}
+
+	raw, _ := json.Marshal(merged)
+	now := time.Now()
+	actorID := actor.ID
+	return tx.Create(&models.ContentRevision{
+		ContentType: contentType,
+		ContentID:   id,
+		Number:      cur.Number + 1,
+		BaseNumber:  cur.Number,
+		Status:      RevisionPublished,
+		Data:        string(raw),
+		Note:        note,
+		CreatedBy:   &actorID,
+		PublishedBy: &actorID,
+		PublishedAt: &now,
+	}).Error
+}
+
+// publish 在事务中把版本内容写入实际内容并生成新的发布序号；锁住内容行保证同一内容的发布串行
+func (s *ContentVersionService) publish(rev *models.ContentRevision, actor Actor, comment string) error {
+	spec := versionSpecs[rev.ContentType]
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockContent(tx, spec, rev.ContentID); err != nil {
+			return err
+		}
+		cur, err := s.current(tx, rev.ContentType, rev.ContentID)
+		if err != nil {
+			return err
+		}
+		if rev.BaseNumber != cur.Number {
+			return ErrRevisionConflict
+		}
+
+		data := decodeRevisionData(rev)
+		if err := writeContent(tx, spec, rev.ContentID, data, actor); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		publisher := actor.ID
+		rev.Number = cur.Number + 1
+		rev.Status = RevisionPublished
+		rev.PublishedBy = &publisher
+		rev.PublishedAt = &now
+		if comment != "" || rev.SubmittedAt != nil {
+			rev.ReviewedBy = &publisher
+			rev.ReviewedAt = &now
+			rev.ReviewComment = comment
+		}
+		return tx.Save(rev).Error
+	})
+}
+
+// Revert 以某个发布版本的内容生成新版本并提交（有发布权限时立即生效）
+func (s *ContentVersionService) Revert(contentType string, id uuid.UUID, number int, note string, actor Actor) (*models.ContentRevision, error) {
+	if !ValidContentType(contentType) {
+		return nil, fmt.Errorf("%w: 不支持的内容类型 %s", ErrRevisionInvalid, contentType)
+	}
+	var target models.ContentRevision
+	err := s.db.Where("content_type = ? AND content_id = ? AND status = ? AND number = ?",
+		contentType, id, RevisionPublished, number).First(&target).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	if note == "" {
+		note = fmt.Sprintf("回滚到版本 %d", number)
+	}
+	draft, err := s.CreateDraft(contentType, id, decodeRevisionData(&target), note, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(draft).Update("reverted_from", number).Error; err != nil {
+		return nil, err
+	}
+	draft.RevertedFrom = number
+	return s.Submit(draft.ID, actor)
+}
+
+// History 内容的全部版本（发布版本按序号倒序，未发布的草稿在前）
+func (s *ContentVersionService) History(contentType string, id uuid.UUID) ([]models.ContentRevision, error) {
+	if !ValidContentType(contentType) {
+		return nil, fmt.Errorf("%w: 不支持的内容类型 %s", ErrRevisionInvalid, contentType)
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		_, err := s.current(tx, contentType, id)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	var revisions []models.ContentRevision
+	err := s.db.Where("content_type = ? AND content_id = ?", contentType, id).
+		Order("CASE WHEN status = 'published' THEN 1 ELSE 0 END, number DESC, created_at DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// Diff 比较两个版本；against 为空时与该内容最新的发布版本比较
+func (s *ContentVersionService) Diff(revisionID uuid.UUID, against *uuid.UUID) (*RevisionDiff, error) {
+	to, err := s.Get(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	var from *models.ContentRevision
+	if against != nil {
+		if from, err = s.Get(*against); err != nil {
+			return nil, err
+		}
+		if from.ContentType != to.ContentType || from.ContentID != to.ContentID {
+			return nil, fmt.Errorf("%w: 只能比较同一内容的版本", ErrRevisionInvalid)
+		}
+	} else {
+		var cur models.ContentRevision
+		err := s.db.Where("content_type = ? AND content_id = ? AND status = ?", to.ContentType, to.ContentID, RevisionPublished).
+			Order("number DESC").First(&cur).Error
+		if err != nil {
+			return nil, err
+		}
+		from = &cur
+		// 与自身比较时改为与上一个发布版本比较
+		if cur.ID == to.ID && to.Number > 1 {
+			var prev models.ContentRevision
+			if err := s.db.Where("content_type = ? AND content_id = ? AND status = ? AND number < ?",
+				to.ContentType, to.ContentID, RevisionPublished, to.Number).Order("number DESC").First(&prev).Error; err == nil {
+				from = &prev
+			}
+		}
+	}
+
+	spec := versionSpecs[to.ContentType]
+	fromData, toData := decodeRevisionData(from), decodeRevisionData(to)
+	diff := &RevisionDiff{From: from, To: to, Changes: make([]FieldChange, 0, len(spec.Fields))}
+	for _, f := range spec.Fields {
+		change := FieldChange{Field: f.Key, Label: f.Label, From: fromData[f.Key], To: toData[f.Key]}
+		change.Changed = !valuesEqual(change.From, change.To)
+		if change.Changed && (f.Kind == fieldText || f.Kind == fieldString) {
+			a, _ := change.From.(string)
+			b, _ := change.To.(string)
+			change.Ops = textdiff.Diff(a, b)
+		}
+		if change.Changed && f.Kind == fieldList {
+			change.Ops = textdiff.Diff(listLines(change.From), listLines(change.To))
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+	return diff, nil
+}
+
+// ReviewQueue 待审核的修改，按提交时间排序
+func (s *ContentVersionService) ReviewQueue(contentType string, page, pageSize int) ([]models.ContentRevision, int64, error) {
+	query := s.db.Model(&models.ContentRevision{}).Where("status = ?", RevisionPending)
+	if contentType != "" {
+		query = query.Where("content_type = ?", contentType)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var revisions []models.ContentRevision
+	err := query.Order("submitted_at").Offset((page - 1) * pageSize).Limit(pageSize).Find(&revisions).Error
+	return revisions, total, err
+}
+
+// listLines 把列表字段转换为每项一行的文本，便于逐项比较
+func listLines(v interface{}) string {
+	raw, _ := v.(string)
+	texts, _ := StrictStringList(raw)
+	if len(texts) == 0 {
+		return ""
+	}
+	return strings.Join(texts, "\n") + "\n"
+}
+
+func valuesEqual(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+// ModelChanges 把绑定后的模型转换为版本字段（按 JSON 字段名提取）
+func ModelChanges(contentType string, model interface{}) (map[string]interface{}, error) {
+	spec, ok := versionSpecs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: 不支持的内容类型 %s", ErrRevisionInvalid, contentType)
+	}
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	changes := make(map[string]interface{}, len(spec.Fields))
+	for _, f := range spec.Fields {
+		if v, ok := all[f.Key]; ok {
+			changes[f.Key] = v
+		}
+	}
+	return changes, nil
+}
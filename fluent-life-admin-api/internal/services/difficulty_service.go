@@ -0,0 +1,265 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/pinyin"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 难度分数到级别的分界：低于 difficultyIntermediateAt 为 basic，低于 difficultyAdvancedAt 为 intermediate
+const (
+	difficultyIntermediateAt = 35
+	difficultyAdvancedAt     = 60
+	// lowCoverage 字表覆盖率低于该值时分析结果仅供参考
+	lowCoverage = 0.8
+)
+
+// plosiveInitials 塞音声母，口吃者在这些音节起始处最容易出现阻塞
+var plosiveInitials = map[string]bool{"b": true, "p": true, "d": true, "t": true, "g": true, "k": true}
+
+// confusableInitials 容易混淆的声母对（平翘舌、鼻边音、唇齿/舌根、送气/不送气等）
+var confusableInitials = map[[2]string]bool{}
+
+// confusableFinals 容易混淆的韵母对（前后鼻音）
+var confusableFinals = map[[2]string]bool{}
+
+func init() {
+	for _, pair := range [][2]string{
+		{"z", "zh"}, {"c", "ch"}, {"s", "sh"}, {"n", "l"}, {"l", "r"}, {"f", "h"},
+		{"b", "p"}, {"d", "t"}, {"g", "k"}, {"j", "zh"}, {"q", "ch"}, {"x", "sh"},
+	} {
+		confusableInitials[pair] = true
+		confusableInitials[[2]string{pair[1], pair[0]}] = true
+	}
+	for _, pair := range [][2]string{{"an", "ang"}, {"en", "eng"}, {"in", "ing"}, {"ian", "iang"}, {"uan", "uang"}} {
+		confusableFinals[pair] = true
+		confusableFinals[[2]string{pair[1], pair[0]}] = true
+	}
+}
+
+// DifficultyFeatures 难度特征
+type DifficultyFeatures struct {
+	Syllables         int     `json:"syllables"`           // 可识别的音节数
+	LongestPhrase     int     `json:"longest_phrase"`      // 最长的不停顿音节串
+	PlosiveRatio      float64 `json:"plosive_ratio"`       // 塞音声母音节占比
+	ConfusablePairs   int     `json:"confusable_pairs"`    // 相邻音节中声母或韵母易混的对数
+	ConfusableDensity float64 `json:"confusable_density"`  // 易混对数 / 相邻对数
+	RepeatedInitials  float64 `json:"repeated_initials"`   // 相邻音节声母相同的比例
+	ToneChangeRatio   float64 `json:"tone_change_ratio"`   // 相邻音节声调不同的比例
+	ToneSandhiSites   int     `json:"tone_sandhi_sites"`   // 变调位置数（上上相连、"一"、"不"变调）
+	ToneSandhiDensity float64 `json:"tone_sandhi_density"` // 变调位置数 / 音节数
+}
+
+// DifficultyAnalysis 绕口令难度分析结果
+type DifficultyAnalysis struct {
+	Score          float64             `json:"score"` // 0 ~ 100
+	SuggestedLevel string              `json:"suggested_level"`
+	Features       DifficultyFeatures  `json:"features"`
+	Coverage       float64             `json:"coverage"`         // 字表覆盖率
+	UnknownChars   []string            `json:"unknown_chars"`    // 字表中没有的字，不参与计算
+	LowConfidence  bool                `json:"low_confidence"`   // 覆盖率过低，结果仅供参考
+	Pinyin         [][]pinyin.Syllable `json:"pinyin,omitempty"` // 按短语切分的拼音，仅单条分析时返回
+}
+
+// AnalyzeDifficulty 把文本转换为拼音并计算难度特征、分数与建议级别
+func AnalyzeDifficulty(text string) *DifficultyAnalysis {
+	converted := pinyin.Convert(text)
+	f := DifficultyFeatures{}
+
+	pairs := 0
+	plosives := 0
+	repeated := 0
+	toneChanges := 0
+	for _, phrase := range converted.Phrases {
+		f.Syllables += len(phrase)
+		if len(phrase) > f.LongestPhrase {
+			f.LongestPhrase = len(phrase)
+		}
+		for i, s := range phrase {
+			if plosiveInitials[s.Initial] {
+				plosives++
+			}
+			if isSandhiSite(phrase, i) {
+				f.ToneSandhiSites++
+			}
+			if i == 0 {
+				continue
+			}
+			prev := phrase[i-1]
+			pairs++
+			if confusableInitials[[2]string{prev.Initial, s.Initial}] || confusableFinals[[2]string{prev.Final, s.Final}] {
+				f.ConfusablePairs++
+			}
+			if s.Initial != "" && s.Initial == prev.Initial {
+				repeated++
+			}
+			if s.Tone != prev.Tone {
+				toneChanges++
+			}
+		}
+	}
+	if f.Syllables > 0 {
+		f.PlosiveRatio = round3(float64(plosives) / float64(f.Syllables))
+		f.ToneSandhiDensity = round3(float64(f.ToneSandhiSites) / float64(f.Syllables))
+	}
+	if pairs > 0 {
+		f.ConfusableDensity = round3(float64(f.ConfusablePairs) / float64(pairs))
+		f.RepeatedInitials = round3(float64(repeated) / float64(pairs))
+		f.ToneChangeRatio = round3(float64(toneChanges) / float64(pairs))
+	}
+
+	score := 25*capRatio(float64(f.Syllables)/60) +
+		5*capRatio(float64(f.LongestPhrase)/12) +
+		15*capRatio(f.PlosiveRatio/0.5) +
+		25*capRatio(f.ConfusableDensity/0.5) +
+		15*capRatio(f.RepeatedInitials/0.4) +
+		5*capRatio(f.ToneChangeRatio) +
+		10*capRatio(f.ToneSandhiDensity/0.2)
+	score = math.Round(score*10) / 10
+
+	coverage := converted.Coverage()
+	unknown := converted.Unknown
+	if unknown == nil {
+		unknown = []string{}
+	}
+	return &DifficultyAnalysis{
+		Score:          score,
+		SuggestedLevel: LevelForScore(score),
+		Features:       f,
+		Coverage:       round3(coverage),
+		UnknownChars:   unknown,
+		LowConfidence:  coverage < lowCoverage || f.Syllables == 0,
+		Pinyin:         converted.Phrases,
+	}
+}
+
+// isSandhiSite 判断短语中第 i 个音节是否发生变调：上声后接上声，"一"后接任意声调，"不"后接去声
+func isSandhiSite(phrase []pinyin.Syllable, i int) bool {
+	if i+1 >= len(phrase) {
+		return false
+	}
+	cur, next := phrase[i], phrase[i+1]
+	switch {
+	case cur.Char == "一":
+		return next.Tone >= 1 && next.Tone <= 4
+	case cur.Char == "不":
+		return next.Tone == 4
+	default:
+		return cur.Tone == 3 && next.Tone == 3
+	}
+}
+
+// LevelForScore 分数对应的建议级别
+func LevelForScore(score float64) string {
+	switch {
+	case score < difficultyIntermediateAt:
+		return "basic"
+	case score < difficultyAdvancedAt:
+		return "intermediate"
+	default:
+		return "advanced"
+	}
+}
+
+func capRatio(v float64) float64 {
+	if v > 1 {
+		return 1
+	}
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// RegradeItem 一条绕口令的重新评级结果
+type RegradeItem struct {
+	ID             uuid.UUID `json:"id"`
+	Title          string    `json:"title"`
+	CurrentLevel   string    `json:"current_level"`
+	SuggestedLevel string    `json:"suggested_level"`
+	Score          float64   `json:"score"`
+	LowConfidence  bool      `json:"low_confidence"`
+	Changed        bool      `json:"changed"`
+	Skipped        bool      `json:"skipped"` // 覆盖率过低且未强制，不修改级别
+}
+
+// RegradeResult 批量重新评级结果
+type RegradeResult struct {
+	DryRun  bool          `json:"dry_run"`
+	Total   int           `json:"total"`
+	Changed int           `json:"changed"`
+	Skipped int           `json:"skipped"`
+	Items   []RegradeItem `json:"items"`
+}
+
+// DifficultyService 绕口令难度评级
+type DifficultyService struct {
+	db *gorm.DB
+}
+
+func NewDifficultyService(db *gorm.DB) *DifficultyService {
+	return &DifficultyService{db: db}
+}
+
+// Regrade 重新计算绕口令的难度分数与级别；ids 为空时处理全部。
+// 覆盖率过低的内容只更新分数，除非 includeLowConfidence 为 true
+func (s *DifficultyService) Regrade(ids []string, dryRun, includeLowConfidence bool) (*RegradeResult, error) {
+	query := s.db.Model(&models.TongueTwister{}).Select("id, title, content, level")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	var twisters []models.TongueTwister
+	if err := query.Order("created_at").Find(&twisters).Error; err != nil {
+		return nil, fmt.Errorf("查询绕口令失败: %w", err)
+	}
+
+	result := &RegradeResult{DryRun: dryRun, Total: len(twisters), Items: make([]RegradeItem, 0, len(twisters))}
+	for _, t := range twisters {
+		analysis := AnalyzeDifficulty(t.Content)
+		item := RegradeItem{
+			ID:             t.ID,
+			Title:          t.Title,
+			CurrentLevel:   t.Level,
+			SuggestedLevel: analysis.SuggestedLevel,
+			Score:          analysis.Score,
+			LowConfidence:  analysis.LowConfidence,
+		}
+		if analysis.LowConfidence && !includeLowConfidence {
+			item.Skipped = true
+			result.Skipped++
+		} else if t.Level != analysis.SuggestedLevel {
+			item.Changed = true
+			result.Changed++
+		}
+		result.Items = append(result.Items, item)
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range result.Items {
+			updates := map[string]interface{}{"difficulty_score": item.Score}
+			if item.Changed {
+				updates["level"] = item.SuggestedLevel
+			}
+			if err := tx.Model(&models.TongueTwister{}).Where("id = ?", item.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存评级失败: %w", err)
+	}
+	return result, nil
+}
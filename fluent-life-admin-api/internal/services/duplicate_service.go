@@ -0,0 +1,493 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/textsim"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 参与近似重复检测的内容类型
+const (
+	DuplicateKindTongueTwister   = "tongue_twister"
+	DuplicateKindDailyExpression = "daily_expression"
+	DuplicateKindPracticeText    = "speech_practice_text" // 语音技巧中的单条练习文本
+)
+
+// 审核结论
+const (
+	DuplicateDecisionKeep  = "keep"
+	DuplicateDecisionMerge = "merge"
+)
+
+const (
+	// DefaultDuplicateThreshold 默认相似度阈值（字符二元组 Jaccard）
+	DefaultDuplicateThreshold = 0.8
+	// duplicateShingleSize n-gram 长度，中文短文本用二元组效果较好
+	duplicateShingleSize = 2
+	// duplicateMinLength 规范化后少于该长度的文本不参与检测，避免短文本误判
+	duplicateMinLength = 4
+)
+
+// ErrDuplicateClusterChanged 簇成员已变化（内容被修改、删除或有新的近似内容），需要刷新后重新审核
+var ErrDuplicateClusterChanged = errors.New("重复簇已变化，请刷新后重试")
+
+// DuplicateItem 参与检测的一条文本
+type DuplicateItem struct {
+	Key       string    `json:"key"` // kind:id，练习文本的 id 为条目 ID
+	Kind      string    `json:"kind"`
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"` // 练习文本为所属语音技巧的名称
+	Text      string    `json:"text"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+
+	shingles map[string]struct{}
+}
+
+// DuplicateCluster 一组互相近似的内容
+type DuplicateCluster struct {
+	ID            string                  `json:"id"` // 成员指纹
+	Members       []*DuplicateItem        `json:"members"`
+	MaxSimilarity float64                 `json:"max_similarity"`
+	MinSimilarity float64                 `json:"min_similarity"` // 簇内相连成员间的最低相似度
+	SuggestedKeep string                  `json:"suggested_keep"` // 建议保留最早创建的启用内容
+	Review        *models.DuplicateReview `json:"review,omitempty"`
+}
+
+// DuplicateMatch 与某条文本近似的已有内容
+type DuplicateMatch struct {
+	Key        string  `json:"key"`
+	Kind       string  `json:"kind"`
+	Title      string  `json:"title"`
+	Text       string  `json:"text"`
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateIndex 内存中的相似度索引，按 n-gram 集合大小排序后用大小过滤减少比较
+type DuplicateIndex struct {
+	items []*DuplicateItem
+}
+
+// NewDuplicateIndex 创建索引，过短的文本会被忽略
+func NewDuplicateIndex(items []*DuplicateItem) *DuplicateIndex {
+	idx := &DuplicateIndex{}
+	for _, item := range items {
+		idx.Add(item)
+	}
+	return idx
+}
+
+// Add 加入一条文本，返回是否被收录
+func (idx *DuplicateIndex) Add(item *DuplicateItem) bool {
+	if item.shingles == nil {
+		normalized := textsim.Normalize(item.Text)
+		if len([]rune(normalized)) < duplicateMinLength {
+			return false
+		}
+		item.shingles = textsim.Shingles(normalized, duplicateShingleSize)
+	}
+	idx.items = append(idx.items, item)
+	return true
+}
+
+// Similar 返回与文本相似度不低于阈值的内容，按相似度从高到低排序
+func (idx *DuplicateIndex) Similar(text string, threshold float64) []DuplicateMatch {
+	normalized := textsim.Normalize(text)
+	if len([]rune(normalized)) < duplicateMinLength {
+		return nil
+	}
+	shingles := textsim.Shingles(normalized, duplicateShingleSize)
+
+	var matches []DuplicateMatch
+	for _, item := range idx.items {
+		if !textsim.SizeCompatible(len(shingles), len(item.shingles), threshold) {
+			continue
+		}
+		if sim := textsim.Jaccard(shingles, item.shingles); sim >= threshold {
+			matches = append(matches, DuplicateMatch{
+				Key:        item.Key,
+				Kind:       item.Kind,
+				Title:      item.Title,
+				Text:       item.Text,
+				Similarity: roundSimilarity(sim),
+			})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	return matches
+}
+
+// DuplicateService 练习内容近似重复检测与审核
+type DuplicateService struct {
+	db *gorm.DB
+}
+
+func NewDuplicateService(db *gorm.DB) *DuplicateService {
+	return &DuplicateService{db: db}
+}
+
+// ValidDuplicateThreshold 校验相似度阈值，0 表示使用默认值
+func ValidDuplicateThreshold(threshold float64) (float64, error) {
+	if threshold == 0 {
+		return DefaultDuplicateThreshold, nil
+	}
+	if threshold < 0.5 || threshold > 1 {
+		return 0, fmt.Errorf("相似度阈值应在 0.5 ~ 1 之间")
+	}
+	return threshold, nil
+}
+
+// LoadItems 读取全部绕口令、每日朗诵文案与语音技巧练习文本
+func (s *DuplicateService) LoadItems() ([]*DuplicateItem, error) {
+	var items []*DuplicateItem
+
+	var twisters []models.TongueTwister
+	if err := s.db.Select("id, title, content, is_active, created_at").Find(&twisters).Error; err != nil {
+		return nil, fmt.Errorf("查询绕口令失败: %w", err)
+	}
+	for _, t := range twisters {
+		items = append(items, &DuplicateItem{
+			Key: DuplicateKindTongueTwister + ":" + t.ID.String(), Kind: DuplicateKindTongueTwister,
+			ID: t.ID, Title: t.Title, Text: t.Content, IsActive: t.IsActive, CreatedAt: t.CreatedAt,
+		})
+	}
+
+	var expressions []models.DailyExpression
+	if err := s.db.Select("id, title, content, is_active, created_at").Find(&expressions).Error; err != nil {
+		return nil, fmt.Errorf("查询每日朗诵文案失败: %w", err)
+	}
+	for _, e := range expressions {
+		items = append(items, &DuplicateItem{
+			Key: DuplicateKindDailyExpression + ":" + e.ID.String(), Kind: DuplicateKindDailyExpression,
+			ID: e.ID, Title: e.Title, Text: e.Content, IsActive: e.IsActive, CreatedAt: e.CreatedAt,
+		})
+	}
+
+	var texts []struct {
+		models.SpeechPracticeText
+		Name            string
+		TechniqueActive bool
+	}
+	err := s.db.Table("speech_practice_texts AS p").
+		Select("p.id, p.content, p.is_active, p.created_at, t.name, t.is_active AS technique_active").
+		Joins("JOIN speech_techniques t ON t.id = p.technique_id").
+		Scan(&texts).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询语音技巧练习文本失败: %w", err)
+	}
+	for _, t := range texts {
+		items = append(items, &DuplicateItem{
+			Key: DuplicateKindPracticeText + ":" + t.ID.String(), Kind: DuplicateKindPracticeText,
+			ID: t.ID, Title: t.Name, Text: t.Content, IsActive: t.IsActive && t.TechniqueActive, CreatedAt: t.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// Clusters 计算近似重复簇；kind 非空时只返回包含该类型成员的簇
+func (s *DuplicateService) Clusters(threshold float64, kind string) ([]*DuplicateCluster, error) {
+	items, err := s.LoadItems()
+	if err != nil {
+		return nil, err
+	}
+	clusters := buildClusters(NewDuplicateIndex(items).items, threshold)
+
+	if kind != "" {
+		filtered := clusters[:0]
+		for _, cluster := range clusters {
+			for _, m := range cluster.Members {
+				if m.Kind == kind {
+					filtered = append(filtered, cluster)
+					break
+				}
+			}
+		}
+		clusters = filtered
+	}
+
+	if len(clusters) > 0 {
+		ids := make([]string, len(clusters))
+		for i, cluster := range clusters {
+			ids[i] = cluster.ID
+		}
+		var reviews []models.DuplicateReview
+		if err := s.db.Where("fingerprint IN ?", ids).Order("created_at").Find(&reviews).Error; err != nil {
+			return nil, fmt.Errorf("查询审核记录失败: %w", err)
+		}
+		latest := make(map[string]models.DuplicateReview, len(reviews))
+		for _, r := range reviews {
+			latest[r.Fingerprint] = r
+		}
+		for _, cluster := range clusters {
+			if r, ok := latest[cluster.ID]; ok {
+				review := r
+				cluster.Review = &review
+			}
+		}
+	}
+	return clusters, nil
+}
+
+// buildClusters 两两比较（按集合大小排序后提前终止），相似的内容用并查集合并成簇
+func buildClusters(items []*DuplicateItem, threshold float64) []*DuplicateCluster {
+	sort.Slice(items, func(i, j int) bool { return len(items[i].shingles) < len(items[j].shingles) })
+
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type edge struct {
+		a   int
+		sim float64
+	}
+	var edges []edge
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if !textsim.SizeCompatible(len(items[i].shingles), len(items[j].shingles), threshold) {
+				break
+			}
+			sim := textsim.Jaccard(items[i].shingles, items[j].shingles)
+			if sim < threshold {
+				continue
+			}
+			parent[find(i)] = find(j)
+			edges = append(edges, edge{a: i, sim: sim})
+		}
+	}
+
+	groups := make(map[int]*DuplicateCluster)
+	for i, item := range items {
+		root := find(i)
+		cluster, ok := groups[root]
+		if !ok {
+			cluster = &DuplicateCluster{MinSimilarity: 1}
+			groups[root] = cluster
+		}
+		cluster.Members = append(cluster.Members, item)
+	}
+	for _, e := range edges {
+		cluster := groups[find(e.a)]
+		if e.sim > cluster.MaxSimilarity {
+			cluster.MaxSimilarity = e.sim
+		}
+		if e.sim < cluster.MinSimilarity {
+			cluster.MinSimilarity = e.sim
+		}
+	}
+
+	var clusters []*DuplicateCluster
+	for _, cluster := range groups {
+		if len(cluster.Members) < 2 {
+			continue
+		}
+		sort.Slice(cluster.Members, func(i, j int) bool {
+			a, b := cluster.Members[i], cluster.Members[j]
+			if a.IsActive != b.IsActive {
+				return a.IsActive
+			}
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.Key < b.Key
+		})
+		cluster.SuggestedKeep = cluster.Members[0].Key
+		cluster.MaxSimilarity = roundSimilarity(cluster.MaxSimilarity)
+		cluster.MinSimilarity = roundSimilarity(cluster.MinSimilarity)
+		cluster.ID = clusterFingerprint(cluster.Members)
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].MaxSimilarity != clusters[j].MaxSimilarity {
+			return clusters[i].MaxSimilarity > clusters[j].MaxSimilarity
+		}
+		if len(clusters[i].Members) != len(clusters[j].Members) {
+			return len(clusters[i].Members) > len(clusters[j].Members)
+		}
+		return clusters[i].ID < clusters[j].ID
+	})
+	return clusters
+}
+
+// findCluster 重新计算后按指纹查找簇，找不到说明成员已变化
+func (s *DuplicateService) findCluster(id string, threshold float64) (*DuplicateCluster, error) {
+	clusters, err := s.Clusters(threshold, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, cluster := range clusters {
+		if cluster.ID == id {
+			return cluster, nil
+		}
+	}
+	return nil, ErrDuplicateClusterChanged
+}
+
+// Keep 确认簇内内容不是重复，全部保留；成员不变时该簇不再出现在待审核列表中
+func (s *DuplicateService) Keep(id string, threshold float64, note string, reviewedBy uuid.UUID) (*models.DuplicateReview, error) {
+	cluster, err := s.findCluster(id, threshold)
+	if err != nil {
+		return nil, err
+	}
+	review := &models.DuplicateReview{
+		Fingerprint: cluster.ID,
+		Decision:    DuplicateDecisionKeep,
+		Members:     memberKeysJSON(cluster.Members),
+		Removed:     "[]",
+		Threshold:   threshold,
+		Note:        note,
+		ReviewedBy:  reviewedBy,
+	}
+	if err := s.db.Create(review).Error; err != nil {
+		return nil, fmt.Errorf("保存审核记录失败: %w", err)
+	}
+	return review, nil
+}
+
+// Merge 保留 keepKey 对应的内容并删除 removeKeys；removeKeys 为空时删除与保留项同类型的其余成员。
+// 绕口令、每日朗诵文案与练习文本条目直接删除，练习文本所属技巧的 practice_texts 列随之重新生成
+func (s *DuplicateService) Merge(id string, threshold float64, keepKey string, removeKeys []string, note string, reviewedBy uuid.UUID) (*models.DuplicateReview, error) {
+	cluster, err := s.findCluster(id, threshold)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]*DuplicateItem, len(cluster.Members))
+	for _, m := range cluster.Members {
+		members[m.Key] = m
+	}
+	keep, ok := members[keepKey]
+	if !ok {
+		return nil, fmt.Errorf("保留项不在该簇中")
+	}
+
+	var remove []*DuplicateItem
+	if len(removeKeys) == 0 {
+		for _, m := range cluster.Members {
+			if m.Key != keep.Key && m.Kind == keep.Kind {
+				remove = append(remove, m)
+			}
+		}
+	} else {
+		picked := make(map[string]bool)
+		for _, key := range removeKeys {
+			m, ok := members[key]
+			if !ok {
+				return nil, fmt.Errorf("%s 不在该簇中", key)
+			}
+			if key == keep.Key {
+				return nil, fmt.Errorf("保留项不能同时被删除")
+			}
+			if !picked[key] {
+				picked[key] = true
+				remove = append(remove, m)
+			}
+		}
+	}
+	if len(remove) == 0 {
+		return nil, fmt.Errorf("没有需要删除的内容")
+	}
+
+	review := &models.DuplicateReview{
+		Fingerprint: cluster.ID,
+		Decision:    DuplicateDecisionMerge,
+		KeepKey:     keep.Key,
+		Members:     memberKeysJSON(cluster.Members),
+		Removed:     memberKeysJSON(remove),
+		Threshold:   threshold,
+		Note:        note,
+		ReviewedBy:  reviewedBy,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var textIDs []uuid.UUID
+		for _, m := range remove {
+			switch m.Kind {
+			case DuplicateKindTongueTwister:
+				if err := tx.Delete(&models.TongueTwister{}, "id = ?", m.ID).Error; err != nil {
+					return err
+				}
+				if err := discardRevisions(tx, VersionTongueTwister, m.ID); err != nil {
+					return err
+				}
+			case DuplicateKindDailyExpression:
+				if err := tx.Delete(&models.DailyExpression{}, "id = ?", m.ID).Error; err != nil {
+					return err
+				}
+				if err := discardRevisions(tx, VersionDailyExpression, m.ID); err != nil {
+					return err
+				}
+			case DuplicateKindPracticeText:
+				textIDs = append(textIDs, m.ID)
+			}
+		}
+		if len(textIDs) > 0 {
+			if err := removePracticeTexts(tx, textIDs); err != nil {
+				return err
+			}
+		}
+		return tx.Create(review).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("合并失败: %w", err)
+	}
+	return review, nil
+}
+
+// removePracticeTexts 删除练习文本条目并重新生成所属技巧的 practice_texts 列
+func removePracticeTexts(tx *gorm.DB, ids []uuid.UUID) error {
+	var techniqueIDs []uuid.UUID
+	if err := tx.Model(&models.SpeechPracticeText{}).Where("id IN ?", ids).Distinct().Pluck("technique_id", &techniqueIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&models.SpeechPracticeText{}).Error; err != nil {
+		return err
+	}
+	items := NewSpeechItemService(tx)
+	for _, techniqueID := range techniqueIDs {
+		if err := items.syncColumn(tx, techniqueID, SpeechItemPracticeTexts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clusterFingerprint 成员键排序后取 SHA-1 前 16 字节
+func clusterFingerprint(members []*DuplicateItem) string {
+	keys := make([]string, len(members))
+	for i, m := range members {
+		keys[i] = m.Key
+	}
+	sort.Strings(keys)
+	sum := sha1.Sum([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
+
+func memberKeysJSON(members []*DuplicateItem) string {
+	keys := make([]string, len(members))
+	for i, m := range members {
+		keys[i] = m.Key
+	}
+	data, _ := json.Marshal(keys)
+	return string(data)
+}
+
+func roundSimilarity(v float64) float64 {
+	return float64(int(v*1000+0.5)) / 1000
+}
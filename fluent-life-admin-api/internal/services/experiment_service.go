@@ -0,0 +1,706 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 实验目标类型
+const (
+	ExperimentTargetExposureStep = "exposure_step"
+	ExperimentTargetAIRole       = "ai_role"
+)
+
+// 实验状态
+const (
+	ExperimentStatusDraft   = "draft"
+	ExperimentStatusRunning = "running"
+	ExperimentStatusStopped = "stopped"
+)
+
+const (
+	defaultExperimentWindowHours = 168
+	maxExperimentWindowHours     = 720
+	maxExperimentVariants        = 5
+	// 95% 置信水平对应的正态分位数
+	experimentZ = 1.959964
+)
+
+var (
+	ErrExperimentNotFound = errors.New("实验不存在")
+	ErrExperimentInvalid  = errors.New("实验配置无效")
+	ErrExperimentState    = errors.New("实验状态不允许该操作")
+	ErrExperimentConflict = errors.New("该目标已有进行中的实验")
+
+	variantKeyPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+)
+
+// ExperimentOverrides 分组覆盖的内容，未设置的字段沿用原内容。
+// 脱敏步骤实验可覆盖 guide_content、popup_configs，AI 角色实验可覆盖 system_prompt
+type ExperimentOverrides struct {
+	GuideContent *string         `json:"guide_content,omitempty"`
+	PopupConfigs json.RawMessage `json:"popup_configs,omitempty"`
+	SystemPrompt *string         `json:"system_prompt,omitempty"`
+}
+
+func (o ExperimentOverrides) empty() bool {
+	return o.GuideContent == nil && len(o.PopupConfigs) == 0 && o.SystemPrompt == nil
+}
+
+// ExperimentVariant 实验分组；第一个分组为对照组
+type ExperimentVariant struct {
+	Key       string              `json:"key"`
+	Name      string              `json:"name"`
+	Weight    int                 `json:"weight"` // 分流权重，按各组权重占比分配用户
+	Overrides ExperimentOverrides `json:"overrides"`
+}
+
+// ExperimentInput 创建或修改实验的参数
+type ExperimentInput struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	TargetType  string              `json:"target_type"`
+	TargetID    string              `json:"target_id"`
+	Variants    []ExperimentVariant `json:"variants"`
+	WindowHours int                 `json:"window_hours"`
+}
+
+// ExperimentAssignment 用户在进行中实验里分到的组
+type ExperimentAssignment struct {
+	ExperimentID uuid.UUID           `json:"experiment_id"`
+	TargetType   string              `json:"target_type"`
+	TargetID     string              `json:"target_id"`
+	ModuleID     string              `json:"module_id,omitempty"`
+	Variant      string              `json:"variant"`
+	Overrides    ExperimentOverrides `json:"overrides"`
+}
+
+// ConfidenceInterval 置信区间
+type ConfidenceInterval struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// ExperimentVariantResult 分组的实验结果
+type ExperimentVariantResult struct {
+	Key            string             `json:"key"`
+	Name           string             `json:"name"`
+	Users          int64              `json:"users"`     // 曝光用户数
+	Converted      int64              `json:"converted"` // 窗口内完成目标的用户数
+	ConversionRate float64            `json:"conversion_rate"`
+	ConversionCI   ConfidenceInterval `json:"conversion_ci"` // Wilson 区间
+	// 相对对照组的转化率差值及其置信区间、双比例 z 检验的 p 值，对照组为空
+	Lift   *float64            `json:"lift"`
+	LiftCI *ConfidenceInterval `json:"lift_ci"`
+	PValue *float64            `json:"p_value"`
+	// 转化用户在窗口内的平均训练时长（秒）
+	MeanDuration float64            `json:"mean_duration"`
+	DurationCI   ConfidenceInterval `json:"duration_ci"`
+}
+
+// ExperimentResults 实验结果
+type ExperimentResults struct {
+	Experiment  *models.Experiment        `json:"experiment"`
+	Outcome     string                    `json:"outcome"` // 转化口径说明
+	Confidence  float64                   `json:"confidence"`
+	Control     string                    `json:"control"`
+	Variants    []ExperimentVariantResult `json:"variants"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+}
+
+// ExperimentService A/B 实验：按用户ID哈希稳定分组，记录曝光，并以训练记录计算转化
+type ExperimentService struct {
+	db *gorm.DB
+}
+
+func NewExperimentService(db *gorm.DB) *ExperimentService {
+	return &ExperimentService{db: db}
+}
+
+// ExperimentVariants 解析实验的分组配置
+func ExperimentVariants(e *models.Experiment) ([]ExperimentVariant, error) {
+	var variants []ExperimentVariant
+	if err := json.Unmarshal([]byte(e.Variants), &variants); err != nil {
+		return nil, fmt.Errorf("解析实验分组失败: %w", err)
+	}
+	return variants, nil
+}
+
+// AssignVariant 按实验ID与用户ID的哈希把用户稳定地分到某个组，同一用户在同一实验中始终得到相同结果
+func AssignVariant(experimentID, userID uuid.UUID, variants []ExperimentVariant) ExperimentVariant {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	sum := sha256.Sum256([]byte(experimentID.String() + ":" + userID.String()))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for _, v := range variants {
+		if bucket < v.Weight {
+			return v
+		}
+		bucket -= v.Weight
+	}
+	return variants[len(variants)-1]
+}
+
+// normalizeExperimentInput 校验并规范化实验参数
+func normalizeExperimentInput(in *ExperimentInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.TargetID = strings.TrimSpace(in.TargetID)
+	if in.Name == "" {
+		return fmt.Errorf("%w: name 不能为空", ErrExperimentInvalid)
+	}
+	if in.TargetType != ExperimentTargetExposureStep && in.TargetType != ExperimentTargetAIRole {
+		return fmt.Errorf("%w: target_type 只能是 %s 或 %s", ErrExperimentInvalid, ExperimentTargetExposureStep, ExperimentTargetAIRole)
+	}
+	if in.TargetID == "" {
+		return fmt.Errorf("%w: target_id 不能为空", ErrExperimentInvalid)
+	}
+	if in.WindowHours == 0 {
+		in.WindowHours = defaultExperimentWindowHours
+	}
+	if in.WindowHours < 1 || in.WindowHours > maxExperimentWindowHours {
+		return fmt.Errorf("%w: window_hours 应在 1-%d 之间", ErrExperimentInvalid, maxExperimentWindowHours)
+	}
+	if len(in.Variants) < 2 || len(in.Variants) > maxExperimentVariants {
+		return fmt.Errorf("%w: 分组数量应为 2-%d 个", ErrExperimentInvalid, maxExperimentVariants)
+	}
+
+	keys := make(map[string]bool)
+	hasOverrides := false
+	for i := range in.Variants {
+		v := &in.Variants[i]
+		path := fmt.Sprintf("variants[%d]", i)
+		if !variantKeyPattern.MatchString(v.Key) {
+			return fmt.Errorf("%w: %s.key 只能包含小写字母、数字、-、_，长度 1-32", ErrExperimentInvalid, path)
+		}
+		if keys[v.Key] {
+			return fmt.Errorf("%w: 分组 key %s 重复", ErrExperimentInvalid, v.Key)
+		}
+		keys[v.Key] = true
+		if strings.TrimSpace(v.Name) == "" {
+			v.Name = v.Key
+		}
+		if v.Weight == 0 {
+			v.Weight = 1
+		}
+		if v.Weight < 0 || v.Weight > 100 {
+			return fmt.Errorf("%w: %s.weight 应在 1-100 之间", ErrExperimentInvalid, path)
+		}
+
+		o := &v.Overrides
+		switch in.TargetType {
+		case ExperimentTargetExposureStep:
+			if o.SystemPrompt != nil {
+				return fmt.Errorf("%w: 脱敏步骤实验不能覆盖 system_prompt", ErrExperimentInvalid)
+			}
+			if o.GuideContent != nil && strings.TrimSpace(*o.GuideContent) == "" {
+				return fmt.Errorf("%w: %s.overrides.guide_content 不能为空", ErrExperimentInvalid, path)
+			}
+			if len(o.PopupConfigs) > 0 {
+				raw := string(o.PopupConfigs)
+				// 与步骤接口一致，弹窗配置既可以是 JSON 数组，也可以是 JSON 字符串
+				var s string
+				if json.Unmarshal(o.PopupConfigs, &s) == nil {
+					raw = s
+				}
+				normalized, errs := ValidatePopupConfigs(raw, path+".overrides.popup_configs")
+				if len(errs) > 0 {
+					return fmt.Errorf("%w: %s", ErrExperimentInvalid, errs[0].Error())
+				}
+				o.PopupConfigs = json.RawMessage(normalized)
+			}
+		case ExperimentTargetAIRole:
+			if o.GuideContent != nil || len(o.PopupConfigs) > 0 {
+				return fmt.Errorf("%w: AI 角色实验只能覆盖 system_prompt", ErrExperimentInvalid)
+			}
+			if o.SystemPrompt != nil && strings.TrimSpace(*o.SystemPrompt) == "" {
+				return fmt.Errorf("%w: %s.overrides.system_prompt 不能为空", ErrExperimentInvalid, path)
+			}
+		}
+		if !o.empty() {
+			hasOverrides = true
+		}
+	}
+	if !hasOverrides {
+		return fmt.Errorf("%w: 至少一个分组需要覆盖内容", ErrExperimentInvalid)
+	}
+	return nil
+}
+
+// resolveTarget 检查实验目标是否存在，脱敏步骤实验同时记录步骤所属模块与顺序
+func resolveTarget(tx *gorm.DB, e *models.Experiment) error {
+	switch e.TargetType {
+	case ExperimentTargetExposureStep:
+		stepID, err := uuid.Parse(e.TargetID)
+		if err != nil {
+			return fmt.Errorf("%w: 无效的步骤ID", ErrExperimentInvalid)
+		}
+		var step models.ExposureStep
+		if err := tx.Select("id, module_id, step_order").First(&step, "id = ?", stepID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("%w: 步骤 %s 不存在", ErrExperimentInvalid, e.TargetID)
+			}
+			return err
+		}
+		e.ModuleID = step.ModuleID
+		e.StepOrder = step.StepOrder
+	case ExperimentTargetAIRole:
+		var setting models.AppSetting
+		err := tx.Where("key = ?", "ai_simulation_roles").First(&setting).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		var roles []struct {
+			ID string `json:"id"`
+		}
+		if err == nil {
+			if err := json.Unmarshal([]byte(setting.Value), &roles); err != nil {
+				return fmt.Errorf("解析AI角色配置失败: %w", err)
+			}
+		}
+		for _, r := range roles {
+			if r.ID == e.TargetID {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: AI 角色 %s 不存在", ErrExperimentInvalid, e.TargetID)
+	}
+	return nil
+}
+
+func (s *ExperimentService) apply(tx *gorm.DB, e *models.Experiment, in ExperimentInput) error {
+	if err := normalizeExperimentInput(&in); err != nil {
+		return err
+	}
+	variants, err := json.Marshal(in.Variants)
+	if err != nil {
+		return err
+	}
+	e.Name = in.Name
+	e.Description = in.Description
+	e.TargetType = in.TargetType
+	e.TargetID = in.TargetID
+	e.Variants = string(variants)
+	e.WindowHours = in.WindowHours
+	return resolveTarget(tx, e)
+}
+
+// List 按状态、目标类型筛选实验
+func (s *ExperimentService) List(status, targetType string, page, pageSize int) ([]models.Experiment, int64, error) {
+	query := s.db.Model(&models.Experiment{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	experiments := make([]models.Experiment, 0)
+	err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&experiments).Error
+	return experiments, total, err
+}
+
+// Get 获取实验
+func (s *ExperimentService) Get(id uuid.UUID) (*models.Experiment, error) {
+	var e models.Experiment
+	if err := s.db.First(&e, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrExperimentNotFound
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Create 创建草稿状态的实验
+func (s *ExperimentService) Create(in ExperimentInput, actor *uuid.UUID) (*models.Experiment, error) {
+	e := &models.Experiment{Status: ExperimentStatusDraft, CreatedBy: actor}
+	if err := s.apply(s.db, e, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(e).Error; err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// lockExperiment 锁定实验行
+func lockExperiment(tx *gorm.DB, id uuid.UUID) (*models.Experiment, error) {
+	var e models.Experiment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&e, "id = ?", id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrExperimentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Update 修改实验，只有草稿状态的实验可以修改
+func (s *ExperimentService) Update(id uuid.UUID, in ExperimentInput) (*models.Experiment, error) {
+	var e *models.Experiment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if e, err = lockExperiment(tx, id); err != nil {
+			return err
+		}
+		if e.Status != ExperimentStatusDraft {
+			return fmt.Errorf("%w: 只能修改未开始的实验", ErrExperimentState)
+		}
+		if err := s.apply(tx, e, in); err != nil {
+			return err
+		}
+		return tx.Save(e).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Delete 删除实验及其曝光记录，进行中的实验需先停止
+func (s *ExperimentService) Delete(id uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		e, err := lockExperiment(tx, id)
+		if err != nil {
+			return err
+		}
+		if e.Status == ExperimentStatusRunning {
+			return fmt.Errorf("%w: 进行中的实验需先停止", ErrExperimentState)
+		}
+		if err := tx.Where("experiment_id = ?", id).Delete(&models.ExperimentExposure{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(e).Error
+	})
+}
+
+// Start 开始实验；同一目标同时只能有一个进行中的实验
+func (s *ExperimentService) Start(id uuid.UUID) (*models.Experiment, error) {
+	var e *models.Experiment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if e, err = lockExperiment(tx, id); err != nil {
+			return err
+		}
+		if e.Status != ExperimentStatusDraft {
+			return fmt.Errorf("%w: 只能开始未开始的实验，已停止的实验请新建", ErrExperimentState)
+		}
+		// 目标可能在创建实验后被删除，开始时重新确认并更新步骤所属模块与顺序
+		if err := resolveTarget(tx, e); err != nil {
+			return err
+		}
+		var running int64
+		err = tx.Model(&models.Experiment{}).
+			Where("target_type = ? AND target_id = ? AND status = ? AND id <> ?", e.TargetType, e.TargetID, ExperimentStatusRunning, e.ID).
+			Count(&running).Error
+		if err != nil {
+			return err
+		}
+		if running > 0 {
+			return ErrExperimentConflict
+		}
+		now := time.Now()
+		e.Status = ExperimentStatusRunning
+		e.StartedAt = &now
+		return tx.Save(e).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Stop 停止实验，停止后不再分组和记录曝光，结果仍可查询
+func (s *ExperimentService) Stop(id uuid.UUID) (*models.Experiment, error) {
+	var e *models.Experiment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if e, err = lockExperiment(tx, id); err != nil {
+			return err
+		}
+		if e.Status != ExperimentStatusRunning {
+			return fmt.Errorf("%w: 实验未在进行中", ErrExperimentState)
+		}
+		now := time.Now()
+		e.Status = ExperimentStatusStopped
+		e.StoppedAt = &now
+		return tx.Save(e).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *ExperimentService) running() ([]models.Experiment, error) {
+	var experiments []models.Experiment
+	err := s.db.Where("status = ?", ExperimentStatusRunning).Order("started_at ASC").Find(&experiments).Error
+	return experiments, err
+}
+
+// experimentAppliesTo 实验是否作用于该语言的用户：步骤实验的文案只有原文语言，其它语言的用户看不到，不参与分组与曝光
+func experimentAppliesTo(e *models.Experiment, locale string) bool {
+	return e.TargetType != ExperimentTargetExposureStep || locale == DefaultLocale
+}
+
+// Assignments 用户在 locale 语言下参与的进行中实验的分组（只计算分组，不记录曝光）
+func (s *ExperimentService) Assignments(userID uuid.UUID, locale string) ([]ExperimentAssignment, error) {
+	experiments, err := s.running()
+	if err != nil {
+		return nil, err
+	}
+	assignments := make([]ExperimentAssignment, 0, len(experiments))
+	for i := range experiments {
+		e := &experiments[i]
+		if !experimentAppliesTo(e, locale) {
+			continue
+		}
+		variants, err := ExperimentVariants(e)
+		if err != nil {
+			return nil, err
+		}
+		v := AssignVariant(e.ID, userID, variants)
+		assignments = append(assignments, ExperimentAssignment{
+			ExperimentID: e.ID,
+			TargetType:   e.TargetType,
+			TargetID:     e.TargetID,
+			ModuleID:     e.ModuleID,
+			Variant:      v.Key,
+			Overrides:    v.Overrides,
+		})
+	}
+	return assignments, nil
+}
+
+// ApplyToModules 把用户所在分组的文案覆盖到线上模块（原文语言）的对应步骤上
+func (s *ExperimentService) ApplyToModules(modules []models.ExposureModule, userID uuid.UUID) error {
+	assignments, err := s.Assignments(userID, DefaultLocale)
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		if a.TargetType != ExperimentTargetExposureStep {
+			continue
+		}
+		for i := range modules {
+			for j := range modules[i].Steps {
+				step := &modules[i].Steps[j]
+				if step.ID.String() != a.TargetID {
+					continue
+				}
+				if a.Overrides.GuideContent != nil {
+					step.GuideContent = *a.Overrides.GuideContent
+				}
+				if len(a.Overrides.PopupConfigs) > 0 {
+					step.PopupConfigs = string(a.Overrides.PopupConfigs)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RecordExposure 记录用户看到了实验内容；首次曝光时间用于计算转化窗口，重复曝光只累加次数。
+// 用户所用语言不参与该实验时拒绝记录，避免把没看到实验文案的用户计入分组
+func (s *ExperimentService) RecordExposure(id, userID uuid.UUID, locale string) (*models.ExperimentExposure, error) {
+	e, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != ExperimentStatusRunning {
+		return nil, fmt.Errorf("%w: 实验未在进行中", ErrExperimentState)
+	}
+	if !experimentAppliesTo(e, locale) {
+		return nil, fmt.Errorf("%w: 步骤实验只对 %s 用户生效", ErrExperimentState, DefaultLocale)
+	}
+	variants, err := ExperimentVariants(e)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	exposure := &models.ExperimentExposure{
+		ExperimentID:   e.ID,
+		UserID:         userID,
+		Variant:        AssignVariant(e.ID, userID, variants).Key,
+		ExposureCount:  1,
+		FirstExposedAt: now,
+		LastExposedAt:  now,
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "experiment_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"exposure_count":  gorm.Expr("experiment_exposures.exposure_count + 1"),
+			"last_exposed_at": now,
+		}),
+	}).Create(exposure).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.First(exposure, "experiment_id = ? AND user_id = ?", e.ID, userID).Error; err != nil {
+		return nil, err
+	}
+	return exposure, nil
+}
+
+// outcomeCondition 转化口径：曝光后窗口内的训练记录满足的条件
+func outcomeCondition(e *models.Experiment) (string, string, []interface{}) {
+	if e.TargetType == ExperimentTargetAIRole {
+		return "使用该 AI 角色完成训练（训练记录 data.ai_role_id 为该角色）",
+			"(t.data->>'ai_role_id' = ?)",
+			[]interface{}{e.TargetID}
+	}
+	// 到达后续步骤视为已经完成该步骤，与漏斗统计一致
+	return "完成该步骤或之后的步骤（exposure 训练记录的 step_id 为该步骤或 step_order 不小于该步骤）",
+		`(t.type = 'exposure' AND t.data->>'module_id' = ? AND (t.data->>'step_id' = ? OR
+			CASE WHEN t.data->>'step_order' ~ '^[0-9]+$' THEN (t.data->>'step_order')::int END >= ?))`,
+		[]interface{}{e.ModuleID, e.TargetID, e.StepOrder}
+}
+
+type experimentVariantRow struct {
+	Variant      string
+	Users        int64
+	Converted    int64
+	MeanDuration *float64
+	SDDuration   *float64
+}
+
+// Results 计算各组的转化率及置信区间；转化以首次曝光后 window_hours 内的训练记录计算，
+// 窗口尚未结束的用户同样计入
+func (s *ExperimentService) Results(id uuid.UUID) (*ExperimentResults, error) {
+	e, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := ExperimentVariants(e)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome, condition, args := outcomeCondition(e)
+	query := `
+		SELECT e.variant, COUNT(*) AS users, COUNT(o.user_id) AS converted,
+			AVG(o.duration) AS mean_duration, STDDEV_SAMP(o.duration) AS sd_duration
+		FROM experiment_exposures e
+		LEFT JOIN LATERAL (
+			SELECT t.user_id, SUM(t.duration) AS duration
+			FROM training_records t
+			WHERE t.user_id = e.user_id
+				AND t.timestamp >= e.first_exposed_at
+				AND t.timestamp < e.first_exposed_at + make_interval(hours => ?)
+				AND ` + condition + `
+			GROUP BY t.user_id
+		) o ON TRUE
+		WHERE e.experiment_id = ?
+		GROUP BY e.variant`
+	params := append([]interface{}{e.WindowHours}, args...)
+	params = append(params, e.ID)
+	var rows []experimentVariantRow
+	if err := s.db.Raw(query, params...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("统计实验结果失败: %w", err)
+	}
+	byVariant := make(map[string]experimentVariantRow, len(rows))
+	for _, row := range rows {
+		byVariant[row.Variant] = row
+	}
+
+	results := &ExperimentResults{
+		Experiment:  e,
+		Outcome:     outcome,
+		Confidence:  0.95,
+		Control:     variants[0].Key,
+		Variants:    make([]ExperimentVariantResult, 0, len(variants)),
+		GeneratedAt: time.Now(),
+	}
+	control := byVariant[variants[0].Key]
+	for i, v := range variants {
+		row := byVariant[v.Key]
+		r := ExperimentVariantResult{
+			Key:       v.Key,
+			Name:      v.Name,
+			Users:     row.Users,
+			Converted: row.Converted,
+		}
+		if row.Users > 0 {
+			r.ConversionRate = roundStat(float64(row.Converted) / float64(row.Users))
+		}
+		r.ConversionCI = wilsonInterval(row.Converted, row.Users)
+		if row.MeanDuration != nil {
+			r.MeanDuration = roundStat(*row.MeanDuration)
+			r.DurationCI = ConfidenceInterval{Lower: r.MeanDuration, Upper: r.MeanDuration}
+			if row.SDDuration != nil && row.Converted > 1 {
+				half := experimentZ * *row.SDDuration / math.Sqrt(float64(row.Converted))
+				r.DurationCI = ConfidenceInterval{
+					Lower: roundStat(math.Max(0, *row.MeanDuration-half)),
+					Upper: roundStat(*row.MeanDuration + half),
+				}
+			}
+		}
+		if i > 0 && control.Users > 0 && row.Users > 0 {
+			r.Lift, r.LiftCI, r.PValue = compareProportions(control.Converted, control.Users, row.Converted, row.Users)
+		}
+		results.Variants = append(results.Variants, r)
+	}
+	return results, nil
+}
+
+// wilsonInterval 比例的 Wilson 置信区间，样本量小或比例接近 0、1 时比正态近似可靠
+func wilsonInterval(x, n int64) ConfidenceInterval {
+	if n == 0 {
+		return ConfidenceInterval{}
+	}
+	p := float64(x) / float64(n)
+	nf := float64(n)
+	z2 := experimentZ * experimentZ
+	denom := 1 + z2/nf
+	center := (p + z2/(2*nf)) / denom
+	half := experimentZ * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf)) / denom
+	return ConfidenceInterval{
+		Lower: roundStat(math.Max(0, center-half)),
+		Upper: roundStat(math.Min(1, center+half)),
+	}
+}
+
+// compareProportions 实验组相对对照组的转化率差值、差值的置信区间及双比例 z 检验的双侧 p 值
+func compareProportions(x0, n0, x1, n1 int64) (*float64, *ConfidenceInterval, *float64) {
+	p0 := float64(x0) / float64(n0)
+	p1 := float64(x1) / float64(n1)
+	diff := p1 - p0
+	se := math.Sqrt(p0*(1-p0)/float64(n0) + p1*(1-p1)/float64(n1))
+	ci := &ConfidenceInterval{
+		Lower: roundStat(diff - experimentZ*se),
+		Upper: roundStat(diff + experimentZ*se),
+	}
+
+	pooled := float64(x0+x1) / float64(n0+n1)
+	pooledSE := math.Sqrt(pooled * (1 - pooled) * (1/float64(n0) + 1/float64(n1)))
+	pValue := 1.0
+	if pooledSE > 0 {
+		pValue = math.Erfc(math.Abs(diff/pooledSE) / math.Sqrt2)
+	}
+	lift := roundStat(diff)
+	pValue = roundStat(pValue)
+	return &lift, ci, &pValue
+}
+
+func roundStat(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		x, n int64
+		want ConfidenceInterval
+	}{
+		{"no samples", 0, 0, ConfidenceInterval{}},
+		{"none converted", 0, 10, ConfidenceInterval{Lower: 0, Upper: 0.2775}},
+		{"all converted", 10, 10, ConfidenceInterval{Lower: 0.7225, Upper: 1}},
+		{"single sample", 0, 1, ConfidenceInterval{Lower: 0, Upper: 0.7935}},
+		{"half", 50, 100, ConfidenceInterval{Lower: 0.4038, Upper: 0.5962}},
+		{"small proportion", 1, 20, ConfidenceInterval{Lower: 0.0089, Upper: 0.2361}},
+		{"large sample", 300, 1000, ConfidenceInterval{Lower: 0.2724, Upper: 0.3291}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wilsonInterval(tt.x, tt.n)
+			if got != tt.want {
+				t.Errorf("wilsonInterval(%d, %d) = %+v, want %+v", tt.x, tt.n, got, tt.want)
+			}
+			if tt.n > 0 {
+				p := float64(tt.x) / float64(tt.n)
+				if got.Lower > p || got.Upper < p || got.Lower < 0 || got.Upper > 1 {
+					t.Errorf("interval %+v should contain %v and stay within [0, 1]", got, p)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareProportions(t *testing.T) {
+	tests := []struct {
+		name           string
+		x0, n0, x1, n1 int64
+		lift           float64
+		ci             ConfidenceInterval
+		pValue         float64
+	}{
+		{"significant lift", 100, 1000, 130, 1000, 0.03, ConfidenceInterval{Lower: 0.0021, Upper: 0.0579}, 0.0355},
+		{"symmetric drop", 130, 1000, 100, 1000, -0.03, ConfidenceInterval{Lower: -0.0579, Upper: -0.0021}, 0.0355},
+		{"highly significant", 10, 200, 30, 200, 0.1, ConfidenceInterval{Lower: 0.042, Upper: 0.158}, 0.0009},
+		{"small samples", 1, 10, 2, 10, 0.1, ConfidenceInterval{Lower: -0.2099, Upper: 0.4099}, 0.5312},
+		{"identical", 50, 100, 50, 100, 0, ConfidenceInterval{Lower: -0.1386, Upper: 0.1386}, 1},
+		// 两组都没有转化或全部转化时合并标准误为 0，p 值按 1 处理而不是 NaN
+		{"none converted", 0, 50, 0, 50, 0, ConfidenceInterval{}, 1},
+		{"all converted", 20, 20, 20, 20, 0, ConfidenceInterval{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lift, ci, pValue := compareProportions(tt.x0, tt.n0, tt.x1, tt.n1)
+			if math.IsNaN(*lift) || math.IsNaN(*pValue) || math.IsNaN(ci.Lower) || math.IsNaN(ci.Upper) {
+				t.Fatalf("got NaN: lift=%v ci=%+v p=%v", *lift, *ci, *pValue)
+			}
+			if *lift != tt.lift {
+				t.Errorf("lift = %v, want %v", *lift, tt.lift)
+			}
+			if *ci != tt.ci {
+				t.Errorf("ci = %+v, want %+v", *ci, tt.ci)
+			}
+			if *pValue != tt.pValue {
+				t.Errorf("p = %v, want %v", *pValue, tt.pValue)
+			}
+		})
+	}
+}
+
+func TestAssignVariant(t *testing.T) {
+	variants := []ExperimentVariant{{Key: "control", Weight: 1}, {Key: "a", Weight: 3}}
+	experimentID := uuid.MustParse("6f1c2f1e-3d0b-4e55-9a51-2b8a7c1f0e01")
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		userID := uuid.NewSHA1(uuid.NameSpaceOID, []byte{byte(i), byte(i >> 8)})
+		v := AssignVariant(experimentID, userID, variants)
+		if again := AssignVariant(experimentID, userID, variants); again.Key != v.Key {
+			t.Fatalf("user %s assigned %s then %s", userID, v.Key, again.Key)
+		}
+		counts[v.Key]++
+	}
+	// 权重 1:3，允许 ±5% 的偏差
+	if share := float64(counts["a"]) / 4000; share < 0.70 || share > 0.80 {
+		t.Errorf("variant a share = %.3f, want about 0.75 (counts %v)", share, counts)
+	}
+
+	single := []ExperimentVariant{{Key: "only", Weight: 5}}
+	if got := AssignVariant(experimentID, uuid.New(), single); got.Key != "only" {
+		t.Errorf("single variant assigned %q", got.Key)
+	}
+}
+
+func TestExperimentAppliesTo(t *testing.T) {
+	tests := []struct {
+		target, locale string
+		want           bool
+	}{
+		{ExperimentTargetExposureStep, DefaultLocale, true},
+		{ExperimentTargetExposureStep, "en-US", false},
+		{ExperimentTargetAIRole, DefaultLocale, true},
+		{ExperimentTargetAIRole, "en-US", true},
+	}
+	for _, tt := range tests {
+		if got := experimentAppliesTo(&models.Experiment{TargetType: tt.target}, tt.locale); got != tt.want {
+			t.Errorf("experimentAppliesTo(%s, %s) = %v, want %v", tt.target, tt.locale, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,733 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 脱敏模块包格式
+const (
+	ExposureBundleFormat  = "fluent-life.exposure-bundle"
+	ExposureBundleVersion = 1
+)
+
+// 导入时模块ID冲突的处理方式
+const (
+	BundleConflictSkip      = "skip"      // 跳过已存在的模块
+	BundleConflictOverwrite = "overwrite" // 覆盖模块字段与步骤，按步骤ID或顺序沿用原有步骤
+	BundleConflictRename    = "rename"    // 以新ID导入（<id>-copy、<id>-copy-2…）
+)
+
+// 模块处理结果
+const (
+	BundleActionCreate    = "create"
+	BundleActionOverwrite = "overwrite"
+	BundleActionRename    = "rename"
+	BundleActionSkip      = "skip"
+	BundleActionInvalid   = "invalid"
+)
+
+// maxExposureModuleIDLength 模块ID长度上限（与译文表 content_id 一致）
+const maxExposureModuleIDLength = 64
+
+var (
+	ErrBundleInvalid          = errors.New("脱敏模块包无效")
+	ErrExposureModuleExists   = errors.New("模块ID已存在")
+	ErrExposureModuleNotFound = errors.New("模块不存在")
+)
+
+// ExposureBundle 可在环境间迁移的脱敏模块包
+type ExposureBundle struct {
+	Format     string         `json:"format"`
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Modules    []BundleModule `json:"modules"`
+}
+
+// BundleModule 包中的模块
+type BundleModule struct {
+	ID           string       `json:"id"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Icon         string       `json:"icon"`
+	Color        string       `json:"color"`
+	DisplayOrder int          `json:"display_order"`
+	IsActive     bool         `json:"is_active"`
+	Steps        []BundleStep `json:"steps"`
+}
+
+// BundleStep 包中的步骤；覆盖导入时 ID 用于匹配原有步骤，新建或改名导入时重新生成
+type BundleStep struct {
+	ID                  string          `json:"id,omitempty"`
+	StepOrder           int             `json:"step_order"`
+	StepType            string          `json:"step_type"`
+	Title               string          `json:"title"`
+	Description         string          `json:"description"`
+	GuideContent        string          `json:"guide_content"`
+	ScenarioListTitle   string          `json:"scenario_list_title"`
+	ScenarioListContent string          `json:"scenario_list_content"`
+	PopupConfigs        json.RawMessage `json:"popup_configs"`
+	Config              json.RawMessage `json:"config,omitempty"`
+	Icon                string          `json:"icon"`
+}
+
+// model 转为步骤模型（不含ID与模块ID）
+func (s *BundleStep) model() models.ExposureStep {
+	return models.ExposureStep{
+		StepOrder:           s.StepOrder,
+		StepType:            s.StepType,
+		Title:               s.Title,
+		Description:         s.Description,
+		GuideContent:        s.GuideContent,
+		ScenarioListTitle:   s.ScenarioListTitle,
+		ScenarioListContent: s.ScenarioListContent,
+		PopupConfigs:        string(s.PopupConfigs),
+		Config:              string(s.Config),
+		Icon:                s.Icon,
+	}
+}
+
+// BundleModuleResult 单个模块的导入结果
+type BundleModuleResult struct {
+	Index    int                `json:"index"` // 模块在包中的下标 + 1
+	SourceID string             `json:"source_id"`
+	ID       string             `json:"id,omitempty"` // 写入后的模块ID
+	Action   string             `json:"action"`
+	Steps    int                `json:"steps"`
+	StepIDs  map[string]string  `json:"step_ids,omitempty"` // 包中步骤ID → 写入后的步骤ID
+	Errors   []ImportFieldError `json:"errors,omitempty"`
+}
+
+// BundleImportReport 导入报告
+type BundleImportReport struct {
+	Conflict    string                `json:"conflict"`
+	DryRun      bool                  `json:"dry_run"`
+	Created     int                   `json:"created"`
+	Overwritten int                   `json:"overwritten"`
+	Renamed     int                   `json:"renamed"`
+	Skipped     int                   `json:"skipped"`
+	Invalid     int                   `json:"invalid"`
+	Modules     []*BundleModuleResult `json:"modules"`
+}
+
+// ExposureBundleService 脱敏模块导入导出与复制
+type ExposureBundleService struct {
+	db *gorm.DB
+}
+
+func NewExposureBundleService(db *gorm.DB) *ExposureBundleService {
+	return &ExposureBundleService{db: db}
+}
+
+// Export 导出指定模块（ids 为空时导出全部）及其步骤
+func (s *ExposureBundleService) Export(ids []string) (*ExposureBundle, error) {
+	query := s.db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("step_order ASC, created_at ASC")
+	}).Order("display_order ASC, created_at ASC")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	var modules []models.ExposureModule
+	if err := query.Find(&modules).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		found := make(map[string]bool, len(modules))
+		for _, m := range modules {
+			found[m.ID] = true
+		}
+		var missing []string
+		for _, id := range ids {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrExposureModuleNotFound, strings.Join(missing, ", "))
+		}
+	}
+
+	bundle := &ExposureBundle{
+		Format:     ExposureBundleFormat,
+		Version:    ExposureBundleVersion,
+		ExportedAt: time.Now(),
+		Modules:    make([]BundleModule, len(modules)),
+	}
+	for i, m := range modules {
+		bm, err := bundleModuleFrom(&m)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Modules[i] = bm
+	}
+	return bundle, nil
+}
+
+// stepJSON 把步骤中保存的 JSON 文本转为 RawMessage；不是有效 JSON 时（历史数据）改为 JSON 字符串，
+// 保证包可以序列化，并由步骤校验指出该字段的问题
+func stepJSON(raw string) (json.RawMessage, bool) {
+	if json.Valid([]byte(raw)) {
+		return json.RawMessage(raw), true
+	}
+	quoted, _ := json.Marshal(raw)
+	return quoted, false
+}
+
+// bundleModuleFrom 把模块及已加载的步骤转为包中的模块；
+// 步骤的 popup_configs 或 config 不是有效 JSON 时返回指明步骤的错误，同时返回按字符串保存这些字段的模块
+func bundleModuleFrom(m *models.ExposureModule) (BundleModule, error) {
+	bm := BundleModule{
+		ID:           m.ID,
+		Title:        m.Title,
+		Description:  m.Description,
+		Icon:         m.Icon,
+		Color:        m.Color,
+		DisplayOrder: m.DisplayOrder,
+		IsActive:     m.IsActive,
+		Steps:        make([]BundleStep, len(m.Steps)),
+	}
+	var invalid []string
+	for j, step := range m.Steps {
+		popup := json.RawMessage("[]")
+		if strings.TrimSpace(step.PopupConfigs) != "" {
+			var ok bool
+			if popup, ok = stepJSON(step.PopupConfigs); !ok {
+				invalid = append(invalid, fmt.Sprintf("第 %d 步「%s」的 popup_configs", j+1, step.Title))
+			}
+		}
+		var config json.RawMessage
+		if strings.TrimSpace(step.Config) != "" {
+			var ok bool
+			if config, ok = stepJSON(step.Config); !ok {
+				invalid = append(invalid, fmt.Sprintf("第 %d 步「%s」的 config", j+1, step.Title))
+			}
+		}
+		bm.Steps[j] = BundleStep{
+			ID:                  step.ID.String(),
+			StepOrder:           step.StepOrder,
+			StepType:            step.StepType,
+			Title:               step.Title,
+			Description:         step.Description,
+			GuideContent:        step.GuideContent,
+			ScenarioListTitle:   step.ScenarioListTitle,
+			ScenarioListContent: step.ScenarioListContent,
+			PopupConfigs:        popup,
+			Config:              config,
+			Icon:                step.Icon,
+		}
+	}
+	if len(invalid) > 0 {
+		return bm, fmt.Errorf("%w: 模块 %s 中%s不是有效的 JSON", ErrBundleInvalid, m.ID, strings.Join(invalid, "、"))
+	}
+	return bm, nil
+}
+
+// model 转为模块模型，步骤保留包中的ID
+func (m *BundleModule) model() models.ExposureModule {
+	module := models.ExposureModule{
+		ID:           m.ID,
+		Title:        m.Title,
+		Description:  m.Description,
+		Icon:         m.Icon,
+		Color:        m.Color,
+		DisplayOrder: m.DisplayOrder,
+		IsActive:     m.IsActive,
+		Steps:        make([]models.ExposureStep, len(m.Steps)),
+	}
+	for i := range m.Steps {
+		step := m.Steps[i].model()
+		step.ID, _ = uuid.Parse(m.Steps[i].ID)
+		step.ModuleID = m.ID
+		module.Steps[i] = step
+	}
+	return module
+}
+
+// ParseExposureBundle 解析并检查包格式与版本
+func ParseExposureBundle(data []byte) (*ExposureBundle, error) {
+	var bundle ExposureBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("%w: JSON 格式错误: %v", ErrBundleInvalid, err)
+	}
+	if bundle.Format != ExposureBundleFormat {
+		return nil, fmt.Errorf("%w: format 应为 %s", ErrBundleInvalid, ExposureBundleFormat)
+	}
+	if bundle.Version < 1 || bundle.Version > ExposureBundleVersion {
+		return nil, fmt.Errorf("%w: 不支持的版本 %d（当前支持 %d）", ErrBundleInvalid, bundle.Version, ExposureBundleVersion)
+	}
+	if len(bundle.Modules) == 0 {
+		return nil, fmt.Errorf("%w: 没有模块", ErrBundleInvalid)
+	}
+	return &bundle, nil
+}
+
+// validateExposureModuleID 校验模块ID
+func validateExposureModuleID(id string) string {
+	switch {
+	case id == "":
+		return "不能为空"
+	case utf8.RuneCountInString(id) > maxExposureModuleIDLength:
+		return fmt.Sprintf("不能超过 %d 个字符", maxExposureModuleIDLength)
+	case strings.ContainsAny(id, " \t\r\n/?#"):
+		return "不能包含空白或 / ? #"
+	}
+	return ""
+}
+
+// validateBundleModule 校验模块与步骤字段，错误路径形如 steps[0].popup_configs
+func validateBundleModule(m *BundleModule) []ImportFieldError {
+	var errs []ImportFieldError
+	add := func(field, message string) {
+		errs = append(errs, ImportFieldError{Field: field, Message: message})
+	}
+	if msg := validateExposureModuleID(m.ID); msg != "" {
+		add("id", msg)
+	}
+	for field, value := range map[string]string{"title": m.Title, "description": m.Description, "icon": m.Icon, "color": m.Color} {
+		if strings.TrimSpace(value) == "" {
+			add(field, "不能为空")
+		}
+	}
+	for i := range m.Steps {
+		path := fmt.Sprintf("steps[%d].", i)
+		if id := m.Steps[i].ID; id != "" {
+			if _, err := uuid.Parse(id); err != nil {
+				add(path+"id", "不是有效的 UUID")
+			}
+		}
+		step := m.Steps[i].model()
+		for _, e := range ValidateExposureStep(&step) {
+			add(path+e.Path, e.Message)
+		}
+	}
+	// 按字段路径排序，使校验结果稳定
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// Import 导入包中的模块：校验失败的模块不写入，其余模块在同一事务中写入。
+// 覆盖时沿用原有步骤的ID（实验与译文按步骤ID关联），只删除包中已不存在的步骤；被运行中实验使用的步骤不允许删除
+func (s *ExposureBundleService) Import(bundle *ExposureBundle, conflict string, dryRun bool) (*BundleImportReport, error) {
+	switch conflict {
+	case BundleConflictSkip, BundleConflictOverwrite, BundleConflictRename:
+	default:
+		return nil, fmt.Errorf("%w: conflict 应为 skip、overwrite 或 rename", ErrBundleInvalid)
+	}
+	report := &BundleImportReport{Conflict: conflict, DryRun: dryRun, Modules: make([]*BundleModuleResult, 0, len(bundle.Modules))}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		taken := make(map[string]bool) // 本次导入已使用的模块ID
+		seen := make(map[string]bool)  // 包中已出现的模块ID
+		for i := range bundle.Modules {
+			m := &bundle.Modules[i]
+			result := &BundleModuleResult{Index: i + 1, SourceID: m.ID, Steps: len(m.Steps)}
+			report.Modules = append(report.Modules, result)
+
+			result.Errors = validateBundleModule(m)
+			if m.ID != "" && seen[m.ID] {
+				result.Errors = append(result.Errors, ImportFieldError{Field: "id", Message: "与包中前面的模块重复"})
+			}
+			seen[m.ID] = true
+			if len(result.Errors) > 0 {
+				result.Action = BundleActionInvalid
+				report.Invalid++
+				continue
+			}
+
+			exists, err := exposureModuleExists(tx, m.ID)
+			if err != nil {
+				return err
+			}
+			targetID := m.ID
+			switch {
+			case !exists && !taken[m.ID]:
+				result.Action = BundleActionCreate
+			case conflict == BundleConflictSkip:
+				result.Action = BundleActionSkip
+			case conflict == BundleConflictOverwrite && !taken[m.ID]:
+				result.Action = BundleActionOverwrite
+			default:
+				result.Action = BundleActionRename
+				if targetID, err = availableModuleID(tx, m.ID, taken); err != nil {
+					return err
+				}
+			}
+			if result.Action == BundleActionSkip {
+				report.Skipped++
+				continue
+			}
+			var plan *stepPlan
+			if result.Action == BundleActionOverwrite {
+				if plan, err = planModuleSteps(tx, targetID, m.Steps, false); err != nil {
+					return err
+				}
+				conflicts, err := plan.experimentConflicts(tx)
+				if err != nil {
+					return err
+				}
+				if len(conflicts) > 0 {
+					result.Errors = conflicts
+					result.Action = BundleActionInvalid
+					report.Invalid++
+					continue
+				}
+			}
+			taken[targetID] = true
+			result.ID = targetID
+
+			switch result.Action {
+			case BundleActionCreate:
+				report.Created++
+			case BundleActionOverwrite:
+				report.Overwritten++
+			case BundleActionRename:
+				report.Renamed++
+			}
+			if dryRun {
+				continue
+			}
+			stepIDs, err := writeBundleModule(tx, m, targetID, plan)
+			if err != nil {
+				return fmt.Errorf("写入模块 %s 失败: %w", m.ID, err)
+			}
+			result.StepIDs = stepIDs
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func exposureModuleExists(tx *gorm.DB, id string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.ExposureModule{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// availableModuleID 生成未被使用的模块ID：<id>-copy、<id>-copy-2…
+func availableModuleID(tx *gorm.DB, id string, taken map[string]bool) (string, error) {
+	for n := 1; ; n++ {
+		suffix := "-copy"
+		if n > 1 {
+			suffix = fmt.Sprintf("-copy-%d", n)
+		}
+		base := id
+		if max := maxExposureModuleIDLength - len(suffix); len(base) > max {
+			base = strings.ToValidUTF8(base[:max], "")
+		}
+		candidate := base + suffix
+		if taken[candidate] {
+			continue
+		}
+		exists, err := exposureModuleExists(tx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+}
+
+// stepPlan 覆盖模块步骤时包中每个步骤写入的ID，以及不再保留的原有步骤
+type stepPlan struct {
+	ids      []uuid.UUID
+	existing map[uuid.UUID]bool // 模块中已有的步骤，写入时更新而非新建
+	removed  []string
+}
+
+// planModuleSteps 为包中的步骤确定ID：优先沿用ID相同的原有步骤；keepIDs 时包中的有效ID即使已被删除也原样恢复（快照回滚），
+// 否则沿用 step_order 相同的原有步骤；都不满足时生成新ID。未被沿用的原有步骤需要删除
+func planModuleSteps(tx *gorm.DB, moduleID string, steps []BundleStep, keepIDs bool) (*stepPlan, error) {
+	var current []models.ExposureStep
+	if err := tx.Select("id", "step_order").Where("module_id = ?", moduleID).
+		Order("step_order ASC, created_at ASC").Find(&current).Error; err != nil {
+		return nil, err
+	}
+	plan := &stepPlan{ids: make([]uuid.UUID, len(steps)), existing: make(map[uuid.UUID]bool, len(current))}
+	for _, step := range current {
+		plan.existing[step.ID] = true
+	}
+	claimed := make(map[uuid.UUID]bool, len(steps))
+	parsed := make([]uuid.UUID, len(steps))
+	for i, s := range steps {
+		if id, err := uuid.Parse(s.ID); err == nil {
+			parsed[i] = id
+			if plan.existing[id] && !claimed[id] {
+				plan.ids[i], claimed[id] = id, true
+			}
+		}
+	}
+
+	// 快照中已被删除的步骤按原ID恢复，ID已被其它模块占用时改为新ID
+	restorable := make(map[uuid.UUID]bool)
+	if keepIDs {
+		var candidates []uuid.UUID
+		for i, id := range parsed {
+			if plan.ids[i] == uuid.Nil && id != uuid.Nil && !plan.existing[id] {
+				candidates = append(candidates, id)
+			}
+		}
+		if len(candidates) > 0 {
+			var used []uuid.UUID
+			if err := tx.Model(&models.ExposureStep{}).Where("id IN ?", candidates).Pluck("id", &used).Error; err != nil {
+				return nil, err
+			}
+			for _, id := range candidates {
+				restorable[id] = true
+			}
+			for _, id := range used {
+				restorable[id] = false
+			}
+		}
+	}
+
+	for i, s := range steps {
+		if plan.ids[i] != uuid.Nil {
+			continue
+		}
+		if id := parsed[i]; keepIDs && restorable[id] && !claimed[id] {
+			plan.ids[i], claimed[id] = id, true
+			continue
+		}
+		if !keepIDs {
+			for _, step := range current {
+				if step.StepOrder == s.StepOrder && !claimed[step.ID] {
+					plan.ids[i], claimed[step.ID] = step.ID, true
+					break
+				}
+			}
+		}
+		if plan.ids[i] == uuid.Nil {
+			plan.ids[i] = uuid.New()
+			claimed[plan.ids[i]] = true
+		}
+	}
+
+	for _, step := range current {
+		if !claimed[step.ID] {
+			plan.removed = append(plan.removed, step.ID.String())
+		}
+	}
+	return plan, nil
+}
+
+// experimentConflicts 返回将被删除、但正被运行中实验使用的步骤
+func (p *stepPlan) experimentConflicts(tx *gorm.DB) ([]ImportFieldError, error) {
+	if len(p.removed) == 0 {
+		return nil, nil
+	}
+	var experiments []models.Experiment
+	if err := tx.Where("target_type = ? AND status = ? AND target_id IN ?", ExperimentTargetExposureStep, ExperimentStatusRunning, p.removed).
+		Find(&experiments).Error; err != nil {
+		return nil, err
+	}
+	errs := make([]ImportFieldError, 0, len(experiments))
+	for _, e := range experiments {
+		errs = append(errs, ImportFieldError{
+			Field:   "steps",
+			Message: fmt.Sprintf("步骤 %s 正被运行中的实验「%s」使用，不能删除；请先停止实验或保留该步骤", e.TargetID, e.Name),
+		})
+	}
+	return errs, nil
+}
+
+// writeBundleModule 写入模块草稿及步骤；plan 不为空时覆盖已有模块：按 plan 更新或新建步骤，只删除不再保留的步骤及其译文。
+// 上线状态由发布控制：新建的模块为未上线，覆盖不改变已有模块的上线状态
+func writeBundleModule(tx *gorm.DB, m *BundleModule, targetID string, plan *stepPlan) (map[string]string, error) {
+	module := models.ExposureModule{
+		ID:           targetID,
+		Title:        m.Title,
+		Description:  m.Description,
+		Icon:         m.Icon,
+		Color:        m.Color,
+		DisplayOrder: m.DisplayOrder,
+	}
+	if plan != nil {
+		err := tx.Model(&models.ExposureModule{}).Where("id = ?", targetID).Updates(map[string]interface{}{
+			"title":         module.Title,
+			"description":   module.Description,
+			"icon":          module.Icon,
+			"color":         module.Color,
+			"display_order": module.DisplayOrder,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+		if err := deleteSteps(tx, plan.removed); err != nil {
+			return nil, err
+		}
+	} else {
+		// is_active 列有默认值，false 需要显式写入
+		if err := tx.Create(&module).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Model(&models.ExposureModule{}).Where("id = ?", targetID).Update("is_active", false).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	stepIDs := make(map[string]string, len(m.Steps))
+	for i, s := range m.Steps {
+		step := s.model()
+		ValidateExposureStep(&step)
+		step.ID = uuid.New()
+		if plan != nil {
+			step.ID = plan.ids[i]
+		}
+		step.ModuleID = targetID
+		if plan != nil && plan.existing[step.ID] {
+			err := tx.Model(&models.ExposureStep{}).Where("id = ?", step.ID).Updates(map[string]interface{}{
+				"step_order":            step.StepOrder,
+				"step_type":             step.StepType,
+				"title":                 step.Title,
+				"description":           step.Description,
+				"guide_content":         step.GuideContent,
+				"scenario_list_title":   step.ScenarioListTitle,
+				"scenario_list_content": step.ScenarioListContent,
+				"popup_configs":         step.PopupConfigs,
+				"config":                step.Config,
+				"icon":                  step.Icon,
+			}).Error
+			if err != nil {
+				return nil, err
+			}
+		} else if err := tx.Create(&step).Error; err != nil {
+			return nil, err
+		}
+		source := s.ID
+		if source == "" {
+			source = fmt.Sprintf("steps[%d]", i)
+		}
+		stepIDs[source] = step.ID.String()
+	}
+	return stepIDs, nil
+}
+
+// deleteSteps 删除指定步骤及其译文
+func deleteSteps(tx *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("content_type = ? AND content_id IN ?", TranslateExposureStep, ids).Delete(&models.ContentTranslation{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN ?", ids).Delete(&models.ExposureStep{}).Error
+}
+
+// Clone 深拷贝模块、步骤及其译文；newID 为空时自动生成，新模块默认停用并排在最后
+func (s *ExposureBundleService) Clone(srcID, newID, title string) (*models.ExposureModule, error) {
+	var clone models.ExposureModule
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var src models.ExposureModule
+		err := tx.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step_order ASC, created_at ASC")
+		}).First(&src, "id = ?", srcID).Error
+		if err == gorm.ErrRecordNotFound {
+			return ErrExposureModuleNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if newID == "" {
+			if newID, err = availableModuleID(tx, srcID, nil); err != nil {
+				return err
+			}
+		} else {
+			if msg := validateExposureModuleID(newID); msg != "" {
+				return fmt.Errorf("%w: 模块ID%s", ErrBundleInvalid, msg)
+			}
+			exists, err := exposureModuleExists(tx, newID)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return fmt.Errorf("%w: %s", ErrExposureModuleExists, newID)
+			}
+		}
+		if title == "" {
+			title = src.Title + "（副本）"
+		}
+
+		var maxOrder struct{ Max *int }
+		if err := tx.Model(&models.ExposureModule{}).Select("MAX(display_order) AS max").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		order := 0
+		if maxOrder.Max != nil {
+			order = *maxOrder.Max + 1
+		}
+
+		clone = models.ExposureModule{
+			ID:           newID,
+			Title:        title,
+			Description:  src.Description,
+			Icon:         src.Icon,
+			Color:        src.Color,
+			DisplayOrder: order,
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&clone).Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		idMap := map[string]string{srcID: newID}
+		for _, step := range src.Steps {
+			copied := step
+			copied.ID = uuid.New()
+			copied.ModuleID = newID
+			copied.CreatedAt, copied.UpdatedAt = time.Time{}, time.Time{}
+			if copied.PopupConfigs == "" {
+				copied.PopupConfigs = "[]"
+			}
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+			idMap[step.ID.String()] = copied.ID.String()
+			clone.Steps = append(clone.Steps, copied)
+		}
+		return copyTranslations(tx, idMap, map[string]string{srcID: TranslateExposureModule}, TranslateExposureStep)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// copyTranslations 把 idMap 中源内容的译文复制到新内容；types 指定特定源ID的内容类型，其余使用 defaultType
+func copyTranslations(tx *gorm.DB, idMap map[string]string, types map[string]string, defaultType string) error {
+	for from, to := range idMap {
+		contentType := defaultType
+		if t, ok := types[from]; ok {
+			contentType = t
+		}
+		var rows []models.ContentTranslation
+		if err := tx.Where("content_type = ? AND content_id = ?", contentType, from).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			row.ID = uuid.Nil
+			row.ContentID = to
+			row.CreatedAt, row.UpdatedAt = time.Time{}, time.Time{}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
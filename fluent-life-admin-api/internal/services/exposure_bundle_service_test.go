@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"fluent-life-admin-api/internal/models"
+)
+
+func TestBundleModuleFromStepJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		popup      string
+		config     string
+		wantPopup  string
+		wantConfig string
+		wantErr    []string // 错误信息中应包含的片段，为空表示没有错误
+	}{
+		{name: "valid", popup: `[{"title":"提示"}]`, config: `{"a":1}`, wantPopup: `[{"title":"提示"}]`, wantConfig: `{"a":1}`},
+		{name: "empty popup defaults to array", popup: "  ", wantPopup: `[]`},
+		{name: "invalid popup", popup: `[{"title":`, wantPopup: `"[{\"title\":"`, wantErr: []string{"第 1 步「说话」的 popup_configs"}},
+		{
+			name: "invalid popup and config", popup: `not json`, config: `{a:1}`,
+			wantPopup: `"not json"`, wantConfig: `"{a:1}"`,
+			wantErr: []string{"popup_configs", "config"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := &models.ExposureModule{
+				ID:    "speak",
+				Title: "开口",
+				Steps: []models.ExposureStep{{Title: "说话", PopupConfigs: tt.popup, Config: tt.config}},
+			}
+			bm, err := bundleModuleFrom(module)
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tt.wantErr) > 0 {
+				if !errors.Is(err, ErrBundleInvalid) {
+					t.Fatalf("error = %v, want ErrBundleInvalid", err)
+				}
+				for _, part := range tt.wantErr {
+					if !strings.Contains(err.Error(), part) {
+						t.Errorf("error %q should mention %q", err, part)
+					}
+				}
+			}
+			step := bm.Steps[0]
+			if string(step.PopupConfigs) != tt.wantPopup {
+				t.Errorf("popup_configs = %s, want %s", step.PopupConfigs, tt.wantPopup)
+			}
+			if string(step.Config) != tt.wantConfig {
+				t.Errorf("config = %s, want %s", step.Config, tt.wantConfig)
+			}
+			// 即使字段无效，模块也必须可以序列化（快照与导出依赖这一点）
+			if _, err := json.Marshal(bm); err != nil {
+				t.Errorf("marshal bundle module: %v", err)
+			}
+		})
+	}
+}
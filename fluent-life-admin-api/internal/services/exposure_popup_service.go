@@ -0,0 +1,108 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/jsonschema"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+//go:embed schemas/popup_configs.schema.json
+var popupConfigsSchemaJSON []byte
+
+var popupConfigsSchema = jsonschema.MustParse(popupConfigsSchemaJSON)
+
+var ErrPopupConfigsInvalid = errors.New("弹窗配置不符合规范")
+
+// PopupConfigsSchema 弹窗配置的 JSON Schema 原文
+func PopupConfigsSchema() json.RawMessage {
+	return popupConfigsSchemaJSON
+}
+
+// ValidatePopupConfigs 按 schema 校验弹窗配置，返回规范化后的值（空串、null 视为空数组）。
+// 错误路径以 prefix 开头，如 popup_configs[0].buttons[1].action.url
+func ValidatePopupConfigs(raw, prefix string) (string, []jsonschema.Error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || trimmed == "null" {
+		return "[]", nil
+	}
+	errs := popupConfigsSchema.ValidateJSON([]byte(trimmed))
+	for i := range errs {
+		switch {
+		case errs[i].Path == "":
+			errs[i].Path = prefix
+		case strings.HasPrefix(errs[i].Path, "["):
+			errs[i].Path = prefix + errs[i].Path
+		default:
+			errs[i].Path = prefix + "." + errs[i].Path
+		}
+	}
+	if len(errs) > 0 {
+		return "", errs
+	}
+	return trimmed, nil
+}
+
+// PopupConfigsViolation 一个不符合规范的步骤
+type PopupConfigsViolation struct {
+	StepID    uuid.UUID          `json:"step_id"`
+	ModuleID  string             `json:"module_id"`
+	StepOrder int                `json:"step_order"`
+	Title     string             `json:"title"`
+	Raw       string             `json:"raw"`
+	Errors    []jsonschema.Error `json:"errors"`
+}
+
+// PopupConfigsAuditReport 存量弹窗配置检查结果
+type PopupConfigsAuditReport struct {
+	Checked    int                     `json:"checked"`
+	Valid      int                     `json:"valid"`
+	Empty      int                     `json:"empty"` // popup_configs 为 NULL 或空串
+	Fixed      int                     `json:"fixed"` // fix_empty 时改写为 [] 的步骤数
+	Violations []PopupConfigsViolation `json:"violations"`
+}
+
+// AuditPopupConfigs 检查全部步骤的弹窗配置（moduleID 为空时检查全部模块）；fixEmpty 时把空值改写为 []
+func AuditPopupConfigs(db *gorm.DB, moduleID string, fixEmpty bool) (*PopupConfigsAuditReport, error) {
+	query := db.Model(&models.ExposureStep{}).Select("id, module_id, step_order, title, popup_configs")
+	if moduleID != "" {
+		query = query.Where("module_id = ?", moduleID)
+	}
+	var steps []models.ExposureStep
+	if err := query.Order("module_id, step_order").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+
+	report := &PopupConfigsAuditReport{Checked: len(steps), Violations: []PopupConfigsViolation{}}
+	var empty []uuid.UUID
+	for _, step := range steps {
+		if strings.TrimSpace(step.PopupConfigs) == "" || strings.TrimSpace(step.PopupConfigs) == "null" {
+			report.Empty++
+			empty = append(empty, step.ID)
+			continue
+		}
+		if _, errs := ValidatePopupConfigs(step.PopupConfigs, "popup_configs"); len(errs) > 0 {
+			report.Violations = append(report.Violations, PopupConfigsViolation{
+				StepID: step.ID, ModuleID: step.ModuleID, StepOrder: step.StepOrder, Title: step.Title,
+				Raw: step.PopupConfigs, Errors: errs,
+			})
+			continue
+		}
+		report.Valid++
+	}
+
+	if fixEmpty && len(empty) > 0 {
+		result := db.Model(&models.ExposureStep{}).Where("id IN ?", empty).Update("popup_configs", "[]")
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		report.Fixed = int(result.RowsAffected)
+	}
+	return report, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"fluent-life-admin-api/pkg/jsonschema"
+)
+
+func TestValidatePopupConfigs(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		want     string
+		wantErrs []jsonschema.Error
+	}{
+		{name: "empty", raw: "  ", want: "[]"},
+		{name: "null", raw: "null", want: "[]"},
+		{
+			name: "full popup",
+			raw:  ` [{"id":"p1","trigger":{"type":"keyword","keywords":["深呼吸"]},"title":"提示","body":"慢慢说","buttons":[{"text":"去看看","action":{"type":"open_url","url":"https://example.com"}}]}] `,
+			want: `[{"id":"p1","trigger":{"type":"keyword","keywords":["深呼吸"]},"title":"提示","body":"慢慢说","buttons":[{"text":"去看看","action":{"type":"open_url","url":"https://example.com"}}]}]`,
+		},
+		{name: "legacy format", raw: `[{"keywords":"放松,呼吸","title":"提示","content":"内容"}]`, want: `[{"keywords":"放松,呼吸","title":"提示","content":"内容"}]`},
+		{
+			name:     "not json",
+			raw:      `[{`,
+			wantErrs: []jsonschema.Error{{Path: "popup_configs", Message: "不是有效的 JSON: unexpected EOF"}},
+		},
+		{
+			name:     "object instead of array",
+			raw:      `{"title":"x"}`,
+			wantErrs: []jsonschema.Error{{Path: "popup_configs", Message: "类型应为 array，实际为 object"}},
+		},
+		{
+			name: "nested paths",
+			raw:  `[{"trigger":{"type":"delay"},"title":"x","body":"y","buttons":[{"text":"ok","action":{"type":"goto_step"}}]}]`,
+			wantErrs: []jsonschema.Error{
+				{Path: "popup_configs[0].buttons[0].action.step_order", Message: "缺少必填字段"},
+				{Path: "popup_configs[0].trigger.delay_seconds", Message: "缺少必填字段"},
+			},
+		},
+		{
+			name:     "missing trigger and body",
+			raw:      `[{"title":"x"}]`,
+			wantErrs: []jsonschema.Error{{Path: "popup_configs[0].body", Message: "缺少必填字段"}, {Path: "popup_configs[0].trigger", Message: "缺少必填字段"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := ValidatePopupConfigs(tt.raw, "popup_configs")
+			if !reflect.DeepEqual(errs, tt.wantErrs) {
+				t.Fatalf("errors\n got  %v\n want %v", errs, tt.wantErrs)
+			}
+			if got != tt.want {
+				t.Errorf("normalized = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
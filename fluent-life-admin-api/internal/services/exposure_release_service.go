@@ -0,0 +1,502 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/jsonschema"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("快照不存在")
+	ErrScheduleInvalid  = errors.New("发布时间无效")
+)
+
+// ExposureValidationError 发布前模块或步骤校验失败
+type ExposureValidationError struct {
+	Errors []jsonschema.Error
+}
+
+func (e *ExposureValidationError) Error() string {
+	return fmt.Sprintf("模块校验失败（%d 处）: %s", len(e.Errors), e.Errors[0].Error())
+}
+
+// ExposureReleaseState 模块的发布状态
+type ExposureReleaseState struct {
+	models.ExposureModuleRelease
+	Status        string                         `json:"status"`         // live 已上线、offline 未上线
+	HasChanges    bool                           `json:"has_changes"`    // 草稿与线上版本是否不同
+	LatestVersion int                            `json:"latest_version"` // 最新快照版本
+	LiveSnapshot  *models.ExposureModuleSnapshot `json:"live_snapshot"`  // 线上快照（不含内容）
+	DraftErrors   []jsonschema.Error             `json:"draft_errors"`   // 草稿发布前校验错误
+}
+
+// 发布状态
+const (
+	ReleaseStatusLive    = "live"
+	ReleaseStatusOffline = "offline"
+)
+
+// ExposureReleaseService 脱敏模块的发布快照、定时上下线与回滚。
+// exposure_modules / exposure_steps 为草稿，编辑即时保存但不影响用户；发布时生成快照，公开接口只读取线上快照
+type ExposureReleaseService struct {
+	db *gorm.DB
+}
+
+func NewExposureReleaseService(db *gorm.DB) *ExposureReleaseService {
+	return &ExposureReleaseService{db: db}
+}
+
+// lockModule 锁定模块行，发布、排序等修改步骤的操作互斥
+func lockModule(tx *gorm.DB, moduleID string) (*models.ExposureModule, error) {
+	var module models.ExposureModule
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&module, "id = ?", moduleID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrExposureModuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &module, nil
+}
+
+// LockExposureModule 在事务中锁定模块行，供批量调整步骤等操作与发布互斥
+func LockExposureModule(tx *gorm.DB, moduleID string) error {
+	_, err := lockModule(tx, moduleID)
+	return err
+}
+
+// draft 读取模块草稿（模块及全部步骤）
+func draft(tx *gorm.DB, module *models.ExposureModule) (BundleModule, error) {
+	if err := tx.Where("module_id = ?", module.ID).Order("step_order ASC, created_at ASC").Find(&module.Steps).Error; err != nil {
+		return BundleModule{}, err
+	}
+	// 无效的 JSON 字段按字符串保留，由发布前校验（validateDraft）指出具体步骤
+	bm, _ := bundleModuleFrom(module)
+	return bm, nil
+}
+
+// validateDraft 发布前校验模块字段与全部步骤
+func validateDraft(bm *BundleModule) []jsonschema.Error {
+	var errs []jsonschema.Error
+	for _, e := range validateBundleModule(bm) {
+		errs = append(errs, jsonschema.Error{Path: e.Field, Message: e.Message})
+	}
+	return errs
+}
+
+// release 读取模块的发布状态，不存在时返回零值
+func release(tx *gorm.DB, moduleID string) (*models.ExposureModuleRelease, error) {
+	r := &models.ExposureModuleRelease{ModuleID: moduleID}
+	err := tx.Where("module_id = ?", moduleID).Limit(1).Find(r).Error
+	return r, err
+}
+
+func latestVersion(tx *gorm.DB, moduleID string) (int, error) {
+	var max struct{ Max *int }
+	if err := tx.Model(&models.ExposureModuleSnapshot{}).Select("MAX(version) AS max").Where("module_id = ?", moduleID).Scan(&max).Error; err != nil {
+		return 0, err
+	}
+	if max.Max == nil {
+		return 0, nil
+	}
+	return *max.Max, nil
+}
+
+// saveLive 保存快照并设为线上版本，同步模块的 is_active
+func saveLive(tx *gorm.DB, moduleID string, data []byte, note string, rolledBackFrom *int, actor *uuid.UUID, clearPublishAt bool) (*models.ExposureModuleSnapshot, error) {
+	version, err := latestVersion(tx, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &models.ExposureModuleSnapshot{
+		ModuleID:       moduleID,
+		Version:        version + 1,
+		Data:           string(data),
+		Note:           note,
+		RolledBackFrom: rolledBackFrom,
+		PublishedBy:    actor,
+		PublishedAt:    time.Now(),
+	}
+	if err := tx.Create(snapshot).Error; err != nil {
+		return nil, err
+	}
+
+	r, err := release(tx, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	r.LiveVersion = &snapshot.Version
+	if clearPublishAt {
+		r.PublishAt = nil
+	}
+	r.UpdatedBy = actor
+	if err := tx.Save(r).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.ExposureModule{}).Where("id = ?", moduleID).Update("is_active", true).Error; err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Publish 把当前草稿原子地发布为新的线上快照；草稿校验不通过时返回 *ExposureValidationError
+func (s *ExposureReleaseService) Publish(moduleID, note string, actor *uuid.UUID) (*models.ExposureModuleSnapshot, error) {
+	return s.publish(moduleID, note, actor, true)
+}
+
+func (s *ExposureReleaseService) publish(moduleID, note string, actor *uuid.UUID, validate bool) (*models.ExposureModuleSnapshot, error) {
+	var snapshot *models.ExposureModuleSnapshot
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		snapshot, err = publishTx(tx, moduleID, note, actor, validate)
+		return err
+	})
+	return snapshot, err
+}
+
+// publishTx 在调用方的事务中发布模块草稿
+func publishTx(tx *gorm.DB, moduleID, note string, actor *uuid.UUID, validate bool) (*models.ExposureModuleSnapshot, error) {
+	module, err := lockModule(tx, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	bm, err := draft(tx, module)
+	if err != nil {
+		return nil, err
+	}
+	if validate {
+		if errs := validateDraft(&bm); len(errs) > 0 {
+			return nil, &ExposureValidationError{Errors: errs}
+		}
+	}
+	data, err := json.Marshal(bm)
+	if err != nil {
+		return nil, err
+	}
+	return saveLive(tx, moduleID, data, note, nil, actor, true)
+}
+
+// Create 创建模块草稿；publish 时在同一事务中发布，发布失败则模块也不会创建
+func (s *ExposureReleaseService) Create(module *models.ExposureModule, publish bool, note string, actor *uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// 上线状态由发布控制：先以下线状态创建（Select("*") 让 is_active=false 也写入，而不是被列默认值 true 取代）
+		module.IsActive = false
+		if err := tx.Select("*").Create(module).Error; err != nil {
+			return err
+		}
+		if !publish {
+			return nil
+		}
+		if _, err := publishTx(tx, module.ID, note, actor, true); err != nil {
+			return err
+		}
+		module.IsActive = true
+		return nil
+	})
+}
+
+// Unpublish 下线模块，快照保留
+func (s *ExposureReleaseService) Unpublish(moduleID string, actor *uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockModule(tx, moduleID); err != nil {
+			return err
+		}
+		r, err := release(tx, moduleID)
+		if err != nil {
+			return err
+		}
+		r.LiveVersion = nil
+		r.UnpublishAt = nil
+		r.UpdatedBy = actor
+		if err := tx.Save(r).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ExposureModule{}).Where("id = ?", moduleID).Update("is_active", false).Error
+	})
+}
+
+// Schedule 设置定时发布与下线时间，传 nil 表示取消
+func (s *ExposureReleaseService) Schedule(moduleID string, publishAt, unpublishAt *time.Time, actor *uuid.UUID) (*models.ExposureModuleRelease, error) {
+	now := time.Now()
+	if publishAt != nil && !publishAt.After(now) {
+		return nil, fmt.Errorf("%w: 发布时间需晚于当前时间", ErrScheduleInvalid)
+	}
+	if unpublishAt != nil && !unpublishAt.After(now) {
+		return nil, fmt.Errorf("%w: 下线时间需晚于当前时间", ErrScheduleInvalid)
+	}
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return nil, fmt.Errorf("%w: 下线时间需晚于发布时间", ErrScheduleInvalid)
+	}
+
+	var r *models.ExposureModuleRelease
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockModule(tx, moduleID); err != nil {
+			return err
+		}
+		var err error
+		if r, err = release(tx, moduleID); err != nil {
+			return err
+		}
+		r.PublishAt, r.UnpublishAt, r.UpdatedBy = publishAt, unpublishAt, actor
+		return tx.Save(r).Error
+	})
+	return r, err
+}
+
+// Rollback 以历史快照的内容生成新的线上快照；restoreDraft 时同时用该快照覆盖草稿：步骤按快照中的ID更新或恢复，
+// 保留其译文与实验关联，只删除快照中没有的步骤；待删除的步骤被运行中实验使用时拒绝回滚
+func (s *ExposureReleaseService) Rollback(moduleID string, version int, note string, restoreDraft bool, actor *uuid.UUID) (*models.ExposureModuleSnapshot, error) {
+	var snapshot *models.ExposureModuleSnapshot
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		module, err := lockModule(tx, moduleID)
+		if err != nil {
+			return err
+		}
+		var source models.ExposureModuleSnapshot
+		if err := tx.Where("module_id = ? AND version = ?", moduleID, version).First(&source).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrSnapshotNotFound
+			}
+			return err
+		}
+		if note == "" {
+			note = fmt.Sprintf("回滚到版本 %d", version)
+		}
+		data := []byte(source.Data)
+		if restoreDraft {
+			var bm BundleModule
+			if err := json.Unmarshal(data, &bm); err != nil {
+				return fmt.Errorf("解析版本 %d 的快照失败: %w", version, err)
+			}
+			plan, err := planModuleSteps(tx, moduleID, bm.Steps, true)
+			if err != nil {
+				return err
+			}
+			conflicts, err := plan.experimentConflicts(tx)
+			if err != nil {
+				return err
+			}
+			if len(conflicts) > 0 {
+				errs := make([]jsonschema.Error, 0, len(conflicts))
+				for _, c := range conflicts {
+					errs = append(errs, jsonschema.Error{Path: c.Field, Message: c.Message})
+				}
+				return &ExposureValidationError{Errors: errs}
+			}
+			if _, err := writeBundleModule(tx, &bm, moduleID, plan); err != nil {
+				return err
+			}
+			// 以写回后的草稿生成快照，使快照中的步骤ID与草稿一致
+			if err := tx.First(module, "id = ?", moduleID).Error; err != nil {
+				return err
+			}
+			restored, err := draft(tx, module)
+			if err != nil {
+				return err
+			}
+			if data, err = json.Marshal(restored); err != nil {
+				return err
+			}
+		}
+		snapshot, err = saveLive(tx, moduleID, data, note, &version, actor, false)
+		return err
+	})
+	return snapshot, err
+}
+
+// State 模块的发布状态，含草稿是否有未发布的修改
+func (s *ExposureReleaseService) State(moduleID string) (*ExposureReleaseState, error) {
+	var module models.ExposureModule
+	if err := s.db.First(&module, "id = ?", moduleID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrExposureModuleNotFound
+		}
+		return nil, err
+	}
+	r, err := release(s.db, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	bm, err := draft(s.db, &module)
+	if err != nil {
+		return nil, err
+	}
+	state := &ExposureReleaseState{ExposureModuleRelease: *r, Status: ReleaseStatusOffline, HasChanges: true, DraftErrors: validateDraft(&bm)}
+	if state.LatestVersion, err = latestVersion(s.db, moduleID); err != nil {
+		return nil, err
+	}
+	if r.LiveVersion == nil {
+		return state, nil
+	}
+
+	state.Status = ReleaseStatusLive
+	var live models.ExposureModuleSnapshot
+	if err := s.db.Where("module_id = ? AND version = ?", moduleID, *r.LiveVersion).First(&live).Error; err != nil {
+		return nil, err
+	}
+	var liveModule BundleModule
+	if err := json.Unmarshal([]byte(live.Data), &liveModule); err != nil {
+		return nil, err
+	}
+	// 上线状态由发布控制，is_active 不算作内容修改
+	liveModule.IsActive, bm.IsActive = false, false
+	state.HasChanges = !sameJSON(liveModule, bm)
+	live.Data = ""
+	state.LiveSnapshot = &live
+	return state, nil
+}
+
+func sameJSON(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// Snapshots 快照列表（不含内容），按版本倒序
+func (s *ExposureReleaseService) Snapshots(moduleID string, page, pageSize int) ([]models.ExposureModuleSnapshot, int64, error) {
+	query := s.db.Model(&models.ExposureModuleSnapshot{}).Where("module_id = ?", moduleID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var snapshots []models.ExposureModuleSnapshot
+	err := query.Omit("data").Order("version DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&snapshots).Error
+	return snapshots, total, err
+}
+
+// Snapshot 某个版本的快照（含内容）
+func (s *ExposureReleaseService) Snapshot(moduleID string, version int) (*models.ExposureModuleSnapshot, error) {
+	var snapshot models.ExposureModuleSnapshot
+	if err := s.db.Where("module_id = ? AND version = ?", moduleID, version).First(&snapshot).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Forget 删除模块的发布状态与全部快照（删除模块时调用）
+func (s *ExposureReleaseService) Forget(moduleID string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("module_id = ?", moduleID).Delete(&models.ExposureModuleRelease{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("module_id = ?", moduleID).Delete(&models.ExposureModuleSnapshot{}).Error
+	})
+}
+
+// LiveModules 全部线上模块（快照内容），按展示顺序排列
+func (s *ExposureReleaseService) LiveModules() ([]models.ExposureModule, error) {
+	var snapshots []models.ExposureModuleSnapshot
+	err := s.db.Model(&models.ExposureModuleSnapshot{}).
+		Joins("JOIN exposure_module_releases r ON r.module_id = exposure_module_snapshots.module_id AND r.live_version = exposure_module_snapshots.version").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	// 模块之间的排序取草稿的 display_order，调整顺序即时生效
+	var orders []models.ExposureModule
+	if err := s.db.Select("id, display_order").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	displayOrder := make(map[string]int, len(orders))
+	for _, m := range orders {
+		displayOrder[m.ID] = m.DisplayOrder
+	}
+
+	modules := make([]models.ExposureModule, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		var bm BundleModule
+		if err := json.Unmarshal([]byte(snapshot.Data), &bm); err != nil {
+			log.Printf("[exposure] 模块 %s 版本 %d 的快照无法解析: %v", snapshot.ModuleID, snapshot.Version, err)
+			continue
+		}
+		module := bm.model()
+		module.IsActive = true
+		if order, ok := displayOrder[module.ID]; ok {
+			module.DisplayOrder = order
+		}
+		modules = append(modules, module)
+	}
+	sort.SliceStable(modules, func(i, j int) bool {
+		if modules[i].DisplayOrder != modules[j].DisplayOrder {
+			return modules[i].DisplayOrder < modules[j].DisplayOrder
+		}
+		return modules[i].ID < modules[j].ID
+	})
+	return modules, nil
+}
+
+// Bootstrap 为还没有发布状态的模块建立发布状态：已启用的模块以当前内容生成版本 1 并上线（不做校验）
+func (s *ExposureReleaseService) Bootstrap() error {
+	var modules []models.ExposureModule
+	err := s.db.Where("id NOT IN (?)", s.db.Model(&models.ExposureModuleRelease{}).Select("module_id")).Find(&modules).Error
+	if err != nil {
+		return err
+	}
+	for _, module := range modules {
+		if module.IsActive {
+			if _, err := s.publish(module.ID, "初始版本", nil, false); err != nil {
+				return fmt.Errorf("发布模块 %s 失败: %w", module.ID, err)
+			}
+			continue
+		}
+		if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ExposureModuleRelease{ModuleID: module.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runDue 执行到期的定时发布与下线；发布失败（如草稿校验不通过）时保留排期并记录日志
+func (s *ExposureReleaseService) runDue(now time.Time) {
+	var due []models.ExposureModuleRelease
+	if err := s.db.Where("publish_at <= ? OR unpublish_at <= ?", now, now).Find(&due).Error; err != nil {
+		log.Printf("[exposure] 查询待执行的发布排期失败: %v", err)
+		return
+	}
+	for _, r := range due {
+		if r.PublishAt != nil && !r.PublishAt.After(now) && (r.UnpublishAt == nil || r.UnpublishAt.After(now)) {
+			if _, err := s.Publish(r.ModuleID, "定时发布", r.UpdatedBy); err != nil {
+				log.Printf("[exposure] 模块 %s 定时发布失败: %v", r.ModuleID, err)
+			}
+			continue
+		}
+		if r.UnpublishAt != nil && !r.UnpublishAt.After(now) {
+			// 发布时间也已过但晚于下线时间时，视为排期作废，直接下线并清除两项排期
+			if err := s.Unpublish(r.ModuleID, r.UpdatedBy); err != nil {
+				log.Printf("[exposure] 模块 %s 定时下线失败: %v", r.ModuleID, err)
+				continue
+			}
+			if r.PublishAt != nil {
+				s.db.Model(&models.ExposureModuleRelease{}).Where("module_id = ?", r.ModuleID).Update("publish_at", nil)
+			}
+		}
+	}
+}
+
+// RunScheduler 每分钟执行到期的定时发布与下线
+func (s *ExposureReleaseService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		s.runDue(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
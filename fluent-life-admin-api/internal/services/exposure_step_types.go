@@ -0,0 +1,280 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/jsonschema"
+)
+
+// StepField 步骤的可编辑字段，供管理端动态渲染表单
+type StepField struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Input string `json:"input"` // text、textarea、popup_configs
+	Hint  string `json:"hint,omitempty"`
+}
+
+// stepFields 步骤类型可声明的字段；title、description、icon、step_order 所有类型都必填，不在此列；
+// config 的结构由各类型的 config_schema 定义
+var stepFields = []StepField{
+	{Key: "guide_content", Label: "执行指南内容", Input: "textarea", Hint: "支持多行文本，弹窗关键词在此高亮"},
+	{Key: "scenario_list_title", Label: "场景列表标题", Input: "text"},
+	{Key: "scenario_list_content", Label: "场景列表内容", Input: "textarea", Hint: "每行一个场景"},
+	{Key: "popup_configs", Label: "弹窗配置", Input: "popup_configs"},
+}
+
+// StepRenderHints 客户端渲染提示
+type StepRenderHints struct {
+	Component   string `json:"component"`              // 客户端渲染该步骤的组件
+	Icon        string `json:"icon"`                   // 新建步骤时的默认图标
+	ActionLabel string `json:"action_label,omitempty"` // 完成步骤的主按钮文案
+	FullScreen  bool   `json:"full_screen,omitempty"`  // 是否全屏展示
+}
+
+// ExposureStepType 步骤类型定义
+type ExposureStepType struct {
+	Type         string          `json:"type"`
+	Label        string          `json:"label"`
+	Description  string          `json:"description"`
+	Required     []string        `json:"required"` // 除通用必填字段外，该类型必填的字段
+	Optional     []string        `json:"optional"`
+	ConfigSchema json.RawMessage `json:"config_schema"` // config 字段的 JSON Schema
+	Render       StepRenderHints `json:"render"`
+
+	configSchema *jsonschema.Schema
+}
+
+var (
+	stepTypesMu sync.RWMutex
+	stepTypes   []*ExposureStepType
+)
+
+// RegisterExposureStepType 注册步骤类型；类型重复、字段未定义或 config schema 无效时 panic。
+// 新增步骤类型只需在此文件中注册，创建/更新/导入步骤时自动按定义校验
+func RegisterExposureStepType(t ExposureStepType) {
+	if len(t.ConfigSchema) == 0 {
+		t.ConfigSchema = json.RawMessage(`{"type": "object", "additionalProperties": false}`)
+	}
+	schema, err := jsonschema.Parse(t.ConfigSchema)
+	if err != nil {
+		panic(fmt.Sprintf("步骤类型 %s 的 config_schema 无效: %v", t.Type, err))
+	}
+	t.configSchema = schema
+	for _, key := range append(append([]string{}, t.Required...), t.Optional...) {
+		if stepField(key) == nil {
+			panic(fmt.Sprintf("步骤类型 %s 声明了未定义的字段 %s", t.Type, key))
+		}
+	}
+	if t.Required == nil {
+		t.Required = []string{}
+	}
+	if t.Optional == nil {
+		t.Optional = []string{}
+	}
+
+	stepTypesMu.Lock()
+	defer stepTypesMu.Unlock()
+	for _, existing := range stepTypes {
+		if existing.Type == t.Type {
+			panic("步骤类型重复注册: " + t.Type)
+		}
+	}
+	stepTypes = append(stepTypes, &t)
+}
+
+// ExposureStepTypes 已注册的步骤类型（按注册顺序）
+func ExposureStepTypes() []*ExposureStepType {
+	stepTypesMu.RLock()
+	defer stepTypesMu.RUnlock()
+	return append([]*ExposureStepType(nil), stepTypes...)
+}
+
+// LookupExposureStepType 按名称查找步骤类型
+func LookupExposureStepType(name string) (*ExposureStepType, bool) {
+	stepTypesMu.RLock()
+	defer stepTypesMu.RUnlock()
+	for _, t := range stepTypes {
+		if t.Type == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// ExposureStepFields 步骤类型可声明的字段定义
+func ExposureStepFields() []StepField {
+	return stepFields
+}
+
+func stepField(key string) *StepField {
+	for i := range stepFields {
+		if stepFields[i].Key == key {
+			return &stepFields[i]
+		}
+	}
+	return nil
+}
+
+// stepFieldValue 读取步骤字段的值
+func stepFieldValue(step *models.ExposureStep, key string) string {
+	switch key {
+	case "guide_content":
+		return step.GuideContent
+	case "scenario_list_title":
+		return step.ScenarioListTitle
+	case "scenario_list_content":
+		return step.ScenarioListContent
+	case "popup_configs":
+		return step.PopupConfigs
+	}
+	return ""
+}
+
+// ValidateExposureStep 按步骤类型校验步骤，并把空的 popup_configs、config 规范化为 []、{}
+func ValidateExposureStep(step *models.ExposureStep) []jsonschema.Error {
+	var errs []jsonschema.Error
+	add := func(path, message string) {
+		errs = append(errs, jsonschema.Error{Path: path, Message: message})
+	}
+	for key, value := range map[string]string{"title": step.Title, "description": step.Description, "icon": step.Icon} {
+		if strings.TrimSpace(value) == "" {
+			add(key, "不能为空")
+		}
+	}
+
+	popupConfigs, popupErrs := ValidatePopupConfigs(step.PopupConfigs, "popup_configs")
+	errs = append(errs, popupErrs...)
+	if len(popupErrs) == 0 {
+		step.PopupConfigs = popupConfigs
+	}
+
+	stepType, ok := LookupExposureStepType(step.StepType)
+	if !ok {
+		add("step_type", "无效的步骤类型 "+step.StepType)
+		return sortStepErrors(errs)
+	}
+	for _, key := range stepType.Required {
+		value := strings.TrimSpace(stepFieldValue(step, key))
+		if value == "" || (key == "popup_configs" && value == "[]") {
+			add(key, "该步骤类型必填")
+		}
+	}
+
+	config := strings.TrimSpace(step.Config)
+	if config == "" || config == "null" {
+		config = "{}"
+	}
+	configErrs := stepType.configSchema.ValidateJSON([]byte(config))
+	for _, e := range configErrs {
+		if e.Path == "" {
+			e.Path = "config"
+		} else {
+			e.Path = "config." + e.Path
+		}
+		errs = append(errs, e)
+	}
+	if len(configErrs) == 0 {
+		step.Config = config
+	}
+	return sortStepErrors(errs)
+}
+
+func sortStepErrors(errs []jsonschema.Error) []jsonschema.Error {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+func init() {
+	RegisterExposureStepType(ExposureStepType{
+		Type:        "approach",
+		Label:       "搭讪/接近",
+		Description: "按执行指南在现实场景中主动接近他人",
+		Required:    []string{"guide_content"},
+		Optional:    []string{"scenario_list_title", "scenario_list_content", "popup_configs"},
+		ConfigSchema: json.RawMessage(`{
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"min_attempts": {"type": "integer", "minimum": 1, "maximum": 20, "default": 1, "description": "完成步骤需要的尝试次数"}
+			}
+		}`),
+		Render: StepRenderHints{Component: "GuideStep", Icon: "👋", ActionLabel: "我完成了"},
+	})
+	RegisterExposureStepType(ExposureStepType{
+		Type:        "conversation",
+		Label:       "对话",
+		Description: "与 AI 角色进行模拟对话",
+		Required:    []string{"guide_content"},
+		Optional:    []string{"scenario_list_title", "scenario_list_content", "popup_configs"},
+		ConfigSchema: json.RawMessage(`{
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"ai_role_id": {"type": "string", "minLength": 1, "maxLength": 64, "description": "使用的 AI 模拟角色，为空时由用户选择"},
+				"max_turns": {"type": "integer", "minimum": 1, "maximum": 50, "default": 10}
+			}
+		}`),
+		Render: StepRenderHints{Component: "ConversationStep", Icon: "💬", ActionLabel: "开始对话", FullScreen: true},
+	})
+	RegisterExposureStepType(ExposureStepType{
+		Type:        "upload",
+		Label:       "录制/上传视频",
+		Description: "录制或上传练习视频",
+		Optional:    []string{"guide_content", "popup_configs"},
+		ConfigSchema: json.RawMessage(`{
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"max_duration_seconds": {"type": "integer", "minimum": 5, "maximum": 600, "default": 60},
+				"allow_gallery": {"type": "boolean", "default": true, "description": "是否允许从相册选择"}
+			}
+		}`),
+		Render: StepRenderHints{Component: "UploadStep", Icon: "🎥", ActionLabel: "开始录制"},
+	})
+	RegisterExposureStepType(ExposureStepType{
+		Type:        "analysis",
+		Label:       "AI分析",
+		Description: "对上一步上传的视频进行 AI 分析",
+		Optional:    []string{"guide_content"},
+		ConfigSchema: json.RawMessage(`{
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"dimensions": {
+					"type": "array",
+					"maxItems": 5,
+					"items": {"type": "string", "enum": ["fluency", "pace", "eye_contact", "filler_words", "confidence"]},
+					"description": "展示的分析维度，为空时全部展示"
+				}
+			}
+		}`),
+		Render: StepRenderHints{Component: "AnalysisStep", Icon: "🤖", ActionLabel: "查看分析"},
+	})
+	RegisterExposureStepType(ExposureStepType{
+		Type:        "profile",
+		Label:       "个人主页",
+		Description: "引导用户查看个人主页中的练习记录",
+		Optional:    []string{"guide_content"},
+		Render:      StepRenderHints{Component: "ProfileStep", Icon: "👤", ActionLabel: "去看看"},
+	})
+	RegisterExposureStepType(ExposureStepType{
+		Type:        "community",
+		Label:       "感悟广场",
+		Description: "在感悟广场分享练习感受",
+		Optional:    []string{"guide_content", "popup_configs"},
+		ConfigSchema: json.RawMessage(`{
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"post_template": {"type": "string", "maxLength": 500, "description": "发帖输入框的预填内容"},
+				"tags": {"type": "array", "maxItems": 5, "items": {"type": "string", "minLength": 1, "maxLength": 20}}
+			}
+		}`),
+		Render: StepRenderHints{Component: "CommunityStep", Icon: "🌟", ActionLabel: "去分享"},
+	})
+}
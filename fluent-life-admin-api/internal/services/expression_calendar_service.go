@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 日历中每天的状态
+const (
+	CalendarDayOK        = "ok"        // 恰好一篇启用的文案
+	CalendarDayGap       = "gap"       // 没有启用的文案
+	CalendarDayCollision = "collision" // 多篇启用的文案排在同一天
+)
+
+// 日历中每天的附加提示
+const (
+	CalendarFlagInactiveOnly = "inactive_only" // 当天只有未启用的文案
+	CalendarFlagSourceRepeat = "source_repeat" // 与前几天的来源重复，违反来源间隔规则
+)
+
+// 今日文案的来源
+const (
+	TodayScheduled = "scheduled" // 排期在当天
+	TodayPool      = "pool"      // 当天无排期，从待排期池中按日期轮换选取（不写入排期）
+	TodayPrevious  = "previous"  // 池为空，回退到最近一篇已发布的文案
+	TodayNone      = "none"
+)
+
+const dateLayout = "2006-01-02"
+
+// ScheduleRules 自动排期的来源多样性规则
+type ScheduleRules struct {
+	// SourceCooldownDays 同一来源出现后，前后多少天内不再安排该来源；0 表示不限制
+	SourceCooldownDays int `json:"source_cooldown_days"`
+	// MaxPerSourcePerMonth 同一来源每月最多安排的篇数；0 表示不限制
+	MaxPerSourcePerMonth int `json:"max_per_source_per_month"`
+}
+
+// DefaultScheduleRules 默认规则：同一来源至少间隔 2 天
+var DefaultScheduleRules = ScheduleRules{SourceCooldownDays: 2}
+
+// CalendarExpression 日历中的一篇文案
+type CalendarExpression struct {
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	Source   string    `json:"source"`
+	IsActive bool      `json:"is_active"`
+}
+
+// CalendarDay 日历中的一天
+type CalendarDay struct {
+	Date        string               `json:"date"`
+	Weekday     int                  `json:"weekday"` // 0 为周日
+	Status      string               `json:"status"`
+	Flags       []string             `json:"flags,omitempty"`
+	Expressions []CalendarExpression `json:"expressions"`
+}
+
+// ExpressionCalendar 某月的排期概览
+type ExpressionCalendar struct {
+	Month      string        `json:"month"`
+	Days       []CalendarDay `json:"days"`
+	Covered    int           `json:"covered"`
+	Gaps       int           `json:"gaps"`
+	Collisions int           `json:"collisions"`
+	PoolSize   int64         `json:"pool_size"` // 待排期池中启用的文案数
+}
+
+// ScheduleAssignment 自动排期的一条分配
+type ScheduleAssignment struct {
+	Date   string    `json:"date"`
+	ID     uuid.UUID `json:"id"`
+	Title  string    `json:"title"`
+	Source string    `json:"source"`
+}
+
+// ScheduleResult 自动排期结果
+type ScheduleResult struct {
+	Start         string               `json:"start"`
+	End           string               `json:"end"` // 含当天
+	DryRun        bool                 `json:"dry_run"`
+	Rules         ScheduleRules        `json:"rules"`
+	Assignments   []ScheduleAssignment `json:"assignments"`
+	Unfilled      []string             `json:"unfilled"` // 没有符合规则的文案可填的空档
+	PoolRemaining int                  `json:"pool_remaining"`
+}
+
+// TodayExpression 今日文案解析结果
+type TodayExpression struct {
+	Date       string                  `json:"date"`
+	Resolution string                  `json:"resolution"`
+	Collision  bool                    `json:"collision"` // 当天排了多篇，按创建时间取第一篇
+	Expression *models.DailyExpression `json:"expression"`
+	Locale     string                  `json:"locale,omitempty"` // 文案所用语言，由接口按请求设置
+}
+
+// ExpressionCalendarService 每日朗诵文案排期
+type ExpressionCalendarService struct {
+	db *gorm.DB
+}
+
+func NewExpressionCalendarService(db *gorm.DB) *ExpressionCalendarService {
+	return &ExpressionCalendarService{db: db}
+}
+
+// ParseMonth 解析 YYYY-MM，为空时返回当前月份（北京时间）
+func ParseMonth(s string) (time.Time, error) {
+	if strings.TrimSpace(s) == "" {
+		now := time.Now().In(ReportLocation)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	t, err := time.Parse("2006-01", strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("月份格式应为 YYYY-MM")
+	}
+	return t, nil
+}
+
+// CalendarToday 当前日期（北京时间），以 UTC 零点表示，与 date 列的取值一致
+func CalendarToday() time.Time {
+	now := time.Now().In(ReportLocation)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// scheduledBetween 查询 [start, end] 内已排期的文案（日期均为 UTC 零点）
+func (s *ExpressionCalendarService) scheduledBetween(start, end time.Time) ([]models.DailyExpression, error) {
+	var expressions []models.DailyExpression
+	err := s.db.Select("id, title, source, date, is_active, created_at").
+		Where("date >= ? AND date <= ?", start.Format(dateLayout), end.Format(dateLayout)).
+		Order("date, created_at").Find(&expressions).Error
+	return expressions, err
+}
+
+// Calendar 返回某月每天的排期情况，并标记空档、冲突与来源重复
+func (s *ExpressionCalendarService) Calendar(month time.Time, rules ScheduleRules) (*ExpressionCalendar, error) {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+
+	// 多查询冷却期内的前几天，用于判断月初的来源重复
+	expressions, err := s.scheduledBetween(first.AddDate(0, 0, -rules.SourceCooldownDays), last)
+	if err != nil {
+		return nil, fmt.Errorf("查询排期失败: %w", err)
+	}
+	byDate := make(map[string][]models.DailyExpression)
+	for _, e := range expressions {
+		key := e.Date.Format(dateLayout)
+		byDate[key] = append(byDate[key], e)
+	}
+
+	calendar := &ExpressionCalendar{Month: first.Format("2006-01")}
+	if err := s.db.Model(&models.DailyExpression{}).Where("date IS NULL AND is_active = ?", true).Count(&calendar.PoolSize).Error; err != nil {
+		return nil, fmt.Errorf("查询待排期池失败: %w", err)
+	}
+
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dateLayout)
+		day := CalendarDay{Date: key, Weekday: int(d.Weekday()), Expressions: []CalendarExpression{}}
+		active := 0
+		for _, e := range byDate[key] {
+			day.Expressions = append(day.Expressions, CalendarExpression{ID: e.ID, Title: e.Title, Source: e.Source, IsActive: e.IsActive})
+			if e.IsActive {
+				active++
+			}
+		}
+
+		switch {
+		case active == 0:
+			day.Status = CalendarDayGap
+			calendar.Gaps++
+			if len(day.Expressions) > 0 {
+				day.Flags = append(day.Flags, CalendarFlagInactiveOnly)
+			}
+		case active > 1:
+			day.Status = CalendarDayCollision
+			calendar.Collisions++
+			calendar.Covered++
+		default:
+			day.Status = CalendarDayOK
+			calendar.Covered++
+		}
+
+		if active > 0 && rules.SourceCooldownDays > 0 && repeatsRecentSource(byDate, d, rules.SourceCooldownDays) {
+			day.Flags = append(day.Flags, CalendarFlagSourceRepeat)
+		}
+		calendar.Days = append(calendar.Days, day)
+	}
+	return calendar, nil
+}
+
+// repeatsRecentSource 当天启用文案的来源是否在前 cooldown 天内出现过
+func repeatsRecentSource(byDate map[string][]models.DailyExpression, d time.Time, cooldown int) bool {
+	recent := make(map[string]bool)
+	for i := 1; i <= cooldown; i++ {
+		for _, e := range byDate[d.AddDate(0, 0, -i).Format(dateLayout)] {
+			if e.IsActive && e.Source != "" {
+				recent[e.Source] = true
+			}
+		}
+	}
+	for _, e := range byDate[d.Format(dateLayout)] {
+		if e.IsActive && recent[e.Source] {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoSchedule 把待排期池中启用的文案按创建顺序填入 [start, end] 内的空档（今天之前的日期不处理）。
+// 每个空档优先选择本月使用次数最少的来源，并遵守来源间隔与每月上限；来源为空的文案不受来源规则限制。
+// 分配的日期通过内容版本服务写入，每篇文案记录一个发布版本
+func (s *ExpressionCalendarService) AutoSchedule(start, end time.Time, rules ScheduleRules, dryRun bool, actor Actor) (*ScheduleResult, error) {
+	if today := CalendarToday(); start.Before(today) {
+		start = today
+	}
+	result := &ScheduleResult{
+		Start:       start.Format(dateLayout),
+		End:         end.Format(dateLayout),
+		DryRun:      dryRun,
+		Rules:       rules,
+		Assignments: []ScheduleAssignment{},
+		Unfilled:    []string{},
+	}
+	if end.Before(start) {
+		return result, nil
+	}
+
+	// 来源规则需要看到区间两侧冷却期内以及所在月份的已有排期
+	from := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if f := start.AddDate(0, 0, -rules.SourceCooldownDays); f.Before(from) {
+		from = f
+	}
+	to := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, -1)
+	if t := end.AddDate(0, 0, rules.SourceCooldownDays); t.After(to) {
+		to = t
+	}
+	scheduled, err := s.scheduledBetween(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("查询排期失败: %w", err)
+	}
+
+	sourcesByDate := make(map[string][]string)
+	filled := make(map[string]bool)
+	monthCounts := make(map[string]map[string]int) // 月份 -> 来源 -> 篇数
+	use := func(d time.Time, source string) {
+		key := d.Format(dateLayout)
+		filled[key] = true
+		if source == "" {
+			return
+		}
+		sourcesByDate[key] = append(sourcesByDate[key], source)
+		month := d.Format("2006-01")
+		if monthCounts[month] == nil {
+			monthCounts[month] = make(map[string]int)
+		}
+		monthCounts[month][source]++
+	}
+	for _, e := range scheduled {
+		if e.IsActive {
+			use(*e.Date, e.Source)
+		}
+	}
+
+	var pool []models.DailyExpression
+	if err := s.db.Select("id, title, source, created_at").Where("date IS NULL AND is_active = ?", true).
+		Order("created_at, id").Find(&pool).Error; err != nil {
+		return nil, fmt.Errorf("查询待排期池失败: %w", err)
+	}
+
+	allowed := func(d time.Time, source string) bool {
+		if source == "" {
+			return true
+		}
+		if rules.MaxPerSourcePerMonth > 0 && monthCounts[d.Format("2006-01")][source] >= rules.MaxPerSourcePerMonth {
+			return false
+		}
+		for i := -rules.SourceCooldownDays; i <= rules.SourceCooldownDays; i++ {
+			for _, used := range sourcesByDate[d.AddDate(0, 0, i).Format(dateLayout)] {
+				if used == source {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dateLayout)
+		if filled[key] {
+			continue
+		}
+		pick := -1
+		for i, e := range pool {
+			if !allowed(d, e.Source) {
+				continue
+			}
+			// 同等条件下保持创建顺序，只在本月使用次数更少时替换
+			if pick < 0 || monthCounts[d.Format("2006-01")][e.Source] < monthCounts[d.Format("2006-01")][pool[pick].Source] {
+				pick = i
+			}
+		}
+		if pick < 0 {
+			result.Unfilled = append(result.Unfilled, key)
+			continue
+		}
+		e := pool[pick]
+		pool = append(pool[:pick], pool[pick+1:]...)
+		use(d, e.Source)
+		result.Assignments = append(result.Assignments, ScheduleAssignment{Date: key, ID: e.ID, Title: e.Title, Source: e.Source})
+	}
+	result.PoolRemaining = len(pool)
+
+	if dryRun || len(result.Assignments) == 0 {
+		return result, nil
+	}
+	versions := NewContentVersionService(s.db)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, a := range result.Assignments {
+			// 只更新仍在池中的文案，避免覆盖排期期间被手工安排的日期
+			var pooled struct{ ID uuid.UUID }
+			err := tx.Model(&models.DailyExpression{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id").Where("id = ? AND date IS NULL", a.ID).Take(&pooled).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("文案「%s」已被排期，请重新生成", a.Title)
+			}
+			if err != nil {
+				return err
+			}
+			if err := versions.Apply(tx, VersionDailyExpression, a.ID, map[string]interface{}{"date": a.Date}, "自动排期", actor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存排期失败: %w", err)
+	}
+	return result, nil
+}
+
+// Today 解析某天展示的文案：当天排期 → 待排期池轮换 → 最近一篇已发布的文案
+func (s *ExpressionCalendarService) Today(date time.Time) (*TodayExpression, error) {
+	key := date.Format(dateLayout)
+	result := &TodayExpression{Date: key, Resolution: TodayNone}
+
+	var scheduled []models.DailyExpression
+	if err := s.db.Where("date = ? AND is_active = ?", key, true).Order("created_at, id").Find(&scheduled).Error; err != nil {
+		return nil, err
+	}
+	if len(scheduled) > 0 {
+		result.Resolution = TodayScheduled
+		result.Collision = len(scheduled) > 1
+		result.Expression = &scheduled[0]
+		return result, nil
+	}
+
+	// 按日期轮换选取池中的文案，同一天多次请求结果一致
+	var poolIDs []uuid.UUID
+	if err := s.db.Model(&models.DailyExpression{}).Where("date IS NULL AND is_active = ?", true).
+		Order("created_at, id").Pluck("id", &poolIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(poolIDs) > 0 {
+		days := int(date.Unix() / 86400)
+		index := days % len(poolIDs)
+		if index < 0 {
+			index += len(poolIDs)
+		}
+		var expression models.DailyExpression
+		if err := s.db.First(&expression, "id = ?", poolIDs[index]).Error; err != nil {
+			return nil, err
+		}
+		result.Resolution = TodayPool
+		result.Expression = &expression
+		return result, nil
+	}
+
+	var previous models.DailyExpression
+	err := s.db.Where("date < ? AND is_active = ?", key, true).Order("date DESC, created_at").First(&previous).Error
+	if err == nil {
+		result.Resolution = TodayPrevious
+		result.Expression = &previous
+		return result, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// FunnelStep 漏斗中的一个步骤
+type FunnelStep struct {
+	StepID    string `json:"step_id"`
+	StepOrder int    `json:"step_order"`
+	StepType  string `json:"step_type"`
+	Title     string `json:"title"`
+	Users     int    `json:"users"` // 到达该步骤（或之后步骤）的用户数
+	// 相对上一步的转化率，第一步为相对模块参与用户数
+	Conversion float64 `json:"conversion"`
+	// 相对第一步的整体转化率
+	OverallConversion float64 `json:"overall_conversion"`
+	// 从上一步首次到达至本步首次到达的耗时中位数（秒），无样本时为 null
+	MedianSecondsFromPrev *float64 `json:"median_seconds_from_prev"`
+	TimingSamples         int      `json:"timing_samples"`
+}
+
+// ModuleFunnel 单个脱敏练习模块的步骤完成漏斗
+type ModuleFunnel struct {
+	ModuleID     string       `json:"module_id"`
+	ModuleTitle  string       `json:"module_title"`
+	StartDate    string       `json:"start_date"`
+	EndDate      string       `json:"end_date"`
+	Timezone     string       `json:"timezone"`
+	Participants int          `json:"participants"` // 区间内有该模块训练记录的用户数
+	Records      int64        `json:"records"`
+	Unresolved   int64        `json:"unresolved"` // 无法识别步骤信息的记录数
+	Steps        []FunnelStep `json:"steps"`
+}
+
+// FunnelService 基于 exposure 类型训练记录计算模块步骤漏斗
+type FunnelService struct {
+	db *gorm.DB
+}
+
+// NewFunnelService 创建漏斗分析服务
+func NewFunnelService(db *gorm.DB) *FunnelService {
+	return &FunnelService{db: db}
+}
+
+// exposureRecordRow 训练记录中与漏斗相关的字段
+type exposureRecordRow struct {
+	UserID    string
+	Timestamp time.Time
+	StepOrder string
+	StepID    string
+	StepType  string
+}
+
+// ModuleFunnel 计算模块在 [r.Start, r.End) 区间内的漏斗
+// 训练记录 Data 中的步骤信息按 step_order、step_id、step_type 的优先级识别；
+// 到达后续步骤的用户视为已经通过前面的步骤
+func (s *FunnelService) ModuleFunnel(moduleID string, r DateRange) (*ModuleFunnel, error) {
+	var module models.ExposureModule
+	err := s.db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("step_order ASC")
+	}).First(&module, "id = ?", moduleID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []exposureRecordRow
+	err = s.db.Table("training_records").
+		Select("user_id, timestamp, data->>'step_order' AS step_order, data->>'step_id' AS step_id, data->>'step_type' AS step_type").
+		Where("type = ? AND data->>'module_id' = ? AND timestamp >= ? AND timestamp < ?", "exposure", moduleID, r.Start, r.End).
+		Order("timestamp ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询训练记录失败: %w", err)
+	}
+
+	result := &ModuleFunnel{
+		ModuleID:    module.ID,
+		ModuleTitle: module.Title,
+		StartDate:   r.Start.Format("2006-01-02"),
+		EndDate:     r.LastDay().Format("2006-01-02"),
+		Timezone:    ReportTimezone,
+		Records:     int64(len(rows)),
+		Steps:       make([]FunnelStep, 0, len(module.Steps)),
+	}
+
+	// 步骤索引：位置 i 对应 module.Steps[i]
+	byOrder := make(map[int]int)
+	byID := make(map[string]int)
+	byType := make(map[string]int)
+	for i, step := range module.Steps {
+		byOrder[step.StepOrder] = i
+		byID[step.ID.String()] = i
+		if _, ok := byType[step.StepType]; !ok {
+			byType[step.StepType] = i
+		}
+	}
+	resolve := func(row exposureRecordRow) (int, bool) {
+		if row.StepOrder != "" {
+			if order, err := strconv.Atoi(row.StepOrder); err == nil {
+				if i, ok := byOrder[order]; ok {
+					return i, true
+				}
+			}
+		}
+		if i, ok := byID[row.StepID]; ok && row.StepID != "" {
+			return i, true
+		}
+		if i, ok := byType[row.StepType]; ok && row.StepType != "" {
+			return i, true
+		}
+		return 0, false
+	}
+
+	// 每个用户每个步骤首次到达的时间，以及到达的最远步骤
+	firstReach := make(map[string]map[int]time.Time)
+	furthest := make(map[string]int)
+	for _, row := range rows {
+		if _, ok := furthest[row.UserID]; !ok {
+			furthest[row.UserID] = -1
+		}
+		i, ok := resolve(row)
+		if !ok {
+			result.Unresolved++
+			continue
+		}
+		if firstReach[row.UserID] == nil {
+			firstReach[row.UserID] = make(map[int]time.Time)
+		}
+		if _, seen := firstReach[row.UserID][i]; !seen {
+			firstReach[row.UserID][i] = row.Timestamp // rows 已按时间升序
+		}
+		if i > furthest[row.UserID] {
+			furthest[row.UserID] = i
+		}
+	}
+	result.Participants = len(furthest)
+
+	for i, step := range module.Steps {
+		fs := FunnelStep{
+			StepID:    step.ID.String(),
+			StepOrder: step.StepOrder,
+			StepType:  step.StepType,
+			Title:     step.Title,
+		}
+		var durations []float64
+		for userID, max := range furthest {
+			if max >= i {
+				fs.Users++
+			}
+			if i == 0 {
+				continue
+			}
+			prev, okPrev := firstReach[userID][i-1]
+			cur, okCur := firstReach[userID][i]
+			if okPrev && okCur && !cur.Before(prev) {
+				durations = append(durations, cur.Sub(prev).Seconds())
+			}
+		}
+		if i == 0 {
+			fs.Conversion = ratio(fs.Users, result.Participants)
+		} else {
+			fs.Conversion = ratio(fs.Users, result.Steps[i-1].Users)
+			fs.TimingSamples = len(durations)
+			fs.MedianSecondsFromPrev = median(durations)
+		}
+		result.Steps = append(result.Steps, fs)
+	}
+	if len(result.Steps) > 0 {
+		for i := range result.Steps {
+			result.Steps[i].OverallConversion = ratio(result.Steps[i].Users, result.Steps[0].Users)
+		}
+	}
+	return result, nil
+}
+
+// AllModuleFunnels 计算全部模块的漏斗，按模块展示顺序排列
+func (s *FunnelService) AllModuleFunnels(r DateRange) ([]*ModuleFunnel, error) {
+	var moduleIDs []string
+	if err := s.db.Model(&models.ExposureModule{}).Order("display_order ASC, created_at DESC").Pluck("id", &moduleIDs).Error; err != nil {
+		return nil, fmt.Errorf("查询模块失败: %w", err)
+	}
+	funnels := make([]*ModuleFunnel, 0, len(moduleIDs))
+	for _, id := range moduleIDs {
+		funnel, err := s.ModuleFunnel(id, r)
+		if err != nil {
+			return nil, err
+		}
+		funnels = append(funnels, funnel)
+	}
+	return funnels, nil
+}
+
+// median 返回中位数，空切片返回 nil
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	v := values[mid]
+	if len(values)%2 == 0 {
+		v = (values[mid-1] + values[mid]) / 2
+	}
+	return &v
+}
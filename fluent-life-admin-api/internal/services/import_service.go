@@ -0,0 +1,964 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/xlsx"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 可导入的内容类型
+const (
+	ImportTongueTwisters   = "tongue_twisters"
+	ImportDailyExpressions = "daily_expressions"
+	ImportSpeechTechniques = "speech_techniques"
+)
+
+// 导入模式
+const (
+	ImportModeInsert = "insert" // 与已有内容重复的行跳过
+	ImportModeUpsert = "upsert" // 与已有内容重复的行覆盖更新
+)
+
+// 行处理结果
+const (
+	ImportActionCreate        = "create"
+	ImportActionUpdate        = "update"
+	ImportActionDuplicate     = "duplicate"      // 与已有内容重复，insert 模式下跳过
+	ImportActionFileDuplicate = "file_duplicate" // 与文件中前面的行重复，跳过
+	ImportActionInvalid       = "invalid"
+)
+
+// TongueTwisterLevels 绕口令难度枚举
+var TongueTwisterLevels = []string{"basic", "intermediate", "advanced"}
+
+// levelAliases 难度的中文写法
+var levelAliases = map[string]string{
+	"初级": "basic",
+	"基础": "basic",
+	"中级": "intermediate",
+	"高级": "advanced",
+	"进阶": "advanced",
+}
+
+// ImportField 导入字段定义
+type ImportField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Required bool     `json:"required"`
+	Aliases  []string `json:"aliases,omitempty"` // 自动匹配的表头别名
+	Hint     string   `json:"hint,omitempty"`
+}
+
+// ImportFieldError 行内某字段的校验错误
+type ImportFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportRowResult 单行的处理结果
+type ImportRowResult struct {
+	Row        int                `json:"row"` // CSV/XLSX 为表格行号（表头为第 1 行），JSON 为数组下标 + 1
+	Action     string             `json:"action"`
+	Key        string             `json:"key,omitempty"`
+	ExistingID string             `json:"existing_id,omitempty"`
+	Errors     []ImportFieldError `json:"errors,omitempty"`
+	Similar    []DuplicateMatch   `json:"similar,omitempty"` // 与已有内容或文件中前面的行近似（仅提示，不影响写入）
+	Values     map[string]string  `json:"values,omitempty"`
+}
+
+// ImportReport 导入（或预览）结果
+type ImportReport struct {
+	ID             uuid.UUID         `json:"id"`
+	Kind           string            `json:"kind"`
+	FileName       string            `json:"file_name"`
+	Mode           string            `json:"mode"`
+	DryRun         bool              `json:"dry_run"`
+	Mapping        map[string]string `json:"mapping"`         // 源列 -> 字段
+	UnmappedFields []string          `json:"unmapped_fields"` // 没有对应源列的字段
+	Total          int               `json:"total"`
+	Valid          int               `json:"valid"`
+	Invalid        int               `json:"invalid"`
+	Duplicates     int               `json:"duplicates"`
+	NearDuplicates int               `json:"near_duplicates"`
+	Created        int               `json:"created"`
+	Updated        int               `json:"updated"`
+	Skipped        int               `json:"skipped"`
+	Rows           []ImportRowResult `json:"rows"`
+}
+
+// ImportOptions 导入参数
+type ImportOptions struct {
+	Kind     string
+	FileName string
+	Mode     string
+	DryRun   bool
+	Mapping  map[string]string // 源列 -> 字段，值为空表示忽略该列；未指定的列按字段名或别名自动匹配
+}
+
+// importRecord 校验通过的一行
+type importRecord struct {
+	row      int
+	key      string
+	model    interface{}
+	columns  map[string]interface{} // 更新时写入的列（只包含文件中提供的字段）
+	existing *uuid.UUID
+}
+
+// importSpec 一种内容类型的导入规则
+type importSpec struct {
+	table  string
+	fields []ImportField
+	// versionType 内容版本管理中的类型，覆盖更新已有内容时记录为新的发布版本
+	versionType string
+	// keyField 判断重复的字段，按规范化后的值比较
+	keyField string
+	// duplicateKind 近似重复检测中的内容类型，similarTexts 返回参与检测的文本
+	duplicateKind string
+	similarTexts  func(values map[string]string) []string
+	build         func(values map[string]string, provided map[string]bool) (interface{}, map[string]interface{}, []ImportFieldError)
+	// afterWrite 每行写入后的附加处理（同一事务），existing 为被更新的已有内容 ID，新建时为空
+	afterWrite func(tx *gorm.DB, model interface{}, existing *uuid.UUID, values map[string]string) error
+}
+
+var importSpecs = map[string]*importSpec{
+	ImportTongueTwisters: {
+		table:         "tongue_twisters",
+		versionType:   VersionTongueTwister,
+		keyField:      "content",
+		duplicateKind: DuplicateKindTongueTwister,
+		similarTexts:  contentText,
+		fields: []ImportField{
+			{Key: "title", Label: "标题", Required: true, Aliases: []string{"标题", "名称"}},
+			{Key: "content", Label: "内容", Required: true, Aliases: []string{"内容", "正文"}},
+			{Key: "tips", Label: "提示", Aliases: []string{"提示", "技巧"}},
+			{Key: "level", Label: "难度", Aliases: []string{"难度", "级别"}, Hint: "basic / intermediate / advanced，或 初级 / 中级 / 高级；留空按拼音分析自动评级"},
+			{Key: "order", Label: "排序", Aliases: []string{"排序", "顺序"}},
+			{Key: "is_active", Label: "是否启用", Aliases: []string{"是否启用", "启用"}},
+		},
+		build: buildTongueTwister,
+	},
+	ImportDailyExpressions: {
+		table:         "daily_expressions",
+		versionType:   VersionDailyExpression,
+		keyField:      "title",
+		duplicateKind: DuplicateKindDailyExpression,
+		similarTexts:  contentText,
+		fields: []ImportField{
+			{Key: "title", Label: "标题", Required: true, Aliases: []string{"标题", "名称"}},
+			{Key: "content", Label: "内容", Required: true, Aliases: []string{"内容", "正文"}},
+			{Key: "tips", Label: "朗诵技巧", Aliases: []string{"朗诵技巧", "提示"}},
+			{Key: "source", Label: "来源", Aliases: []string{"来源", "出处"}},
+			{Key: "date", Label: "发布日期", Aliases: []string{"发布日期", "日期"}, Hint: "YYYY-MM-DD，留空进入待排期池"},
+			{Key: "is_active", Label: "是否启用", Aliases: []string{"是否启用", "启用"}},
+		},
+		build: buildDailyExpression,
+	},
+	ImportSpeechTechniques: {
+		table:         "speech_techniques",
+		versionType:   VersionSpeechTechnique,
+		keyField:      "name",
+		duplicateKind: DuplicateKindPracticeText,
+		similarTexts:  practiceTexts,
+		fields: []ImportField{
+			{Key: "name", Label: "技巧名称", Required: true, Aliases: []string{"技巧名称", "名称"}},
+			{Key: "icon", Label: "图标", Aliases: []string{"图标"}},
+			{Key: "description", Label: "简短描述", Aliases: []string{"简短描述", "描述"}},
+			{Key: "tips", Label: "训练要点", Aliases: []string{"训练要点", "要点"}, Hint: "JSON 数组，或用换行 / | 分隔"},
+			{Key: "practice_texts", Label: "练习文本", Aliases: []string{"练习文本"}, Hint: "JSON 数组，或用换行 / | 分隔"},
+			{Key: "order", Label: "排序", Aliases: []string{"排序", "顺序"}},
+			{Key: "is_active", Label: "是否启用", Aliases: []string{"是否启用", "启用"}},
+		},
+		build:      buildSpeechTechnique,
+		afterWrite: writeSpeechItems,
+	},
+}
+
+func contentText(values map[string]string) []string {
+	return []string{values["content"]}
+}
+
+func practiceTexts(values map[string]string) []string {
+	list, _ := ParseStringList(values["practice_texts"])
+	texts, _ := StrictStringList(list)
+	return texts
+}
+
+// ImportFields 返回内容类型的字段定义，类型不存在时返回 false
+func ImportFields(kind string) ([]ImportField, bool) {
+	spec, ok := importSpecs[kind]
+	if !ok {
+		return nil, false
+	}
+	return spec.fields, true
+}
+
+// ImportService 练习内容批量导入
+type ImportService struct {
+	db *gorm.DB
+}
+
+// NewImportService 创建导入服务
+func NewImportService(db *gorm.DB) *ImportService {
+	return &ImportService{db: db}
+}
+
+// ParseImportTable 将 CSV/XLSX/JSON 文件解析为表头与数据行；format 为空时按文件扩展名判断
+// 返回的 firstRow 为第一条数据在原文件中的行号，用于错误报告定位
+func ParseImportTable(format, fileName string, data []byte) (headers []string, rows [][]string, firstRow int, err error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	}
+	switch format {
+	case "csv":
+		data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		all, err := reader.ReadAll()
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("CSV 解析失败: %w", err)
+		}
+		if len(all) == 0 {
+			return nil, nil, 0, fmt.Errorf("文件为空")
+		}
+		return all[0], all[1:], 2, nil
+	case "xlsx":
+		all, err := xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if len(all) == 0 {
+			return nil, nil, 0, fmt.Errorf("文件为空")
+		}
+		return all[0], all[1:], 2, nil
+	case "json":
+		return parseJSONTable(data)
+	default:
+		return nil, nil, 0, fmt.Errorf("不支持的文件格式: %s（仅支持 csv、xlsx、json）", format)
+	}
+}
+
+// parseJSONTable 将对象数组转换为表格，表头为所有对象键按首次出现顺序的并集
+func parseJSONTable(data []byte) ([]string, [][]string, int, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, nil, 0, fmt.Errorf("JSON 应为对象数组: %w", err)
+	}
+
+	var headers []string
+	index := make(map[string]int)
+	rows := make([][]string, 0, len(items))
+	for i, item := range items {
+		keys, values, err := orderedObject(item)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("第 %d 个元素不是对象: %w", i+1, err)
+		}
+		row := make([]string, len(headers))
+		for j, key := range keys {
+			col, ok := index[key]
+			if !ok {
+				col = len(headers)
+				index[key] = col
+				headers = append(headers, key)
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			row[col] = jsonCellString(values[j])
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows, 1, nil
+}
+
+// orderedObject 按原始顺序读取 JSON 对象的键值
+func orderedObject(data json.RawMessage) ([]string, []json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("应为对象")
+	}
+	var keys []string
+	var values []json.RawMessage
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, value)
+	}
+	return keys, values, nil
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// jsonCellString 字符串取原值，null 为空，其余（数字、布尔、数组）保留 JSON 文本
+func jsonCellString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	text := strings.TrimSpace(string(raw))
+	if text == "null" {
+		return ""
+	}
+	return text
+}
+
+// resolveMapping 生成源列到字段的映射：显式映射优先，其次字段名，再次别名
+func resolveMapping(spec *importSpec, headers []string, explicit map[string]string) (map[int]string, map[string]string, error) {
+	known := make(map[string]bool, len(spec.fields))
+	lookup := make(map[string]string)
+	for _, f := range spec.fields {
+		known[f.Key] = true
+		lookup[strings.ToLower(f.Key)] = f.Key
+		lookup[strings.ToLower(f.Label)] = f.Key
+		for _, alias := range f.Aliases {
+			lookup[strings.ToLower(alias)] = f.Key
+		}
+	}
+
+	byIndex := make(map[int]string)
+	byName := make(map[string]string)
+	used := make(map[string]bool)
+	for i, header := range headers {
+		header = strings.TrimSpace(header)
+		field, ok := explicit[header]
+		if ok {
+			if field == "" {
+				continue // 显式忽略
+			}
+			if !known[field] {
+				return nil, nil, fmt.Errorf("列 %s 映射到了未知字段 %s", header, field)
+			}
+		} else {
+			field = lookup[strings.ToLower(header)]
+		}
+		if field == "" || used[field] {
+			continue
+		}
+		used[field] = true
+		byIndex[i] = field
+		byName[header] = field
+	}
+	return byIndex, byName, nil
+}
+
+// Import 解析、校验并（非预览时）写入数据库；返回的报告会同时保存到 content_imports 供下载错误报告
+func (s *ImportService) Import(headers []string, rows [][]string, firstRow int, opts ImportOptions, createdBy uuid.UUID) (*ImportReport, error) {
+	spec, ok := importSpecs[opts.Kind]
+	if !ok {
+		return nil, fmt.Errorf("不支持的导入类型: %s", opts.Kind)
+	}
+	if opts.Mode == "" {
+		opts.Mode = ImportModeInsert
+	}
+	if opts.Mode != ImportModeInsert && opts.Mode != ImportModeUpsert {
+		return nil, fmt.Errorf("导入模式仅支持 insert 或 upsert")
+	}
+
+	byIndex, byName, err := resolveMapping(spec, headers, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		Kind:     opts.Kind,
+		FileName: opts.FileName,
+		Mode:     opts.Mode,
+		DryRun:   opts.DryRun,
+		Mapping:  byName,
+		Rows:     make([]ImportRowResult, 0, len(rows)),
+	}
+	mapped := make(map[string]bool)
+	for _, field := range byIndex {
+		mapped[field] = true
+	}
+	for _, f := range spec.fields {
+		if !mapped[f.Key] {
+			report.UnmappedFields = append(report.UnmappedFields, f.Key)
+		}
+	}
+
+	// 逐行校验
+	var records []*importRecord
+	seen := make(map[string]int)
+	for i, row := range rows {
+		values := make(map[string]string)
+		provided := make(map[string]bool)
+		empty := true
+		for col, field := range byIndex {
+			if col < len(row) {
+				v := strings.TrimSpace(row[col])
+				values[field] = v
+				provided[field] = true
+				if v != "" {
+					empty = false
+				}
+			}
+		}
+		if empty {
+			continue // 忽略空行
+		}
+		report.Total++
+
+		result := ImportRowResult{Row: firstRow + i, Values: values}
+		model, columns, errs := spec.build(values, provided)
+		if len(errs) > 0 {
+			result.Action = ImportActionInvalid
+			result.Errors = errs
+			report.Invalid++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+
+		result.Key = NormalizeContentKey(values[spec.keyField])
+		if prev, dup := seen[result.Key]; dup {
+			result.Action = ImportActionFileDuplicate
+			result.Errors = []ImportFieldError{{Field: spec.keyField, Message: fmt.Sprintf("与第 %d 行重复", prev)}}
+			report.Skipped++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+		seen[result.Key] = result.Row
+		report.Valid++
+		report.Rows = append(report.Rows, result)
+		records = append(records, &importRecord{row: len(report.Rows) - 1, key: result.Key, model: model, columns: columns})
+	}
+
+	// 与已有内容比对
+	existing, err := s.existingKeys(spec)
+	if err != nil {
+		return nil, err
+	}
+	var toWrite []*importRecord
+	for _, rec := range records {
+		result := &report.Rows[rec.row]
+		id, dup := existing[rec.key]
+		switch {
+		case !dup:
+			result.Action = ImportActionCreate
+			report.Created++
+			toWrite = append(toWrite, rec)
+		case opts.Mode == ImportModeUpsert:
+			result.Action = ImportActionUpdate
+			result.ExistingID = id.String()
+			rec.existing = &id
+			report.Duplicates++
+			report.Updated++
+			toWrite = append(toWrite, rec)
+		default:
+			result.Action = ImportActionDuplicate
+			result.ExistingID = id.String()
+			report.Duplicates++
+			report.Skipped++
+		}
+	}
+
+	if err := s.markNearDuplicates(spec, report, toWrite); err != nil {
+		return nil, err
+	}
+
+	if !opts.DryRun && len(toWrite) > 0 {
+		versions := NewContentVersionService(s.db)
+		note := "导入覆盖"
+		if opts.FileName != "" {
+			note += "：" + opts.FileName
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			for _, rec := range toWrite {
+				if rec.existing != nil {
+					if err := s.update(tx, versions, spec, *rec.existing, rec.columns, note, createdBy); err != nil {
+						return fmt.Errorf("第 %d 行更新失败: %w", report.Rows[rec.row].Row, err)
+					}
+				} else if err := tx.Select("*").Create(rec.model).Error; err != nil {
+					// Select("*") 让 is_active=false 等零值也写入，而不是被列默认值 true 取代
+					return fmt.Errorf("第 %d 行写入失败: %w", report.Rows[rec.row].Row, err)
+				}
+				if spec.afterWrite != nil {
+					if err := spec.afterWrite(tx, rec.model, rec.existing, report.Rows[rec.row].Values); err != nil {
+						return fmt.Errorf("第 %d 行写入失败: %w", report.Rows[rec.row].Row, err)
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.saveReport(report, createdBy); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// update 覆盖更新已有内容：纳入版本管理的字段通过版本服务写入并生成发布版本，其余列直接更新
+func (s *ImportService) update(tx *gorm.DB, versions *ContentVersionService, spec *importSpec, id uuid.UUID, columns map[string]interface{}, note string, createdBy uuid.UUID) error {
+	versioned := make(map[string]bool)
+	for _, f := range versionSpecs[spec.versionType].Fields {
+		versioned[f.Key] = true
+	}
+	changes := make(map[string]interface{})
+	rest := make(map[string]interface{})
+	for key, value := range columns {
+		if versioned[key] {
+			changes[key] = value
+		} else {
+			rest[key] = value
+		}
+	}
+	if len(rest) > 0 {
+		if err := tx.Table(spec.table).Where("id = ?", id).Updates(rest).Error; err != nil {
+			return err
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return versions.Apply(tx, spec.versionType, id, changes, note, Actor{ID: createdBy})
+}
+
+// markNearDuplicates 对将要写入的行做近似重复检测（与已有内容及文件中前面的行比较），结果只作提示
+func (s *ImportService) markNearDuplicates(spec *importSpec, report *ImportReport, records []*importRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	items, err := NewDuplicateService(s.db).LoadItems()
+	if err != nil {
+		return err
+	}
+	index := NewDuplicateIndex(items)
+
+	for _, rec := range records {
+		result := &report.Rows[rec.row]
+		self := ""
+		if rec.existing != nil {
+			self = spec.duplicateKind + ":" + rec.existing.String()
+		}
+		seen := make(map[string]bool)
+		texts := spec.similarTexts(result.Values)
+		for _, text := range texts {
+			for _, m := range index.Similar(text, DefaultDuplicateThreshold) {
+				// upsert 时跳过被更新的那条内容本身
+				if self != "" && (m.Key == self || strings.HasPrefix(m.Key, self+"#")) {
+					continue
+				}
+				if !seen[m.Key] {
+					seen[m.Key] = true
+					result.Similar = append(result.Similar, m)
+				}
+			}
+		}
+		if len(result.Similar) > 0 {
+			report.NearDuplicates++
+		}
+		for i, text := range texts {
+			index.Add(&DuplicateItem{
+				Key:   fmt.Sprintf("import:%d#%d", result.Row, i),
+				Kind:  spec.duplicateKind,
+				Title: fmt.Sprintf("导入文件第 %d 行", result.Row),
+				Text:  text,
+			})
+		}
+	}
+	return nil
+}
+
+// existingKeys 返回已有内容的规范化键到 ID 的映射（同键多条时取最早创建的一条）
+func (s *ImportService) existingKeys(spec *importSpec) (map[string]uuid.UUID, error) {
+	var rows []struct {
+		ID  uuid.UUID
+		Key string
+	}
+	err := s.db.Table(spec.table).Select("id, " + spec.keyField + " AS key").Order("created_at DESC").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询已有内容失败: %w", err)
+	}
+	keys := make(map[string]uuid.UUID, len(rows))
+	for _, row := range rows {
+		keys[NormalizeContentKey(row.Key)] = row.ID
+	}
+	return keys, nil
+}
+
+// saveReport 保存导入记录；错误报告只保留非成功行，避免存储全部内容
+func (s *ImportService) saveReport(report *ImportReport, createdBy uuid.UUID) error {
+	problems := make([]ImportRowResult, 0)
+	for _, row := range report.Rows {
+		if row.Action == ImportActionInvalid || row.Action == ImportActionFileDuplicate || row.Action == ImportActionDuplicate || len(row.Similar) > 0 {
+			problems = append(problems, row)
+		}
+	}
+	errorsJSON, _ := json.Marshal(problems)
+	mappingJSON, _ := json.Marshal(report.Mapping)
+
+	record := models.ContentImport{
+		Kind:           report.Kind,
+		FileName:       report.FileName,
+		Mode:           report.Mode,
+		DryRun:         report.DryRun,
+		Mapping:        string(mappingJSON),
+		Total:          report.Total,
+		Valid:          report.Valid,
+		Invalid:        report.Invalid,
+		Duplicates:     report.Duplicates,
+		NearDuplicates: report.NearDuplicates,
+		Created:        report.Created,
+		Updated:        report.Updated,
+		Skipped:        report.Skipped,
+		Problems:       string(errorsJSON),
+		CreatedBy:      createdBy,
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return fmt.Errorf("保存导入记录失败: %w", err)
+	}
+	report.ID = record.ID
+	return nil
+}
+
+// WriteErrorReport 以 CSV 输出导入记录中的问题行：行号、处理结果、错误信息及原始字段值
+func WriteErrorReport(w io.Writer, record *models.ContentImport) error {
+	var problems []ImportRowResult
+	if record.Problems != "" {
+		if err := json.Unmarshal([]byte(record.Problems), &problems); err != nil {
+			return err
+		}
+	}
+	fields, _ := ImportFields(record.Kind)
+
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	header := []string{"行号", "处理结果", "错误信息"}
+	for _, f := range fields {
+		header = append(header, f.Label)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	actionLabels := map[string]string{
+		ImportActionInvalid:       "校验失败",
+		ImportActionDuplicate:     "与已有内容重复",
+		ImportActionFileDuplicate: "文件内重复",
+		ImportActionCreate:        "新增（存在近似内容）",
+		ImportActionUpdate:        "更新（存在近似内容）",
+	}
+	for _, p := range problems {
+		messages := make([]string, 0, len(p.Errors))
+		for _, e := range p.Errors {
+			messages = append(messages, e.Field+": "+e.Message)
+		}
+		if p.Action == ImportActionDuplicate && p.ExistingID != "" {
+			messages = append(messages, "已有内容ID: "+p.ExistingID)
+		}
+		for _, m := range p.Similar {
+			messages = append(messages, fmt.Sprintf("与「%s」近似（%.0f%%，%s）", m.Title, m.Similarity*100, m.Key))
+		}
+		row := []string{strconv.Itoa(p.Row), actionLabels[p.Action], strings.Join(messages, "；")}
+		for _, f := range fields {
+			row = append(row, p.Values[f.Key])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// NormalizeContentKey 规范化用于判重的文本：去除首尾空白、合并连续空白、英文转小写
+func NormalizeContentKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// ===== 各类型的行构建与校验 =====
+
+func requireFields(values map[string]string, keys ...string) []ImportFieldError {
+	var errs []ImportFieldError
+	for _, key := range keys {
+		if values[key] == "" {
+			errs = append(errs, ImportFieldError{Field: key, Message: "必填"})
+		}
+	}
+	return errs
+}
+
+// ParseLevel 解析绕口令难度，支持英文枚举与中文写法
+func ParseLevel(s string) (string, bool) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	for _, level := range TongueTwisterLevels {
+		if v == level {
+			return level, true
+		}
+	}
+	level, ok := levelAliases[v]
+	return level, ok
+}
+
+// ParseContentDate 解析发布日期，支持 YYYY-MM-DD、YYYY/MM/DD 及 RFC3339
+func ParseContentDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02", "2006/01/02", "2006-1-2", "2006/1/2"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+	}
+	return time.Time{}, false
+}
+
+// parseBoolCell 解析布尔值，空值使用默认值
+func parseBoolCell(s string, def bool) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return def, true
+	case "1", "true", "yes", "y", "是", "启用":
+		return true, true
+	case "0", "false", "no", "n", "否", "禁用":
+		return false, true
+	}
+	return false, false
+}
+
+func parseIntCell(s string) (int, bool) {
+	if s == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		// XLSX 数字单元格可能是 "3.0"
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || f != float64(int(f)) {
+			return 0, false
+		}
+		n = int(f)
+	}
+	return n, true
+}
+
+// ParseStringList 将 JSON 数组或按换行、| 分隔的文本转换为 JSON 数组字符串
+func ParseStringList(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "[]", true
+	}
+	if strings.HasPrefix(s, "[") {
+		var items []string
+		if err := json.Unmarshal([]byte(s), &items); err != nil {
+			return "", false
+		}
+		return string(mustMarshal(items)), true
+	}
+	items := make([]string, 0)
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '|' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return string(mustMarshal(items)), true
+}
+
+// pickColumns 只保留文件中提供的字段，用于 upsert 时的部分更新
+func pickColumns(all map[string]interface{}, provided map[string]bool) map[string]interface{} {
+	columns := make(map[string]interface{})
+	for key, value := range all {
+		if provided[key] {
+			columns[key] = value
+		}
+	}
+	return columns
+}
+
+func buildTongueTwister(values map[string]string, provided map[string]bool) (interface{}, map[string]interface{}, []ImportFieldError) {
+	errs := requireFields(values, "title", "content")
+	if len([]rune(values["title"])) > 200 {
+		errs = append(errs, ImportFieldError{Field: "title", Message: "不能超过 200 个字符"})
+	}
+	level, ok := ParseLevel(values["level"])
+	if values["level"] != "" && !ok {
+		errs = append(errs, ImportFieldError{Field: "level", Message: "应为 basic、intermediate 或 advanced"})
+	}
+	order, ok := parseIntCell(values["order"])
+	if !ok {
+		errs = append(errs, ImportFieldError{Field: "order", Message: "应为整数"})
+	}
+	active, ok := parseBoolCell(values["is_active"], true)
+	if !ok {
+		errs = append(errs, ImportFieldError{Field: "is_active", Message: "应为 true/false 或 是/否"})
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	// 难度留空时使用拼音分析给出的建议级别
+	analysis := AnalyzeDifficulty(values["content"])
+	if level == "" {
+		level = analysis.SuggestedLevel
+	}
+
+	model := &models.TongueTwister{
+		Title:           values["title"],
+		Content:         values["content"],
+		Tips:            values["tips"],
+		Level:           level,
+		Order:           order,
+		IsActive:        active,
+		DifficultyScore: analysis.Score,
+	}
+	columns := pickColumns(map[string]interface{}{
+		"title":     model.Title,
+		"content":   model.Content,
+		"tips":      model.Tips,
+		"level":     model.Level,
+		"order":     model.Order,
+		"is_active": model.IsActive,
+	}, provided)
+	if provided["content"] {
+		columns["difficulty_score"] = model.DifficultyScore
+	}
+	return model, columns, nil
+}
+
+func buildDailyExpression(values map[string]string, provided map[string]bool) (interface{}, map[string]interface{}, []ImportFieldError) {
+	errs := requireFields(values, "title", "content")
+	if len([]rune(values["title"])) > 200 {
+		errs = append(errs, ImportFieldError{Field: "title", Message: "不能超过 200 个字符"})
+	}
+	if len([]rune(values["source"])) > 100 {
+		errs = append(errs, ImportFieldError{Field: "source", Message: "不能超过 100 个字符"})
+	}
+	// 日期留空的文案进入待排期池，由自动排期分配日期
+	var date *time.Time
+	if values["date"] != "" {
+		d, ok := ParseContentDate(values["date"])
+		if !ok {
+			errs = append(errs, ImportFieldError{Field: "date", Message: "日期格式应为 YYYY-MM-DD"})
+		}
+		date = &d
+	}
+	active, ok := parseBoolCell(values["is_active"], true)
+	if !ok {
+		errs = append(errs, ImportFieldError{Field: "is_active", Message: "应为 true/false 或 是/否"})
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	model := &models.DailyExpression{
+		Title:    values["title"],
+		Content:  values["content"],
+		Tips:     values["tips"],
+		Source:   values["source"],
+		Date:     date,
+		IsActive: active,
+	}
+	return model, pickColumns(map[string]interface{}{
+		"title":     model.Title,
+		"content":   model.Content,
+		"tips":      model.Tips,
+		"source":    model.Source,
+		"date":      model.Date,
+		"is_active": model.IsActive,
+	}, provided), nil
+}
+
+func buildSpeechTechnique(values map[string]string, provided map[string]bool) (interface{}, map[string]interface{}, []ImportFieldError) {
+	errs := requireFields(values, "name")
+	tips, ok := ParseStringList(values["tips"])
+	if !ok {
+		errs = append(errs, ImportFieldError{Field: "tips", Message: "JSON 数组格式错误"})
+	}
+	texts, ok := ParseStringList(values["practice_texts"])
+	if !ok {
+		errs = append(errs, ImportFieldError{Field: "practice_texts", Message: "JSON 数组格式错误"})
+	}
+	order, ok := parseIntCell(values["order"])
+	if !ok {
+		errs = append(errs, ImportFieldError{Field: "order", Message: "应为整数"})
+	}
+	active, ok := parseBoolCell(values["is_active"], true)
+	if !ok {
+		errs = append(errs, ImportFieldError{Field: "is_active", Message: "应为 true/false 或 是/否"})
+	}
+	if len([]rune(values["name"])) > 100 {
+		errs = append(errs, ImportFieldError{Field: "name", Message: "不能超过 100 个字符"})
+	}
+	if len([]rune(values["description"])) > 200 {
+		errs = append(errs, ImportFieldError{Field: "description", Message: "不能超过 200 个字符"})
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	model := &models.SpeechTechnique{
+		Name:          values["name"],
+		Icon:          values["icon"],
+		Description:   values["description"],
+		Tips:          tips,
+		PracticeTexts: texts,
+		Order:         order,
+		IsActive:      active,
+	}
+	return model, pickColumns(map[string]interface{}{
+		"name":           model.Name,
+		"icon":           model.Icon,
+		"description":    model.Description,
+		"tips":           model.Tips,
+		"practice_texts": model.PracticeTexts,
+		"order":          model.Order,
+		"is_active":      model.IsActive,
+	}, provided), nil
+}
+
+// writeSpeechItems 把新建技巧的训练要点与练习文本写为条目（只处理文件中提供的列）；
+// 覆盖已有技巧时两列作为版本字段写入，由版本发布同步条目
+func writeSpeechItems(tx *gorm.DB, model interface{}, existing *uuid.UUID, values map[string]string) error {
+	if existing != nil {
+		return nil
+	}
+	id := model.(*models.SpeechTechnique).ID
+	items := NewSpeechItemService(tx)
+	for _, kind := range []string{SpeechItemTips, SpeechItemPracticeTexts} {
+		raw, provided := values[kind]
+		if !provided {
+			continue
+		}
+		list, _ := ParseStringList(raw)
+		texts, err := StrictStringList(list)
+		if err != nil {
+			return err
+		}
+		if err := items.Replace(tx, id, kind, texts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/signedurl"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 媒体签名链接的参数；签名同时覆盖管理员与视频，审计日志据此记录是谁看了哪个视频
+const (
+	mediaParamKey   = "k" // 存储 key
+	mediaParamAdmin = "a"
+	mediaParamVideo = "v" // 视频来源:来源记录ID
+)
+
+// ErrMediaNotStored 签名链接指向的不是本存储中的对象（如旧版本签发的外部地址链接），代理不替管理员跳转到外部地址
+var ErrMediaNotStored = errors.New("媒体不在存储中")
+
+// MediaTarget 校验通过的签名链接指向的媒体
+type MediaTarget struct {
+	Key         string // 本存储中的对象，通过代理读取
+	AdminID     uuid.UUID
+	VideoSource string
+	VideoID     *uuid.UUID
+}
+
+// MediaAccessFilter 播放审计日志的筛选条件，Start/End 为半开区间
+type MediaAccessFilter struct {
+	AdminID *uuid.UUID
+	OwnerID *uuid.UUID
+	VideoID *uuid.UUID
+	Start   *time.Time
+	End     *time.Time
+}
+
+// MediaAccessLogRow 播放审计日志及管理员、视频上传者的用户名
+type MediaAccessLogRow struct {
+	models.MediaAccessLog
+	AdminName string `json:"admin_name"`
+	OwnerName string `json:"owner_name"`
+}
+
+// SignURL 为管理员生成带过期时间的媒体访问链接，proxyURL 为代理接口地址；
+// 未配置签名器、地址为空或不在本存储中（外链）时返回空串
+func (s *MediaService) SignURL(proxyURL, rawURL string, adminID uuid.UUID, videoSource string, videoID uuid.UUID) string {
+	if s.signer == nil || rawURL == "" {
+		return ""
+	}
+	key, ok := s.Key(rawURL)
+	if !ok {
+		return ""
+	}
+	params := url.Values{}
+	params.Set(mediaParamKey, key)
+	params.Set(mediaParamAdmin, adminID.String())
+	if videoSource != "" && videoID != uuid.Nil {
+		params.Set(mediaParamVideo, videoSource+":"+videoID.String())
+	}
+	return proxyURL + "?" + s.signer.Sign(params, time.Now())
+}
+
+// VerifyURL 校验签名链接的参数，返回 signedurl.ErrInvalidSignature、signedurl.ErrExpired；
+// 签名有效但不指向本存储中的对象时返回 ErrMediaNotStored
+func (s *MediaService) VerifyURL(params url.Values) (*MediaTarget, error) {
+	if s.signer == nil {
+		return nil, signedurl.ErrInvalidSignature
+	}
+	if err := s.signer.Verify(params, time.Now()); err != nil {
+		return nil, err
+	}
+	adminID, err := uuid.Parse(params.Get(mediaParamAdmin))
+	if err != nil {
+		return nil, signedurl.ErrInvalidSignature
+	}
+	target := &MediaTarget{Key: params.Get(mediaParamKey), AdminID: adminID}
+	if target.Key == "" {
+		return nil, ErrMediaNotStored
+	}
+	if source, id, ok := strings.Cut(params.Get(mediaParamVideo), ":"); ok {
+		if videoID, err := uuid.Parse(id); err == nil {
+			target.VideoSource = source
+			target.VideoID = &videoID
+		}
+	}
+	return target, nil
+}
+
+// LogAccess 记录一次播放，视频上传者从视频索引中查找
+func (s *MediaService) LogAccess(target *MediaTarget, ip, userAgent string) error {
+	entry := &models.MediaAccessLog{
+		AdminID:     target.AdminID,
+		VideoSource: target.VideoSource,
+		VideoID:     target.VideoID,
+		Target:      target.Key,
+		IP:          ip,
+		UserAgent:   truncateRunes(userAgent, 255),
+	}
+	if target.VideoID != nil {
+		var owners []uuid.UUID
+		err := s.db.Model(&models.VideoAsset{}).
+			Where("source = ? AND source_id = ?", target.VideoSource, *target.VideoID).
+			Limit(1).Pluck("user_id", &owners).Error
+		if err != nil {
+			return err
+		}
+		if len(owners) > 0 {
+			entry.OwnerID = &owners[0]
+		}
+	}
+	return s.db.Create(entry).Error
+}
+
+// AccessLogs 按筛选条件分页查询播放审计日志，按时间倒序
+func (s *MediaService) AccessLogs(f MediaAccessFilter, page, pageSize int) ([]MediaAccessLogRow, int64, error) {
+	apply := func(query *gorm.DB) *gorm.DB {
+		if f.AdminID != nil {
+			query = query.Where("l.admin_id = ?", *f.AdminID)
+		}
+		if f.OwnerID != nil {
+			query = query.Where("l.owner_id = ?", *f.OwnerID)
+		}
+		if f.VideoID != nil {
+			query = query.Where("l.video_id = ?", *f.VideoID)
+		}
+		if f.Start != nil {
+			query = query.Where("l.created_at >= ?", *f.Start)
+		}
+		if f.End != nil {
+			query = query.Where("l.created_at < ?", *f.End)
+		}
+		return query
+	}
+
+	var total int64
+	if err := apply(s.db.Table("media_access_logs l")).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	rows := make([]MediaAccessLogRow, 0)
+	err := apply(s.db.Table("media_access_logs l")).
+		Select("l.*, COALESCE(a.username, '') AS admin_name, COALESCE(o.username, '') AS owner_name").
+		Joins("LEFT JOIN users a ON a.id = l.admin_id").
+		Joins("LEFT JOIN users o ON o.id = l.owner_id").
+		Order("l.created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// truncateRunes 按字符截断，对应 varchar(n) 的长度限制
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"fluent-life-admin-api/pkg/signedurl"
+
+	"github.com/google/uuid"
+)
+
+func TestMediaSignAndVerifyURL(t *testing.T) {
+	signer := signedurl.New("secret", time.Minute)
+	s := NewMediaService(nil, nil, "https://cdn.example.com/media", signer, "")
+	adminID := uuid.New()
+	postID := uuid.New()
+	const proxy = "https://admin.example.com/api/v1/media"
+
+	verify := func(link string) (*MediaTarget, error) {
+		t.Helper()
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.VerifyURL(u.Query())
+	}
+
+	link := s.SignURL(proxy, "https://cdn.example.com/media/posts/a.mp4", adminID, VideoSourcePost, postID)
+	if !strings.HasPrefix(link, proxy+"?") {
+		t.Fatalf("SignURL = %q", link)
+	}
+	target, err := verify(link)
+	if err != nil {
+		t.Fatalf("VerifyURL: %v", err)
+	}
+	if target.Key != "posts/a.mp4" || target.AdminID != adminID || target.VideoSource != VideoSourcePost || target.VideoID == nil || *target.VideoID != postID {
+		t.Errorf("target = %+v", target)
+	}
+
+	// 不属于任何视频的媒体不带来源参数
+	target, err = verify(s.SignURL(proxy, "https://cdn.example.com/media/avatars/u.png", adminID, "", uuid.Nil))
+	if err != nil || target.Key != "avatars/u.png" || target.VideoID != nil {
+		t.Errorf("target = %+v, err = %v", target, err)
+	}
+
+	for _, raw := range []string{"", "https://evil.example.com/a.mp4", "https://cdn.example.com/other/a.mp4"} {
+		if link := s.SignURL(proxy, raw, adminID, VideoSourcePost, postID); link != "" {
+			t.Errorf("SignURL(%q) = %q, want empty for media outside storage", raw, link)
+		}
+	}
+
+	// 旧版本为外部地址签发的链接：签名有效也不跳转
+	legacy := url.Values{"u": {"https://evil.example.com/a.mp4"}, "a": {adminID.String()}}
+	query, _ := url.ParseQuery(signer.Sign(legacy, time.Now()))
+	if _, err := s.VerifyURL(query); !errors.Is(err, ErrMediaNotStored) {
+		t.Errorf("legacy external link error = %v, want ErrMediaNotStored", err)
+	}
+
+	// 篡改的链接
+	tampered := strings.Replace(link, "posts%2Fa.mp4", "posts%2Fb.mp4", 1)
+	if _, err := verify(tampered); !errors.Is(err, signedurl.ErrInvalidSignature) {
+		t.Errorf("tampered link error = %v, want ErrInvalidSignature", err)
+	}
+
+	// 未配置签名器时不签发也不接受链接
+	unsigned := NewMediaService(nil, nil, "https://cdn.example.com/media", nil, "")
+	if link := unsigned.SignURL(proxy, "https://cdn.example.com/media/a.mp4", adminID, "", uuid.Nil); link != "" {
+		t.Errorf("SignURL without signer = %q", link)
+	}
+	if _, err := unsigned.VerifyURL(query); !errors.Is(err, signedurl.ErrInvalidSignature) {
+		t.Errorf("VerifyURL without signer = %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/pkg/signedurl"
+	"fluent-life-admin-api/pkg/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 媒体引用来源；头像不属于清理范围，但同样视为引用，避免被当作无引用对象删除
+const (
+	MediaSourceAvatar         = "avatar"
+	MediaSourceVoicePreview   = "voice_preview"   // 音色试听音频，不属于任何用户
+	MediaSourceSpeechPractice = "speech_practice" // 语音技巧练习文本的示范音频，不属于任何用户
+	MediaSourceUnreferenced   = "unreferenced"
+)
+
+const (
+	maxGCSamples = 100
+	maxGCErrors  = 20
+)
+
+// mediaRef 引用某个存储对象的记录
+type mediaRef struct {
+	Source string
+	UserID uuid.UUID
+}
+
+// mediaColumn 以完整地址保存媒体的列；新增保存媒体地址的列时需加入 mediaColumns，否则 GC 会把其中的文件当作无引用对象删除
+type mediaColumn struct {
+	source string
+	table  string
+	userID string // 所属用户的列，不属于任何用户时为 NULL::uuid
+	url    string // 地址的取值表达式
+	match  string // 按地址查找引用的条件，为空时为 url = ?
+}
+
+var mediaColumns = []mediaColumn{
+	{source: VideoSourcePost, table: "posts", userID: "user_id", url: "image"},
+	// 使用 data 上的 GIN 索引
+	{source: VideoSourceExposure, table: "training_records", userID: "user_id", url: "data->>'video_url'", match: "data @> jsonb_build_object('video_url', ?::text)"},
+	{source: MediaSourceAvatar, table: "users", userID: "id", url: "avatar_url"},
+	{source: MediaSourceVoicePreview, table: "voice_types", userID: "NULL::uuid", url: "preview_url"},
+	{source: MediaSourceSpeechPractice, table: "speech_practice_texts", userID: "NULL::uuid", url: "audio_url"},
+}
+
+// MediaUsage 一组对象的数量与字节数
+type MediaUsage struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// MediaSourceUsage 按来源统计的存储用量
+type MediaSourceUsage struct {
+	Source string `json:"source"` // exposure_module, community_post, avatar, voice_preview, speech_practice, unreferenced
+	MediaUsage
+}
+
+// MediaUserUsage 按用户统计的存储用量
+type MediaUserUsage struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	MediaUsage
+}
+
+// MediaUsageReport 存储用量报告；对象被多条记录引用时只计入第一条引用
+type MediaUsageReport struct {
+	Prefix   string             `json:"prefix"`
+	Total    MediaUsage         `json:"total"`
+	BySource []MediaSourceUsage `json:"by_source"`
+	ByUser   []MediaUserUsage   `json:"by_user"` // 按字节数倒序的前 top 个用户
+	Users    int                `json:"users"`   // 有存储用量的用户数
+	Missing  int                `json:"missing"` // 记录中引用但存储中不存在的对象数
+}
+
+// MediaGCReport 无引用对象清理结果
+type MediaGCReport struct {
+	DryRun      bool                 `json:"dry_run"`
+	Prefix      string               `json:"prefix"`
+	MinAge      string               `json:"min_age"`
+	Scanned     int64                `json:"scanned"`
+	Orphans     MediaUsage           `json:"orphans"`
+	Deleted     int64                `json:"deleted"`
+	Samples     []storage.ObjectInfo `json:"samples"` // 最多 100 个无引用对象
+	Errors      []string             `json:"errors"`
+	StartedAt   time.Time            `json:"started_at"`
+	CompletedAt time.Time            `json:"completed_at"`
+}
+
+// MediaService 通过存储接口管理帖子、训练记录引用的媒体文件，并签发管理端播放用的签名链接
+type MediaService struct {
+	db            *gorm.DB
+	store         storage.Storage
+	publicBaseURL string
+	signer        *signedurl.Signer
+	proxyURL      string
+}
+
+// NewMediaService signer 为 nil 时不签发播放链接（命令行工具）；proxyURL 为空时由调用方按请求生成代理地址
+func NewMediaService(db *gorm.DB, store storage.Storage, publicBaseURL string, signer *signedurl.Signer, proxyURL string) *MediaService {
+	return &MediaService{db: db, store: store, publicBaseURL: publicBaseURL, signer: signer, proxyURL: proxyURL}
+}
+
+// ProxyURL 配置的媒体代理接口地址
+func (s *MediaService) ProxyURL() string {
+	return s.proxyURL
+}
+
+// Storage 底层存储
+func (s *MediaService) Storage() storage.Storage {
+	return s.store
+}
+
+// Key 媒体地址对应的存储 key，外链等非本存储的地址返回 false
+func (s *MediaService) Key(rawURL string) (string, bool) {
+	return storage.KeyFromURL(s.publicBaseURL, rawURL)
+}
+
+// URL 存储 key 对应的媒体地址
+func (s *MediaService) URL(key string) string {
+	return storage.URLForKey(s.publicBaseURL, key)
+}
+
+// referenced 地址是否仍被 mediaColumns 中的任何记录引用
+func (s *MediaService) referenced(rawURL string) (bool, error) {
+	for _, col := range mediaColumns {
+		match := col.match
+		if match == "" {
+			match = col.url + " = ?"
+		}
+		var count int64
+		if err := s.db.Table(col.table).Where(match, rawURL).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RemoveURLs 删除已不再被引用的媒体对象，应在删除或清空数据库引用之后调用；
+// 删除失败只记录日志，残留对象由 GC 清理
+func (s *MediaService) RemoveURLs(ctx context.Context, urls []string) int {
+	removed := 0
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		key, ok := s.Key(u)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		inUse, err := s.referenced(u)
+		if err != nil {
+			log.Printf("[media] 检查 %s 的引用失败: %v", u, err)
+			continue
+		}
+		if inUse {
+			continue
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("[media] 删除对象 %s 失败: %v", key, err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// references 收集 mediaColumns 中的全部媒体引用：帖子 image、训练记录 data.video_url、用户头像、音色试听与练习示范音频
+func (s *MediaService) references() (map[string][]mediaRef, error) {
+	refs := make(map[string][]mediaRef)
+	for _, col := range mediaColumns {
+		rows, err := s.db.Table(col.table).
+			Select(col.userID + " AS user_id, " + col.url + " AS url").
+			Where("COALESCE(" + col.url + ", '') <> ''").
+			Rows()
+		if err != nil {
+			return nil, fmt.Errorf("查询媒体引用失败: %w", err)
+		}
+		for rows.Next() {
+			var userID uuid.UUID
+			var url string
+			if err := rows.Scan(&userID, &url); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if key, ok := s.Key(url); ok {
+				refs[key] = append(refs[key], mediaRef{Source: col.source, UserID: userID})
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+// GC 删除 prefix 下没有任何记录引用、且创建超过 minAge 的对象；minAge 为刚上传尚未写入记录的文件留出时间
+func (s *MediaService) GC(ctx context.Context, prefix string, minAge time.Duration, dryRun bool) (*MediaGCReport, error) {
+	refs, err := s.references()
+	if err != nil {
+		return nil, err
+	}
+	return s.sweep(ctx, refs, prefix, minAge, dryRun, time.Now())
+}
+
+// sweep 按已收集的引用清理 prefix 下的无引用对象
+func (s *MediaService) sweep(ctx context.Context, refs map[string][]mediaRef, prefix string, minAge time.Duration, dryRun bool, now time.Time) (*MediaGCReport, error) {
+	report := &MediaGCReport{
+		DryRun:    dryRun,
+		Prefix:    prefix,
+		MinAge:    minAge.String(),
+		Samples:   make([]storage.ObjectInfo, 0),
+		Errors:    make([]string, 0),
+		StartedAt: now,
+	}
+
+	cutoff := report.StartedAt.Add(-minAge)
+	var orphans []storage.ObjectInfo
+	err := s.store.List(ctx, prefix, func(obj storage.ObjectInfo) error {
+		report.Scanned++
+		if _, ok := refs[obj.Key]; ok || obj.LastModified.After(cutoff) {
+			return nil
+		}
+		report.Orphans.Objects++
+		report.Orphans.Bytes += obj.Size
+		if len(report.Samples) < maxGCSamples {
+			report.Samples = append(report.Samples, obj)
+		}
+		orphans = append(orphans, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("遍历存储失败: %w", err)
+	}
+
+	if !dryRun {
+		for _, obj := range orphans {
+			if err := s.store.Delete(ctx, obj.Key); err != nil {
+				if len(report.Errors) < maxGCErrors {
+					report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", obj.Key, err))
+				}
+				continue
+			}
+			report.Deleted++
+		}
+	}
+	report.CompletedAt = time.Now()
+	return report, nil
+}
+
+// Usage 统计 prefix 下对象的存储用量，按来源与用户汇总
+func (s *MediaService) Usage(ctx context.Context, prefix string, top int) (*MediaUsageReport, error) {
+	refs, err := s.references()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MediaUsageReport{Prefix: prefix}
+	bySource := make(map[string]*MediaUsage)
+	byUser := make(map[uuid.UUID]*MediaUsage)
+	found := make(map[string]bool)
+	add := func(m map[string]*MediaUsage, key string, size int64) {
+		if m[key] == nil {
+			m[key] = &MediaUsage{}
+		}
+		m[key].Objects++
+		m[key].Bytes += size
+	}
+	err = s.store.List(ctx, prefix, func(obj storage.ObjectInfo) error {
+		report.Total.Objects++
+		report.Total.Bytes += obj.Size
+		r, ok := refs[obj.Key]
+		if !ok {
+			add(bySource, MediaSourceUnreferenced, obj.Size)
+			return nil
+		}
+		found[obj.Key] = true
+		add(bySource, r[0].Source, obj.Size)
+		if r[0].UserID == uuid.Nil {
+			return nil
+		}
+		if byUser[r[0].UserID] == nil {
+			byUser[r[0].UserID] = &MediaUsage{}
+		}
+		byUser[r[0].UserID].Objects++
+		byUser[r[0].UserID].Bytes += obj.Size
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("遍历存储失败: %w", err)
+	}
+	for key := range refs {
+		if !found[key] && strings.HasPrefix(key, prefix) {
+			report.Missing++
+		}
+	}
+
+	report.BySource = make([]MediaSourceUsage, 0, len(bySource))
+	for source, usage := range bySource {
+		report.BySource = append(report.BySource, MediaSourceUsage{Source: source, MediaUsage: *usage})
+	}
+	sort.Slice(report.BySource, func(i, j int) bool { return report.BySource[i].Bytes > report.BySource[j].Bytes })
+
+	report.Users = len(byUser)
+	report.ByUser = make([]MediaUserUsage, 0, len(byUser))
+	for userID, usage := range byUser {
+		report.ByUser = append(report.ByUser, MediaUserUsage{UserID: userID, MediaUsage: *usage})
+	}
+	sort.Slice(report.ByUser, func(i, j int) bool {
+		if report.ByUser[i].Bytes != report.ByUser[j].Bytes {
+			return report.ByUser[i].Bytes > report.ByUser[j].Bytes
+		}
+		return report.ByUser[i].UserID.String() < report.ByUser[j].UserID.String()
+	})
+	if top > 0 && len(report.ByUser) > top {
+		report.ByUser = report.ByUser[:top]
+	}
+
+	ids := make([]uuid.UUID, 0, len(report.ByUser))
+	for _, u := range report.ByUser {
+		ids = append(ids, u.UserID)
+	}
+	if len(ids) > 0 {
+		var users []struct {
+			ID       uuid.UUID
+			Username string
+		}
+		if err := s.db.Table("users").Select("id, username").Where("id IN ?", ids).Scan(&users).Error; err != nil {
+			return nil, err
+		}
+		names := make(map[uuid.UUID]string, len(users))
+		for _, u := range users {
+			names[u.ID] = u.Username
+		}
+		for i := range report.ByUser {
+			report.ByUser[i].Username = names[report.ByUser[i].UserID]
+		}
+	}
+	return report, nil
+}
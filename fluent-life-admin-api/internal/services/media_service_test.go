@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"fluent-life-admin-api/pkg/storage"
+	"fluent-life-admin-api/pkg/storage/storagetest"
+)
+
+func TestMediaColumnsCoverAllMediaURLs(t *testing.T) {
+	want := map[string]string{
+		"posts":                 "image",
+		"training_records":      "data->>'video_url'",
+		"users":                 "avatar_url",
+		"voice_types":           "preview_url",
+		"speech_practice_texts": "audio_url",
+	}
+	got := make(map[string]string)
+	for _, col := range mediaColumns {
+		got[col.table] = col.url
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mediaColumns = %v, want %v", got, want)
+	}
+}
+
+// gcBackend 一种存储后端及修改对象时间的方式
+type gcBackend struct {
+	name  string
+	open  func(t *testing.T) storage.Storage
+	touch func(t *testing.T, key string, at time.Time)
+}
+
+func gcBackends() []gcBackend {
+	var root string
+	var srv *storagetest.S3Server
+	return []gcBackend{
+		{
+			name: "local",
+			open: func(t *testing.T) storage.Storage {
+				root = t.TempDir()
+				l, err := storage.NewLocal(root)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return l
+			},
+			touch: func(t *testing.T, key string, at time.Time) {
+				if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), at, at); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "s3",
+			open: func(t *testing.T) storage.Storage {
+				srv = storagetest.NewS3Server("media")
+				t.Cleanup(srv.Close)
+				srv.PageSize = 2
+				s, err := storage.NewS3(srv.Config())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := s.EnsureBucket(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+				return s
+			},
+			touch: func(t *testing.T, key string, at time.Time) {
+				if !srv.Touch(key, at) {
+					t.Fatalf("object %s not found", key)
+				}
+			},
+		},
+	}
+}
+
+func TestMediaSweep(t *testing.T) {
+	now := time.Now()
+	objects := []struct {
+		key string
+		age time.Duration
+	}{
+		{"videos/referenced.mp4", 48 * time.Hour},
+		{"videos/practice.mp3", 48 * time.Hour},
+		{"videos/orphan-old.mp4", 48 * time.Hour},
+		{"videos/orphan-new.mp4", time.Minute},
+		{"images/orphan.png", 48 * time.Hour},
+	}
+	refs := map[string][]mediaRef{
+		"videos/referenced.mp4": {{Source: VideoSourcePost}},
+		"videos/practice.mp3":   {{Source: MediaSourceSpeechPractice}},
+	}
+
+	for _, backend := range gcBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			for _, obj := range objects {
+				if err := store.Put(ctx, obj.key, strings.NewReader("data"), 4, ""); err != nil {
+					t.Fatal(err)
+				}
+				backend.touch(t, obj.key, now.Add(-obj.age))
+			}
+			s := &MediaService{store: store}
+
+			report, err := s.sweep(ctx, refs, "videos/", 24*time.Hour, true, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Scanned != 4 || report.Orphans != (MediaUsage{Objects: 1, Bytes: 4}) || report.Deleted != 0 {
+				t.Errorf("dry run report = %+v", report)
+			}
+			if len(report.Samples) != 1 || report.Samples[0].Key != "videos/orphan-old.mp4" {
+				t.Errorf("dry run samples = %+v", report.Samples)
+			}
+			if got := listAll(t, store); len(got) != len(objects) {
+				t.Fatalf("dry run deleted objects: %v", got)
+			}
+
+			report, err = s.sweep(ctx, refs, "videos/", 24*time.Hour, false, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Deleted != 1 || len(report.Errors) != 0 {
+				t.Errorf("report = %+v", report)
+			}
+			want := []string{"images/orphan.png", "videos/orphan-new.mp4", "videos/practice.mp3", "videos/referenced.mp4"}
+			if got := listAll(t, store); !reflect.DeepEqual(got, want) {
+				t.Errorf("remaining objects = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func listAll(t *testing.T, store storage.Storage) []string {
+	t.Helper()
+	var keys []string
+	if err := store.List(context.Background(), "", func(obj storage.ObjectInfo) error {
+		keys = append(keys, obj.Key)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrNotificationNotFound = errors.New("通知不存在")
+
+// NotificationService 用户站内通知
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// List 用户的通知，按时间倒序；同时返回未读数
+func (s *NotificationService) List(userID uuid.UUID, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, int64, error) {
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	var total, unread int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, 0, err
+	}
+	if err := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread).Error; err != nil {
+		return nil, 0, 0, err
+	}
+	notifications := make([]models.Notification, 0)
+	err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&notifications).Error
+	return notifications, total, unread, err
+}
+
+// MarkRead 把用户的一条通知标记为已读，id 为 nil 时标记全部
+func (s *NotificationService) MarkRead(userID uuid.UUID, id *uuid.UUID) (int64, error) {
+	query := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if id != nil {
+		var count int64
+		if err := s.db.Model(&models.Notification{}).Where("id = ? AND user_id = ?", *id, userID).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		if count == 0 {
+			return 0, ErrNotificationNotFound
+		}
+		query = query.Where("id = ?", *id)
+	}
+	result := query.Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"log"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/cron"
+	"fluent-life-admin-api/pkg/mailer"
+
+	"gorm.io/gorm"
+)
+
+// 报表执行触发方式与状态
+const (
+	ReportTriggerSchedule = "schedule"
+	ReportTriggerManual   = "manual"
+
+	ReportStatusRunning = "running"
+	ReportStatusSuccess = "success"
+	ReportStatusFailed  = "failed"
+)
+
+// MetricLabels 指标的中文名称，用于报表展示
+var MetricLabels = map[string]string{
+	MetricNewUsers:        "新增用户",
+	MetricActiveUsers:     "活跃用户",
+	MetricTrainingMinutes: "训练时长（分钟）",
+	MetricPosts:           "新增帖子",
+	MetricComments:        "新增评论",
+	MetricAIConversations: "AI对话",
+}
+
+// ReportService 生成并发送定时报表
+type ReportService struct {
+	db        *gorm.DB
+	analytics *AnalyticsService
+	mailer    *mailer.Mailer
+}
+
+// NewReportService 创建报表服务
+func NewReportService(db *gorm.DB, m *mailer.Mailer) *ReportService {
+	return &ReportService{db: db, analytics: NewAnalyticsService(db), mailer: m}
+}
+
+// ValidateSchedule 校验报表配置，并规范化指标与收件人列表
+func ValidateSchedule(schedule *models.ReportSchedule) error {
+	if strings.TrimSpace(schedule.Name) == "" {
+		return fmt.Errorf("报表名称不能为空")
+	}
+	if _, err := cron.Parse(schedule.CronExpr); err != nil {
+		return fmt.Errorf("cron 表达式无效: %w", err)
+	}
+
+	metrics := splitList(schedule.Metrics)
+	if len(metrics) == 0 {
+		return fmt.Errorf("至少选择一个指标")
+	}
+	for _, metric := range metrics {
+		if !IsKnownMetric(metric) {
+			return fmt.Errorf("不支持的指标: %s", metric)
+		}
+	}
+	schedule.Metrics = strings.Join(metrics, ",")
+
+	recipients := splitList(schedule.Recipients)
+	if len(recipients) == 0 {
+		return fmt.Errorf("收件人不能为空")
+	}
+	for _, addr := range recipients {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("收件人邮箱格式错误: %s", addr)
+		}
+	}
+	schedule.Recipients = strings.Join(recipients, ",")
+
+	g, err := ParseGranularity(schedule.Granularity)
+	if err != nil {
+		return err
+	}
+	schedule.Granularity = string(g)
+	if schedule.RangeDays == 0 {
+		schedule.RangeDays = 7
+	}
+	if schedule.RangeDays < 1 || schedule.RangeDays > 366 {
+		return fmt.Errorf("统计天数应在 1-366 之间")
+	}
+	return nil
+}
+
+// NextRunTime 按北京时间计算 cron 表达式在 after 之后的下一次触发时间
+func NextRunTime(expr string, after time.Time) (*time.Time, error) {
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	next := schedule.Next(after.In(ReportLocation))
+	if next.IsZero() {
+		return nil, fmt.Errorf("cron 表达式在未来五年内不会触发")
+	}
+	return &next, nil
+}
+
+// Run 生成报表并发送，执行结果写入 report_runs
+func (s *ReportService) Run(schedule *models.ReportSchedule, trigger string) (*models.ReportRun, error) {
+	end := StartOfDay(time.Now())
+	r := DateRange{Start: end.AddDate(0, 0, -schedule.RangeDays), End: end}
+
+	run := &models.ReportRun{
+		ScheduleID: schedule.ID,
+		Trigger:    trigger,
+		Status:     ReportStatusRunning,
+		StartDate:  r.Start.Format("2006-01-02"),
+		EndDate:    r.LastDay().Format("2006-01-02"),
+		Recipients: schedule.Recipients,
+		StartedAt:  time.Now(),
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("创建执行记录失败: %w", err)
+	}
+
+	runErr := s.generateAndSend(schedule, r)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = ReportStatusSuccess
+	if runErr != nil {
+		run.Status = ReportStatusFailed
+		run.Error = runErr.Error()
+	}
+	s.db.Model(run).Updates(map[string]interface{}{
+		"status":      run.Status,
+		"error":       run.Error,
+		"finished_at": run.FinishedAt,
+	})
+	s.db.Model(schedule).UpdateColumn("last_run_at", finished)
+	return run, runErr
+}
+
+func (s *ReportService) generateAndSend(schedule *models.ReportSchedule, r DateRange) error {
+	g, err := ParseGranularity(schedule.Granularity)
+	if err != nil {
+		return err
+	}
+	result, err := s.analytics.TimeSeries(r, g, splitList(schedule.Metrics))
+	if err != nil {
+		return fmt.Errorf("查询统计数据失败: %w", err)
+	}
+
+	msg, err := buildReportMessage(schedule, result)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(*msg)
+}
+
+// buildReportMessage 组装报表邮件：HTML 正文与 CSV 附件
+func buildReportMessage(schedule *models.ReportSchedule, result *TimeSeriesResult) (*mailer.Message, error) {
+	html, err := renderReportHTML(schedule, result)
+	if err != nil {
+		return nil, fmt.Errorf("渲染报表失败: %w", err)
+	}
+	csvData, err := renderReportCSV(result)
+	if err != nil {
+		return nil, fmt.Errorf("生成CSV失败: %w", err)
+	}
+
+	return &mailer.Message{
+		To:      splitList(schedule.Recipients),
+		Subject: fmt.Sprintf("%s（%s ~ %s）", schedule.Name, result.StartDate, result.EndDate),
+		HTML:    html,
+		Attachments: []mailer.Attachment{{
+			Filename:    fmt.Sprintf("report_%s_%s.csv", result.StartDate, result.EndDate),
+			ContentType: "text/csv; charset=utf-8",
+			Data:        csvData,
+		}},
+	}, nil
+}
+
+// RunScheduler 每分钟检查到期的报表并执行；通过条件更新 next_run_at 抢占，多实例部署时不会重复发送
+func (s *ReportService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		s.runDue(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ReportService) runDue(now time.Time) {
+	var schedules []models.ReportSchedule
+	if err := s.db.Where("enabled = ? AND (next_run_at IS NULL OR next_run_at <= ?)", true, now).Find(&schedules).Error; err != nil {
+		log.Printf("[report] 查询待执行报表失败: %v", err)
+		return
+	}
+	for i := range schedules {
+		schedule := &schedules[i]
+		next, err := NextRunTime(schedule.CronExpr, now)
+		if err != nil {
+			log.Printf("[report] 报表 %s 的 cron 表达式无效: %v", schedule.ID, err)
+			continue
+		}
+
+		claim := s.db.Model(&models.ReportSchedule{}).Where("id = ?", schedule.ID)
+		if schedule.NextRunAt == nil {
+			// 新建或刚启用的报表只计算下一次触发时间，不立即发送
+			claim.Where("next_run_at IS NULL").UpdateColumn("next_run_at", next)
+			continue
+		}
+		result := claim.Where("next_run_at = ?", *schedule.NextRunAt).UpdateColumn("next_run_at", next)
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue // 已被其他实例抢占
+		}
+		if _, err := s.Run(schedule, ReportTriggerSchedule); err != nil {
+			log.Printf("[report] 报表 %s 发送失败: %v", schedule.Name, err)
+		}
+	}
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family: -apple-system, 'PingFang SC', 'Microsoft YaHei', sans-serif; color: #333;">
+<h2>{{.Title}}</h2>
+<p>统计区间：{{.StartDate}} ~ {{.EndDate}}（{{.Timezone}}）</p>
+<h3>汇总</h3>
+<table cellpadding="6" cellspacing="0" border="1" style="border-collapse: collapse;">
+<tr style="background: #f5f5f5;"><th>指标</th><th>合计</th></tr>
+{{range .Totals}}<tr><td>{{.Label}}</td><td style="text-align: right;">{{.Value}}</td></tr>
+{{end}}</table>
+<h3>明细</h3>
+<table cellpadding="6" cellspacing="0" border="1" style="border-collapse: collapse;">
+<tr style="background: #f5f5f5;"><th>时间</th>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range $i, $v := .}}<td{{if $i}} style="text-align: right;"{{end}}>{{$v}}</td>{{end}}</tr>
+{{end}}</table>
+<p style="color: #999; font-size: 12px;">此邮件由流畅人生管理后台自动发送，明细数据见附件 CSV。</p>
+</body></html>`))
+
+type reportTotal struct {
+	Label string
+	Value string
+}
+
+// seriesLabel 返回序列的展示名称，带维度的序列追加维度值
+func seriesLabel(series Series) string {
+	label := MetricLabels[series.Metric]
+	if label == "" {
+		label = series.Metric
+	}
+	if series.Dimension != "" {
+		label += " - " + series.Dimension
+	}
+	return label
+}
+
+func formatMetricValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// reportTable 将时间序列转为 时间 × 序列 的表格
+func reportTable(result *TimeSeriesResult) ([]string, [][]string) {
+	headers := make([]string, 0, len(result.Series))
+	for _, series := range result.Series {
+		headers = append(headers, seriesLabel(series))
+	}
+	rows := make([][]string, 0, len(result.Buckets))
+	for i, bucket := range result.Buckets {
+		row := []string{bucket}
+		for _, series := range result.Series {
+			row = append(row, formatMetricValue(series.Values[i]))
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func renderReportHTML(schedule *models.ReportSchedule, result *TimeSeriesResult) (string, error) {
+	headers, rows := reportTable(result)
+	totals := make([]reportTotal, 0, len(result.Series))
+	for _, series := range result.Series {
+		totals = append(totals, reportTotal{Label: seriesLabel(series), Value: formatMetricValue(series.Total)})
+	}
+
+	var buf bytes.Buffer
+	err := reportTemplate.Execute(&buf, map[string]interface{}{
+		"Title":     schedule.Name,
+		"StartDate": result.StartDate,
+		"EndDate":   result.EndDate,
+		"Timezone":  result.Timezone,
+		"Totals":    totals,
+		"Headers":   headers,
+		"Rows":      rows,
+	})
+	return buf.String(), err
+}
+
+// renderReportCSV 输出带 UTF-8 BOM 的 CSV，便于 Excel 直接打开
+func renderReportCSV(result *TimeSeriesResult) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(&buf)
+
+	headers, rows := reportTable(result)
+	if err := writer.Write(append([]string{"时间"}, headers...)); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// splitList 解析逗号分隔的列表，忽略空项并去重
+func splitList(s string) []string {
+	items := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/mailer"
+	"fluent-life-admin-api/pkg/mailer/mailertest"
+)
+
+func TestReportEmail(t *testing.T) {
+	srv, err := mailertest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	schedule := &models.ReportSchedule{Name: "运营日报", Recipients: "ops@example.com, ops@example.com ,pm@example.com"}
+	result := &TimeSeriesResult{
+		Granularity: GranularityDay,
+		StartDate:   "2026-01-01",
+		EndDate:     "2026-01-02",
+		Timezone:    "Asia/Shanghai",
+		Buckets:     []string{"2026-01-01", "2026-01-02"},
+		Series: []Series{
+			{Metric: MetricNewUsers, Values: []float64{3, 5}, Total: 8},
+			{Metric: "custom_metric", Dimension: "exposure", Values: []float64{1.5, 0}, Total: 1.5},
+		},
+	}
+	msg, err := buildReportMessage(schedule, result)
+	if err != nil {
+		t.Fatalf("buildReportMessage: %v", err)
+	}
+	m := mailer.New(mailer.Config{Host: srv.Host, Port: srv.Port, From: "report@fluentlife.test"})
+	if err := m.Send(*msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("收到 %d 封邮件，期望 1 封", len(msgs))
+	}
+	if got := strings.Join(msgs[0].To, ","); got != "ops@example.com,pm@example.com" {
+		t.Errorf("收件人 = %s", got)
+	}
+	_, parts, err := msgs[0].Parse()
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("分段数 = %d，期望 2", len(parts))
+	}
+
+	html := string(parts[0].Body)
+	for _, want := range []string{
+		"<h2>运营日报</h2>",
+		"2026-01-01 ~ 2026-01-02（Asia/Shanghai）",
+		"<td>新增用户</td><td style=\"text-align: right;\">8</td>",
+		"<th>custom_metric - exposure</th>",
+		"<td>2026-01-02</td><td style=\"text-align: right;\">5</td>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML 中缺少 %q", want)
+		}
+	}
+
+	if parts[1].Filename != "report_2026-01-01_2026-01-02.csv" {
+		t.Errorf("附件名 = %q", parts[1].Filename)
+	}
+	wantCSV := "\xEF\xBB\xBF时间,新增用户,custom_metric - exposure\n2026-01-01,3,1.5\n2026-01-02,5,0\n"
+	if !bytes.Equal(parts[1].Body, []byte(wantCSV)) {
+		t.Errorf("CSV = %q，期望 %q", parts[1].Body, wantCSV)
+	}
+}
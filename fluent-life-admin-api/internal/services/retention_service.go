@@ -0,0 +1,272 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultLanguage 用户未保存设置时视为使用的语言，与 UserSettings.Language 的默认值一致
+const DefaultLanguage = "zh-CN"
+
+// RetentionDays 每个队列额外输出的第 N 日留存
+var RetentionDays = []int{1, 7, 30}
+
+// RetentionFilter 留存分析的用户筛选条件
+type RetentionFilter struct {
+	Gender   string `json:"gender,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// RetentionCohort 一个注册队列的留存数据
+// Retained[k] 为第 k 个周期（按队列粒度，0 为注册当期）有回访的人数，只包含已经开始的周期
+type RetentionCohort struct {
+	Cohort    string              `json:"cohort"`
+	Size      int                 `json:"size"`
+	Retained  []int               `json:"retained"`
+	Rates     []float64           `json:"rates"`
+	DayN      map[string]*float64 `json:"day_n"` // "day1"/"day7"/"day30" 留存率，尚无可观测用户时为 null
+	dayNCount map[int][2]int      // N -> [回访人数, 可观测人数]
+}
+
+// RetentionResult 留存分析结果（队列三角）
+type RetentionResult struct {
+	Granularity Granularity       `json:"granularity"`
+	StartDate   string            `json:"start_date"`
+	EndDate     string            `json:"end_date"`
+	Timezone    string            `json:"timezone"`
+	Periods     int               `json:"periods"`
+	Filter      RetentionFilter   `json:"filter"`
+	Cohorts     []RetentionCohort `json:"cohorts"`
+}
+
+// RetentionService 基于训练记录与冥想进度计算注册队列留存
+type RetentionService struct {
+	db *gorm.DB
+}
+
+// NewRetentionService 创建留存分析服务
+func NewRetentionService(db *gorm.DB) *RetentionService {
+	return &RetentionService{db: db}
+}
+
+// cohortUserQuery 返回区间内注册且满足筛选条件的用户查询
+func (s *RetentionService) cohortUserQuery(r DateRange, filter RetentionFilter) *gorm.DB {
+	query := s.db.Table("users").Where("users.created_at >= ? AND users.created_at < ?", r.Start, r.End)
+	if filter.Gender != "" {
+		query = query.Where("users.gender = ?", filter.Gender)
+	}
+	if filter.Language != "" {
+		if filter.Language == DefaultLanguage {
+			// 没有保存设置的用户按默认语言计算
+			query = query.Where("NOT EXISTS (SELECT 1 FROM user_settings us WHERE us.user_id = users.id AND us.language <> ?)", filter.Language)
+		} else {
+			query = query.Where("EXISTS (SELECT 1 FROM user_settings us WHERE us.user_id = users.id AND us.language = ?)", filter.Language)
+		}
+	}
+	return query
+}
+
+// Cohorts 按注册日/周分组计算留存三角，periods 为输出的最大周期数（不含第 0 期）
+func (s *RetentionService) Cohorts(r DateRange, g Granularity, periods int, filter RetentionFilter) (*RetentionResult, error) {
+	if g != GranularityDay && g != GranularityWeek {
+		return nil, fmt.Errorf("留存分析仅支持按天或按周分组")
+	}
+
+	var users []struct {
+		UserID    string
+		SignupDay string
+	}
+	signupExpr := fmt.Sprintf("to_char(users.created_at AT TIME ZONE '%s', 'YYYY-MM-DD') AS signup_day", ReportTimezone)
+	if err := s.cohortUserQuery(r, filter).Select("users.id AS user_id, " + signupExpr).Scan(&users).Error; err != nil {
+		return nil, fmt.Errorf("查询注册用户失败: %w", err)
+	}
+
+	var activities []struct {
+		UserID    string
+		ActiveDay string
+	}
+	activeExpr := fmt.Sprintf("to_char(a.ts AT TIME ZONE '%s', 'YYYY-MM-DD')", ReportTimezone)
+	err := s.db.Raw(`
+		SELECT DISTINCT a.user_id, `+activeExpr+` AS active_day FROM (
+			SELECT user_id, timestamp AS ts FROM training_records WHERE timestamp >= ?
+			UNION ALL
+			SELECT user_id, updated_at AS ts FROM meditation_progresses WHERE updated_at >= ? AND deleted_at IS NULL
+		) a WHERE a.user_id IN (?)`,
+		r.Start, r.Start, s.cohortUserQuery(r, filter).Select("users.id"),
+	).Scan(&activities).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询回访记录失败: %w", err)
+	}
+
+	activeDays := make(map[string][]time.Time)
+	for _, a := range activities {
+		day, err := ParseDate(a.ActiveDay)
+		if err != nil {
+			continue
+		}
+		activeDays[a.UserID] = append(activeDays[a.UserID], day)
+	}
+
+	today := StartOfDay(time.Now())
+	cohorts := make(map[string]*RetentionCohort)
+	for _, u := range users {
+		signup, err := ParseDate(u.SignupDay)
+		if err != nil {
+			continue
+		}
+		cohortStart := TruncateTime(signup, g)
+		key := cohortStart.Format("2006-01-02")
+		cohort, ok := cohorts[key]
+		if !ok {
+			observable := observablePeriods(cohortStart, today, g, periods)
+			cohort = &RetentionCohort{
+				Cohort:    key,
+				Retained:  make([]int, observable+1),
+				dayNCount: make(map[int][2]int),
+			}
+			cohorts[key] = cohort
+		}
+		cohort.Size++
+
+		seenPeriods := make(map[int]bool)
+		seenDays := make(map[int]bool)
+		for _, day := range activeDays[u.UserID] {
+			if day.Before(signup) {
+				continue
+			}
+			seenDays[daysBetween(signup, day)] = true
+			if k := periodIndex(cohortStart, day, g); k < len(cohort.Retained) {
+				seenPeriods[k] = true
+			}
+		}
+		for k := range seenPeriods {
+			cohort.Retained[k]++
+		}
+		for _, n := range RetentionDays {
+			if signup.AddDate(0, 0, n).After(today) {
+				continue // 第 N 日尚未到来，不计入分母
+			}
+			counts := cohort.dayNCount[n]
+			counts[1]++
+			if seenDays[n] {
+				counts[0]++
+			}
+			cohort.dayNCount[n] = counts
+		}
+	}
+
+	result := &RetentionResult{
+		Granularity: g,
+		StartDate:   r.Start.Format("2006-01-02"),
+		EndDate:     r.LastDay().Format("2006-01-02"),
+		Timezone:    ReportTimezone,
+		Periods:     periods,
+		Filter:      filter,
+		Cohorts:     make([]RetentionCohort, 0, len(cohorts)),
+	}
+	for _, cohort := range cohorts {
+		cohort.Rates = make([]float64, len(cohort.Retained))
+		for k, n := range cohort.Retained {
+			cohort.Rates[k] = ratio(n, cohort.Size)
+		}
+		cohort.DayN = make(map[string]*float64, len(RetentionDays))
+		for _, n := range RetentionDays {
+			counts := cohort.dayNCount[n]
+			var rate *float64
+			if counts[1] > 0 {
+				v := ratio(counts[0], counts[1])
+				rate = &v
+			}
+			cohort.DayN["day"+strconv.Itoa(n)] = rate
+		}
+		result.Cohorts = append(result.Cohorts, *cohort)
+	}
+	sort.Slice(result.Cohorts, func(i, j int) bool { return result.Cohorts[i].Cohort < result.Cohorts[j].Cohort })
+	return result, nil
+}
+
+// WriteCSV 以 CSV 输出留存三角，带 UTF-8 BOM 便于 Excel 直接打开
+func (r *RetentionResult) WriteCSV(w io.Writer) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+
+	unit := "D"
+	if r.Granularity == GranularityWeek {
+		unit = "W"
+	}
+	header := []string{"cohort", "size"}
+	for _, n := range RetentionDays {
+		header = append(header, fmt.Sprintf("day%d_rate", n))
+	}
+	for k := 0; k <= r.Periods; k++ {
+		header = append(header, fmt.Sprintf("%s%d", unit, k))
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, cohort := range r.Cohorts {
+		row := []string{cohort.Cohort, strconv.Itoa(cohort.Size)}
+		for _, n := range RetentionDays {
+			row = append(row, formatRate(cohort.DayN["day"+strconv.Itoa(n)]))
+		}
+		for k := 0; k <= r.Periods; k++ {
+			if k < len(cohort.Rates) {
+				rate := cohort.Rates[k]
+				row = append(row, formatRate(&rate))
+			} else {
+				row = append(row, "")
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// observablePeriods 返回队列截至今天已经开始的最大周期序号（不超过 periods）
+func observablePeriods(cohortStart, today time.Time, g Granularity, periods int) int {
+	k := periodIndex(cohortStart, today, g)
+	if k > periods {
+		return periods
+	}
+	return k
+}
+
+// periodIndex 返回 day 相对队列起点所在的周期序号
+func periodIndex(cohortStart, day time.Time, g Granularity) int {
+	days := daysBetween(cohortStart, day)
+	if g == GranularityWeek {
+		return days / 7
+	}
+	return days
+}
+
+// daysBetween 返回两个北京时间零点之间相差的自然日数
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Round(time.Hour).Hours() / 24)
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func formatRate(rate *float64) string {
+	if rate == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*rate*100, 'f', 2, 64) + "%"
+}
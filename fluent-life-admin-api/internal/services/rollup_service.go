@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 预聚合指标名（写入 daily_metrics.metric）
+const (
+	RollupNewUsers         = "new_users"
+	RollupActiveUsers      = "active_users"
+	RollupActiveUsers7d    = "active_users_7d" // 截至当天的最近7天去重活跃用户
+	RollupTrainingRecords  = "training_records"
+	RollupTrainingSeconds  = "training_seconds"
+	RollupPosts            = "posts"
+	RollupComments         = "comments"
+	RollupPostLikes        = "post_likes"
+	RollupPostCollections  = "post_collections"
+	RollupFollows          = "follows"
+	RollupRooms            = "rooms"
+	RollupRoomsActive      = "rooms_active" // 快照：汇总时处于开启状态的房间数
+	RollupAIConversations  = "ai_conversations"
+	RollupTongueTwisters   = "tongue_twisters"
+	RollupDailyExpressions = "daily_expressions"
+)
+
+// flowSource 描述一个按天累加的流量型指标来自哪张表
+type flowSource struct {
+	Metric    string
+	Table     string
+	Column    string // 归属日期的时间列
+	Dimension string // 维度列，为空表示无维度
+	Value     string // 聚合表达式
+}
+
+var flowSources = []flowSource{
+	{Metric: RollupNewUsers, Table: "users", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupTrainingRecords, Table: "training_records", Column: "timestamp", Dimension: "type", Value: "COUNT(*)"},
+	{Metric: RollupTrainingSeconds, Table: "training_records", Column: "timestamp", Dimension: "type", Value: "COALESCE(SUM(duration), 0)"},
+	{Metric: RollupPosts, Table: "posts", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupComments, Table: "comments", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupPostLikes, Table: "post_likes", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupPostCollections, Table: "post_collections", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupFollows, Table: "follows", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupRooms, Table: "practice_rooms", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupAIConversations, Table: "ai_conversations", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupTongueTwisters, Table: "tongue_twisters", Column: "created_at", Value: "COUNT(*)"},
+	{Metric: RollupDailyExpressions, Table: "daily_expressions", Column: "created_at", Value: "COUNT(*)"},
+}
+
+// RollupService 把原始表按天汇总到 daily_metrics，并为仪表盘提供读取接口
+type RollupService struct {
+	db        *gorm.DB
+	analytics *AnalyticsService
+}
+
+// NewRollupService 创建预聚合服务
+func NewRollupService(db *gorm.DB) *RollupService {
+	return &RollupService{db: db, analytics: NewAnalyticsService(db)}
+}
+
+// metricDate 把北京时间的自然日转换为 date 列使用的值（UTC 零点，避免驱动按时区换算日期）
+func metricDate(day time.Time) time.Time {
+	day = day.In(ReportLocation)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Rollup 重新计算区间内每一天的指标并覆盖写入 daily_metrics
+func (s *RollupService) Rollup(r DateRange) error {
+	days, err := BucketKeys(r, GranularityDay)
+	if err != nil {
+		return err
+	}
+	computedAt := time.Now()
+	rows := make([]models.DailyMetric, 0)
+	metrics := make([]string, 0, len(flowSources)+2)
+
+	for _, src := range flowSources {
+		var results []bucketRow
+		dimension, groupBy := "''", "1"
+		if src.Dimension != "" {
+			dimension, groupBy = src.Dimension, "1, 2"
+		}
+		sql := fmt.Sprintf(
+			"SELECT %s AS bucket, %s AS dimension, %s AS value FROM %s WHERE %s >= ? AND %s < ? GROUP BY %s",
+			bucketExpr(GranularityDay, src.Column), dimension, src.Value, src.Table, src.Column, src.Column, groupBy,
+		)
+		if err := s.db.Raw(sql, r.Start, r.End).Scan(&results).Error; err != nil {
+			return fmt.Errorf("汇总 %s 失败: %w", src.Metric, err)
+		}
+
+		dimensions := []string{""}
+		if src.Metric == RollupTrainingRecords || src.Metric == RollupTrainingSeconds {
+			dimensions = TrainingTypes
+		}
+		rows = append(rows, buildMetricRows(src.Metric, days, dimensions, results, computedAt)...)
+		metrics = append(metrics, src.Metric)
+	}
+
+	var active []bucketRow
+	if err := s.db.Raw(activeUsersSQL(GranularityDay), r.Start, r.End, r.Start, r.End).Scan(&active).Error; err != nil {
+		return fmt.Errorf("汇总活跃用户失败: %w", err)
+	}
+	rows = append(rows, buildMetricRows(RollupActiveUsers, days, []string{""}, active, computedAt)...)
+	metrics = append(metrics, RollupActiveUsers)
+
+	for _, key := range days {
+		day, _ := ParseDate(key)
+		count, err := s.analytics.ActiveUserCount(day.AddDate(0, 0, -6), day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		rows = append(rows, models.DailyMetric{Date: metricDate(day), Metric: RollupActiveUsers7d, Value: float64(count), ComputedAt: computedAt})
+	}
+	metrics = append(metrics, RollupActiveUsers7d)
+
+	// 快照型指标只能反映当前状态，仅在汇总区间包含今天时写入今天
+	today := StartOfDay(computedAt)
+	includesToday := !today.Before(r.Start) && today.Before(r.End)
+	if includesToday {
+		var activeRooms int64
+		if err := s.db.Model(&models.PracticeRoom{}).Where("is_active = ?", true).Count(&activeRooms).Error; err != nil {
+			return fmt.Errorf("汇总开启房间数失败: %w", err)
+		}
+		rows = append(rows, models.DailyMetric{Date: metricDate(today), Metric: RollupRoomsActive, Value: float64(activeRooms), ComputedAt: computedAt})
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		startKey, endKey := r.Start.Format("2006-01-02"), r.End.Format("2006-01-02")
+		if err := tx.Where("date >= ? AND date < ? AND metric IN ?", startKey, endKey, metrics).Delete(&models.DailyMetric{}).Error; err != nil {
+			return fmt.Errorf("清理旧汇总数据失败: %w", err)
+		}
+		if includesToday {
+			if err := tx.Where("date = ? AND metric = ?", today.Format("2006-01-02"), RollupRoomsActive).Delete(&models.DailyMetric{}).Error; err != nil {
+				return fmt.Errorf("清理旧快照数据失败: %w", err)
+			}
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return fmt.Errorf("写入汇总数据失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// buildMetricRows 按天、按维度补零生成汇总行，查询结果中出现的未知维度也会保留
+func buildMetricRows(metric string, days, dimensions []string, results []bucketRow, computedAt time.Time) []models.DailyMetric {
+	values := make(map[[2]string]float64)
+	for _, day := range days {
+		for _, dim := range dimensions {
+			values[[2]string{day, dim}] = 0
+		}
+	}
+	for _, row := range results {
+		values[[2]string{row.Bucket, row.Dimension}] += row.Value
+	}
+
+	rows := make([]models.DailyMetric, 0, len(values))
+	for key, value := range values {
+		day, err := ParseDate(key[0])
+		if err != nil {
+			continue
+		}
+		rows = append(rows, models.DailyMetric{
+			Date:       metricDate(day),
+			Metric:     metric,
+			Dimension:  key[1],
+			Value:      value,
+			ComputedAt: computedAt,
+		})
+	}
+	return rows
+}
+
+// Backfill 按月分段重建 [start, end) 区间的历史汇总数据
+func (s *RollupService) Backfill(r DateRange, progress func(DateRange)) error {
+	for chunkStart := r.Start; chunkStart.Before(r.End); {
+		chunkEnd := chunkStart.AddDate(0, 1, 0)
+		if chunkEnd.After(r.End) {
+			chunkEnd = r.End
+		}
+		chunk := DateRange{Start: chunkStart, End: chunkEnd}
+		if err := s.Rollup(chunk); err != nil {
+			return fmt.Errorf("汇总 %s ~ %s 失败: %w", chunk.Start.Format("2006-01-02"), chunk.LastDay().Format("2006-01-02"), err)
+		}
+		if progress != nil {
+			progress(chunk)
+		}
+		chunkStart = chunkEnd
+	}
+	return nil
+}
+
+// EarliestDataDate 返回最早一条用户或训练记录所在的自然日，用于确定全量回填的起点
+func (s *RollupService) EarliestDataDate() (time.Time, error) {
+	var earliest *time.Time
+	err := s.db.Raw(`
+		SELECT MIN(ts) FROM (
+			SELECT MIN(created_at) AS ts FROM users
+			UNION ALL
+			SELECT MIN(timestamp) AS ts FROM training_records
+		) t`).Scan(&earliest).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	if earliest == nil {
+		return StartOfDay(time.Now()), nil
+	}
+	return StartOfDay(*earliest), nil
+}
+
+// RunScheduler 周期性增量汇总今天的数据；首次运行及跨天后的第一次运行会同时重算昨天，作为夜间定稿
+func (s *RollupService) RunScheduler(ctx context.Context, interval time.Duration) {
+	lastDay := ""
+	run := func() {
+		today := StartOfDay(time.Now())
+		start := today
+		if lastDay != today.Format("2006-01-02") {
+			start = today.AddDate(0, 0, -1)
+		}
+		if err := s.Rollup(DateRange{Start: start, End: today.AddDate(0, 0, 1)}); err != nil {
+			log.Printf("[rollup] 汇总失败: %v", err)
+			return
+		}
+		lastDay = today.Format("2006-01-02")
+	}
+
+	run()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
+
+// DashboardStats 仪表盘使用的汇总统计
+type DashboardStats struct {
+	// 用户统计
+	TotalUsers        int64 `json:"total_users"`
+	ActiveUsers       int64 `json:"active_users"`         // 最近7天活跃用户（有训练记录或登录）
+	NewUsersToday     int64 `json:"new_users_today"`      // 今日新增用户
+	NewUsersThisWeek  int64 `json:"new_users_this_week"`  // 本周新增用户
+	NewUsersThisMonth int64 `json:"new_users_this_month"` // 本月新增用户
+
+	// 训练统计
+	TotalRecords    int64 `json:"total_records"`
+	MeditationCount int64 `json:"meditation_count"`
+	AirflowCount    int64 `json:"airflow_count"`
+	ExposureCount   int64 `json:"exposure_count"`
+	PracticeCount   int64 `json:"practice_count"`
+	TotalDuration   int64 `json:"total_duration"` // 总训练时长（分钟）
+	AvgDuration     int64 `json:"avg_duration"`   // 平均训练时长（分钟）
+
+	// 社区统计
+	TotalPosts       int64 `json:"total_posts"`
+	TotalComments    int64 `json:"total_comments"`
+	TotalLikes       int64 `json:"total_likes"`
+	TotalCollections int64 `json:"total_collections"`
+	TotalFollows     int64 `json:"total_follows"`
+	TotalRooms       int64 `json:"total_rooms"`
+	ActiveRooms      int64 `json:"active_rooms"`
+
+	// AI功能统计
+	TotalAIConversations int64 `json:"total_ai_conversations"`
+
+	// 内容统计
+	TotalTongueTwisters   int64 `json:"total_tongue_twisters"`
+	TotalDailyExpressions int64 `json:"total_daily_expressions"`
+
+	// 汇总数据的最近计算时间，实时计算时为空
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// metricTotal 某个流量型指标在一段时间内的合计，Week/Month 只累加本周、本月以内的部分
+type metricTotal struct {
+	Metric    string
+	Dimension string
+	Total     float64
+	Week      float64
+	Month     float64
+}
+
+// DashboardStats 仪表盘统计。已结束的自然日累加 daily_metrics 中的汇总行，只有今天（北京时间）实时计数后叠加；
+// 历史以汇总（回填）数据为准，删除原始数据后需重新回填对应日期。
+// 最近7天去重活跃用户无法按天相加，优先读取今天的快照，尚未汇总时实时计算。
+// 历史汇总有缺失（未回填或汇总任务中断过）时第二个返回值为 false，由调用方改为全部实时计算
+func (s *RollupService) DashboardStats(now time.Time) (*DashboardStats, bool, error) {
+	today := StartOfDay(now)
+	complete, err := s.historyComplete(today)
+	if err != nil || !complete {
+		return nil, false, err
+	}
+
+	flowMetrics := make([]string, 0, len(flowSources))
+	for _, src := range flowSources {
+		flowMetrics = append(flowMetrics, src.Metric)
+	}
+	var closed []metricTotal
+	if err := s.db.Raw(`
+		SELECT metric, dimension,
+			SUM(value) AS total,
+			SUM(CASE WHEN date >= ? THEN value ELSE 0 END) AS week,
+			SUM(CASE WHEN date >= ? THEN value ELSE 0 END) AS month
+		FROM daily_metrics
+		WHERE date < ? AND metric IN ?
+		GROUP BY metric, dimension`,
+		metricDate(StartOfWeek(now)), metricDate(StartOfMonth(now)), metricDate(today), flowMetrics).
+		Scan(&closed).Error; err != nil {
+		return nil, false, err
+	}
+	var updatedAt *time.Time
+	if err := s.db.Raw("SELECT MAX(computed_at) FROM daily_metrics WHERE date < ? AND metric IN ?", metricDate(today), flowMetrics).
+		Scan(&updatedAt).Error; err != nil {
+		return nil, false, err
+	}
+
+	live, err := s.flowTotals(today, now)
+	if err != nil {
+		return nil, false, err
+	}
+	stats := buildDashboardStats(closed, live)
+	stats.UpdatedAt = updatedAt
+
+	if err := s.db.Model(&models.PracticeRoom{}).Where("is_active = ?", true).Count(&stats.ActiveRooms).Error; err != nil {
+		return nil, false, err
+	}
+	var snapshots []models.DailyMetric
+	if err := s.db.Where("metric = ? AND date = ?", RollupActiveUsers7d, metricDate(today)).
+		Limit(1).Find(&snapshots).Error; err != nil {
+		return nil, false, err
+	}
+	if len(snapshots) > 0 {
+		stats.ActiveUsers = int64(snapshots[0].Value)
+	} else if stats.ActiveUsers, err = s.analytics.ActiveUserCount(today.AddDate(0, 0, -6), now); err != nil {
+		return nil, false, err
+	}
+	return stats, true, nil
+}
+
+// historyComplete 检查从最早数据到昨天的每一天都已有汇总行（Rollup 对每天都会写入 new_users，无数据时为 0）
+func (s *RollupService) historyComplete(today time.Time) (bool, error) {
+	earliest, err := s.EarliestDataDate()
+	if err != nil {
+		return false, err
+	}
+	days := int64(metricDate(today).Sub(metricDate(earliest)).Hours() / 24)
+	if days <= 0 {
+		return true, nil
+	}
+	var covered int64
+	if err := s.db.Model(&models.DailyMetric{}).
+		Where("metric = ? AND dimension = '' AND date >= ? AND date < ?", RollupNewUsers, metricDate(earliest), metricDate(today)).
+		Count(&covered).Error; err != nil {
+		return false, err
+	}
+	return covered >= days, nil
+}
+
+// flowTotals 实时统计 [start, end) 内各流量型指标的合计，一次查询覆盖所有来源表
+func (s *RollupService) flowTotals(start, end time.Time) ([]metricTotal, error) {
+	parts := make([]string, 0, len(flowSources))
+	args := make([]interface{}, 0, len(flowSources)*2)
+	for _, src := range flowSources {
+		dimension, groupBy := "''", ""
+		if src.Dimension != "" {
+			dimension, groupBy = src.Dimension, " GROUP BY "+src.Dimension
+		}
+		parts = append(parts, fmt.Sprintf(
+			"SELECT '%s' AS metric, %s AS dimension, %s AS total FROM %s WHERE %s >= ? AND %s < ?%s",
+			src.Metric, dimension, src.Value, src.Table, src.Column, src.Column, groupBy,
+		))
+		args = append(args, start, end)
+	}
+	var rows []metricTotal
+	if err := s.db.Raw(strings.Join(parts, " UNION ALL "), args...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("实时统计今日数据失败: %w", err)
+	}
+	// 今天同时属于本周与本月
+	for i := range rows {
+		rows[i].Week, rows[i].Month = rows[i].Total, rows[i].Total
+	}
+	return rows, nil
+}
+
+// buildDashboardStats 把已结束自然日的汇总合计与今天的实时合计相加，换算为仪表盘字段
+func buildDashboardStats(closed, today []metricTotal) *DashboardStats {
+	stats := &DashboardStats{}
+	var trainingSeconds int64
+	add := func(t metricTotal) {
+		total := int64(t.Total)
+		switch t.Metric {
+		case RollupNewUsers:
+			stats.TotalUsers += total
+			stats.NewUsersThisWeek += int64(t.Week)
+			stats.NewUsersThisMonth += int64(t.Month)
+		case RollupTrainingRecords:
+			stats.TotalRecords += total
+			switch t.Dimension {
+			case "meditation":
+				stats.MeditationCount += total
+			case "airflow":
+				stats.AirflowCount += total
+			case "exposure":
+				stats.ExposureCount += total
+			case "practice":
+				stats.PracticeCount += total
+			}
+		case RollupTrainingSeconds:
+			trainingSeconds += total
+		case RollupPosts:
+			stats.TotalPosts += total
+		case RollupComments:
+			stats.TotalComments += total
+		case RollupPostLikes:
+			stats.TotalLikes += total
+		case RollupPostCollections:
+			stats.TotalCollections += total
+		case RollupFollows:
+			stats.TotalFollows += total
+		case RollupRooms:
+			stats.TotalRooms += total
+		case RollupAIConversations:
+			stats.TotalAIConversations += total
+		case RollupTongueTwisters:
+			stats.TotalTongueTwisters += total
+		case RollupDailyExpressions:
+			stats.TotalDailyExpressions += total
+		}
+	}
+	for _, t := range closed {
+		add(t)
+	}
+	for _, t := range today {
+		add(t)
+		if t.Metric == RollupNewUsers {
+			stats.NewUsersToday += int64(t.Total)
+		}
+	}
+
+	stats.TotalDuration = trainingSeconds / 60
+	if stats.TotalRecords > 0 {
+		stats.AvgDuration = trainingSeconds / stats.TotalRecords / 60
+	}
+	return stats
+}
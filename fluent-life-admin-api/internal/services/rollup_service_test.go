@@ -0,0 +1,42 @@
+package services
+
+import "testing"
+
+func TestBuildDashboardStats(t *testing.T) {
+	closed := []metricTotal{
+		{Metric: RollupNewUsers, Total: 100, Week: 7, Month: 20},
+		{Metric: RollupTrainingRecords, Dimension: "meditation", Total: 30},
+		{Metric: RollupTrainingRecords, Dimension: "exposure", Total: 10},
+		{Metric: RollupTrainingRecords, Dimension: "legacy", Total: 2},
+		{Metric: RollupTrainingSeconds, Dimension: "meditation", Total: 3600},
+		{Metric: RollupPosts, Total: 5},
+	}
+	today := []metricTotal{
+		{Metric: RollupNewUsers, Total: 3, Week: 3, Month: 3},
+		{Metric: RollupTrainingRecords, Dimension: "meditation", Total: 4, Week: 4, Month: 4},
+		{Metric: RollupTrainingSeconds, Dimension: "meditation", Total: 1440, Week: 1440, Month: 1440},
+		{Metric: RollupPosts, Total: 1, Week: 1, Month: 1},
+	}
+	stats := buildDashboardStats(closed, today)
+
+	checks := []struct {
+		name      string
+		got, want int64
+	}{
+		{"total_users", stats.TotalUsers, 103},
+		{"new_users_today", stats.NewUsersToday, 3},
+		{"new_users_this_week", stats.NewUsersThisWeek, 10},
+		{"new_users_this_month", stats.NewUsersThisMonth, 23},
+		{"total_records", stats.TotalRecords, 46},
+		{"meditation_count", stats.MeditationCount, 34},
+		{"exposure_count", stats.ExposureCount, 10},
+		{"total_duration", stats.TotalDuration, 84},
+		{"avg_duration", stats.AvgDuration, 1},
+		{"total_posts", stats.TotalPosts, 6},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+}
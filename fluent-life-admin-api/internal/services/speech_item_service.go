@@ -0,0 +1,473 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 语音技巧的条目类型，与 speech_techniques 中对应的列名相同
+const (
+	SpeechItemTips          = "tips"
+	SpeechItemPracticeTexts = "practice_texts"
+)
+
+// speechItemMaxLen 单条要点或练习文本的最大字数
+const speechItemMaxLen = 2000
+
+var (
+	ErrSpeechItemNotFound = errors.New("条目不存在")
+	ErrSpeechItemInvalid  = errors.New("条目校验失败")
+)
+
+type speechItemKind struct {
+	Label string
+	Table string
+}
+
+var speechItemKinds = map[string]speechItemKind{
+	SpeechItemTips:          {Label: "训练要点", Table: "speech_technique_tips"},
+	SpeechItemPracticeTexts: {Label: "练习文本", Table: "speech_practice_texts"},
+}
+
+// SpeechItemInput 新建或修改条目的字段，nil 表示不修改；难度与音频只对练习文本有效
+type SpeechItemInput struct {
+	Content    *string `json:"content"`
+	Order      *int    `json:"order"`
+	Difficulty *string `json:"difficulty"`
+	AudioURL   *string `json:"audio_url"`
+	IsActive   *bool   `json:"is_active"`
+}
+
+func newSpeechItemModel(kind string) interface{} {
+	if kind == SpeechItemTips {
+		return &models.SpeechTechniqueTip{}
+	}
+	return &models.SpeechPracticeText{}
+}
+
+// speechItemRow 两种条目共有的列
+type speechItemRow struct {
+	ID       uuid.UUID
+	Content  string
+	Order    int
+	IsActive bool
+}
+
+// StrictStringList 严格解析 JSON 字符串数组；空串视为空数组
+func StrictStringList(raw string) ([]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return []string{}, nil
+	}
+	var items []string
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, fmt.Errorf("应为 JSON 字符串数组: %v", err)
+	}
+	cleaned := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			cleaned = append(cleaned, item)
+		}
+	}
+	return cleaned, nil
+}
+
+// SpeechItemService 语音技巧的训练要点与练习文本条目管理。
+// 条目是唯一数据来源，speech_techniques 的 tips、practice_texts 列由启用的条目按顺序生成，供现有客户端读取
+type SpeechItemService struct {
+	db *gorm.DB
+}
+
+func NewSpeechItemService(db *gorm.DB) *SpeechItemService {
+	return &SpeechItemService{db: db}
+}
+
+func (s *SpeechItemService) techniqueExists(tx *gorm.DB, techniqueID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&models.SpeechTechnique{}).Where("id = ?", techniqueID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: 语音技巧", ErrContentNotFound)
+	}
+	return nil
+}
+
+// List 技巧的全部条目（含停用的），按顺序排列
+func (s *SpeechItemService) List(techniqueID uuid.UUID, kind string) (interface{}, error) {
+	if err := s.techniqueExists(s.db, techniqueID); err != nil {
+		return nil, err
+	}
+	query := s.db.Where("technique_id = ?", techniqueID).Order(`"order", created_at`)
+	if kind == SpeechItemTips {
+		var tips []models.SpeechTechniqueTip
+		return tips, query.Find(&tips).Error
+	}
+	var texts []models.SpeechPracticeText
+	return texts, query.Find(&texts).Error
+}
+
+// validateSpeechItemInput 校验条目字段，creating 为 true 时内容必填
+func validateSpeechItemInput(kind string, in SpeechItemInput, creating bool) error {
+	var problems []string
+	if in.Content != nil {
+		content := strings.TrimSpace(*in.Content)
+		in.Content = &content
+		if content == "" {
+			problems = append(problems, "内容不能为空")
+		} else if len([]rune(content)) > speechItemMaxLen {
+			problems = append(problems, fmt.Sprintf("内容不能超过 %d 个字符", speechItemMaxLen))
+		}
+	} else if creating {
+		problems = append(problems, "内容不能为空")
+	}
+	if kind == SpeechItemTips {
+		if in.Difficulty != nil || in.AudioURL != nil {
+			problems = append(problems, "训练要点不支持难度与音频")
+		}
+	} else {
+		if in.Difficulty != nil && !containsString(TongueTwisterLevels, *in.Difficulty) {
+			problems = append(problems, "难度应为 basic、intermediate 或 advanced")
+		}
+		if in.AudioURL != nil && len(*in.AudioURL) > 500 {
+			problems = append(problems, "音频地址不能超过 500 个字符")
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrSpeechItemInvalid, strings.Join(problems, "；"))
+	}
+	return nil
+}
+
+// Create 新建条目；未指定顺序时追加到末尾
+func (s *SpeechItemService) Create(techniqueID uuid.UUID, kind string, in SpeechItemInput) (interface{}, error) {
+	if err := validateSpeechItemInput(kind, in, true); err != nil {
+		return nil, err
+	}
+	var created interface{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.techniqueExists(tx, techniqueID); err != nil {
+			return err
+		}
+		order := 0
+		if in.Order != nil {
+			order = *in.Order
+		} else {
+			var last struct{ Max *int }
+			if err := tx.Table(speechItemKinds[kind].Table).Where("technique_id = ?", techniqueID).
+				Select(`MAX("order") AS max`).Scan(&last).Error; err != nil {
+				return err
+			}
+			if last.Max != nil {
+				order = *last.Max + 1
+			}
+		}
+		active := true
+		if in.IsActive != nil {
+			active = *in.IsActive
+		}
+		content := strings.TrimSpace(*in.Content)
+
+		// Select("*") 让 is_active=false 也写入，而不是被列默认值 true 取代
+		if kind == SpeechItemTips {
+			tip := &models.SpeechTechniqueTip{TechniqueID: techniqueID, Content: content, Order: order, IsActive: active}
+			if err := tx.Select("*").Create(tip).Error; err != nil {
+				return err
+			}
+			created = tip
+		} else {
+			text := &models.SpeechPracticeText{TechniqueID: techniqueID, Content: content, Order: order, Difficulty: "basic", IsActive: active}
+			if in.Difficulty != nil {
+				text.Difficulty = *in.Difficulty
+			}
+			if in.AudioURL != nil {
+				text.AudioURL = strings.TrimSpace(*in.AudioURL)
+			}
+			if err := tx.Select("*").Create(text).Error; err != nil {
+				return err
+			}
+			created = text
+		}
+		return s.syncColumn(tx, techniqueID, kind)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update 修改条目
+func (s *SpeechItemService) Update(techniqueID uuid.UUID, kind string, itemID uuid.UUID, in SpeechItemInput) (interface{}, error) {
+	if err := validateSpeechItemInput(kind, in, false); err != nil {
+		return nil, err
+	}
+	var updated interface{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		model := newSpeechItemModel(kind)
+		if err := tx.Where("id = ? AND technique_id = ?", itemID, techniqueID).First(model).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSpeechItemNotFound
+			}
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if in.Content != nil {
+			updates["content"] = strings.TrimSpace(*in.Content)
+		}
+		if in.Order != nil {
+			updates["order"] = *in.Order
+		}
+		if in.IsActive != nil {
+			updates["is_active"] = *in.IsActive
+		}
+		if in.Difficulty != nil {
+			updates["difficulty"] = *in.Difficulty
+		}
+		if in.AudioURL != nil {
+			updates["audio_url"] = strings.TrimSpace(*in.AudioURL)
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(model).Updates(updates).Error; err != nil {
+				return err
+			}
+			if err := tx.First(model, "id = ?", itemID).Error; err != nil {
+				return err
+			}
+		}
+		updated = model
+		return s.syncColumn(tx, techniqueID, kind)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete 删除条目
+func (s *SpeechItemService) Delete(techniqueID uuid.UUID, kind string, itemID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND technique_id = ?", itemID, techniqueID).Delete(newSpeechItemModel(kind))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSpeechItemNotFound
+		}
+		return s.syncColumn(tx, techniqueID, kind)
+	})
+}
+
+// Reorder 按 ids 的顺序重排条目，ids 必须恰好包含该技巧的全部条目
+func (s *SpeechItemService) Reorder(techniqueID uuid.UUID, kind string, ids []uuid.UUID) error {
+	table := speechItemKinds[kind].Table
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []uuid.UUID
+		if err := tx.Table(table).Where("technique_id = ?", techniqueID).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		known := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+		seen := make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			if !known[id] || seen[id] {
+				return fmt.Errorf("%w: 排序列表包含不属于该技巧或重复的条目", ErrSpeechItemInvalid)
+			}
+			seen[id] = true
+		}
+		if len(ids) != len(existing) {
+			return fmt.Errorf("%w: 排序列表应包含该技巧的全部 %d 个条目", ErrSpeechItemInvalid, len(existing))
+		}
+		for i, id := range ids {
+			if err := tx.Table(table).Where("id = ?", id).Update("order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return s.syncColumn(tx, techniqueID, kind)
+	})
+}
+
+// Replace 以文本列表整体替换技巧的条目（兼容按数组编辑的旧接口与导入）：
+// 内容相同的已有条目保留其难度、音频等属性并按列表重新排序，列表中没有的启用条目被删除，停用的条目保留
+func (s *SpeechItemService) Replace(tx *gorm.DB, techniqueID uuid.UUID, kind string, texts []string) error {
+	table := speechItemKinds[kind].Table
+	var rows []speechItemRow
+	if err := tx.Table(table).Select(`id, content, "order", is_active`).
+		Where("technique_id = ?", techniqueID).Order(`"order", created_at`).Find(&rows).Error; err != nil {
+		return err
+	}
+	byContent := make(map[string][]speechItemRow)
+	for _, row := range rows {
+		byContent[row.Content] = append(byContent[row.Content], row)
+	}
+
+	used := make(map[uuid.UUID]bool)
+	for i, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if candidates := byContent[text]; len(candidates) > 0 {
+			row := candidates[0]
+			byContent[text] = candidates[1:]
+			used[row.ID] = true
+			if err := tx.Table(table).Where("id = ?", row.ID).
+				Updates(map[string]interface{}{"order": i + 1, "is_active": true}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		var model interface{}
+		if kind == SpeechItemTips {
+			model = &models.SpeechTechniqueTip{TechniqueID: techniqueID, Content: text, Order: i + 1, IsActive: true}
+		} else {
+			model = &models.SpeechPracticeText{TechniqueID: techniqueID, Content: text, Order: i + 1, Difficulty: "basic", IsActive: true}
+		}
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if row.IsActive && !used[row.ID] {
+			if err := tx.Where("id = ?", row.ID).Delete(newSpeechItemModel(kind)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return s.syncColumn(tx, techniqueID, kind)
+}
+
+// SyncList 启用条目的内容与列表不一致时按列表替换（见 Replace），一致时不做修改，避免重排条目顺序
+func (s *SpeechItemService) SyncList(tx *gorm.DB, techniqueID uuid.UUID, kind string, texts []string) error {
+	var contents []string
+	if err := tx.Table(speechItemKinds[kind].Table).
+		Where("technique_id = ? AND is_active = ?", techniqueID, true).
+		Order(`"order", created_at`).Pluck("content", &contents).Error; err != nil {
+		return err
+	}
+	if len(contents) == len(texts) {
+		same := true
+		for i := range texts {
+			if contents[i] != texts[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return s.syncColumn(tx, techniqueID, kind)
+		}
+	}
+	return s.Replace(tx, techniqueID, kind, texts)
+}
+
+// syncColumn 用启用的条目重新生成 speech_techniques 中对应的 JSON 数组列
+func (s *SpeechItemService) syncColumn(tx *gorm.DB, techniqueID uuid.UUID, kind string) error {
+	var contents []string
+	if err := tx.Table(speechItemKinds[kind].Table).
+		Where("technique_id = ? AND is_active = ?", techniqueID, true).
+		Order(`"order", created_at`).Pluck("content", &contents).Error; err != nil {
+		return err
+	}
+	if contents == nil {
+		contents = []string{}
+	}
+	data, _ := json.Marshal(contents)
+	return tx.Model(&models.SpeechTechnique{}).Where("id = ?", techniqueID).Update(kind, string(data)).Error
+}
+
+// DeleteForTechniques 删除技巧时一并删除其条目
+func (s *SpeechItemService) DeleteForTechniques(tx *gorm.DB, techniqueIDs []string) error {
+	for kind := range speechItemKinds {
+		if err := tx.Where("technique_id IN ?", techniqueIDs).Delete(newSpeechItemModel(kind)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SpeechMigrationFailure 无法解析的旧数据
+type SpeechMigrationFailure struct {
+	TechniqueID uuid.UUID `json:"technique_id"`
+	Name        string    `json:"name"`
+	Field       string    `json:"field"`
+	Raw         string    `json:"raw"`
+	Error       string    `json:"error"`
+}
+
+// SpeechMigrationReport 旧数据迁移结果
+type SpeechMigrationReport struct {
+	DryRun       bool                     `json:"dry_run"`
+	Techniques   int                      `json:"techniques"`
+	Migrated     int                      `json:"migrated"`      // 至少迁移了一个字段的技巧数
+	AlreadyDone  int                      `json:"already_done"`  // 两个字段都已有条目、无需迁移的技巧数
+	TipsCreated  int                      `json:"tips_created"`  // 新建的训练要点条数
+	TextsCreated int                      `json:"texts_created"` // 新建的练习文本条数
+	Failures     []SpeechMigrationFailure `json:"failures"`      // 解析失败的字段，保持原样不迁移
+}
+
+// MigrateLegacy 把 speech_techniques 中 JSON 字符串形式的要点与练习文本迁移为条目。
+// 已有条目的字段跳过；解析失败的字段不迁移、原列保持不变，并在报告中列出，修正后可重新执行
+func (s *SpeechItemService) MigrateLegacy(dryRun bool) (*SpeechMigrationReport, error) {
+	var techniques []models.SpeechTechnique
+	if err := s.db.Select("id, name, tips, practice_texts").Order(`"order", created_at`).Find(&techniques).Error; err != nil {
+		return nil, fmt.Errorf("查询语音技巧失败: %w", err)
+	}
+	report := &SpeechMigrationReport{DryRun: dryRun, Techniques: len(techniques), Failures: []SpeechMigrationFailure{}}
+
+	for _, t := range techniques {
+		migrated := false
+		pending := 0
+		for _, kind := range []string{SpeechItemTips, SpeechItemPracticeTexts} {
+			var count int64
+			if err := s.db.Table(speechItemKinds[kind].Table).Where("technique_id = ?", t.ID).Count(&count).Error; err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				continue
+			}
+			pending++
+			raw := t.Tips
+			if kind == SpeechItemPracticeTexts {
+				raw = t.PracticeTexts
+			}
+			texts, err := StrictStringList(raw)
+			if err != nil {
+				report.Failures = append(report.Failures, SpeechMigrationFailure{
+					TechniqueID: t.ID, Name: t.Name, Field: kind, Raw: raw, Error: err.Error(),
+				})
+				continue
+			}
+			if kind == SpeechItemTips {
+				report.TipsCreated += len(texts)
+			} else {
+				report.TextsCreated += len(texts)
+			}
+			migrated = true
+			if dryRun {
+				continue
+			}
+			techniqueID := t.ID
+			if err := s.db.Transaction(func(tx *gorm.DB) error {
+				return s.Replace(tx, techniqueID, kind, texts)
+			}); err != nil {
+				return nil, fmt.Errorf("迁移 %s 的%s失败: %w", t.Name, speechItemKinds[kind].Label, err)
+			}
+		}
+		if pending == 0 {
+			report.AlreadyDone++
+		}
+		if migrated {
+			report.Migrated++
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,541 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultLocale 内容的原文语言，译文缺失时最终回退到原文
+const DefaultLocale = "zh-CN"
+
+// SupportedLocales 支持的语言
+var SupportedLocales = []string{"zh-CN", "zh-TW", "zh-HK", "en-US"}
+
+// localeFallbacks 译文缺失时依次尝试的其他语言（不含原文语言，原文总在最后）
+var localeFallbacks = map[string][]string{
+	"zh-HK": {"zh-TW"},
+}
+
+// localeAliases 常见的语言写法
+var localeAliases = map[string]string{
+	"zh":         "zh-CN",
+	"zh-hans":    "zh-CN",
+	"zh-hans-cn": "zh-CN",
+	"zh-sg":      "zh-CN",
+	"zh-hant":    "zh-TW",
+	"zh-hant-tw": "zh-TW",
+	"zh-hant-hk": "zh-HK",
+	"zh-mo":      "zh-HK",
+	"en":         "en-US",
+}
+
+// 可翻译的内容类型
+const (
+	TranslateTongueTwister      = "tongue_twister"
+	TranslateDailyExpression    = "daily_expression"
+	TranslateSpeechTechnique    = "speech_technique"
+	TranslateSpeechTip          = "speech_technique_tip"
+	TranslateSpeechPracticeText = "speech_practice_text"
+	TranslateHelpCategory       = "help_category"
+	TranslateHelpArticle        = "help_article"
+	TranslateExposureModule     = "exposure_module"
+	TranslateExposureStep       = "exposure_step"
+)
+
+var (
+	ErrTranslationInvalid = errors.New("翻译参数错误")
+)
+
+// TranslatableType 一种可翻译内容的定义
+type TranslatableType struct {
+	Type   string   `json:"type"`
+	Label  string   `json:"label"`
+	Fields []string `json:"fields"`
+
+	table        string
+	titleField   string // 缺失列表中用于展示的字段
+	activeColumn string // 有启用状态时只统计启用的内容
+}
+
+// translatableTypes 按展示顺序排列
+var translatableTypes = []*TranslatableType{
+	{Type: TranslateTongueTwister, Label: "绕口令", Fields: []string{"title", "content", "tips"}, table: "tongue_twisters", titleField: "title", activeColumn: "is_active"},
+	{Type: TranslateDailyExpression, Label: "每日朗诵文案", Fields: []string{"title", "content", "tips"}, table: "daily_expressions", titleField: "title", activeColumn: "is_active"},
+	{Type: TranslateSpeechTechnique, Label: "语音技巧", Fields: []string{"name", "description"}, table: "speech_techniques", titleField: "name", activeColumn: "is_active"},
+	{Type: TranslateSpeechTip, Label: "语音技巧训练要点", Fields: []string{"content"}, table: "speech_technique_tips", titleField: "content", activeColumn: "is_active"},
+	{Type: TranslateSpeechPracticeText, Label: "语音技巧练习文本", Fields: []string{"content"}, table: "speech_practice_texts", titleField: "content", activeColumn: "is_active"},
+	{Type: TranslateHelpCategory, Label: "帮助分类", Fields: []string{"name"}, table: "help_categories", titleField: "name"},
+	{Type: TranslateHelpArticle, Label: "帮助文章", Fields: []string{"question", "answer"}, table: "help_articles", titleField: "question", activeColumn: "is_active"},
+	{Type: TranslateExposureModule, Label: "脱敏练习模块", Fields: []string{"title", "description"}, table: "exposure_modules", titleField: "title", activeColumn: "is_active"},
+	{Type: TranslateExposureStep, Label: "脱敏练习步骤", Fields: []string{"title", "description", "guide_content", "scenario_list_title", "scenario_list_content"}, table: "exposure_steps", titleField: "title"},
+}
+
+// TranslatableTypes 全部可翻译的内容类型
+func TranslatableTypes() []*TranslatableType {
+	return translatableTypes
+}
+
+func translatableType(contentType string) (*TranslatableType, bool) {
+	for _, t := range translatableTypes {
+		if t.Type == contentType {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (t *TranslatableType) hasField(field string) bool {
+	for _, f := range t.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeLocale 把 zh_TW、zh-hant、en 等写法规范为支持的语言；
+// 未收录的地区按语言匹配第一个支持的语言（如 en-GB → en-US）
+func NormalizeLocale(s string) (string, bool) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "_", "-"))
+	if key == "" {
+		return "", false
+	}
+	for _, locale := range SupportedLocales {
+		if strings.ToLower(locale) == key {
+			return locale, true
+		}
+	}
+	if locale, ok := localeAliases[key]; ok {
+		return locale, true
+	}
+	lang := strings.SplitN(key, "-", 2)[0]
+	for _, locale := range SupportedLocales {
+		if strings.ToLower(strings.SplitN(locale, "-", 2)[0]) == lang {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// ResolveLocale 按查询参数、Accept-Language（按权重）的顺序确定语言，都无法识别时使用原文语言
+func ResolveLocale(query, acceptLanguage string) string {
+	if locale, ok := NormalizeLocale(query); ok {
+		return locale
+	}
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		pieces := strings.Split(strings.TrimSpace(part), ";")
+		c := candidate{tag: strings.TrimSpace(pieces[0]), q: 1}
+		for _, p := range pieces[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if q, err := strconv.ParseFloat(v, 64); err == nil {
+					c.q = q
+				}
+			}
+		}
+		if c.tag != "" && c.tag != "*" && c.q > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if locale, ok := NormalizeLocale(c.tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// FallbackChain 语言的回退顺序，最后一项总是原文语言
+func FallbackChain(locale string) []string {
+	chain := []string{locale}
+	seen := map[string]bool{locale: true}
+	for _, fallback := range localeFallbacks[locale] {
+		if !seen[fallback] {
+			seen[fallback] = true
+			chain = append(chain, fallback)
+		}
+	}
+	if !seen[DefaultLocale] {
+		chain = append(chain, DefaultLocale)
+	}
+	return chain
+}
+
+// sourceHash 原文摘要，用于判断译文是否过期
+func sourceHash(source string) string {
+	sum := sha1.Sum([]byte(source))
+	return hex.EncodeToString(sum[:16])
+}
+
+// TranslationService 内容多语言翻译
+type TranslationService struct {
+	db *gorm.DB
+}
+
+func NewTranslationService(db *gorm.DB) *TranslationService {
+	return &TranslationService{db: db}
+}
+
+var translationSchemaCache sync.Map
+
+// Localize 把 dest（模型指针或模型切片指针）中可翻译字段替换为 locale 下的译文，
+// 每个字段按回退顺序独立取第一个存在的译文，都没有时保留原文
+func (s *TranslationService) Localize(contentType string, dest interface{}, locale string) error {
+	spec, ok := translatableType(contentType)
+	if !ok {
+		return fmt.Errorf("%w: 不支持的内容类型 %s", ErrTranslationInvalid, contentType)
+	}
+	chain := FallbackChain(locale)
+	lookup := chain[:len(chain)-1]
+	if len(lookup) == 0 {
+		return nil
+	}
+
+	sch, err := schema.Parse(dest, &translationSchemaCache, s.db.NamingStrategy)
+	if err != nil {
+		return err
+	}
+	rv := reflect.Indirect(reflect.ValueOf(dest))
+	var elems []reflect.Value
+	switch rv.Kind() {
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			elem := rv.Index(i)
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			elems = append(elems, elem)
+		}
+	case reflect.Struct:
+		elems = append(elems, rv)
+	}
+	if len(elems) == 0 || sch.PrioritizedPrimaryField == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	ids := make([]string, len(elems))
+	for i, elem := range elems {
+		v, _ := sch.PrioritizedPrimaryField.ValueOf(ctx, elem)
+		ids[i] = fmt.Sprint(v)
+	}
+	var translations []models.ContentTranslation
+	if err := s.db.Where("content_type = ? AND content_id IN ? AND locale IN ?", contentType, ids, lookup).
+		Find(&translations).Error; err != nil {
+		return err
+	}
+	if len(translations) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(translations))
+	for _, t := range translations {
+		values[t.ContentID+"|"+t.Field+"|"+t.Locale] = t.Value
+	}
+
+	for i, elem := range elems {
+		for _, field := range spec.Fields {
+			f := sch.LookUpField(field)
+			if f == nil {
+				continue
+			}
+			for _, loc := range lookup {
+				if v, ok := values[ids[i]+"|"+field+"|"+loc]; ok && v != "" {
+					if err := f.Set(ctx, elem, v); err != nil {
+						return err
+					}
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sourceValues 读取内容的原文；内容不存在时返回 ErrContentNotFound
+func (s *TranslationService) sourceValues(spec *TranslatableType, id string) (map[string]string, error) {
+	cols := []string{"id::text AS id"}
+	for _, f := range spec.Fields {
+		cols = append(cols, `"`+f+`"`)
+	}
+	var rows []map[string]interface{}
+	if err := s.db.Table(spec.table).Select(cols).Where("id::text = ?", id).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrContentNotFound, spec.Label)
+	}
+	source := make(map[string]string, len(spec.Fields))
+	for _, f := range spec.Fields {
+		if v, ok := rows[0][f].(string); ok {
+			source[f] = v
+		}
+	}
+	return source, nil
+}
+
+// TranslationValue 一个字段的译文
+type TranslationValue struct {
+	Value     string `json:"value"`
+	Stale     bool   `json:"stale"` // 翻译后原文已修改
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ItemTranslations 一条内容的原文与各语言译文
+type ItemTranslations struct {
+	Type    string                                 `json:"type"`
+	ID      string                                 `json:"id"`
+	Source  map[string]string                      `json:"source"`
+	Locales map[string]map[string]TranslationValue `json:"locales"`
+}
+
+// Get 一条内容的原文与全部译文
+func (s *TranslationService) Get(contentType, id string) (*ItemTranslations, error) {
+	spec, ok := translatableType(contentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: 不支持的内容类型 %s", ErrTranslationInvalid, contentType)
+	}
+	source, err := s.sourceValues(spec, id)
+	if err != nil {
+		return nil, err
+	}
+	var translations []models.ContentTranslation
+	if err := s.db.Where("content_type = ? AND content_id = ?", contentType, id).Find(&translations).Error; err != nil {
+		return nil, err
+	}
+	item := &ItemTranslations{Type: contentType, ID: id, Source: source, Locales: map[string]map[string]TranslationValue{}}
+	for _, t := range translations {
+		if item.Locales[t.Locale] == nil {
+			item.Locales[t.Locale] = map[string]TranslationValue{}
+		}
+		item.Locales[t.Locale][t.Field] = TranslationValue{
+			Value:     t.Value,
+			Stale:     t.SourceHash != sourceHash(source[t.Field]),
+			UpdatedAt: t.UpdatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+	return item, nil
+}
+
+// Save 保存一条内容在某个语言下的译文；值为空串表示删除该字段的译文
+func (s *TranslationService) Save(contentType, id, locale string, fields map[string]string, updatedBy uuid.UUID) (*ItemTranslations, error) {
+	spec, ok := translatableType(contentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: 不支持的内容类型 %s", ErrTranslationInvalid, contentType)
+	}
+	normalized, ok := NormalizeLocale(locale)
+	if !ok || normalized == DefaultLocale {
+		return nil, fmt.Errorf("%w: 语言应为 %s 之一（原文 %s 请直接编辑内容）", ErrTranslationInvalid, strings.Join(SupportedLocales[1:], "、"), DefaultLocale)
+	}
+	for field := range fields {
+		if !spec.hasField(field) {
+			return nil, fmt.Errorf("%w: %s 没有可翻译字段 %s", ErrTranslationInvalid, spec.Label, field)
+		}
+	}
+	source, err := s.sourceValues(spec, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for field, value := range fields {
+			if strings.TrimSpace(value) == "" {
+				if err := tx.Where("content_type = ? AND content_id = ? AND locale = ? AND field = ?", contentType, id, normalized, field).
+					Delete(&models.ContentTranslation{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			by := updatedBy
+			t := models.ContentTranslation{
+				ContentType: contentType,
+				ContentID:   id,
+				Locale:      normalized,
+				Field:       field,
+				Value:       value,
+				SourceHash:  sourceHash(source[field]),
+				UpdatedBy:   &by,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "content_type"}, {Name: "content_id"}, {Name: "locale"}, {Name: "field"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "source_hash", "updated_by", "updated_at"}),
+			}).Create(&t).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(contentType, id)
+}
+
+// DeleteLocale 删除一条内容在某个语言下的全部译文
+func (s *TranslationService) DeleteLocale(contentType, id, locale string) error {
+	if _, ok := translatableType(contentType); !ok {
+		return fmt.Errorf("%w: 不支持的内容类型 %s", ErrTranslationInvalid, contentType)
+	}
+	normalized, ok := NormalizeLocale(locale)
+	if !ok {
+		return fmt.Errorf("%w: 不支持的语言 %s", ErrTranslationInvalid, locale)
+	}
+	return s.db.Where("content_type = ? AND content_id = ? AND locale = ?", contentType, id, normalized).
+		Delete(&models.ContentTranslation{}).Error
+}
+
+// MissingItem 缺少译文的一条内容
+type MissingItem struct {
+	Type    string   `json:"type"`
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Missing []string `json:"missing"` // 没有该语言译文的字段
+	Stale   []string `json:"stale"`   // 有译文但原文已修改的字段
+	// Fallback 缺失字段当前实际展示的语言（回退链上的其他语言或原文）
+	Fallback map[string]string `json:"fallback"`
+}
+
+// MissingSummary 某类内容的翻译进度
+type MissingSummary struct {
+	Type          string `json:"type"`
+	Label         string `json:"label"`
+	Items         int    `json:"items"`    // 需要翻译的内容数
+	Complete      int    `json:"complete"` // 全部字段都有未过期译文的内容数
+	MissingFields int    `json:"missing_fields"`
+	StaleFields   int    `json:"stale_fields"`
+}
+
+// MissingReport 某个语言的缺失翻译
+type MissingReport struct {
+	Locale   string           `json:"locale"`
+	Fallback []string         `json:"fallback"`
+	Summary  []MissingSummary `json:"summary"`
+	Items    []MissingItem    `json:"items"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// Missing 列出某个语言下缺少或已过期译文的内容；contentType 为空时统计全部类型。
+// 原文为空的字段不需要翻译；有启用状态的内容只统计启用的
+func (s *TranslationService) Missing(locale, contentType string, includeStale bool, page, pageSize int) (*MissingReport, error) {
+	normalized, ok := NormalizeLocale(locale)
+	if !ok || normalized == DefaultLocale {
+		return nil, fmt.Errorf("%w: 语言应为 %s 之一", ErrTranslationInvalid, strings.Join(SupportedLocales[1:], "、"))
+	}
+	specs := translatableTypes
+	if contentType != "" {
+		spec, ok := translatableType(contentType)
+		if !ok {
+			return nil, fmt.Errorf("%w: 不支持的内容类型 %s", ErrTranslationInvalid, contentType)
+		}
+		specs = []*TranslatableType{spec}
+	}
+	chain := FallbackChain(normalized)
+
+	report := &MissingReport{Locale: normalized, Fallback: chain[1:], Summary: []MissingSummary{}, Page: page, PageSize: pageSize}
+	var all []MissingItem
+	for _, spec := range specs {
+		cols := []string{"id::text AS id"}
+		for _, f := range spec.Fields {
+			cols = append(cols, `"`+f+`"`)
+		}
+		query := s.db.Table(spec.table).Select(cols)
+		if spec.activeColumn != "" {
+			query = query.Where(spec.activeColumn+" = ?", true)
+		}
+		var rows []map[string]interface{}
+		if err := query.Order("created_at").Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("查询%s失败: %w", spec.Label, err)
+		}
+		var translations []models.ContentTranslation
+		if err := s.db.Select("content_id, locale, field, source_hash").
+			Where("content_type = ? AND locale IN ?", spec.Type, chain[:len(chain)-1]).
+			Find(&translations).Error; err != nil {
+			return nil, err
+		}
+		hashes := make(map[string]string, len(translations))
+		for _, t := range translations {
+			hashes[t.ContentID+"|"+t.Field+"|"+t.Locale] = t.SourceHash
+		}
+
+		summary := MissingSummary{Type: spec.Type, Label: spec.Label}
+		for _, row := range rows {
+			id, _ := row["id"].(string)
+			item := MissingItem{Type: spec.Type, ID: id, Missing: []string{}, Stale: []string{}, Fallback: map[string]string{}}
+			item.Title, _ = row[spec.titleField].(string)
+			if r := []rune(item.Title); len(r) > 50 {
+				item.Title = string(r[:50]) + "…"
+			}
+			needed := false
+			for _, field := range spec.Fields {
+				source, _ := row[field].(string)
+				if strings.TrimSpace(source) == "" {
+					continue
+				}
+				needed = true
+				hash, ok := hashes[id+"|"+field+"|"+normalized]
+				if !ok {
+					item.Missing = append(item.Missing, field)
+					item.Fallback[field] = DefaultLocale
+					for _, loc := range chain[1 : len(chain)-1] {
+						if _, ok := hashes[id+"|"+field+"|"+loc]; ok {
+							item.Fallback[field] = loc
+							break
+						}
+					}
+					continue
+				}
+				if hash != sourceHash(source) {
+					item.Stale = append(item.Stale, field)
+				}
+			}
+			if !needed {
+				continue
+			}
+			summary.Items++
+			summary.MissingFields += len(item.Missing)
+			summary.StaleFields += len(item.Stale)
+			if len(item.Missing) == 0 && len(item.Stale) == 0 {
+				summary.Complete++
+				continue
+			}
+			if len(item.Missing) > 0 || includeStale {
+				all = append(all, item)
+			}
+		}
+		report.Summary = append(report.Summary, summary)
+	}
+
+	report.Total = len(all)
+	start := (page - 1) * pageSize
+	if start > len(all) {
+		start = len(all)
+	}
+	end := start + pageSize
+	if end > len(all) {
+		end = len(all)
+	}
+	report.Items = all[start:end]
+	return report, nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/videoanalysis"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// videoAnalysisTimeout 单次分析的超时时间
+const videoAnalysisTimeout = 2 * time.Minute
+
+var (
+	ErrVideoAnalysisNotFound    = errors.New("分析记录不存在")
+	ErrVideoAnalysisUnsupported = errors.New("只支持分析脱敏练习上传的视频")
+)
+
+// defaultVideoAnalysisPrompt 与客户端后端分析接口使用的提示词保持一致
+const defaultVideoAnalysisPrompt = "你是一位专业的口吃矫正训练导师。请分析用户上传的练习视频，从以下维度给出 0-100 的评分，并用鼓励的语气给出具体、可执行的改进建议。"
+
+// VideoAnalysisFilter 分析记录的筛选条件
+type VideoAnalysisFilter struct {
+	Source   string
+	VideoID  *uuid.UUID
+	UserID   *uuid.UUID
+	ModuleID string
+	StepID   *uuid.UUID
+	Status   string
+}
+
+// VideoAnalysisRow 分析记录及用户名、模块与步骤标题
+type VideoAnalysisRow struct {
+	models.VideoAnalysis
+	Username    string `json:"username"`
+	ModuleTitle string `json:"module_title"`
+	StepTitle   string `json:"step_title"`
+}
+
+// VideoAnalysisService 管理脱敏练习视频的 AI 分析结果，重新分析通过可替换的分析器完成
+type VideoAnalysisService struct {
+	db       *gorm.DB
+	media    *MediaService
+	analyzer videoanalysis.Analyzer
+}
+
+func NewVideoAnalysisService(db *gorm.DB, media *MediaService, analyzer videoanalysis.Analyzer) *VideoAnalysisService {
+	return &VideoAnalysisService{db: db, media: media, analyzer: analyzer}
+}
+
+func (f VideoAnalysisFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Source != "" {
+		query = query.Where("a.video_source = ?", f.Source)
+	}
+	if f.VideoID != nil {
+		query = query.Where("a.video_id = ?", *f.VideoID)
+	}
+	if f.UserID != nil {
+		query = query.Where("a.user_id = ?", *f.UserID)
+	}
+	if f.ModuleID != "" {
+		query = query.Where("a.module_id = ?", f.ModuleID)
+	}
+	if f.StepID != nil {
+		query = query.Where("a.step_id = ?", *f.StepID)
+	}
+	if f.Status != "" {
+		query = query.Where("a.status = ?", f.Status)
+	}
+	return query
+}
+
+func (s *VideoAnalysisService) rows() *gorm.DB {
+	return s.db.Table("video_analyses a").
+		Select("a.*, COALESCE(users.username, '') AS username, COALESCE(m.title, '') AS module_title, COALESCE(st.title, '') AS step_title").
+		Joins("LEFT JOIN users ON users.id = a.user_id").
+		Joins("LEFT JOIN exposure_modules m ON m.id = a.module_id").
+		Joins("LEFT JOIN exposure_steps st ON st.id = a.step_id")
+}
+
+// List 按筛选条件分页查询分析记录，按时间倒序
+func (s *VideoAnalysisService) List(f VideoAnalysisFilter, page, pageSize int) ([]VideoAnalysisRow, int64, error) {
+	var total int64
+	if err := f.apply(s.db.Table("video_analyses a")).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	rows := make([]VideoAnalysisRow, 0)
+	err := f.apply(s.rows()).
+		Order("a.created_at DESC, a.id").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// Get 按ID查找分析记录
+func (s *VideoAnalysisService) Get(id uuid.UUID) (*VideoAnalysisRow, error) {
+	var rows []VideoAnalysisRow
+	if err := s.rows().Where("a.id = ?", id).Limit(1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrVideoAnalysisNotFound
+	}
+	return &rows[0], nil
+}
+
+// Run 用当前分析器重新分析视频并保存结果；分析失败同样保存记录（Status 为 failed），
+// 只有视频不存在、不支持或数据库错误时返回 error。prompt 为空时按步骤生成
+func (s *VideoAnalysisService) Run(ctx context.Context, source string, sourceID uuid.UUID, prompt string, triggeredBy *uuid.UUID) (*VideoAnalysisRow, error) {
+	asset, err := NewVideoAssetService(s.db).Get(source, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Source != VideoSourceExposure {
+		return nil, ErrVideoAnalysisUnsupported
+	}
+
+	analysis := &models.VideoAnalysis{
+		AssetID:     asset.ID,
+		VideoSource: asset.Source,
+		VideoID:     asset.SourceID,
+		UserID:      asset.UserID,
+		ModuleID:    asset.ModuleID,
+		Analyzer:    s.analyzer.Name(),
+		TriggeredBy: triggeredBy,
+	}
+	if stepID, err := uuid.Parse(asset.StepID); err == nil {
+		analysis.StepID = &stepID
+	}
+	dimensions, err := s.dimensions(asset.ModuleID)
+	if err != nil {
+		return nil, err
+	}
+	analysis.Prompt = strings.TrimSpace(prompt)
+	if analysis.Prompt == "" {
+		analysis.Prompt, err = s.prompt(analysis.StepID, dimensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, latency, err := s.analyze(ctx, asset.URL, analysis.Prompt, dimensions)
+	analysis.LatencyMs = latency.Milliseconds()
+	if err != nil {
+		analysis.Status = models.VideoAnalysisFailed
+		analysis.Error = err.Error()
+	} else {
+		analysis.Status = models.VideoAnalysisSuccess
+		analysis.Model = result.Model
+		analysis.Verdict = result.Verdict
+		analysis.Scores = make(models.JSONB, len(result.Scores))
+		for d, score := range result.Scores {
+			analysis.Scores[d] = math.Round(score*10) / 10
+		}
+	}
+	if err := s.db.Create(analysis).Error; err != nil {
+		return nil, err
+	}
+	return s.Get(analysis.ID)
+}
+
+// analyze 从存储读取视频并调用分析器，返回耗时
+func (s *VideoAnalysisService) analyze(ctx context.Context, videoURL, prompt string, dimensions []string) (*videoanalysis.Result, time.Duration, error) {
+	key, ok := s.media.Key(videoURL)
+	if !ok {
+		return nil, 0, errors.New("视频不在媒体存储中，无法读取")
+	}
+	ctx, cancel := context.WithTimeout(ctx, videoAnalysisTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rc, info, err := s.media.Storage().Get(ctx, key)
+	if err != nil {
+		return nil, time.Since(start), fmt.Errorf("读取视频失败: %w", err)
+	}
+	defer rc.Close()
+	result, err := s.analyzer.Analyze(ctx, &videoanalysis.Request{
+		Video:       rc,
+		ContentType: info.ContentType,
+		Prompt:      prompt,
+		Dimensions:  dimensions,
+	})
+	return result, time.Since(start), err
+}
+
+// dimensions 模块中"AI分析"步骤配置的分析维度，未配置时为全部维度
+func (s *VideoAnalysisService) dimensions(moduleID string) ([]string, error) {
+	if moduleID == "" {
+		return videoanalysis.Dimensions, nil
+	}
+	var configs []string
+	err := s.db.Model(&models.ExposureStep{}).
+		Where("module_id = ? AND step_type = ?", moduleID, "analysis").
+		Select("COALESCE(config::text, '')").
+		Order("step_order").Limit(1).
+		Scan(&configs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 || configs[0] == "" {
+		return videoanalysis.Dimensions, nil
+	}
+	var config struct {
+		Dimensions []string `json:"dimensions"`
+	}
+	if err := json.Unmarshal([]byte(configs[0]), &config); err != nil || len(config.Dimensions) == 0 {
+		return videoanalysis.Dimensions, nil
+	}
+	return config.Dimensions, nil
+}
+
+// prompt 默认提示词：评分维度及视频所属步骤的标题、说明
+func (s *VideoAnalysisService) prompt(stepID *uuid.UUID, dimensions []string) (string, error) {
+	var b strings.Builder
+	b.WriteString(defaultVideoAnalysisPrompt)
+	labels := make([]string, 0, len(dimensions))
+	for _, d := range dimensions {
+		if label, ok := videoanalysis.DimensionLabels[d]; ok {
+			labels = append(labels, label)
+		}
+	}
+	b.WriteString("\n评分维度：" + strings.Join(labels, "、"))
+
+	if stepID != nil {
+		var steps []models.ExposureStep
+		if err := s.db.Where("id = ?", *stepID).Limit(1).Find(&steps).Error; err != nil {
+			return "", err
+		}
+		if len(steps) > 0 {
+			b.WriteString("\n练习任务：" + steps[0].Title)
+			if steps[0].Description != "" {
+				b.WriteString("\n任务说明：" + steps[0].Description)
+			}
+		}
+	}
+	return b.String(), nil
+}
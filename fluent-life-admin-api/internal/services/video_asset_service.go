@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 视频来源
+const (
+	VideoSourceExposure = "exposure_module"
+	VideoSourcePost     = "community_post"
+)
+
+// videoSyncLookback 增量同步时向前多扫描的时长：训练记录的 video_url 可能在记录创建之后才上传完成
+const videoSyncLookback = 24 * time.Hour
+
+var ErrVideoNotFound = errors.New("视频不存在")
+
+const (
+	recordVideoCondition = "t.type = 'exposure' AND COALESCE(t.data->>'video_url', '') <> ''"
+	// 帖子的 image 列同时存放图片与视频，按扩展名识别视频
+	postVideoCondition = "COALESCE(p.image, '') <> '' AND (p.image LIKE '%.webm%' OR p.image LIKE '%.mp4%')"
+)
+
+// resetModerationOnURLChange 用户更换视频后重新进入审核队列；SET 中的 video_assets.url 为更新前的值
+const resetModerationOnURLChange = `
+				moderation_status = CASE WHEN video_assets.url <> EXCLUDED.url THEN '` + models.VideoModerationPending + `' ELSE video_assets.moderation_status END,
+				moderation_reason = CASE WHEN video_assets.url <> EXCLUDED.url THEN '' ELSE video_assets.moderation_reason END,
+				reviewer_note = CASE WHEN video_assets.url <> EXCLUDED.url THEN '' ELSE video_assets.reviewer_note END,
+				reviewed_by = CASE WHEN video_assets.url <> EXCLUDED.url THEN NULL ELSE video_assets.reviewed_by END,
+				reviewed_at = CASE WHEN video_assets.url <> EXCLUDED.url THEN NULL ELSE video_assets.reviewed_at END`
+
+// VideoSyncReport 一次同步的结果
+type VideoSyncReport struct {
+	Records int64 `json:"records"` // 写入/更新的训练记录视频数
+	Posts   int64 `json:"posts"`   // 写入/更新的帖子视频数
+	Removed int64 `json:"removed"` // 来源已删除或已不含视频而移除的索引数
+}
+
+// VideoAssetFilter 视频列表的筛选条件，Start/End 为半开区间
+type VideoAssetFilter struct {
+	Source   string
+	UserID   *uuid.UUID
+	ModuleID string
+	Status   string // 审核状态
+	Start    *time.Time
+	End      *time.Time
+}
+
+// VideoAssetRow 视频索引及用户名、模块标题
+type VideoAssetRow struct {
+	models.VideoAsset
+	Username    string
+	ModuleTitle string
+}
+
+// VideoAssetService 维护 video_assets 视频索引。
+// 训练记录与帖子由客户端后端写入，索引通过周期性同步保持一致；管理端删除视频时同时更新索引
+type VideoAssetService struct {
+	db *gorm.DB
+}
+
+func NewVideoAssetService(db *gorm.DB) *VideoAssetService {
+	return &VideoAssetService{db: db}
+}
+
+// Sync 把 since 之后创建的训练记录视频、之后更新的帖子视频写入索引，并移除来源已失效的索引；
+// since 为零值时全量同步
+func (s *VideoAssetService) Sync(since time.Time) (*VideoSyncReport, error) {
+	report := &VideoSyncReport{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		recordSince, postSince := "", ""
+		var args []interface{}
+		if !since.IsZero() {
+			recordSince = " AND t.created_at >= ?"
+			postSince = " AND p.updated_at >= ?"
+			args = append(args, since)
+		}
+
+		// 被拒的视频已被用户更换或移除时，撤销对来源的隐藏/标记：新视频重新进入审核队列，由审核结果决定是否再次隐藏。
+		// 需在下面的 upsert 重置审核状态之前执行，扫描范围与 upsert 相同
+		if err := tx.Exec(`
+			UPDATE training_records t SET flagged = FALSE
+			FROM video_assets va
+			WHERE va.source = '`+VideoSourceExposure+`' AND va.source_id = t.id AND va.moderation_status = '`+models.VideoModerationRejected+`'
+				AND t.flagged AND COALESCE(t.data->>'video_url', '') <> va.url`+recordSince, args...).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			UPDATE posts p SET hidden = FALSE
+			FROM video_assets va
+			WHERE va.source = '`+VideoSourcePost+`' AND va.source_id = p.id AND va.moderation_status = '`+models.VideoModerationRejected+`'
+				AND p.hidden AND COALESCE(p.image, '') <> va.url`+postSince, args...).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			INSERT INTO video_assets (id, source, source_id, user_id, url, module_id, step_id, title, duration, created_at, synced_at)
+			SELECT gen_random_uuid(), '`+VideoSourceExposure+`', t.id, t.user_id, t.data->>'video_url',
+				COALESCE(t.data->>'module_id', ''), COALESCE(t.data->>'step_id', ''),
+				LEFT(COALESCE(t.data->>'step_title', ''), 200), t.duration, t.created_at, NOW()
+			FROM training_records t
+			WHERE `+recordVideoCondition+recordSince+`
+			ON CONFLICT (source, source_id) DO UPDATE SET
+				user_id = EXCLUDED.user_id, url = EXCLUDED.url, module_id = EXCLUDED.module_id, step_id = EXCLUDED.step_id,
+				title = EXCLUDED.title, duration = EXCLUDED.duration, created_at = EXCLUDED.created_at, synced_at = EXCLUDED.synced_at,`+
+			resetModerationOnURLChange,
+			args...)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Records = result.RowsAffected
+
+		result = tx.Exec(`
+			INSERT INTO video_assets (id, source, source_id, user_id, url, module_id, step_id, title, duration, created_at, synced_at)
+			SELECT gen_random_uuid(), '`+VideoSourcePost+`', p.id, p.user_id, p.image, '', '',
+				CASE WHEN char_length(p.content) > 50 THEN LEFT(p.content, 50) || '...' ELSE p.content END,
+				0, p.created_at, NOW()
+			FROM posts p
+			WHERE `+postVideoCondition+postSince+`
+			ON CONFLICT (source, source_id) DO UPDATE SET
+				user_id = EXCLUDED.user_id, url = EXCLUDED.url, title = EXCLUDED.title,
+				created_at = EXCLUDED.created_at, synced_at = EXCLUDED.synced_at,`+
+			resetModerationOnURLChange,
+			args...)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Posts = result.RowsAffected
+
+		// 按主键逐条确认来源仍然存在且包含视频，删除与更新都能覆盖
+		result = tx.Exec(`
+			DELETE FROM video_assets va
+			WHERE (va.source = '` + VideoSourceExposure + `' AND NOT EXISTS (
+					SELECT 1 FROM training_records t WHERE t.id = va.source_id AND ` + recordVideoCondition + `))
+				OR (va.source = '` + VideoSourcePost + `' AND NOT EXISTS (
+					SELECT 1 FROM posts p WHERE p.id = va.source_id AND ` + postVideoCondition + `))`)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Removed = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// RunScheduler 周期性增量同步；首次运行及跨天后的第一次运行做全量同步
+func (s *VideoAssetService) RunScheduler(ctx context.Context, interval time.Duration) {
+	lastDay := ""
+	var lastRun time.Time
+	run := func() {
+		now := time.Now()
+		today := StartOfDay(now).Format("2006-01-02")
+		var since time.Time
+		if lastDay == today {
+			since = lastRun.Add(-videoSyncLookback)
+		}
+		report, err := s.Sync(since)
+		if err != nil {
+			log.Printf("[video-assets] 同步失败: %v", err)
+			return
+		}
+		if report.Records+report.Posts+report.Removed > 0 {
+			log.Printf("[video-assets] 同步完成: 训练记录 %d, 帖子 %d, 移除 %d", report.Records, report.Posts, report.Removed)
+		}
+		lastDay = today
+		lastRun = now
+	}
+
+	run()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
+
+func (f VideoAssetFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Source != "" {
+		query = query.Where("va.source = ?", f.Source)
+	}
+	if f.UserID != nil {
+		query = query.Where("va.user_id = ?", *f.UserID)
+	}
+	if f.ModuleID != "" {
+		query = query.Where("va.module_id = ?", f.ModuleID)
+	}
+	if f.Status != "" {
+		query = query.Where("va.moderation_status = ?", f.Status)
+	}
+	if f.Start != nil {
+		query = query.Where("va.created_at >= ?", *f.Start)
+	}
+	if f.End != nil {
+		query = query.Where("va.created_at < ?", *f.End)
+	}
+	return query
+}
+
+func (s *VideoAssetService) rows() *gorm.DB {
+	return s.db.Table("video_assets va").
+		Select("va.*, COALESCE(users.username, '') AS username, COALESCE(m.title, '') AS module_title").
+		Joins("LEFT JOIN users ON users.id = va.user_id").
+		Joins("LEFT JOIN exposure_modules m ON m.id = va.module_id")
+}
+
+// List 按筛选条件分页查询视频，按来源记录的创建时间倒序
+func (s *VideoAssetService) List(f VideoAssetFilter, page, pageSize int) ([]VideoAssetRow, int64, error) {
+	var total int64
+	if err := f.apply(s.db.Table("video_assets va")).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	rows := make([]VideoAssetRow, 0)
+	err := f.apply(s.rows()).
+		Order("va.created_at DESC, va.id").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// Get 按来源记录ID查找视频，source 为空时不限来源
+func (s *VideoAssetService) Get(source string, sourceID uuid.UUID) (*VideoAssetRow, error) {
+	query := s.rows().Where("va.source_id = ?", sourceID)
+	if source != "" {
+		query = query.Where("va.source = ?", source)
+	}
+	var rows []VideoAssetRow
+	if err := query.Limit(1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrVideoNotFound
+	}
+	return &rows[0], nil
+}
+
+// Delete 从来源记录中移除视频（清除训练记录的 video_url 或帖子的 image）并删除索引，返回被删除的索引；
+// 存储中的文件由调用方通过 MediaService.RemoveURLs 删除
+func (s *VideoAssetService) Delete(source string, sourceID uuid.UUID) (*VideoAssetRow, error) {
+	asset, err := s.Get(source, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		switch asset.Source {
+		case VideoSourceExposure:
+			result = tx.Exec("UPDATE training_records SET data = data - 'video_url' WHERE id = ?", asset.SourceID)
+		case VideoSourcePost:
+			result = tx.Exec("UPDATE posts SET image = '' WHERE id = ?", asset.SourceID)
+		}
+		if result != nil && result.Error != nil {
+			return result.Error
+		}
+		return tx.Delete(&models.VideoAsset{}, "id = ?", asset.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 审核操作
+const (
+	VideoDecisionApprove = "approve"
+	VideoDecisionReject  = "reject"
+)
+
+const (
+	NotificationTypeVideoRejected = "video_rejected"
+	maxReviewerNoteLength         = 500
+)
+
+var ErrInvalidVideoReview = errors.New("审核参数无效")
+
+// VideoRejectReason 拒绝原因，Label 会出现在发给用户的通知中
+type VideoRejectReason struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// VideoRejectReasons 可选的拒绝原因，other 需要填写备注
+var VideoRejectReasons = []VideoRejectReason{
+	{Code: "inappropriate", Label: "包含不适宜的内容"},
+	{Code: "privacy", Label: "泄露了个人隐私信息"},
+	{Code: "spam", Label: "包含广告或无关推广"},
+	{Code: "irrelevant", Label: "与练习内容无关"},
+	{Code: "quality", Label: "视频无法播放或质量过低"},
+	{Code: "other", Label: "不符合社区规范"},
+}
+
+func rejectReasonLabel(code string) (string, bool) {
+	for _, r := range VideoRejectReasons {
+		if r.Code == code {
+			return r.Label, true
+		}
+	}
+	return "", false
+}
+
+// VideoReviewInput 一次审核的操作、原因与备注
+type VideoReviewInput struct {
+	Decision string `json:"decision"` // approve | reject
+	Reason   string `json:"reason"`   // 拒绝原因代码，拒绝时必填
+	Note     string `json:"note"`     // 审核备注，仅管理端可见
+}
+
+func (in *VideoReviewInput) normalize() error {
+	in.Note = strings.TrimSpace(in.Note)
+	if utf8.RuneCountInString(in.Note) > maxReviewerNoteLength {
+		return fmt.Errorf("%w: 备注不能超过 %d 个字符", ErrInvalidVideoReview, maxReviewerNoteLength)
+	}
+	switch in.Decision {
+	case VideoDecisionApprove:
+		in.Reason = ""
+	case VideoDecisionReject:
+		if _, ok := rejectReasonLabel(in.Reason); !ok {
+			return fmt.Errorf("%w: 无效的拒绝原因 %q", ErrInvalidVideoReview, in.Reason)
+		}
+		if in.Reason == "other" && in.Note == "" {
+			return fmt.Errorf("%w: 选择其他原因时需要填写备注", ErrInvalidVideoReview)
+		}
+	default:
+		return fmt.Errorf("%w: 审核操作只能是 approve 或 reject", ErrInvalidVideoReview)
+	}
+	return nil
+}
+
+// VideoModerationService 视频审核：拒绝时隐藏关联帖子或标记训练记录，并通知上传者；
+// 重新通过时恢复帖子与训练记录
+type VideoModerationService struct {
+	db *gorm.DB
+}
+
+func NewVideoModerationService(db *gorm.DB) *VideoModerationService {
+	return &VideoModerationService{db: db}
+}
+
+// Queue 待审核队列，先上传的先审核
+func (s *VideoModerationService) Queue(source string, page, pageSize int) ([]VideoAssetRow, int64, error) {
+	filter := VideoAssetFilter{Source: source, Status: models.VideoModerationPending}
+	var total int64
+	if err := filter.apply(s.db.Table("video_assets va")).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	rows := make([]VideoAssetRow, 0)
+	err := filter.apply(NewVideoAssetService(s.db).rows()).
+		Order("va.created_at, va.id").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// Counts 各审核状态的视频数
+func (s *VideoModerationService) Counts(source string) (map[string]int64, error) {
+	counts := map[string]int64{
+		models.VideoModerationPending:  0,
+		models.VideoModerationApproved: 0,
+		models.VideoModerationRejected: 0,
+	}
+	var rows []struct {
+		ModerationStatus string
+		Count            int64
+	}
+	query := s.db.Table("video_assets").Select("moderation_status, COUNT(*) AS count").Group("moderation_status")
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		counts[r.ModerationStatus] = r.Count
+	}
+	return counts, nil
+}
+
+// Review 审核一个视频，source 为空时不限来源；返回审核后的视频
+func (s *VideoModerationService) Review(source string, sourceID uuid.UUID, in VideoReviewInput, reviewer *uuid.UUID) (*VideoAssetRow, error) {
+	if err := in.normalize(); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("source_id = ?", sourceID)
+		if source != "" {
+			query = query.Where("source = ?", source)
+		}
+		var assets []models.VideoAsset
+		if err := query.Limit(1).Find(&assets).Error; err != nil {
+			return err
+		}
+		if len(assets) == 0 {
+			return ErrVideoNotFound
+		}
+		asset := assets[0]
+		previous := asset.ModerationStatus
+
+		status := models.VideoModerationApproved
+		if in.Decision == VideoDecisionReject {
+			status = models.VideoModerationRejected
+		}
+		now := time.Now()
+		err := tx.Model(&models.VideoAsset{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+			"moderation_status": status,
+			"moderation_reason": in.Reason,
+			"reviewer_note":     in.Note,
+			"reviewed_by":       reviewer,
+			"reviewed_at":       now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case status == models.VideoModerationRejected && previous != models.VideoModerationRejected:
+			if err := setVideoSourceRejected(tx, &asset, true); err != nil {
+				return err
+			}
+			return notifyVideoRejected(tx, &asset, in.Reason)
+		case status == models.VideoModerationApproved:
+			// 不只看上一个状态：被拒视频更换后由同步重置为待审核，来源上可能仍残留隐藏/标记
+			return setVideoSourceRejected(tx, &asset, false)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewVideoAssetService(s.db).Get(source, sourceID)
+}
+
+// setVideoSourceRejected 隐藏/恢复帖子，或标记/取消标记训练记录
+func setVideoSourceRejected(tx *gorm.DB, asset *models.VideoAsset, rejected bool) error {
+	switch asset.Source {
+	case VideoSourcePost:
+		return tx.Table("posts").Where("id = ?", asset.SourceID).Update("hidden", rejected).Error
+	case VideoSourceExposure:
+		return tx.Table("training_records").Where("id = ?", asset.SourceID).Update("flagged", rejected).Error
+	}
+	return nil
+}
+
+// notifyVideoRejected 通知上传者视频未通过审核；审核备注不发给用户
+func notifyVideoRejected(tx *gorm.DB, asset *models.VideoAsset, reason string) error {
+	label, _ := rejectReasonLabel(reason)
+	var content string
+	switch asset.Source {
+	case VideoSourcePost:
+		content = fmt.Sprintf("你在感悟广场发布的视频未通过审核，原因：%s。该帖子已被隐藏，其他用户将无法看到。", label)
+	default:
+		where := "脱敏练习"
+		if asset.Title != "" {
+			where = fmt.Sprintf("脱敏练习「%s」", asset.Title)
+		}
+		content = fmt.Sprintf("你在%s中上传的视频未通过审核，原因：%s。", where, label)
+	}
+	data, err := json.Marshal(map[string]string{
+		"source":    asset.Source,
+		"source_id": asset.SourceID.String(),
+		"reason":    reason,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.Notification{
+		UserID:  asset.UserID,
+		Type:    NotificationTypeVideoRejected,
+		Title:   "你的视频未通过审核",
+		Content: content,
+		Data:    string(data),
+	}).Error
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fluent-life-admin-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AIRolesSettingKey AI 实战模拟角色配置在 app_settings 中的 key
+const AIRolesSettingKey = "ai_simulation_roles"
+
+const (
+	maxVoiceCatalogSize   = 1 << 20
+	maxVoiceUsageUsers    = 50
+	voiceCatalogFetchWait = 30 * time.Second
+)
+
+// 被引用音色的状态
+const (
+	VoiceStatusEnabled  = "enabled"
+	VoiceStatusDisabled = "disabled"
+	VoiceStatusRemoved  = "removed" // 音色表中有，但已不在供应商清单中
+	VoiceStatusUnknown  = "unknown" // 音色表中不存在
+)
+
+var (
+	ErrInvalidVoiceCatalog = errors.New("音色清单无效")
+	ErrInvalidVoiceRemap   = errors.New("音色替换参数无效")
+)
+
+// VoiceCatalogEntry 供应商清单中的一个音色
+type VoiceCatalogEntry struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     *bool  `json:"enabled"` // 缺省为启用
+}
+
+func (e *VoiceCatalogEntry) enabled() bool {
+	return e.Enabled == nil || *e.Enabled
+}
+
+// VoiceCatalog 供应商音色清单，格式为 {"provider": "...", "voices": [...]} 或直接为音色数组
+type VoiceCatalog struct {
+	Provider string              `json:"provider"`
+	Voices   []VoiceCatalogEntry `json:"voices"`
+}
+
+// ParseVoiceCatalog 解析并校验音色清单
+func ParseVoiceCatalog(data []byte) (*VoiceCatalog, error) {
+	data = bytes.TrimSpace(data)
+	var catalog VoiceCatalog
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &catalog.Voices); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidVoiceCatalog, err)
+		}
+	} else if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVoiceCatalog, err)
+	}
+	if len(catalog.Voices) == 0 {
+		return nil, fmt.Errorf("%w: 清单中没有音色", ErrInvalidVoiceCatalog)
+	}
+
+	seen := make(map[string]bool, len(catalog.Voices))
+	for i := range catalog.Voices {
+		v := &catalog.Voices[i]
+		v.Type = strings.TrimSpace(v.Type)
+		v.Name = strings.TrimSpace(v.Name)
+		v.Description = strings.TrimSpace(v.Description)
+		switch {
+		case v.Type == "":
+			return nil, fmt.Errorf("%w: 第 %d 个音色缺少 type", ErrInvalidVoiceCatalog, i+1)
+		case seen[v.Type]:
+			return nil, fmt.Errorf("%w: 音色 %s 重复", ErrInvalidVoiceCatalog, v.Type)
+		case utf8.RuneCountInString(v.Type) > 100:
+			return nil, fmt.Errorf("%w: 音色 %s 的 type 超过 100 个字符", ErrInvalidVoiceCatalog, v.Type)
+		case utf8.RuneCountInString(v.Name) > 100:
+			return nil, fmt.Errorf("%w: 音色 %s 的名称超过 100 个字符", ErrInvalidVoiceCatalog, v.Type)
+		case utf8.RuneCountInString(v.Description) > 255:
+			return nil, fmt.Errorf("%w: 音色 %s 的描述超过 255 个字符", ErrInvalidVoiceCatalog, v.Type)
+		}
+		if v.Name == "" {
+			v.Name = v.Type
+		}
+		seen[v.Type] = true
+	}
+	return &catalog, nil
+}
+
+// LoadVoiceCatalog 从 http(s) 地址或本地文件读取音色清单
+func LoadVoiceCatalog(ctx context.Context, source string) (*VoiceCatalog, error) {
+	var data []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		ctx, cancel := context.WithTimeout(ctx, voiceCatalogFetchWait)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidVoiceCatalog, err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("下载音色清单失败: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("下载音色清单失败: HTTP %d", resp.StatusCode)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxVoiceCatalogSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("下载音色清单失败: %w", err)
+		}
+	} else {
+		var err error
+		data, err = os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("读取音色清单失败: %w", err)
+		}
+	}
+	if len(data) > maxVoiceCatalogSize {
+		return nil, fmt.Errorf("%w: 清单超过 1MB", ErrInvalidVoiceCatalog)
+	}
+	return ParseVoiceCatalog(data)
+}
+
+// VoiceUserRef 使用某音色的用户
+type VoiceUserRef struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+// VoiceRoleRef 使用某音色的 AI 角色
+type VoiceRoleRef struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// VoiceUsage 一个音色被用户设置与 AI 角色引用的情况
+type VoiceUsage struct {
+	Type      string         `json:"type"`
+	Name      string         `json:"name,omitempty"`
+	Status    string         `json:"status"` // enabled | disabled | removed | unknown
+	UserCount int64          `json:"user_count"`
+	Users     []VoiceUserRef `json:"users"` // 最多 50 个
+	Roles     []VoiceRoleRef `json:"roles"`
+}
+
+// VoiceSyncReport 一次音色清单同步的结果
+type VoiceSyncReport struct {
+	DryRun      bool         `json:"dry_run"`
+	Provider    string       `json:"provider"`
+	Created     []string     `json:"created"`
+	Updated     []string     `json:"updated"`
+	Unchanged   int          `json:"unchanged"`
+	Disabled    []string     `json:"disabled"`    // 本次同步停用的音色
+	Missing     []string     `json:"missing"`     // 音色表中有、清单中没有的音色，不会删除
+	Unavailable []VoiceUsage `json:"unavailable"` // 仍被引用，但已停用、不在清单中或不存在的音色
+}
+
+// VoiceRemapReport 批量替换音色的结果
+type VoiceRemapReport struct {
+	DryRun bool     `json:"dry_run"`
+	From   []string `json:"from"`
+	To     string   `json:"to"`
+	Users  int64    `json:"users"` // 替换的用户设置数
+	Roles  []string `json:"roles"` // 替换的 AI 角色ID
+}
+
+// VoiceCatalogService 音色清单同步、引用检查与批量替换
+type VoiceCatalogService struct {
+	db *gorm.DB
+}
+
+func NewVoiceCatalogService(db *gorm.DB) *VoiceCatalogService {
+	return &VoiceCatalogService{db: db}
+}
+
+// Sync 按清单新增或更新音色，不删除任何音色；disableMissing 为 true 时停用不在清单中的音色
+func (s *VoiceCatalogService) Sync(catalog *VoiceCatalog, disableMissing, dryRun bool) (*VoiceSyncReport, error) {
+	report := &VoiceSyncReport{
+		DryRun:   dryRun,
+		Provider: catalog.Provider,
+		Created:  make([]string, 0),
+		Updated:  make([]string, 0),
+		Disabled: make([]string, 0),
+		Missing:  make([]string, 0),
+	}
+	inCatalog := make(map[string]*VoiceCatalogEntry, len(catalog.Voices))
+	for i := range catalog.Voices {
+		inCatalog[catalog.Voices[i].Type] = &catalog.Voices[i]
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.VoiceType
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("type").Find(&existing).Error; err != nil {
+			return err
+		}
+		byType := make(map[string]*models.VoiceType, len(existing))
+		for i := range existing {
+			v := &existing[i]
+			byType[v.Type] = v
+			if _, ok := inCatalog[v.Type]; ok {
+				continue
+			}
+			report.Missing = append(report.Missing, v.Type)
+			if disableMissing && v.Enabled {
+				report.Disabled = append(report.Disabled, v.Type)
+				if !dryRun {
+					if err := tx.Model(v).Update("enabled", false).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		for i := range catalog.Voices {
+			entry := &catalog.Voices[i]
+			current, ok := byType[entry.Type]
+			if !ok {
+				report.Created = append(report.Created, entry.Type)
+				if !dryRun {
+					voice := models.VoiceType{Name: entry.Name, Type: entry.Type, Description: entry.Description, Enabled: entry.enabled()}
+					// Select("*") 让 enabled=false 也写入，而不是被列默认值 true 取代
+					if err := tx.Select("*").Create(&voice).Error; err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if current.Name == entry.Name && current.Description == entry.Description && current.Enabled == entry.enabled() {
+				report.Unchanged++
+				continue
+			}
+			report.Updated = append(report.Updated, entry.Type)
+			if current.Enabled && !entry.enabled() {
+				report.Disabled = append(report.Disabled, entry.Type)
+			}
+			if !dryRun {
+				err := tx.Model(current).Updates(map[string]interface{}{
+					"name":        entry.Name,
+					"description": entry.Description,
+					"enabled":     entry.enabled(),
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 按同步后的状态检查引用；预览时音色表未变化，状态由清单推算
+	usage, err := s.Usage()
+	if err != nil {
+		return nil, err
+	}
+	report.Unavailable = make([]VoiceUsage, 0)
+	for _, u := range usage {
+		entry, ok := inCatalog[u.Type]
+		switch {
+		case ok && entry.enabled():
+			continue
+		case ok:
+			u.Status = VoiceStatusDisabled
+		case u.Status != VoiceStatusUnknown:
+			u.Status = VoiceStatusRemoved
+		}
+		report.Unavailable = append(report.Unavailable, u)
+	}
+	return report, nil
+}
+
+// Usage 所有被用户设置或 AI 角色引用的音色，按 type 排序
+func (s *VoiceCatalogService) Usage() ([]VoiceUsage, error) {
+	usage := make(map[string]*VoiceUsage)
+	get := func(voiceType string) *VoiceUsage {
+		if usage[voiceType] == nil {
+			usage[voiceType] = &VoiceUsage{Type: voiceType, Users: make([]VoiceUserRef, 0), Roles: make([]VoiceRoleRef, 0)}
+		}
+		return usage[voiceType]
+	}
+
+	var counts []struct {
+		AIVoiceType string
+		Count       int64
+	}
+	err := s.db.Table("user_settings").
+		Select("ai_voice_type, COUNT(*) AS count").
+		Where("COALESCE(ai_voice_type, '') <> ''").
+		Group("ai_voice_type").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		get(c.AIVoiceType).UserCount = c.Count
+	}
+
+	var users []struct {
+		AIVoiceType string
+		UserID      uuid.UUID
+		Username    string
+	}
+	err = s.db.Raw(`
+		SELECT ai_voice_type, user_id, username FROM (
+			SELECT us.ai_voice_type, us.user_id, COALESCE(u.username, '') AS username,
+				ROW_NUMBER() OVER (PARTITION BY us.ai_voice_type ORDER BY u.username, us.user_id) AS rn
+			FROM user_settings us
+			LEFT JOIN users u ON u.id = us.user_id
+			WHERE COALESCE(us.ai_voice_type, '') <> ''
+		) t WHERE rn <= ?`, maxVoiceUsageUsers).
+		Scan(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		v := get(u.AIVoiceType)
+		v.Users = append(v.Users, VoiceUserRef{UserID: u.UserID, Username: u.Username})
+	}
+
+	roles, _, err := loadAIRoles(s.db)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		voiceType, _ := r["voice_type"].(string)
+		if voiceType == "" {
+			continue
+		}
+		id, _ := r["id"].(string)
+		name, _ := r["name"].(string)
+		enabled, _ := r["enabled"].(bool)
+		v := get(voiceType)
+		v.Roles = append(v.Roles, VoiceRoleRef{ID: id, Name: name, Enabled: enabled})
+	}
+
+	types := make([]string, 0, len(usage))
+	for t := range usage {
+		types = append(types, t)
+	}
+	var voices []models.VoiceType
+	if len(types) > 0 {
+		if err := s.db.Where("type IN ?", types).Find(&voices).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range types {
+		usage[t].Status = VoiceStatusUnknown
+	}
+	for _, v := range voices {
+		usage[v.Type].Name = v.Name
+		usage[v.Type].Status = VoiceStatusDisabled
+		if v.Enabled {
+			usage[v.Type].Status = VoiceStatusEnabled
+		}
+	}
+
+	sort.Strings(types)
+	result := make([]VoiceUsage, 0, len(types))
+	for _, t := range types {
+		result = append(result, *usage[t])
+	}
+	return result, nil
+}
+
+// UsageOf 单个音色的引用情况，没有被引用时返回 nil
+func (s *VoiceCatalogService) UsageOf(voiceType string) (*VoiceUsage, error) {
+	usage, err := s.Usage()
+	if err != nil {
+		return nil, err
+	}
+	for i := range usage {
+		if usage[i].Type == voiceType {
+			return &usage[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Remap 把用户设置与 AI 角色中引用 from 音色的地方替换为 to；to 必须是已启用的音色
+func (s *VoiceCatalogService) Remap(from []string, to string, dryRun bool) (*VoiceRemapReport, error) {
+	to = strings.TrimSpace(to)
+	fromSet := make(map[string]bool, len(from))
+	report := &VoiceRemapReport{DryRun: dryRun, From: make([]string, 0, len(from)), To: to, Roles: make([]string, 0)}
+	for _, f := range from {
+		f = strings.TrimSpace(f)
+		if f == "" || fromSet[f] {
+			continue
+		}
+		if f == to {
+			return nil, fmt.Errorf("%w: 替换前后的音色相同", ErrInvalidVoiceRemap)
+		}
+		fromSet[f] = true
+		report.From = append(report.From, f)
+	}
+	if len(report.From) == 0 || to == "" {
+		return nil, fmt.Errorf("%w: from 与 to 不能为空", ErrInvalidVoiceRemap)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.VoiceType{}).Where("type = ? AND enabled = ?", to, true).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: 目标音色 %s 不存在或未启用", ErrInvalidVoiceRemap, to)
+		}
+
+		users := tx.Table("user_settings").Where("ai_voice_type IN ?", report.From)
+		if dryRun {
+			if err := users.Count(&report.Users).Error; err != nil {
+				return err
+			}
+		} else {
+			result := users.Updates(map[string]interface{}{"ai_voice_type": to, "updated_at": time.Now()})
+			if result.Error != nil {
+				return result.Error
+			}
+			report.Users = result.RowsAffected
+		}
+
+		roles, setting, err := loadAIRoles(tx.Clauses(clause.Locking{Strength: "UPDATE"}))
+		if err != nil {
+			return err
+		}
+		for _, r := range roles {
+			if voiceType, _ := r["voice_type"].(string); fromSet[voiceType] {
+				r["voice_type"] = to
+				id, _ := r["id"].(string)
+				report.Roles = append(report.Roles, id)
+			}
+		}
+		if dryRun || len(report.Roles) == 0 {
+			return nil
+		}
+		value, err := json.Marshal(roles)
+		if err != nil {
+			return err
+		}
+		return tx.Model(setting).Update("value", string(value)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// loadAIRoles 读取 AI 角色配置；按原始 JSON 对象读取，保存时保留未知字段
+func loadAIRoles(db *gorm.DB) ([]map[string]interface{}, *models.AppSetting, error) {
+	var settings []models.AppSetting
+	if err := db.Where("key = ?", AIRolesSettingKey).Limit(1).Find(&settings).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(settings) == 0 {
+		return nil, nil, nil
+	}
+	var roles []map[string]interface{}
+	if err := json.Unmarshal([]byte(settings[0].Value), &roles); err != nil {
+		return nil, nil, fmt.Errorf("解析AI角色配置失败: %w", err)
+	}
+	return roles, &settings[0], nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fluent-life-admin-api/internal/models"
+	"fluent-life-admin-api/pkg/tts"
+
+	"gorm.io/gorm"
+)
+
+// DefaultVoicePreviewText 音色未配置试听文本时使用的默认文本
+const DefaultVoicePreviewText = "你好，我是你的练习伙伴。说话不用着急，我们一句一句慢慢来。"
+
+// voicePreviewTimeout 单次合成的超时时间
+const voicePreviewTimeout = time.Minute
+
+// VoicePreview 音色的试听音频
+type VoicePreview struct {
+	URL    string // 存储中的媒体地址
+	Text   string
+	Cached bool // 是否直接使用了缓存
+}
+
+// VoicePreviewService 合成音色试听音频并缓存在媒体存储中；合成服务、音色类型或试听文本变化后缓存失效
+type VoicePreviewService struct {
+	db       *gorm.DB
+	media    *MediaService
+	provider tts.Provider
+}
+
+func NewVoicePreviewService(db *gorm.DB, media *MediaService, provider tts.Provider) *VoicePreviewService {
+	return &VoicePreviewService{db: db, media: media, provider: provider}
+}
+
+// previewText 音色的试听文本
+func previewText(voice *models.VoiceType) string {
+	if text := strings.TrimSpace(voice.PreviewText); text != "" {
+		return text
+	}
+	return DefaultVoicePreviewText
+}
+
+// hash 当前合成服务、音色与试听文本的摘要，与 PreviewHash 一致时缓存有效
+func (s *VoicePreviewService) hash(voice *models.VoiceType) string {
+	sum := sha256.Sum256([]byte(s.provider.Name() + "\n" + voice.Type + "\n" + previewText(voice)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CachedURL 有效的缓存试听音频地址，没有缓存或已失效时返回空串
+func (s *VoicePreviewService) CachedURL(voice *models.VoiceType) string {
+	if voice.PreviewURL == "" || voice.PreviewHash != s.hash(voice) {
+		return ""
+	}
+	return voice.PreviewURL
+}
+
+// Preview 返回音色的试听音频，缓存失效或 force 为 true 时重新合成并替换旧文件
+func (s *VoicePreviewService) Preview(ctx context.Context, voice *models.VoiceType, force bool) (*VoicePreview, error) {
+	text := previewText(voice)
+	if url := s.CachedURL(voice); url != "" && !force {
+		return &VoicePreview{URL: url, Text: text, Cached: true}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, voicePreviewTimeout)
+	defer cancel()
+	audio, err := s.provider.Synthesize(ctx, &tts.Request{Voice: voice.Type, Text: text})
+	if err != nil {
+		return nil, fmt.Errorf("合成试听音频失败: %w", err)
+	}
+	if len(audio.Audio) == 0 {
+		return nil, errors.New("合成试听音频失败: 音频为空")
+	}
+
+	hash := s.hash(voice)
+	key := fmt.Sprintf("voice-previews/%s/%s%s", voice.ID, hash[:16], audio.Ext)
+	if err := s.media.Storage().Put(ctx, key, bytes.NewReader(audio.Audio), int64(len(audio.Audio)), audio.ContentType); err != nil {
+		return nil, fmt.Errorf("保存试听音频失败: %w", err)
+	}
+	url := s.media.URL(key)
+	err = s.db.Model(voice).UpdateColumns(map[string]interface{}{"preview_url": url, "preview_hash": hash}).Error
+	if err != nil {
+		return nil, err
+	}
+	old := voice.PreviewURL
+	voice.PreviewURL, voice.PreviewHash = url, hash
+	if old != "" && old != url {
+		s.media.RemoveURLs(ctx, []string{old})
+	}
+	return &VoicePreview{URL: url, Text: text}, nil
+}
+
+// Invalidate 清除音色的试听缓存并删除文件，音色删除或修改后调用
+func (s *VoicePreviewService) Invalidate(ctx context.Context, voice *models.VoiceType) {
+	if voice.PreviewURL == "" {
+		return
+	}
+	old := voice.PreviewURL
+	err := s.db.Model(&models.VoiceType{}).Where("id = ?", voice.ID).
+		UpdateColumns(map[string]interface{}{"preview_url": "", "preview_hash": ""}).Error
+	if err != nil {
+		log.Printf("[voice] 清除音色 %s 的试听缓存失败: %v", voice.Type, err)
+		return
+	}
+	voice.PreviewURL, voice.PreviewHash = "", ""
+	s.media.RemoveURLs(ctx, []string{old})
+}
@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"1,,2 * * * *",
+		"* * * foo *",
+	}
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) 期望返回错误", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, shanghai)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"* * * * *", "2026-01-01 10:00", "2026-01-01 10:01"},
+		{"0 9 * * *", "2026-01-01 09:00", "2026-01-02 09:00"},
+		{"0 9 * * *", "2026-01-01 08:59", "2026-01-01 09:00"},
+		{"*/15 * * * *", "2026-01-01 10:16", "2026-01-01 10:30"},
+		{"5/20 * * * *", "2026-01-01 10:26", "2026-01-01 10:45"},
+		{"0 8-10 * * *", "2026-01-01 10:30", "2026-01-02 08:00"},
+		{"30 7 1,15 * *", "2026-01-02 00:00", "2026-01-15 07:30"},
+		{"0 0 * feb *", "2026-01-10 00:00", "2026-02-01 00:00"},
+		{"0 9 * * mon-fri", "2026-01-02 10:00", "2026-01-05 09:00"}, // 周五之后是下周一
+		{"0 0 * * 7", "2026-01-01 00:00", "2026-01-04 00:00"},       // 7 表示周日
+		{"0 0 13 * 5", "2026-01-01 00:00", "2026-01-02 00:00"},      // 日与星期满足其一
+		{"0 0 29 2 *", "2026-01-01 00:00", "2028-02-29 00:00"},
+		{"@weekly", "2026-01-01 00:00", "2026-01-05 00:00"},
+		{"@monthly", "2026-01-31 23:59", "2026-02-01 00:00"},
+		{"0 0 31 2 *", "2026-01-01 00:00", ""}, // 永不触发
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		got := s.Next(at(tt.after))
+		if tt.want == "" {
+			if !got.IsZero() {
+				t.Errorf("%q after %s = %v，期望零值", tt.expr, tt.after, got)
+			}
+			continue
+		}
+		if !got.Equal(at(tt.want)) || got.Location() != shanghai {
+			t.Errorf("%q after %s = %v，期望 %s", tt.expr, tt.after, got, tt.want)
+		}
+	}
+}
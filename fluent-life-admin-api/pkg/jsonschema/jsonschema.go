@@ -0,0 +1,316 @@
+// Package jsonschema 实现 JSON Schema（draft-07）的常用子集，用于校验配置类 JSON 并给出字段路径
+//
+// 支持的关键字：type、enum、const、properties、required、additionalProperties（布尔值）、
+// items、minItems、maxItems、minLength、maxLength、pattern、format（uri）、minimum、maximum、
+// allOf、if/then/else；title、description、default 只作说明，不参与校验。
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema 一个 schema 节点
+type Schema struct {
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+	Else                 *Schema            `json:"else,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Error 一处校验错误，Path 形如 [0].buttons[1].action.url
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+var validTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// Parse 解析 schema 并预编译 pattern，不支持的 type 返回错误
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MustParse 解析内置 schema，出错时 panic
+func MustParse(data []byte) *Schema {
+	s, err := Parse(data)
+	if err != nil {
+		panic("jsonschema: " + err.Error())
+	}
+	return s
+}
+
+func (s *Schema) compile(path string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Type != "" && !validTypes[s.Type] {
+		return fmt.Errorf("%s: 不支持的 type %q", orRoot(path), s.Type)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: pattern 无效: %v", orRoot(path), err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if err := prop.compile(joinKey(path, name)); err != nil {
+			return err
+		}
+	}
+	if err := s.Items.compile(path + "[]"); err != nil {
+		return err
+	}
+	for _, sub := range append([]*Schema{s.If, s.Then, s.Else}, s.AllOf...) {
+		if err := sub.compile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateJSON 解析 JSON 文本后校验；JSON 本身无效时返回一条根路径错误
+func (s *Schema) ValidateJSON(data []byte) []Error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return []Error{{Message: "不是有效的 JSON: " + err.Error()}}
+	}
+	if dec.More() {
+		return []Error{{Message: "不是有效的 JSON: 多余的内容"}}
+	}
+	return s.Validate(value)
+}
+
+// Validate 校验已解码的值（json.Unmarshal 得到的 map/slice/float64 或 json.Number），错误按路径排序
+func (s *Schema) Validate(value interface{}) []Error {
+	var errs []Error
+	s.validate("", value, &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+// Valid 值是否满足 schema
+func (s *Schema) Valid(value interface{}) bool {
+	var errs []Error
+	s.validate("", value, &errs)
+	return len(errs) == 0
+}
+
+func (s *Schema) validate(path string, value interface{}, errs *[]Error) {
+	if s == nil {
+		return
+	}
+	add := func(format string, args ...interface{}) {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		add("类型应为 %s，实际为 %s", s.Type, typeOf(value))
+		return
+	}
+	if s.Const != nil && !equal(value, s.Const) {
+		add("值应为 %v", s.Const)
+	}
+	if len(s.Enum) > 0 {
+		matched := false
+		for _, e := range s.Enum {
+			if equal(value, e) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("取值应为 %s 之一", enumList(s.Enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, Error{Path: joinKey(path, name), Message: "缺少必填字段"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(joinKey(path, k), v[k], errs)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*errs = append(*errs, Error{Path: joinKey(path, k), Message: "不支持的字段"})
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			add("至少需要 %d 项", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			add("最多 %d 项", *s.MaxItems)
+		}
+		for i, item := range v {
+			s.Items.validate(path+"["+strconv.Itoa(i)+"]", item, errs)
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			if *s.MinLength == 1 {
+				add("不能为空")
+			} else {
+				add("长度不能少于 %d 个字符", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			add("长度不能超过 %d 个字符", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("格式不匹配 %s", s.Pattern)
+		}
+		if s.Format == "uri" && v != "" {
+			if u, err := url.Parse(v); err != nil || u.Scheme == "" {
+				add("应为完整的 URL")
+			}
+		}
+	default:
+		if f, ok := number(value); ok {
+			if s.Minimum != nil && f < *s.Minimum {
+				add("不能小于 %v", *s.Minimum)
+			}
+			if s.Maximum != nil && f > *s.Maximum {
+				add("不能大于 %v", *s.Maximum)
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(path, value, errs)
+	}
+	if s.If != nil {
+		if s.If.Valid(value) {
+			s.Then.validate(path, value, errs)
+		} else {
+			s.Else.validate(path, value, errs)
+		}
+	}
+}
+
+func orRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if f, ok := number(value); ok {
+		if f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func hasType(value interface{}, want string) bool {
+	got := typeOf(value)
+	return got == want || (want == "number" && got == "integer")
+}
+
+// equal 比较 JSON 值，数字按数值比较
+func equal(a, b interface{}) bool {
+	if fa, ok := number(a); ok {
+		fb, ok := number(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func enumList(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, "、")
+}
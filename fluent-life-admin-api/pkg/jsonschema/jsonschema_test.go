@@ -0,0 +1,170 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string // 为空表示解析成功
+	}{
+		{"empty", `{}`, ""},
+		{"nested", `{"type":"object","properties":{"a":{"type":"array","items":{"type":"string","pattern":"^x"}}}}`, ""},
+		{"invalid json", `{"type":`, "unexpected end"},
+		{"unknown root type", `{"type":"date"}`, `(root): 不支持的 type "date"`},
+		{"unknown nested type", `{"properties":{"a":{"properties":{"b":{"type":"int"}}}}}`, `a.b: 不支持的 type "int"`},
+		{"unknown item type", `{"properties":{"list":{"items":{"type":"float"}}}}`, `list[]: 不支持的 type "float"`},
+		{"bad pattern", `{"properties":{"a":{"pattern":"("}}}`, "a: pattern 无效"},
+		{"bad pattern in then", `{"if":{},"then":{"pattern":"[a-"}}`, "(root): pattern 无效"},
+		{"bad type in allOf", `{"allOf":[{"type":"any"}]}`, `不支持的 type "any"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.schema))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMustParsePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("MustParse() should panic on invalid schema")
+		}
+	}()
+	MustParse([]byte(`{"type":"nope"}`))
+}
+
+const testSchema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1, "maxLength": 5},
+    "code": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+    "kind": {"type": "string", "enum": ["a", "b"]},
+    "version": {"const": 2},
+    "count": {"type": "integer", "minimum": 1, "maximum": 10},
+    "ratio": {"type": "number", "minimum": 0, "maximum": 1},
+    "flag": {"type": "boolean"},
+    "link": {"type": "string", "format": "uri"},
+    "free": {"type": "object"},
+    "tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string", "minLength": 1}},
+    "action": {
+      "type": "object",
+      "required": ["type"],
+      "properties": {"type": {"type": "string"}, "url": {"type": "string"}, "step": {"type": "integer"}},
+      "allOf": [
+        {"if": {"required": ["type"], "properties": {"type": {"const": "open_url"}}}, "then": {"required": ["url"]}},
+        {"if": {"required": ["type"], "properties": {"type": {"const": "goto"}}}, "then": {"required": ["step"]}, "else": {"properties": {"step": {"maximum": 0}}}}
+      ]
+    }
+  }
+}`
+
+func TestValidateJSON(t *testing.T) {
+	schema := MustParse([]byte(testSchema))
+	tests := []struct {
+		name string
+		doc  string
+		want []Error
+	}{
+		{"minimal", `{"name":"x"}`, nil},
+		{"all fields valid", `{"name":"张三","code":"ab","kind":"b","version":2.0,"count":10,"ratio":0.5,"flag":false,"link":"https://a.b/c","free":{"any":[1]},"tags":["t"],"action":{"type":"goto","step":3}}`, nil},
+		{"empty uri is allowed", `{"name":"x","link":""}`, nil},
+		{"invalid json", `{"name":`, []Error{{Message: "不是有效的 JSON: unexpected EOF"}}},
+		{"trailing content", `{"name":"x"} {}`, []Error{{Message: "不是有效的 JSON: 多余的内容"}}},
+		{"root type", `[]`, []Error{{Message: "类型应为 object，实际为 array"}}},
+		{"missing required", `{}`, []Error{{Path: "name", Message: "缺少必填字段"}}},
+		{"unknown field", `{"name":"x","extra":1}`, []Error{{Path: "extra", Message: "不支持的字段"}}},
+		{"wrong type", `{"name":1}`, []Error{{Path: "name", Message: "类型应为 string，实际为 integer"}}},
+		{"null", `{"name":null}`, []Error{{Path: "name", Message: "类型应为 string，实际为 null"}}},
+		{"empty string", `{"name":""}`, []Error{{Path: "name", Message: "不能为空"}}},
+		{"max length counts runes", `{"name":"一二三四五六"}`, []Error{{Path: "name", Message: "长度不能超过 5 个字符"}}},
+		{"min length", `{"name":"x","code":"a"}`, []Error{{Path: "code", Message: "长度不能少于 2 个字符"}}},
+		{"pattern", `{"name":"x","code":"AB"}`, []Error{{Path: "code", Message: "格式不匹配 ^[a-z]+$"}}},
+		{"enum", `{"name":"x","kind":"c"}`, []Error{{Path: "kind", Message: "取值应为 a、b 之一"}}},
+		{"const", `{"name":"x","version":3}`, []Error{{Path: "version", Message: "值应为 2"}}},
+		{"integer rejects fraction", `{"name":"x","count":1.5}`, []Error{{Path: "count", Message: "类型应为 integer，实际为 number"}}},
+		{"number accepts integer", `{"name":"x","ratio":1}`, nil},
+		{"minimum", `{"name":"x","count":0}`, []Error{{Path: "count", Message: "不能小于 1"}}},
+		{"maximum", `{"name":"x","ratio":1.5}`, []Error{{Path: "ratio", Message: "不能大于 1"}}},
+		{"uri without scheme", `{"name":"x","link":"www.example.com"}`, []Error{{Path: "link", Message: "应为完整的 URL"}}},
+		{"min items", `{"name":"x","tags":[]}`, []Error{{Path: "tags", Message: "至少需要 1 项"}}},
+		{"max items", `{"name":"x","tags":["a","b","c"]}`, []Error{{Path: "tags", Message: "最多 2 项"}}},
+		{"item path", `{"name":"x","tags":["a",""]}`, []Error{{Path: "tags[1]", Message: "不能为空"}}},
+		{"if then", `{"name":"x","action":{"type":"open_url"}}`, []Error{{Path: "action.url", Message: "缺少必填字段"}}},
+		{"if then second branch", `{"name":"x","action":{"type":"goto"}}`, []Error{{Path: "action.step", Message: "缺少必填字段"}}},
+		{"else", `{"name":"x","action":{"type":"close","step":2}}`, []Error{{Path: "action.step", Message: "不能大于 0"}}},
+		{"if skipped when condition field missing", `{"name":"x","action":{}}`, []Error{{Path: "action.type", Message: "缺少必填字段"}}},
+		{
+			"errors sorted by path", `{"tags":[1],"name":"","code":"A","zzz":true}`,
+			[]Error{
+				{Path: "code", Message: "长度不能少于 2 个字符"},
+				{Path: "code", Message: "格式不匹配 ^[a-z]+$"},
+				{Path: "name", Message: "不能为空"},
+				{Path: "tags[0]", Message: "类型应为 string，实际为 integer"},
+				{Path: "zzz", Message: "不支持的字段"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.ValidateJSON([]byte(tt.doc))
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateJSON(%s)\n got  %v\n want %v", tt.doc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDecodedValues(t *testing.T) {
+	schema := MustParse([]byte(`{"type":"object","properties":{"n":{"type":"integer","maximum":3}}}`))
+	tests := []struct {
+		name  string
+		value interface{}
+		valid bool
+	}{
+		{"float64 integer", map[string]interface{}{"n": float64(3)}, true},
+		{"float64 too large", map[string]interface{}{"n": float64(4)}, false},
+		{"json.Number", map[string]interface{}{"n": json.Number("2")}, true},
+		{"int", map[string]interface{}{"n": 5}, false},
+		{"bool is not integer", map[string]interface{}{"n": true}, false},
+		{"not an object", "x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schema.Valid(tt.value); got != tt.valid {
+				t.Errorf("Valid(%v) = %v, want %v", tt.value, got, tt.valid)
+			}
+			if got := len(schema.Validate(tt.value)) == 0; got != tt.valid {
+				t.Errorf("Validate(%v) empty = %v, want %v", tt.value, got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	if got := (Error{Message: "不是有效的 JSON"}).Error(); got != "不是有效的 JSON" {
+		t.Errorf("root error = %q", got)
+	}
+	if got := (Error{Path: "[0].title", Message: "不能为空"}).Error(); got != "[0].title: 不能为空" {
+		t.Errorf("path error = %q", got)
+	}
+}
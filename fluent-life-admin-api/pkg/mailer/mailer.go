@@ -0,0 +1,130 @@
+// Package mailer 通过 SMTP 发送带附件的 HTML 邮件
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Config SMTP 连接配置，Username 为空时不进行认证（如本地 MailHog）
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Attachment 邮件附件
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message 待发送的邮件
+type Message struct {
+	To          []string
+	Subject     string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Mailer SMTP 发信客户端
+type Mailer struct {
+	cfg Config
+}
+
+// New 创建 SMTP 发信客户端
+func New(cfg Config) *Mailer {
+	return &Mailer{cfg: cfg}
+}
+
+// Send 发送邮件；服务器支持 STARTTLS 时由 net/smtp 自动启用
+func (m *Mailer) Send(msg Message) error {
+	if m.cfg.Host == "" {
+		return fmt.Errorf("未配置 SMTP 服务器")
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("收件人不能为空")
+	}
+	body, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+	return smtp.SendMail(addr, auth, m.cfg.From, msg.To, body)
+}
+
+// build 组装 multipart/mixed MIME 邮件
+func (m *Mailer) build(msg Message) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("From", m.cfg.From)
+	writeHeader("To", strings.Join(msg.To, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", `multipart/mixed; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	buf.WriteString("--" + boundary + "\r\n")
+	writeHeader("Content-Type", "text/html; charset=utf-8")
+	writeHeader("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	writeBase64(&buf, []byte(msg.HTML))
+
+	for _, att := range msg.Attachments {
+		contentType := att.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		filename := mime.QEncoding.Encode("utf-8", att.Filename)
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", contentType+`; name="`+filename+`"`)
+		writeHeader("Content-Transfer-Encoding", "base64")
+		writeHeader("Content-Disposition", `attachment; filename="`+filename+`"`)
+		buf.WriteString("\r\n")
+		writeBase64(&buf, att.Data)
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+// writeBase64 按 RFC 2045 每行 76 个字符写入 base64 内容
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "fluentlife-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,87 @@
+package mailer
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+	"testing"
+
+	"fluent-life-admin-api/pkg/mailer/mailertest"
+)
+
+func TestSend(t *testing.T) {
+	srv, err := mailertest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	m := New(Config{Host: srv.Host, Port: srv.Port, From: "noreply@fluentlife.test"})
+	// 超过 76 个字符的内容会按行切分 base64
+	attachment := bytes.Repeat([]byte("日期,新增用户\n2026-01-01,3\n"), 20)
+	err = m.Send(Message{
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "周报（2026-01-01 ~ 2026-01-07）",
+		HTML:    "<h2>周报</h2>",
+		Attachments: []Attachment{
+			{Filename: "报表.csv", ContentType: "text/csv; charset=utf-8", Data: attachment},
+			{Filename: "raw.bin", Data: []byte{0, 1, 2}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("收到 %d 封邮件，期望 1 封", len(msgs))
+	}
+	if msgs[0].From != "noreply@fluentlife.test" {
+		t.Errorf("MAIL FROM = %q", msgs[0].From)
+	}
+	if strings.Join(msgs[0].To, ",") != "a@example.com,b@example.com" {
+		t.Errorf("RCPT TO = %v", msgs[0].To)
+	}
+
+	msg, parts, err := msgs[0].Parse()
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "周报（2026-01-01 ~ 2026-01-07）" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	if got := msg.Header.Get("To"); got != "a@example.com, b@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("分段数 = %d，期望 3", len(parts))
+	}
+	if !strings.HasPrefix(parts[0].ContentType, "text/html") || string(parts[0].Body) != "<h2>周报</h2>" {
+		t.Errorf("正文 = %q %q", parts[0].ContentType, parts[0].Body)
+	}
+	if parts[1].Filename != "报表.csv" || !bytes.Equal(parts[1].Body, attachment) {
+		t.Errorf("CSV 附件 = %q，%d 字节", parts[1].Filename, len(parts[1].Body))
+	}
+	if !strings.HasPrefix(parts[2].ContentType, "application/octet-stream") || !bytes.Equal(parts[2].Body, []byte{0, 1, 2}) {
+		t.Errorf("默认类型附件 = %q %v", parts[2].ContentType, parts[2].Body)
+	}
+}
+
+func TestSendValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		msg  Message
+	}{
+		{"未配置服务器", Config{}, Message{To: []string{"a@example.com"}}},
+		{"没有收件人", Config{Host: "127.0.0.1", Port: "25"}, Message{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New(tt.cfg).Send(tt.msg); err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Package mailertest 测试用的本地 SMTP 服务器，记录收到的邮件并解析 MIME 内容
+package mailertest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+)
+
+// Message 服务器收到的一封邮件
+type Message struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Part 解码后的 MIME 分段
+type Part struct {
+	ContentType string
+	Filename    string // 附件文件名，正文为空
+	Body        []byte
+}
+
+// Server 只支持明文 SMTP 与无认证发信的最小实现，足以配合 net/smtp.SendMail
+type Server struct {
+	Host string
+	Port string
+
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewServer 在 127.0.0.1 的随机端口启动服务器，用完后调用 Close
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	s := &Server{Host: host, Port: port, ln: ln}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Close 停止服务器并等待连接处理结束
+func (s *Server) Close() {
+	s.ln.Close()
+	s.wg.Wait()
+}
+
+// Messages 已收到的邮件
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+
+	reply("220 mailertest ESMTP")
+	var msg Message
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-mailertest")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "HELO"):
+			reply("250 mailertest")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = Message{From: trimAddress(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.To = append(msg.To, trimAddress(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data bytes.Buffer
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.Data = data.Bytes()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// trimAddress 去掉 <addr> 两侧的尖括号及 SIZE 等参数
+func trimAddress(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, ">"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimPrefix(s, "<")
+}
+
+// Parse 解析邮件头与 multipart 分段，base64 内容已解码
+func (m *Message) Parse() (*mail.Message, []Part, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(m.Data))
+	if err != nil {
+		return nil, nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		body, err := decode(msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		return msg, []Part{{ContentType: mediaType, Body: body}}, nil
+	}
+
+	var parts []Part
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := decode(p.Header.Get("Content-Transfer-Encoding"), p)
+		if err != nil {
+			return nil, nil, err
+		}
+		part := Part{ContentType: p.Header.Get("Content-Type"), Body: body}
+		if _, dparams, err := mime.ParseMediaType(p.Header.Get("Content-Disposition")); err == nil {
+			part.Filename, _ = new(mime.WordDecoder).DecodeHeader(dparams["filename"])
+		}
+		parts = append(parts, part)
+	}
+	return msg, parts, nil
+}
+
+func decode(encoding string, r io.Reader) ([]byte, error) {
+	if strings.EqualFold(encoding, "base64") {
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,135 @@
+// Package pinyin 基于内置字表把汉字转换为带声调的拼音音节
+// 字表只收录常用字且每个字只取最常见的读音，适合做难度估计等统计用途，不适合做注音展示
+package pinyin
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+//go:embed dict.txt
+var dictData string
+
+var dict = parseDict(dictData)
+
+// Syllable 一个汉字的拼音
+type Syllable struct {
+	Char    string `json:"char"`
+	Pinyin  string `json:"pinyin"`  // 不含声调，ü 写作 v，如 "lv"
+	Initial string `json:"initial"` // 声母，零声母为空
+	Final   string `json:"final"`
+	Tone    int    `json:"tone"` // 1~4，轻声为 5
+}
+
+// initials 按长度优先排列，保证 zh/ch/sh 先于 z/c/s 匹配
+var initials = []string{"zh", "ch", "sh", "b", "p", "m", "f", "d", "t", "n", "l", "g", "k", "h", "j", "q", "x", "r", "z", "c", "s", "y", "w"}
+
+func parseDict(data string) map[rune]Syllable {
+	m := make(map[rune]Syllable)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		syllable, ok := parseSyllable(fields[0])
+		if !ok {
+			continue
+		}
+		for _, r := range fields[1] {
+			if _, exists := m[r]; exists {
+				continue // 多音字保留先出现的读音
+			}
+			s := syllable
+			s.Char = string(r)
+			m[r] = s
+		}
+	}
+	return m
+}
+
+// parseSyllable 解析 "zhong4" 形式的带调拼音
+func parseSyllable(s string) (Syllable, bool) {
+	if len(s) < 2 {
+		return Syllable{}, false
+	}
+	tone := int(s[len(s)-1] - '0')
+	if tone < 1 || tone > 5 {
+		return Syllable{}, false
+	}
+	py := s[:len(s)-1]
+	syllable := Syllable{Pinyin: py, Tone: tone, Final: py}
+	for _, initial := range initials {
+		if strings.HasPrefix(py, initial) && len(py) > len(initial) {
+			syllable.Initial = initial
+			syllable.Final = py[len(initial):]
+			break
+		}
+	}
+	return syllable, true
+}
+
+// Lookup 查询单个汉字的拼音
+func Lookup(r rune) (Syllable, bool) {
+	s, ok := dict[r]
+	return s, ok
+}
+
+// Result 文本转换结果
+type Result struct {
+	// Phrases 以标点、空白、非汉字及字表中没有的字为界切分的连续音节，相邻关系只在短语内计算
+	Phrases  [][]Syllable
+	Unknown  []string // 字表中没有的汉字（去重）
+	HanCount int      // 汉字总数
+	Known    int      // 已识别的汉字数
+}
+
+// Coverage 字表覆盖率：已识别汉字数 / 汉字总数
+func (r *Result) Coverage() float64 {
+	if r.HanCount == 0 {
+		return 1
+	}
+	return float64(r.Known) / float64(r.HanCount)
+}
+
+// Convert 把文本中的汉字逐字转换为拼音
+func Convert(text string) *Result {
+	result := &Result{}
+	seen := make(map[rune]bool)
+	var phrase []Syllable
+	flush := func() {
+		if len(phrase) > 0 {
+			result.Phrases = append(result.Phrases, phrase)
+			phrase = nil
+		}
+	}
+	for _, r := range text {
+		if !unicode.Is(unicode.Han, r) {
+			flush()
+			continue
+		}
+		result.HanCount++
+		s, ok := dict[r]
+		if !ok {
+			flush()
+			if !seen[r] {
+				seen[r] = true
+				result.Unknown = append(result.Unknown, string(r))
+			}
+			continue
+		}
+		result.Known++
+		phrase = append(phrase, s)
+	}
+	flush()
+	return result
+}
+
+// Size 字表收录的汉字数
+func Size() int {
+	return len(dict)
+}
@@ -0,0 +1,172 @@
+package pinyin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSyllable(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Syllable
+		ok   bool
+	}{
+		{"zhong1", Syllable{Pinyin: "zhong", Initial: "zh", Final: "ong", Tone: 1}, true},
+		{"shi4", Syllable{Pinyin: "shi", Initial: "sh", Final: "i", Tone: 4}, true},
+		{"si4", Syllable{Pinyin: "si", Initial: "s", Final: "i", Tone: 4}, true},
+		{"lv4", Syllable{Pinyin: "lv", Initial: "l", Final: "v", Tone: 4}, true},
+		{"wu3", Syllable{Pinyin: "wu", Initial: "w", Final: "u", Tone: 3}, true},
+		{"er2", Syllable{Pinyin: "er", Final: "er", Tone: 2}, true},
+		{"a1", Syllable{Pinyin: "a", Final: "a", Tone: 1}, true},
+		{"n2", Syllable{Pinyin: "n", Final: "n", Tone: 2}, true},
+		{"le5", Syllable{Pinyin: "le", Initial: "l", Final: "e", Tone: 5}, true},
+		{"zhong", Syllable{}, false},
+		{"zhong0", Syllable{}, false},
+		{"zhong6", Syllable{}, false},
+		{"1", Syllable{}, false},
+		{"", Syllable{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSyllable(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseSyllable(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseDict(t *testing.T) {
+	dict := parseDict(`# 注释
+zhong1 中钟
+zhong4 重中
+
+bad line here
+xx9 错
+shi4 是`)
+	tests := []struct {
+		char   rune
+		pinyin string
+		tone   int
+		ok     bool
+	}{
+		{'中', "zhong", 1, true}, // 多音字保留先出现的读音
+		{'钟', "zhong", 1, true},
+		{'重', "zhong", 4, true},
+		{'是', "shi", 4, true},
+		{'错', "", 0, false},
+		{'注', "", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := dict[tt.char]
+		if ok != tt.ok || got.Pinyin != tt.pinyin || got.Tone != tt.tone {
+			t.Errorf("dict[%q] = %+v, %v, want %s%d, %v", tt.char, got, ok, tt.pinyin, tt.tone, tt.ok)
+		}
+		if ok && got.Char != string(tt.char) {
+			t.Errorf("dict[%q].Char = %q", tt.char, got.Char)
+		}
+	}
+	if len(dict) != 4 {
+		t.Errorf("len(dict) = %d, want 4", len(dict))
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		char rune
+		want string
+		tone int
+	}{
+		{'四', "si", 4},
+		{'十', "shi", 2},
+		{'吃', "chi", 1},
+		{'绿', "lv", 4},
+		{'女', "nv", 3},
+		{'了', "le", 5},
+	}
+	for _, tt := range tests {
+		got, ok := Lookup(tt.char)
+		if !ok || got.Pinyin != tt.want || got.Tone != tt.tone {
+			t.Errorf("Lookup(%q) = %+v, %v, want %s%d", tt.char, got, ok, tt.want, tt.tone)
+		}
+	}
+	if _, ok := Lookup('A'); ok {
+		t.Error("Lookup('A') should not find a syllable")
+	}
+	if Size() < 1000 {
+		t.Errorf("Size() = %d, dictionary looks truncated", Size())
+	}
+}
+
+// phrasePinyin 把转换结果展开为每个短语的带调拼音，便于比较
+func phrasePinyin(r *Result) [][]string {
+	out := make([][]string, 0, len(r.Phrases))
+	for _, phrase := range r.Phrases {
+		list := make([]string, len(phrase))
+		for i, s := range phrase {
+			list[i] = s.Pinyin + string(rune('0'+s.Tone))
+		}
+		out = append(out, list)
+	}
+	return out
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		phrases  [][]string
+		unknown  []string
+		han      int
+		known    int
+		coverage float64
+	}{
+		{
+			name:     "empty",
+			text:     "",
+			phrases:  [][]string{},
+			han:      0,
+			coverage: 1,
+		},
+		{
+			name:     "punctuation splits phrases",
+			text:     "四是四，十是十。",
+			phrases:  [][]string{{"si4", "shi4", "si4"}, {"shi2", "shi4", "shi2"}},
+			han:      6,
+			known:    6,
+			coverage: 1,
+		},
+		{
+			name:     "latin and digits split phrases",
+			text:     "吃abc葡萄1皮",
+			phrases:  [][]string{{"chi1"}, {"pu2", "tao2"}, {"pi2"}},
+			han:      4,
+			known:    4,
+			coverage: 1,
+		},
+		{
+			name:     "unknown characters split phrases and are deduplicated",
+			text:     "四龘是龘四",
+			phrases:  [][]string{{"si4"}, {"shi4"}, {"si4"}},
+			unknown:  []string{"龘"},
+			han:      5,
+			known:    3,
+			coverage: 0.6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Convert(tt.text)
+			if got := phrasePinyin(r); !reflect.DeepEqual(got, tt.phrases) {
+				t.Errorf("phrases = %v, want %v", got, tt.phrases)
+			}
+			if !reflect.DeepEqual(r.Unknown, tt.unknown) {
+				t.Errorf("unknown = %v, want %v", r.Unknown, tt.unknown)
+			}
+			if r.HanCount != tt.han || r.Known != tt.known {
+				t.Errorf("han/known = %d/%d, want %d/%d", r.HanCount, r.Known, tt.han, tt.known)
+			}
+			if got := r.Coverage(); got != tt.coverage {
+				t.Errorf("Coverage() = %v, want %v", got, tt.coverage)
+			}
+		})
+	}
+}
@@ -28,3 +28,11 @@ func Error(c *gin.Context, code int, message string) {
 	})
 }
 
+// ErrorWithData 返回错误并附带详情，如字段级校验错误
+func ErrorWithData(c *gin.Context, code int, message string, data interface{}) {
+	c.JSON(http.StatusOK, Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	})
+}
@@ -0,0 +1,88 @@
+// Package signedurl 带过期时间的 HMAC-SHA256 签名链接参数
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 签名使用的查询参数名
+const (
+	ParamExpires   = "e"
+	ParamSignature = "s"
+)
+
+var (
+	ErrInvalidSignature = errors.New("链接签名无效")
+	ErrExpired          = errors.New("链接已过期")
+)
+
+// Signer 对查询参数签名，签名覆盖除 s 以外的全部参数（含过期时间 e）
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// New 创建签名器；secret 为空时使用随机密钥，进程重启后之前签发的链接失效
+func New(secret string, ttl time.Duration) *Signer {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+	}
+	return &Signer{secret: key, ttl: ttl}
+}
+
+// TTL 签发链接的有效期
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign 设置过期时间与签名参数，返回编码后的查询串
+func (s *Signer) Sign(params url.Values, now time.Time) string {
+	params.Del(ParamSignature)
+	params.Set(ParamExpires, strconv.FormatInt(now.Add(s.ttl).Unix(), 10))
+	params.Set(ParamSignature, s.mac(params))
+	return params.Encode()
+}
+
+// Verify 校验签名与过期时间
+func (s *Signer) Verify(params url.Values, now time.Time) error {
+	sig, err := base64.RawURLEncoding.DecodeString(params.Get(ParamSignature))
+	if err != nil || len(sig) == 0 {
+		return ErrInvalidSignature
+	}
+	unsigned := url.Values{}
+	for name, values := range params {
+		if name != ParamSignature {
+			unsigned[name] = values
+		}
+	}
+	expected, _ := base64.RawURLEncoding.DecodeString(s.mac(unsigned))
+	if !hmac.Equal(sig, expected) {
+		return ErrInvalidSignature
+	}
+	expires, err := strconv.ParseInt(params.Get(ParamExpires), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if now.Unix() > expires {
+		return ErrExpired
+	}
+	return nil
+}
+
+// mac 对按名称排序编码后的参数计算 HMAC
+func (s *Signer) mac(params url.Values) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(params.Encode()))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
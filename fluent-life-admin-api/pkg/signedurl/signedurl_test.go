@@ -0,0 +1,79 @@
+package signedurl
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := New("secret", 30*time.Minute)
+
+	sign := func() url.Values {
+		params := url.Values{"k": {"videos/a b.mp4"}, "a": {"admin-1"}}
+		query, err := url.ParseQuery(signer.Sign(params, now))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return query
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(url.Values)
+		at     time.Time
+		want   error
+	}{
+		{"valid", func(url.Values) {}, now, nil},
+		{"valid until expiry", func(url.Values) {}, now.Add(30 * time.Minute), nil},
+		{"expired", func(url.Values) {}, now.Add(30*time.Minute + time.Second), ErrExpired},
+		{"tampered value", func(q url.Values) { q.Set("k", "videos/b.mp4") }, now, ErrInvalidSignature},
+		{"added param", func(q url.Values) { q.Set("v", "community_post:1") }, now, ErrInvalidSignature},
+		{"removed param", func(q url.Values) { q.Del("a") }, now, ErrInvalidSignature},
+		{"extended expiry", func(q url.Values) { q.Set(ParamExpires, "9999999999") }, now, ErrInvalidSignature},
+		{"missing signature", func(q url.Values) { q.Del(ParamSignature) }, now, ErrInvalidSignature},
+		{"malformed signature", func(q url.Values) { q.Set(ParamSignature, "%%%") }, now, ErrInvalidSignature},
+		{"truncated signature", func(q url.Values) { q.Set(ParamSignature, q.Get(ParamSignature)[:10]) }, now, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := sign()
+			tt.mutate(query)
+			if err := signer.Verify(query, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOtherSecret(t *testing.T) {
+	now := time.Now()
+	query, _ := url.ParseQuery(New("secret-a", time.Minute).Sign(url.Values{"k": {"a.mp4"}}, now))
+	if err := New("secret-b", time.Minute).Verify(query, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify with another secret = %v, want ErrInvalidSignature", err)
+	}
+	// 未配置密钥时每个签名器使用各自的随机密钥
+	query, _ = url.ParseQuery(New("", time.Minute).Sign(url.Values{"k": {"a.mp4"}}, now))
+	if err := New("", time.Minute).Verify(query, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify with another random secret = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSignReplacesExistingSignature(t *testing.T) {
+	now := time.Now()
+	signer := New("secret", time.Hour)
+	params := url.Values{"k": {"a.mp4"}, ParamSignature: {"stale"}, ParamExpires: {"1"}}
+	query, _ := url.ParseQuery(signer.Sign(params, now))
+	if err := signer.Verify(query, now); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+	if got, want := query.Get(ParamExpires), strconv.FormatInt(now.Add(time.Hour).Unix(), 10); got != want {
+		t.Errorf("expires = %s, want %s", got, want)
+	}
+	if signer.TTL() != time.Hour {
+		t.Errorf("TTL() = %v", signer.TTL())
+	}
+}
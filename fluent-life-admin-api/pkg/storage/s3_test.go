@@ -0,0 +1,135 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"fluent-life-admin-api/pkg/storage"
+	"fluent-life-admin-api/pkg/storage/storagetest"
+)
+
+func newS3(t *testing.T) (*storage.S3, *storagetest.S3Server) {
+	t.Helper()
+	srv := storagetest.NewS3Server("media")
+	t.Cleanup(srv.Close)
+	s, err := storage.NewS3(srv.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EnsureBucket(context.Background()); err != nil {
+		t.Fatalf("EnsureBucket: %v", err)
+	}
+	return s, srv
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, srv := newS3(t)
+
+	// bucket 已存在时再次调用不报错
+	if err := s.EnsureBucket(ctx); err != nil {
+		t.Fatalf("EnsureBucket again: %v", err)
+	}
+
+	keys := map[string]string{
+		"videos/2026/a.mp4":  "video-a",
+		"images/图 1+2.png":   "png",
+		"empty/zero.txt":     "",
+		"videos/2026/b.webm": strings.Repeat("b", 4096),
+	}
+	for key, body := range keys {
+		size := int64(len(body))
+		if key == "videos/2026/b.webm" {
+			size = -1 // 大小未知时先读入内存
+		}
+		if err := s.Put(ctx, key, strings.NewReader(body), size, "application/octet-stream"); err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+	}
+	if got, want := srv.Keys(), []string{"empty/zero.txt", "images/图 1+2.png", "videos/2026/a.mp4", "videos/2026/b.webm"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("server keys = %v, want %v", got, want)
+	}
+
+	for key, body := range keys {
+		r, info, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", key, err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		if string(data) != body || info.Size != int64(len(body)) || info.ContentType != "application/octet-stream" || info.LastModified.IsZero() {
+			t.Errorf("Get(%s) = %d bytes, %+v", key, len(data), info)
+		}
+	}
+
+	if _, _, err := s.Get(ctx, "missing.mp4"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Get missing error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "videos/2026/a.mp4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "videos/2026/a.mp4"); err != nil {
+		t.Errorf("deleting a missing object should not fail: %v", err)
+	}
+	if err := s.Put(ctx, "../escape", strings.NewReader("x"), 1, ""); err == nil {
+		t.Error("Put should reject invalid keys")
+	}
+}
+
+func TestS3ListPagination(t *testing.T) {
+	ctx := context.Background()
+	s, srv := newS3(t)
+	srv.PageSize = 2
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("videos/%02d.mp4", i)
+		want = append(want, key)
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Put(ctx, "images/a.png", strings.NewReader("x"), 1, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	err := s.List(ctx, "videos/", func(obj storage.ObjectInfo) error {
+		if obj.Size != 1 || obj.LastModified.IsZero() {
+			t.Errorf("object %+v", obj)
+		}
+		got = append(got, obj.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List = %v, want %v", got, want)
+	}
+
+	stop := errors.New("stop")
+	if err := s.List(ctx, "", func(storage.ObjectInfo) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("List should return the callback error, got %v", err)
+	}
+}
+
+func TestS3RejectsBadCredentials(t *testing.T) {
+	srv := storagetest.NewS3Server("media")
+	defer srv.Close()
+	cfg := srv.Config()
+	cfg.SecretKey = "wrong"
+	s, err := storage.NewS3(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Put(context.Background(), "a.txt", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Put with wrong secret error = %v", err)
+	}
+}
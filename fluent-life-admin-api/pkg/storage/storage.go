@@ -0,0 +1,133 @@
+// Package storage 媒体文件存储：本地文件系统与 S3 兼容对象存储（AWS S3、MinIO 等）
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("对象不存在")
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	ContentType  string    `json:"content_type,omitempty"`
+}
+
+// Storage 媒体存储；key 为以 / 分隔的相对路径，如 videos/2026/01/abc.mp4
+type Storage interface {
+	// Put 写入对象，已存在时覆盖；size 未知时传 -1
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，调用方负责关闭；不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// List 按 key 前缀遍历对象，fn 返回错误时停止遍历
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+}
+
+// 存储驱动
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// Config 存储配置
+type Config struct {
+	Driver string
+	// PublicBaseURL 对象对外访问地址的前缀，对象地址为 PublicBaseURL + key；
+	// 帖子与训练记录中保存的是完整地址，据此换算回 key
+	PublicBaseURL string
+
+	// 本地存储根目录
+	LocalDir string
+
+	// S3 兼容存储
+	S3Endpoint     string // 如 https://s3.amazonaws.com、http://localhost:9000
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool // MinIO 等使用 endpoint/bucket/key 形式的地址
+}
+
+// New 按配置创建存储
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocal(cfg.LocalDir)
+	case DriverS3:
+		return NewS3(S3Config{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+	}
+	return nil, fmt.Errorf("不支持的存储驱动 %s", cfg.Driver)
+}
+
+// CleanKey 校验并规范化 key，拒绝绝对路径与 .. 等越界路径
+func CleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("无效的对象 key: %q", key)
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") || cleaned != key {
+		return "", fmt.Errorf("无效的对象 key: %q", key)
+	}
+	return cleaned, nil
+}
+
+// KeyFromURL 把帖子或训练记录中保存的媒体地址换算为存储 key；不是本存储的地址（如外链）时返回 false。
+// PublicBaseURL 为相对路径（如 /uploads/）时只比较路径，不限域名
+func KeyFromURL(publicBaseURL, rawURL string) (string, bool) {
+	if publicBaseURL == "" || rawURL == "" {
+		return "", false
+	}
+	base, err := url.Parse(publicBaseURL)
+	if err != nil {
+		return "", false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	if base.Host != "" && u.Host != "" && !strings.EqualFold(base.Host, u.Host) {
+		return "", false
+	}
+	if base.Host != "" && u.Host == "" && u.Scheme != "" {
+		return "", false
+	}
+	prefix := base.Path
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if !strings.HasPrefix(u.Path, prefix) {
+		return "", false
+	}
+	key, err := CleanKey(strings.TrimPrefix(u.Path, prefix))
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// URLForKey 对象的对外访问地址
+func URLForKey(publicBaseURL, key string) string {
+	if !strings.HasSuffix(publicBaseURL, "/") {
+		publicBaseURL += "/"
+	}
+	return publicBaseURL + key
+}
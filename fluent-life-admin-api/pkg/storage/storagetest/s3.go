@@ -0,0 +1,251 @@
+// Package storagetest 测试用的 S3 兼容服务器：MinIO 风格的 path-style 地址，校验 SigV4 签名，对象保存在内存中
+package storagetest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fluent-life-admin-api/pkg/storage"
+)
+
+type object struct {
+	data         []byte
+	contentType  string
+	lastModified time.Time
+}
+
+// S3Server 只实现 storage.S3 用到的请求：HEAD/PUT bucket、PUT/GET/DELETE 对象与 ListObjectsV2
+type S3Server struct {
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Region    string
+	// PageSize ListObjectsV2 每页最多返回的对象数，用于覆盖分页
+	PageSize int
+
+	srv     *httptest.Server
+	mu      sync.Mutex
+	buckets map[string]bool
+	objects map[string]*object
+}
+
+// NewS3Server 启动服务器，bucket 需要通过 EnsureBucket 创建；用完后调用 Close
+func NewS3Server(bucket string) *S3Server {
+	s := &S3Server{
+		Bucket:    bucket,
+		AccessKey: "minioadmin",
+		SecretKey: "minioadmin-secret",
+		Region:    "us-east-1",
+		PageSize:  1000,
+		buckets:   make(map[string]bool),
+		objects:   make(map[string]*object),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// URL 服务器地址，即 S3 endpoint
+func (s *S3Server) URL() string {
+	return s.srv.URL
+}
+
+// Config 连接本服务器的 storage.S3 配置
+func (s *S3Server) Config() storage.S3Config {
+	return storage.S3Config{
+		Endpoint:     s.srv.URL,
+		Region:       s.Region,
+		Bucket:       s.Bucket,
+		AccessKey:    s.AccessKey,
+		SecretKey:    s.SecretKey,
+		UsePathStyle: true,
+	}
+}
+
+func (s *S3Server) Close() {
+	s.srv.Close()
+}
+
+// Keys 当前全部对象的 key，已排序
+func (s *S3Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Touch 修改对象的最后修改时间，不存在时返回 false
+func (s *S3Server) Touch(key string, t time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	if ok {
+		obj.lastModified = t.UTC().Truncate(time.Second)
+	}
+	return ok
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
+
+// verify 用同样的规则重新签名，与请求携带的 Authorization 比较
+func (s *S3Server) verify(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+s.AccessKey+"/") {
+		return false
+	}
+	at, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+	req, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	if err != nil {
+		return false
+	}
+	for name, values := range r.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || (strings.HasPrefix(lower, "x-amz-") && lower != "x-amz-date") {
+			req.Header[name] = values
+		}
+	}
+	storage.SignV4(req, s.AccessKey, s.SecretKey, s.Region, "s3", r.Header.Get("X-Amz-Content-Sha256"), at)
+	return req.Header.Get("Authorization") == auth
+}
+
+func (s *S3Server) serve(w http.ResponseWriter, r *http.Request) {
+	if !s.verify(r) {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch", "签名不匹配")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key == "" {
+		s.serveBucket(w, r, bucket)
+		return
+	}
+	if !s.buckets[bucket] {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "bucket 不存在")
+		return
+	}
+	s.serveObject(w, r, key)
+}
+
+func (s *S3Server) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	switch r.Method {
+	case http.MethodHead:
+		if !s.buckets[bucket] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case http.MethodPut:
+		if s.buckets[bucket] {
+			writeError(w, http.StatusConflict, "BucketAlreadyOwnedByYou", "bucket 已存在")
+			return
+		}
+		s.buckets[bucket] = true
+	case http.MethodGet:
+		if !s.buckets[bucket] {
+			writeError(w, http.StatusNotFound, "NoSuchBucket", "bucket 不存在")
+			return
+		}
+		if r.URL.Query().Get("list-type") != "2" {
+			writeError(w, http.StatusNotImplemented, "NotImplemented", "只支持 ListObjectsV2")
+			return
+		}
+		s.list(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+type listContent struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+type listResult struct {
+	XMLName               xml.Name      `xml:"ListBucketResult"`
+	Contents              []listContent `xml:"Contents"`
+	IsTruncated           bool          `xml:"IsTruncated"`
+	NextContinuationToken string        `xml:"NextContinuationToken,omitempty"`
+}
+
+// list 按 key 排序分页，continuation-token 为上一页最后一个 key
+func (s *S3Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := s.PageSize
+	if n, err := strconv.Atoi(query.Get("max-keys")); err == nil && n < limit {
+		limit = n
+	}
+	prefix, after := query.Get("prefix"), query.Get("continuation-token")
+
+	var keys []string
+	for k := range s.objects {
+		if strings.HasPrefix(k, prefix) && k > after {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var result listResult
+	if len(keys) > limit {
+		keys = keys[:limit]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, k := range keys {
+		obj := s.objects[k]
+		result.Contents = append(result.Contents, listContent{Key: k, Size: int64(len(obj.data)), LastModified: obj.lastModified})
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func (s *S3Server) serveObject(w http.ResponseWriter, r *http.Request, key string) {
+	switch r.Method {
+	case http.MethodPut:
+		if r.ContentLength < 0 {
+			writeError(w, http.StatusLengthRequired, "MissingContentLength", "缺少 Content-Length")
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		s.objects[key] = &object{data: data, contentType: r.Header.Get("Content-Type"), lastModified: time.Now().UTC().Truncate(time.Second)}
+	case http.MethodGet:
+		obj, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "对象不存在")
+			return
+		}
+		if obj.contentType != "" {
+			w.Header().Set("Content-Type", obj.contentType)
+		}
+		w.Header().Set("Last-Modified", obj.lastModified.Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Write(obj.data)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
@@ -0,0 +1,127 @@
+// Package textdiff 基于最长公共子序列的简单文本差异，用于内容版本对比展示
+package textdiff
+
+import "strings"
+
+// 操作类型
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// maxCells LCS 表的最大单元数，超过时不再逐段比较，直接整体替换
+const maxCells = 4_000_000
+
+// Op 一段差异
+type Op struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Diff 比较两段文本：多行文本按行比较，单行文本按字符比较，相邻同类操作会合并
+func Diff(a, b string) []Op {
+	if a == b {
+		if a == "" {
+			return nil
+		}
+		return []Op{{Type: Equal, Text: a}}
+	}
+	var x, y []string
+	if strings.Contains(a, "\n") || strings.Contains(b, "\n") {
+		x, y = splitLines(a), splitLines(b)
+	} else {
+		x, y = splitRunes(a), splitRunes(b)
+	}
+	if len(x)*len(y) > maxCells {
+		return replaceAll(a, b)
+	}
+	return merge(lcs(x, y))
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	// 以换行结尾时 SplitAfter 会多出一个空串
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func splitRunes(s string) []string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		parts = append(parts, string(r))
+	}
+	return parts
+}
+
+func replaceAll(a, b string) []Op {
+	var ops []Op
+	if a != "" {
+		ops = append(ops, Op{Type: Delete, Text: a})
+	}
+	if b != "" {
+		ops = append(ops, Op{Type: Insert, Text: b})
+	}
+	return ops
+}
+
+func lcs(x, y []string) []Op {
+	n, m := len(x), len(y)
+	// table[i][j] 为 x[i:] 与 y[j:] 的最长公共子序列长度
+	table := make([][]int32, n+1)
+	for i := range table {
+		table[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]Op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			ops = append(ops, Op{Type: Equal, Text: x[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			ops = append(ops, Op{Type: Delete, Text: x[i]})
+			i++
+		default:
+			ops = append(ops, Op{Type: Insert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, Op{Type: Delete, Text: x[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, Op{Type: Insert, Text: y[j]})
+	}
+	return ops
+}
+
+func merge(ops []Op) []Op {
+	merged := make([]Op, 0, len(ops))
+	for _, op := range ops {
+		if last := len(merged) - 1; last >= 0 && merged[last].Type == op.Type {
+			merged[last].Text += op.Text
+			continue
+		}
+		merged = append(merged, op)
+	}
+	return merged
+}
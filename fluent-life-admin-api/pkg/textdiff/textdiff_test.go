@@ -0,0 +1,106 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Op
+	}{
+		{"both empty", "", "", nil},
+		{"equal", "四是四", "四是四", []Op{{Equal, "四是四"}}},
+		{"insert into empty", "", "新内容", []Op{{Insert, "新内容"}}},
+		{"delete all", "旧内容", "", []Op{{Delete, "旧内容"}}},
+		{
+			name: "single character replaced",
+			a:    "吃葡萄不吐葡萄皮",
+			b:    "吃葡萄不吐萄葡皮",
+			want: []Op{{Equal, "吃葡萄不吐"}, {Delete, "葡"}, {Equal, "萄"}, {Insert, "葡"}, {Equal, "皮"}},
+		},
+		{
+			name: "append characters",
+			a:    "八百标兵",
+			b:    "八百标兵奔北坡",
+			want: []Op{{Equal, "八百标兵"}, {Insert, "奔北坡"}},
+		},
+		{
+			name: "multi-line compares whole lines",
+			a:    "第一行\n第二行\n第三行",
+			b:    "第一行\n第二行改\n第三行",
+			want: []Op{{Equal, "第一行\n"}, {Delete, "第二行\n"}, {Insert, "第二行改\n"}, {Equal, "第三行"}},
+		},
+		{
+			name: "line added at end",
+			a:    "a\n",
+			b:    "a\nb\n",
+			want: []Op{{Equal, "a\n"}, {Insert, "b\n"}},
+		},
+		{
+			name: "single line becomes multi-line",
+			a:    "abc",
+			b:    "abc\n",
+			want: []Op{{Delete, "abc"}, {Insert, "abc\n"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// apply 用差异重建两侧文本，验证差异完整且没有相邻的同类操作
+func apply(t *testing.T, ops []Op) (string, string) {
+	t.Helper()
+	var a, b strings.Builder
+	for i, op := range ops {
+		if i > 0 && ops[i-1].Type == op.Type {
+			t.Errorf("ops[%d] and ops[%d] should have been merged: %v", i-1, i, ops)
+		}
+		switch op.Type {
+		case Equal:
+			a.WriteString(op.Text)
+			b.WriteString(op.Text)
+		case Delete:
+			a.WriteString(op.Text)
+		case Insert:
+			b.WriteString(op.Text)
+		default:
+			t.Fatalf("unknown op type %q", op.Type)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestDiffReconstructs(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"kitten", "sitting"},
+		{"红凤凰粉凤凰", "粉红凤凰花凤凰"},
+		{"一\n二\n三\n四\n", "零\n二\n三\n五\n六\n"},
+		{"", "x\ny"},
+		{"same\n", "same\n"},
+	}
+	for _, tt := range tests {
+		a, b := apply(t, Diff(tt.a, tt.b))
+		if a != tt.a || b != tt.b {
+			t.Errorf("Diff(%q, %q) reconstructs %q, %q", tt.a, tt.b, a, b)
+		}
+	}
+}
+
+func TestDiffTooLarge(t *testing.T) {
+	a := strings.Repeat("甲", 2001)
+	b := strings.Repeat("乙", 2000)
+	want := []Op{{Delete, a}, {Insert, b}}
+	if got := Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() of oversized input should replace the whole text, got %d ops", len(got))
+	}
+}
@@ -0,0 +1,72 @@
+// Package textsim 提供中文短文本的规范化与 n-gram 相似度计算，用于内容近似重复检测
+package textsim
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize 规范化文本：全角字符转半角、英文转小写，并去除空白、标点和符号，
+// 只保留文字与数字，使仅在标点或空白上不同的文本得到相同结果
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '　':
+			continue
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		}
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// Shingles 返回规范化文本的字符 n-gram 集合；文本短于 n 时整段作为一个元素
+func Shingles(normalized string, n int) map[string]struct{} {
+	runes := []rune(normalized)
+	set := make(map[string]struct{})
+	if len(runes) == 0 {
+		return set
+	}
+	if len(runes) <= n {
+		set[string(runes)] = struct{}{}
+		return set
+	}
+	for i := 0; i+n <= len(runes); i++ {
+		set[string(runes[i:i+n])] = struct{}{}
+	}
+	return set
+}
+
+// Jaccard 计算两个 n-gram 集合的 Jaccard 相似度（交集 / 并集），取值 0~1
+func Jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	inter := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	return float64(inter) / float64(union)
+}
+
+// SizeCompatible 判断两个集合的大小是否可能达到给定阈值：Jaccard ≤ min/max，可在比较前快速排除
+func SizeCompatible(sizeA, sizeB int, threshold float64) bool {
+	if sizeA > sizeB {
+		sizeA, sizeB = sizeB, sizeA
+	}
+	if sizeB == 0 {
+		return true
+	}
+	return float64(sizeA)/float64(sizeB) >= threshold
+}
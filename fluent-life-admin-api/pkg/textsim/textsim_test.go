@@ -0,0 +1,134 @@
+package textsim
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"empty", "", ""},
+		{"chinese punctuation", "吃葡萄，不吐葡萄皮。", "吃葡萄不吐葡萄皮"},
+		{"full-width letters and digits", "ＡＢＣ１２３", "abc123"},
+		{"ideographic space", "四是四　十是十", "四是四十是十"},
+		{"ascii punctuation and case", "Hello, World!  ", "helloworld"},
+		{"symbols", "一～二…三《四》😀", "一二三四"},
+		{"whitespace variants", "a\tb\nc\r d", "abcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func keys(set map[string]struct{}) []string {
+	list := make([]string, 0, len(set))
+	for k := range set {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		n    int
+		want []string
+	}{
+		{"empty", "", 2, []string{}},
+		{"shorter than n", "四", 2, []string{"四"}},
+		{"equal to n", "四是", 2, []string{"四是"}},
+		{"bigrams", "四是四", 2, []string{"四是", "是四"}},
+		{"repeated grams collapse", "aaaa", 2, []string{"aa"}},
+		{"trigrams", "abcd", 3, []string{"abc", "bcd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keys(Shingles(tt.in, tt.n)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Shingles(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	set := func(items ...string) map[string]struct{} {
+		s := make(map[string]struct{}, len(items))
+		for _, item := range items {
+			s[item] = struct{}{}
+		}
+		return s
+	}
+	tests := []struct {
+		name string
+		a, b map[string]struct{}
+		want float64
+	}{
+		{"both empty", set(), set(), 1},
+		{"one empty", set("a"), set(), 0},
+		{"identical", set("a", "b"), set("a", "b"), 1},
+		{"disjoint", set("a", "b"), set("c", "d"), 0},
+		{"half", set("a", "b", "c"), set("b", "c", "d"), 0.5},
+		{"subset", set("a"), set("a", "b", "c", "d"), 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Jaccard(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Jaccard() = %v, want %v", got, tt.want)
+			}
+			if got := Jaccard(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Jaccard() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearDuplicateTexts(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		atLeast float64
+		below   float64
+	}{
+		{"punctuation only", "八百标兵奔北坡，炮兵并排北边跑。", "八百标兵奔北坡 炮兵并排北边跑", 1, 1.01},
+		{"one character differs", "吃葡萄不吐葡萄皮，不吃葡萄倒吐葡萄皮", "吃葡萄不吐葡萄皮，不吃葡萄到吐葡萄皮", 0.6, 1},
+		{"unrelated", "吃葡萄不吐葡萄皮", "八百标兵奔北坡", 0, 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Jaccard(Shingles(Normalize(tt.a), 2), Shingles(Normalize(tt.b), 2))
+			if got < tt.atLeast || got >= tt.below {
+				t.Errorf("similarity = %v, want in [%v, %v)", got, tt.atLeast, tt.below)
+			}
+		})
+	}
+}
+
+func TestSizeCompatible(t *testing.T) {
+	tests := []struct {
+		a, b      int
+		threshold float64
+		want      bool
+	}{
+		{0, 0, 0.8, true},
+		{8, 10, 0.8, true},
+		{10, 8, 0.8, true},
+		{7, 10, 0.8, false},
+		{0, 5, 0.1, false},
+		{1, 100, 0, true},
+	}
+	for _, tt := range tests {
+		if got := SizeCompatible(tt.a, tt.b, tt.threshold); got != tt.want {
+			t.Errorf("SizeCompatible(%d, %d, %v) = %v, want %v", tt.a, tt.b, tt.threshold, got, tt.want)
+		}
+	}
+}
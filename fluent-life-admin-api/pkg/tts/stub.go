@@ -0,0 +1,92 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	stubSampleRate = 16000
+	stubToneMs     = 120 // 每个字符的时长
+	stubGapMs      = 30  // 字符间静音
+	stubMaxRunes   = 200
+)
+
+// Stub 本地模拟合成服务，不调用外部服务：每个字符生成一段音调，音高由音色与字符决定，
+// 不同音色听起来可以区分，用于开发与联调
+type Stub struct{}
+
+func NewStub() *Stub {
+	return &Stub{}
+}
+
+func (s *Stub) Name() string {
+	return ProviderStub
+}
+
+func (s *Stub) Synthesize(ctx context.Context, req *Request) (*Result, error) {
+	text := []rune(strings.TrimSpace(req.Text))
+	if len(text) == 0 {
+		return nil, errors.New("合成文本为空")
+	}
+	if len(text) > stubMaxRunes {
+		text = text[:stubMaxRunes]
+	}
+	// 音色决定基准音高：男声 110-170Hz，其余 190-290Hz
+	voice := sha256.Sum256([]byte(req.Voice))
+	base := 190 + float64(voice[0]%100)
+	if strings.Contains(req.Voice, "_male_") {
+		base = 110 + float64(voice[0]%60)
+	}
+
+	toneSamples := stubSampleRate * stubToneMs / 1000
+	gapSamples := stubSampleRate * stubGapMs / 1000
+	pcm := make([]int16, 0, len(text)*(toneSamples+gapSamples))
+	for i, r := range text {
+		if i%20 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			pcm = append(pcm, make([]int16, toneSamples+gapSamples)...)
+			continue
+		}
+		freq := base * (1 + float64(int(r)%7)/12)
+		for n := 0; n < toneSamples; n++ {
+			// 首尾淡入淡出，避免爆音
+			env := math.Min(1, math.Min(float64(n), float64(toneSamples-n))/float64(stubSampleRate/200))
+			v := 0.3 * env * math.Sin(2*math.Pi*freq*float64(n)/stubSampleRate)
+			pcm = append(pcm, int16(v*math.MaxInt16))
+		}
+		pcm = append(pcm, make([]int16, gapSamples)...)
+	}
+	return &Result{Audio: wav(pcm), ContentType: "audio/wav", Ext: ".wav"}, nil
+}
+
+// wav 16 位单声道 PCM 的 WAV 文件
+func wav(pcm []int16) []byte {
+	dataSize := uint32(len(pcm) * 2)
+	var buf bytes.Buffer
+	buf.Grow(44 + int(dataSize))
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))             // fmt 块大小
+	binary.Write(&buf, binary.LittleEndian, uint16(1))              // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1))              // 单声道
+	binary.Write(&buf, binary.LittleEndian, uint32(stubSampleRate)) // 采样率
+	binary.Write(&buf, binary.LittleEndian, uint32(stubSampleRate*2))
+	binary.Write(&buf, binary.LittleEndian, uint16(2))  // 每帧字节数
+	binary.Write(&buf, binary.LittleEndian, uint16(16)) // 位深
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+	binary.Write(&buf, binary.LittleEndian, pcm)
+	return buf.Bytes()
+}
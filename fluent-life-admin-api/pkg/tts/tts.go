@@ -0,0 +1,42 @@
+// Package tts 语音合成接口，合成服务可替换
+package tts
+
+import (
+	"context"
+	"fmt"
+)
+
+// Request 一次合成请求
+type Request struct {
+	Voice string // 音色类型，即 voice_types.type
+	Text  string
+}
+
+// Result 合成的音频
+type Result struct {
+	Audio       []byte
+	ContentType string // 如 audio/wav
+	Ext         string // 文件扩展名，如 .wav
+}
+
+// Provider 语音合成服务
+type Provider interface {
+	// Name 合成服务名称；名称变化时已缓存的试听音频失效
+	Name() string
+	Synthesize(ctx context.Context, req *Request) (*Result, error)
+}
+
+// 合成服务名称
+const (
+	ProviderStub = "stub"
+)
+
+// New 按名称创建合成服务
+func New(name string) (Provider, error) {
+	switch name {
+	case "", ProviderStub:
+		return NewStub(), nil
+	default:
+		return nil, fmt.Errorf("不支持的语音合成服务: %s", name)
+	}
+}
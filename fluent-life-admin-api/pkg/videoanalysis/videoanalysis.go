@@ -0,0 +1,66 @@
+// Package videoanalysis 脱敏练习视频的 AI 分析接口，分析器可替换
+package videoanalysis
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// 分析维度，与脱敏练习"AI分析"步骤配置中的 dimensions 一致
+const (
+	DimensionFluency     = "fluency"
+	DimensionPace        = "pace"
+	DimensionEyeContact  = "eye_contact"
+	DimensionFillerWords = "filler_words"
+	DimensionConfidence  = "confidence"
+)
+
+// Dimensions 全部分析维度
+var Dimensions = []string{DimensionFluency, DimensionPace, DimensionEyeContact, DimensionFillerWords, DimensionConfidence}
+
+// DimensionLabels 分析维度的中文名
+var DimensionLabels = map[string]string{
+	DimensionFluency:     "流畅度",
+	DimensionPace:        "语速",
+	DimensionEyeContact:  "眼神交流",
+	DimensionFillerWords: "口头禅控制",
+	DimensionConfidence:  "自信程度",
+}
+
+// Request 一次分析请求
+type Request struct {
+	Video       io.Reader // 视频内容
+	ContentType string
+	Prompt      string   // 系统提示词
+	Dimensions  []string // 需要评分的维度
+}
+
+// Result 分析结果，Scores 为各维度 0-100 分
+type Result struct {
+	Model   string
+	Verdict string
+	Scores  map[string]float64
+}
+
+// Analyzer 视频分析器
+type Analyzer interface {
+	// Name 分析器名称，记录在分析结果中
+	Name() string
+	Analyze(ctx context.Context, req *Request) (*Result, error)
+}
+
+// 分析器名称
+const (
+	AnalyzerStub = "stub"
+)
+
+// New 按名称创建分析器
+func New(name string) (Analyzer, error) {
+	switch name {
+	case "", AnalyzerStub:
+		return NewStub(), nil
+	default:
+		return nil, fmt.Errorf("不支持的视频分析器: %s", name)
+	}
+}
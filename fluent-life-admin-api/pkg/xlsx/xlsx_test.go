@@ -0,0 +1,191 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := ColumnName(tt.index); got != tt.want {
+			t.Errorf("ColumnName(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+		if got, ok := columnIndex(tt.want + "12"); !ok || got != tt.index {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d", tt.want+"12", got, ok, tt.index)
+		}
+	}
+	if _, ok := columnIndex("12"); ok {
+		t.Error("columnIndex(\"12\") should fail without a column letter")
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "你好 world", "你好 world"},
+		{"keeps whitespace", "a\tb\nc\rd", "a\tb\nc\rd"},
+		{"drops control chars", "a\x00b\x07c\x1f", "abc"},
+		{"truncates", strings.Repeat("字", 32768), strings.Repeat("字", 32767)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize(tt.in); got != tt.want {
+				t.Errorf("sanitize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteThenRead(t *testing.T) {
+	rows := [][]string{
+		{"ID", "名称", "备注"},
+		{"1", "<a & b>", "多行\n文本"},
+		{"2", "  前后空格  ", ""},
+		{"3", "=1+1", "'@x"},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "用户 & 列表")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader(rows[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows[1:] {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("ReadRows() = %q, want %q", got, rows)
+	}
+}
+
+// buildXLSX 按给定的 zip 条目构造工作簿，用于模拟其他软件生成的文件
+func buildXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const testWorkbook = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="S" sheetId="1" r:id="rId3"/></sheets></workbook>`
+
+func TestReadRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  [][]string
+	}{
+		{
+			name: "shared strings, rich text, sparse cells and skipped rows",
+			files: map[string]string{
+				"xl/workbook.xml":            testWorkbook,
+				"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId3" Target="worksheets/data.xml"/></Relationships>`,
+				"xl/sharedStrings.xml":       `<sst><si><t>标题</t></si><si><r><t>富</t></r><r><t>文本</t></r></si></sst>`,
+				"xl/worksheets/data.xml": `<worksheet><sheetData>` +
+					`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+					`<row r="3"><c r="B3"><v>42</v></c><c r="C3" t="b"><v>1</v></c><c r="D3" t="b"><v>0</v></c></row>` +
+					`</sheetData></worksheet>`,
+			},
+			want: [][]string{
+				{"标题", "", "富文本"},
+				{},
+				{"", "42", "true", "false"},
+			},
+		},
+		{
+			name: "absolute relationship target",
+			files: map[string]string{
+				"xl/workbook.xml":            testWorkbook,
+				"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId3" Target="/xl/worksheets/sheet9.xml"/></Relationships>`,
+				"xl/worksheets/sheet9.xml":   `<worksheet><sheetData><row><c t="inlineStr"><is><t>x</t></is></c><c><v>1</v></c></row></sheetData></worksheet>`,
+			},
+			want: [][]string{{"x", "1"}},
+		},
+		{
+			name: "missing relationships falls back to sheet1",
+			files: map[string]string{
+				"xl/workbook.xml":          testWorkbook,
+				"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1"><v>a</v></c></row></sheetData></worksheet>`,
+			},
+			want: [][]string{{"a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildXLSX(t, tt.files)
+			got, err := ReadRows(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadRows() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRowsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		files map[string]string
+	}{
+		{name: "not a zip", data: []byte("name,age\n")},
+		{name: "no workbook", files: map[string]string{"xl/worksheets/sheet1.xml": `<worksheet/>`}},
+		{name: "no sheets", files: map[string]string{"xl/workbook.xml": `<workbook><sheets/></workbook>`}},
+		{name: "missing sheet", files: map[string]string{"xl/workbook.xml": testWorkbook}},
+		{name: "bad shared string index", files: map[string]string{
+			"xl/workbook.xml":          testWorkbook,
+			"xl/sharedStrings.xml":     `<sst><si><t>a</t></si></sst>`,
+			"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>5</v></c></row></sheetData></worksheet>`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data
+			if data == nil {
+				data = buildXLSX(t, tt.files)
+			}
+			if _, err := ReadRows(bytes.NewReader(data), int64(len(data))); err == nil {
+				t.Error("ReadRows() error = nil, want error")
+			}
+		})
+	}
+}